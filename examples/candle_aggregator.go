@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/therox/invest-api-go-sdk/investgo"
+	"go.uber.org/zap"
+)
+
+func main() {
+	// Загружаем конфигурацию для сдк
+	config, err := investgo.LoadConfig("config.yaml")
+	if err != nil {
+		log.Printf("Config loading error %v", err.Error())
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	prod, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("logger creating error %e", err)
+	}
+	logger := prod.Sugar()
+
+	client, err := investgo.NewClient(ctx, config, logger)
+	if err != nil {
+		logger.Fatalf("Client creating error %v", err.Error())
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := client.Shutdown(shutdownCtx)
+		if err != nil {
+			logger.Errorf("client shutdown error %v", err.Error())
+		}
+	}()
+
+	mdStream, err := client.NewMDStreamClient().MarketDataStream()
+	if err != nil {
+		logger.Fatalf(err.Error())
+	}
+	trades, err := mdStream.SubscribeTrade([]string{"BBG004730N88"})
+	if err != nil {
+		logger.Errorf(err.Error())
+	}
+	go func() {
+		err := mdStream.Listen()
+		if err != nil {
+			logger.Errorf(err.Error())
+		}
+	}()
+
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		logger.Fatalf(err.Error())
+	}
+	// 7-минутные свечи, выровненные по началу основной сессии, и свечи по 1000 лотов
+	aggregator, err := investgo.NewCandleAggregator(investgo.CandleAggregatorOptions{
+		Bars: []investgo.BarSpec{investgo.TimeBars(7 * time.Minute), investgo.VolumeBars(1000)},
+		Session: investgo.SessionSchedule{
+			Location: moscow,
+			Open:     10 * time.Hour,
+			Close:    18*time.Hour + 50*time.Minute,
+		},
+	})
+	if err != nil {
+		logger.Fatalf(err.Error())
+	}
+	go aggregator.Run(trades, nil)
+
+	go func() {
+		for e := range aggregator.Events() {
+			if e.Type == investgo.CandleClosed {
+				fmt.Println("candle closed", e.Candle.Start, "close = ", e.Candle.Close.ToFloat(), "volume = ", e.Candle.Volume)
+			}
+		}
+	}()
+
+	<-signals
+}
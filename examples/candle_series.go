@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/therox/invest-api-go-sdk/investgo"
+	pb "github.com/therox/invest-api-go-sdk/proto"
+	"go.uber.org/zap"
+)
+
+func main() {
+	// Загружаем конфигурацию для сдк
+	config, err := investgo.LoadConfig("config.yaml")
+	if err != nil {
+		log.Printf("Config loading error %v", err.Error())
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	prod, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("logger creating error %e", err)
+	}
+	logger := prod.Sugar()
+
+	client, err := investgo.NewClient(ctx, config, logger)
+	if err != nil {
+		logger.Fatalf("Client creating error %v", err.Error())
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := client.Shutdown(shutdownCtx)
+		if err != nil {
+			logger.Errorf("client shutdown error %v", err.Error())
+		}
+	}()
+
+	mdStream, err := client.NewMDStreamClient().MarketDataStream()
+	if err != nil {
+		logger.Fatalf(err.Error())
+	}
+	go func() {
+		err := mdStream.Listen()
+		if err != nil {
+			logger.Errorf(err.Error())
+		}
+	}()
+
+	// 50 последних закрытых минутных свечей и формирующаяся свеча, история загружается после подписки,
+	// поэтому свечи на стыке истории и стрима не теряются и не дублируются
+	series, err := investgo.NewCandleSeries(client.NewMarketDataServiceClient(), investgo.CandleSeriesOptions{
+		InstrumentId: "BBG004730N88",
+		Interval:     pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE,
+		Window:       50,
+		OnClosed: func(c *pb.HistoricCandle) {
+			fmt.Println("candle closed", c.GetTime().AsTime(), "close = ", c.GetClose().ToFloat())
+		},
+	})
+	if err != nil {
+		logger.Fatalf(err.Error())
+	}
+	go func() {
+		err := series.Attach(ctx, mdStream, mdStream.Candles())
+		if err != nil {
+			logger.Errorf(err.Error())
+		}
+	}()
+
+	<-signals
+	fmt.Println("candles in series = ", len(series.Candles()))
+}
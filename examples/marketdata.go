@@ -65,7 +65,10 @@ func main() {
 		}
 	}
 
-	tradingStatusResp, err := MarketDataService.GetTradingStatus(instruments[1])
+	// у каждого метода есть вариант с суффиксом Ctx, контекст задает дедлайн и отмену отдельного запроса
+	reqCtx, reqCancel := context.WithTimeout(ctx, 5*time.Second)
+	tradingStatusResp, err := MarketDataService.GetTradingStatusCtx(reqCtx, instruments[1])
+	reqCancel()
 	if err != nil {
 		logger.Error(err.Error())
 	} else {
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/therox/invest-api-go-sdk/investgo"
+	pb "github.com/therox/invest-api-go-sdk/proto"
+	"go.uber.org/zap"
+)
+
+func main() {
+	// Загружаем конфигурацию для сдк
+	config, err := investgo.LoadConfig("config.yaml")
+	if err != nil {
+		log.Printf("Config loading error %v", err.Error())
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	prod, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("logger creating error %e", err)
+	}
+	logger := prod.Sugar()
+
+	client, err := investgo.NewClient(ctx, config, logger)
+	if err != nil {
+		logger.Fatalf("Client creating error %v", err.Error())
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := client.Shutdown(shutdownCtx)
+		if err != nil {
+			logger.Errorf("client shutdown error %v", err.Error())
+		}
+	}()
+
+	mdStream, err := client.NewMDStreamClient().MarketDataStream()
+	if err != nil {
+		logger.Fatalf(err.Error())
+	}
+	instruments := []string{"BBG004730N88", "BBG00475KKY8", "BBG004RVFCY3"}
+	_, err = mdStream.SubscribeLastPrice(instruments)
+	if err != nil {
+		logger.Errorf(err.Error())
+	}
+	_, err = mdStream.SubscribeOrderBook(instruments[:1], 20)
+	if err != nil {
+		logger.Errorf(err.Error())
+	}
+	go func() {
+		err := mdStream.Listen()
+		if err != nil {
+			logger.Errorf(err.Error())
+		}
+	}()
+
+	// диспетчер читает все каналы стрима и раздает данные потребителям, у каждого потребителя свой буфер,
+	// поэтому медленный потребитель не задерживает остальных
+	dispatcher := investgo.NewMDDispatcher()
+	go dispatcher.Run(mdStream)
+
+	// для стакана важна только актуальная информация, старые стаканы можно выбрасывать
+	orderBooks := dispatcher.OrderBooks(instruments[:1], investgo.ConsumerOptions{
+		BufferSize: 1,
+		Overflow:   investgo.OverflowDropOldest,
+	})
+	go func() {
+		for ob := range orderBooks.C() {
+			fmt.Println("order book bids = ", len(ob.GetBids()), "dropped = ", orderBooks.Dropped())
+		}
+	}()
+
+	// callback вызывается в отдельной горутине потребителя для каждой последней цены по всем инструментам
+	lastPrices := dispatcher.OnLastPrice(nil, investgo.ConsumerOptions{}, func(lp *pb.LastPrice) {
+		fmt.Println(lp.GetFigi(), "last price = ", lp.GetPrice().ToFloat())
+	})
+	defer lastPrices.Close()
+
+	<-signals
+}
@@ -8,6 +8,7 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/therox/invest-api-go-sdk/investgo"
 	pb "github.com/therox/invest-api-go-sdk/proto"
@@ -46,8 +47,12 @@ func main() {
 		logger.Errorf("Client creating error %v", err.Error())
 	}
 	defer func() {
+		// Shutdown отписывается от маркетдаты, останавливает стримы клиента, дожидается
+		// закрытия их каналов и закрывает соединение, но не дольше заданного времени
 		logger.Infof("Closing client connection")
-		err := client.Stop()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := client.Shutdown(shutdownCtx)
 		if err != nil {
 			logger.Errorf("client shutdown error %v", err.Error())
 		}
@@ -72,10 +77,42 @@ func main() {
 		logger.Errorf(err.Error())
 	}
 
-	tradesChan, err := firstMDStream.SubscribeTrade(firstInstrumetsGroup)
+	// Async методы подписки возвращают ожидание ответа сервера со статусами по каждому инструменту,
+	// инструменты с ошибкой не сохраняются в подписках стрима. Ответ обрабатывается в Listen,
+	// данные приходят в канал, который возвращают методы Trades, Candles и т.д.
+	tradesSub, err := firstMDStream.SubscribeTradeAsync(firstInstrumetsGroup)
 	if err != nil {
 		logger.Errorf(err.Error())
 	}
+	tradesChan := firstMDStream.Trades()
+	go func() {
+		res, err := tradesSub.Wait(ctx)
+		if err != nil {
+			logger.Errorf("trades subscription error %v", err.Error())
+			return
+		}
+		if err := res.Err(); err != nil {
+			logger.Errorf(err.Error())
+		}
+	}()
+
+	// при обрыве соединения стрим переподключается и восстанавливает подписки, каналы при этом не закрываются.
+	// политику переподключения можно изменить до вызова Listen
+	reconnectPolicy := investgo.DefaultReconnectPolicy()
+	reconnectPolicy.OnReconnect = func(attempt int, err error) {
+		logger.Infof("md stream reconnect attempt %v, err = %v", attempt, err)
+	}
+	firstMDStream.SetReconnectPolicy(reconnectPolicy)
+
+	// сервер периодически отправляет в стрим Ping, если он не приходит дольше StaleTimeout, стрим считается
+	// зависшим и переоткрывается. Статистику активности стрима можно получить методом Health
+	firstMDStream.SetWatchdog(investgo.WatchdogOptions{
+		StaleTimeout:   5 * time.Minute,
+		ForceReconnect: true,
+		OnStale: func(health investgo.StreamHealth) {
+			logger.Infof("md stream is stale, last ping at %v", health.LastPing)
+		},
+	})
 
 	// функцию Listen нужно вызвать один раз для каждого стрима и в отдельной горутине
 	// для останвки стрима можно использовать метод Stop, он отменяет контекст внутри стрима
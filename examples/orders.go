@@ -87,6 +87,10 @@ func main() {
 	})
 	if err != nil {
 		logger.Errorf("post order %v\n", err.Error())
+		// ошибки сервисов имеют тип *investgo.APIError с кодом ошибки INVEST API и ее категорией
+		if apiErr, ok := investgo.AsAPIError(err); ok {
+			fmt.Printf("code = %v, category = %v, retryable = %v\n", apiErr.Code, apiErr.Category, investgo.IsRetryable(err))
+		}
 	} else {
 		fmt.Printf("post order resp = %v\n", postResp.GetExecutionReportStatus().String())
 	}
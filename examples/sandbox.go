@@ -146,4 +146,17 @@ func main() {
 		fmt.Printf("order status = %v\n", sellResp.GetExecutionReportStatus().String())
 	}
 
+	// Trader объединяет торговые операции песочницы и боевого контура, реализация выбирается по конфигу
+	// (Sandbox: true или EndPoint песочницы), поэтому код стратегии не меняется при переходе в боевой контур
+	trader, err := client.NewTrader()
+	if err != nil {
+		logger.Errorf(err.Error())
+		return
+	}
+	portfolioResp, err := trader.GetPortfolio(newAccId, pb.PortfolioRequest_RUB)
+	if err != nil {
+		logger.Errorf(err.Error())
+	} else {
+		fmt.Printf("portfolio total amount = %v\n", portfolioResp.GetTotalAmountPortfolio().ToFloat())
+	}
 }
@@ -0,0 +1,519 @@
+package investgo
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+// BarType - способ формирования свечей агрегатора
+type BarType int
+
+const (
+	// BarTime - свечи произвольного интервала, выровненные по началу торговой сессии
+	BarTime BarType = iota
+	// BarTick - свечи из заданного количества сделок, строятся только по сделкам
+	BarTick
+	// BarVolume - свечи из заданного количества лотов
+	BarVolume
+	// BarTurnover - свечи с заданным оборотом, оборот считается как цена за инструмент, умноженная на количество лотов
+	BarTurnover
+)
+
+// BarSpec - параметры свечей агрегатора, создается функциями TimeBars, TickBars, VolumeBars и TurnoverBars
+type BarSpec struct {
+	Type BarType
+	// Interval - интервал свечи для BarTime
+	Interval time.Duration
+	// Threshold - количество сделок для BarTick или лотов для BarVolume
+	Threshold int64
+	// Turnover - оборот свечи для BarTurnover
+	Turnover *pb.Quotation
+}
+
+// TimeBars - свечи интервала interval, например 7 или 90 минут
+func TimeBars(interval time.Duration) BarSpec {
+	return BarSpec{Type: BarTime, Interval: interval}
+}
+
+// TickBars - свечи из trades сделок
+func TickBars(trades int64) BarSpec {
+	return BarSpec{Type: BarTick, Threshold: trades}
+}
+
+// VolumeBars - свечи объемом не меньше lots лотов
+func VolumeBars(lots int64) BarSpec {
+	return BarSpec{Type: BarVolume, Threshold: lots}
+}
+
+// TurnoverBars - свечи с оборотом не меньше turnover. Оборот считается в ценах за инструмент, для оборота
+// в валюте turnover нужно разделить на лотность инструмента
+func TurnoverBars(turnover *pb.Quotation) BarSpec {
+	return BarSpec{Type: BarTurnover, Turnover: turnover}
+}
+
+func (s BarSpec) validate() error {
+	switch s.Type {
+	case BarTime:
+		if s.Interval <= 0 {
+			return fmt.Errorf("bar interval must be positive, got %v", s.Interval)
+		}
+	case BarTick, BarVolume:
+		if s.Threshold <= 0 {
+			return fmt.Errorf("bar threshold must be positive, got %v", s.Threshold)
+		}
+	case BarTurnover:
+		if s.Turnover.Sign() <= 0 {
+			return fmt.Errorf("bar turnover must be positive, got %v", s.Turnover.ToString())
+		}
+	default:
+		return fmt.Errorf("unexpected bar type %v", s.Type)
+	}
+	return nil
+}
+
+// SessionSchedule - расписание торговой сессии, по которому выравниваются свечи агрегатора.
+// Open и Close задаются смещением от начала дня в Location, при Close <= Open сессия заканчивается
+// на следующий день. При нулевых Open и Close сессия длится сутки
+type SessionSchedule struct {
+	// Location - часовой пояс расписания, nil - UTC
+	Location *time.Location
+	Open     time.Duration
+	Close    time.Duration
+}
+
+// session - начало и конец сессии, в которую попадает t, false - t вне сессии
+func (s SessionSchedule) session(t time.Time) (time.Time, time.Time, bool) {
+	loc := s.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	length := s.Close - s.Open
+	if length <= 0 {
+		length += 24 * time.Hour
+	}
+	local := t.In(loc)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	// сессия могла начаться накануне
+	for _, d := range []time.Time{day, day.AddDate(0, 0, -1)} {
+		start := d.Add(s.Open)
+		end := start.Add(length)
+		if !t.Before(start) && t.Before(end) {
+			return start, end, true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// CandleEventType - тип события агрегатора
+type CandleEventType int
+
+const (
+	// CandleUpdated - обновление незакрытой свечи
+	CandleUpdated CandleEventType = iota
+	// CandleClosed - свеча закрыта и больше не изменится
+	CandleClosed
+)
+
+// CandleEvent - событие агрегатора
+type CandleEvent struct {
+	Type   CandleEventType
+	Candle AggregatedCandle
+}
+
+// AggregatedCandle - свеча, построенная агрегатором
+type AggregatedCandle struct {
+	Figi          string
+	InstrumentUid string
+	Bar           BarSpec
+	Open          *pb.Quotation
+	High          *pb.Quotation
+	Low           *pb.Quotation
+	Close         *pb.Quotation
+	// Volume - объем в лотах
+	Volume int64
+	// Turnover - оборот, цена за инструмент, умноженная на количество лотов. Для свечей из минутных свечей
+	// оценивается по цене закрытия минутной свечи
+	Turnover *pb.Quotation
+	// Trades - количество сделок, 0 для свечей из минутных свечей
+	Trades int64
+	// Start - время начала свечи, для BarTime - начало интервала
+	Start time.Time
+	// End - для BarTime время окончания интервала, для остальных свечей - время последних данных
+	End time.Time
+	// LastTradeTs - время последних данных, вошедших в свечу
+	LastTradeTs time.Time
+}
+
+// candlePiece - сделка или минутная свеча, добавляемая в свечу агрегатора
+type candlePiece struct {
+	open, high, low, close *pb.Quotation
+	volume                 int64
+	turnover               *pb.Quotation
+	trades                 int64
+	time                   time.Time
+	last                   time.Time
+}
+
+func tradePiece(t *pb.Trade) candlePiece {
+	price := t.GetPrice()
+	ts := t.GetTime().AsTime()
+	return candlePiece{
+		open:     price,
+		high:     price,
+		low:      price,
+		close:    price,
+		volume:   t.GetQuantity(),
+		turnover: saturated(price.MulInt(t.GetQuantity())),
+		trades:   1,
+		time:     ts,
+		last:     ts,
+	}
+}
+
+func minuteCandlePiece(c *pb.Candle) candlePiece {
+	last := c.GetLastTradeTs().AsTime()
+	if c.GetLastTradeTs() == nil {
+		last = c.GetTime().AsTime()
+	}
+	return candlePiece{
+		open:     c.GetOpen(),
+		high:     c.GetHigh(),
+		low:      c.GetLow(),
+		close:    c.GetClose(),
+		volume:   c.GetVolume(),
+		turnover: saturated(c.GetClose().MulInt(c.GetVolume())),
+		time:     c.GetTime().AsTime(),
+		last:     last,
+	}
+}
+
+// saturated - оборот или MaxQuotation при переполнении. Оборот не уменьшается, поэтому переполненный
+// оборот больше любого порога BarTurnover и свеча закрывается
+func saturated(turnover *pb.Quotation, err error) *pb.Quotation {
+	if err != nil {
+		return &pb.Quotation{Units: pb.MaxQuotation.GetUnits(), Nano: pb.MaxQuotation.GetNano()}
+	}
+	return turnover
+}
+
+// merge - свеча c с добавленными данными p
+func (c AggregatedCandle) merge(p candlePiece) AggregatedCandle {
+	if c.Open == nil {
+		c.Open, c.High, c.Low = p.open, p.high, p.low
+		c.Turnover = pb.NewQuotation(0, 0)
+	}
+	if p.high.Cmp(c.High) > 0 {
+		c.High = p.high
+	}
+	if p.low.Cmp(c.Low) < 0 {
+		c.Low = p.low
+	}
+	c.Close = p.close
+	c.Volume += p.volume
+	c.Turnover = saturated(c.Turnover.Add(p.turnover))
+	c.Trades += p.trades
+	if p.last.After(c.LastTradeTs) {
+		c.LastTradeTs = p.last
+	}
+	if c.Bar.Type != BarTime {
+		c.End = c.LastTradeTs
+	}
+	return c
+}
+
+// full - набран объем, количество сделок или оборот свечи
+func (c AggregatedCandle) full() bool {
+	switch c.Bar.Type {
+	case BarTick:
+		return c.Trades >= c.Bar.Threshold
+	case BarVolume:
+		return c.Volume >= c.Bar.Threshold
+	case BarTurnover:
+		return c.Turnover.Cmp(c.Bar.Turnover) >= 0
+	default:
+		return false
+	}
+}
+
+// CandleAggregatorOptions - параметры агрегатора свечей
+type CandleAggregatorOptions struct {
+	// Bars - свечи, которые строятся по каждому инструменту
+	Bars []BarSpec
+	// Session - расписание торговой сессии, данные вне сессии игнорируются, свечи закрываются в конце сессии
+	Session SessionSchedule
+	// ClosedOnly - отправлять только события CandleClosed
+	ClosedOnly bool
+	// BufferSize - размер буфера канала событий, 0 - DefaultConsumerBufferSize
+	BufferSize int
+}
+
+// CandleAggregator - построение свечей произвольных интервалов, тиковых свечей, свечей по объему и обороту
+// из обезличенных сделок или минутных свечей. По каждому изменению свечи отправляется событие CandleUpdated,
+// по закрытию - CandleClosed. Свечи по времени закрываются при получении данных следующего интервала или
+// в Advance по окончании интервала, остальные свечи - при наборе порога или в конце торговой сессии
+type CandleAggregator struct {
+	opts   CandleAggregatorOptions
+	events chan CandleEvent
+	// done - закрывается в начале Close, прерывает отправку события, ожидающую читателя, под mu
+	done chan struct{}
+
+	mu          sync.Mutex
+	instruments map[string]*aggregatedInstrument
+	closed      bool
+	closeOnce   sync.Once
+}
+
+// aggregatedInstrument - свечи агрегатора по одному инструменту
+type aggregatedInstrument struct {
+	figi, uid string
+	// bars - текущие свечи по BarSpec из опций, nil - свеча не начата
+	bars []*AggregatedCandle
+	// pending - последнее состояние текущей минутной свечи, добавляется в свечи после окончания минуты
+	pending *pb.Candle
+	// folded - время начала последней минутной свечи, добавленной в свечи
+	folded time.Time
+}
+
+// NewCandleAggregator - создание агрегатора, данные передаются методами AddTrade, AddCandle или Run
+func NewCandleAggregator(opts CandleAggregatorOptions) (*CandleAggregator, error) {
+	if len(opts.Bars) == 0 {
+		return nil, errors.New("no bars specified")
+	}
+	for _, bar := range opts.Bars {
+		if err := bar.validate(); err != nil {
+			return nil, err
+		}
+	}
+	size := opts.BufferSize
+	if size <= 0 {
+		size = DefaultConsumerBufferSize
+	}
+	return &CandleAggregator{
+		opts:        opts,
+		events:      make(chan CandleEvent, size),
+		done:        make(chan struct{}),
+		instruments: make(map[string]*aggregatedInstrument, 0),
+	}, nil
+}
+
+// Events - канал событий агрегатора, закрывается после Close или завершения Run. Канал нужно читать,
+// иначе добавление данных будет заблокировано
+func (a *CandleAggregator) Events() <-chan CandleEvent {
+	return a.events
+}
+
+// Run - чтение сделок и минутных свечей до закрытия обоих каналов, nil канал не читается. Раз в секунду
+// вызывается Advance, чтобы закрывать свечи без новых данных. После завершения канал событий закрывается
+func (a *CandleAggregator) Run(trades <-chan *pb.Trade, candles <-chan *pb.Candle) {
+	defer a.Close()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for trades != nil || candles != nil {
+		select {
+		case t, ok := <-trades:
+			if !ok {
+				trades = nil
+				continue
+			}
+			a.AddTrade(t)
+		case c, ok := <-candles:
+			if !ok {
+				candles = nil
+				continue
+			}
+			a.AddCandle(c)
+		case now := <-ticker.C:
+			a.Advance(now)
+		}
+	}
+}
+
+// AddTrade - добавление обезличенной сделки во все свечи инструмента, тиковые свечи строятся только по сделкам
+func (a *CandleAggregator) AddTrade(t *pb.Trade) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return
+	}
+	inst := a.instrument(t.GetFigi(), t.GetInstrumentUid())
+	a.add(inst, tradePiece(t), false)
+}
+
+// AddCandle - добавление минутной свечи, например из SubscribeCandle. Незакрытая минутная свеча может
+// приходить несколько раз, в свечи агрегатора она добавляется один раз после окончания минуты, а до этого
+// учитывается только в событиях CandleUpdated. Свечи других интервалов игнорируются
+func (a *CandleAggregator) AddCandle(c *pb.Candle) {
+	if c.GetInterval() != pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE &&
+		c.GetInterval() != pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_UNSPECIFIED {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return
+	}
+	inst := a.instrument(c.GetFigi(), c.GetInstrumentUid())
+	ts := c.GetTime().AsTime()
+	if !inst.folded.IsZero() && !ts.After(inst.folded) {
+		// обновление уже добавленной минуты
+		return
+	}
+	if inst.pending != nil {
+		pendingTime := inst.pending.GetTime().AsTime()
+		if ts.Before(pendingTime) {
+			return
+		}
+		if ts.After(pendingTime) {
+			a.fold(inst)
+		}
+	}
+	inst.pending = c
+	a.add(inst, minuteCandlePiece(c), true)
+}
+
+// Advance - закрытие свечей, интервал или торговая сессия которых закончились к моменту now, и добавление
+// минутных свечей, минута которых закончилась
+func (a *CandleAggregator) Advance(now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return
+	}
+	for _, inst := range a.instruments {
+		if inst.pending != nil && !now.Before(inst.pending.GetTime().AsTime().Add(time.Minute)) {
+			a.fold(inst)
+		}
+		for i, bar := range inst.bars {
+			if bar != nil && !now.Before(a.deadline(*bar)) {
+				a.closeBar(inst, i)
+			}
+		}
+	}
+}
+
+// Close - закрытие канала событий, незакрытые свечи не отправляются. Не блокируется на медленном читателе:
+// событие, ожидающее отправки в заполненный канал, отбрасывается
+func (a *CandleAggregator) Close() {
+	a.closeOnce.Do(func() {
+		close(a.done)
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		a.closed = true
+		close(a.events)
+	})
+}
+
+// instrument - свечи инструмента, инструмент определяется по instrument_uid, а при его отсутствии по figi
+func (a *CandleAggregator) instrument(figi, uid string) *aggregatedInstrument {
+	key := uid
+	if key == "" {
+		key = figi
+	}
+	inst, ok := a.instruments[key]
+	if !ok {
+		inst = &aggregatedInstrument{
+			figi: figi,
+			uid:  uid,
+			bars: make([]*AggregatedCandle, len(a.opts.Bars)),
+		}
+		a.instruments[key] = inst
+	}
+	return inst
+}
+
+// fold - добавление текущей минутной свечи в свечи инструмента
+func (a *CandleAggregator) fold(inst *aggregatedInstrument) {
+	pending := inst.pending
+	inst.pending = nil
+	inst.folded = pending.GetTime().AsTime()
+	a.add(inst, minuteCandlePiece(pending), false)
+}
+
+// add - добавление данных p во все свечи инструмента. preview - p является незакрытой минутной свечой,
+// свечи не изменяются, отправляются только события CandleUpdated с учетом p
+func (a *CandleAggregator) add(inst *aggregatedInstrument, p candlePiece, preview bool) {
+	start, end, ok := a.opts.Session.session(p.time)
+	if !ok {
+		return
+	}
+	for i, spec := range a.opts.Bars {
+		if spec.Type == BarTick && p.trades == 0 {
+			continue
+		}
+		barStart, barEnd := start, end
+		if spec.Type == BarTime {
+			barStart = start.Add(p.time.Sub(start) / spec.Interval * spec.Interval)
+			barEnd = barStart.Add(spec.Interval)
+			if barEnd.After(end) {
+				barEnd = end
+			}
+		}
+		if bar := inst.bars[i]; bar != nil && spec.Type == BarTime && p.time.Before(bar.Start) {
+			// данные уже закрытого интервала
+			continue
+		}
+		// данные следующего интервала или следующей сессии закрывают текущую свечу
+		if bar := inst.bars[i]; bar != nil && !p.time.Before(a.deadline(*bar)) {
+			a.closeBar(inst, i)
+		}
+		bar := inst.bars[i]
+		if bar == nil {
+			bar = &AggregatedCandle{
+				Figi:          inst.figi,
+				InstrumentUid: inst.uid,
+				Bar:           spec,
+				Start:         barStart,
+				End:           barEnd,
+			}
+			if spec.Type != BarTime {
+				bar.Start = p.time
+			}
+		}
+		merged := bar.merge(p)
+		if preview {
+			a.emit(CandleUpdated, merged)
+			continue
+		}
+		inst.bars[i] = &merged
+		if merged.full() {
+			a.closeBar(inst, i)
+			continue
+		}
+		a.emit(CandleUpdated, merged)
+	}
+}
+
+// deadline - время, не позднее которого свеча должна быть закрыта
+func (a *CandleAggregator) deadline(c AggregatedCandle) time.Time {
+	if c.Bar.Type == BarTime {
+		return c.End
+	}
+	_, end, _ := a.opts.Session.session(c.Start)
+	return end
+}
+
+func (a *CandleAggregator) closeBar(inst *aggregatedInstrument, i int) {
+	bar := inst.bars[i]
+	inst.bars[i] = nil
+	a.send(CandleEvent{Type: CandleClosed, Candle: *bar})
+}
+
+func (a *CandleAggregator) emit(t CandleEventType, c AggregatedCandle) {
+	if a.opts.ClosedOnly && t != CandleClosed {
+		return
+	}
+	a.send(CandleEvent{Type: t, Candle: c})
+}
+
+// send - отправка события в канал, вызывается под a.mu. Отправка прерывается в Close, чтобы Close
+// мог взять a.mu и закрыть канал
+func (a *CandleAggregator) send(e CandleEvent) {
+	select {
+	case a.events <- e:
+	case <-a.done:
+	}
+}
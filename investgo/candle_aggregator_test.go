@@ -0,0 +1,191 @@
+package investgo
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func testTrade(price int64, quantity int64, ts time.Time) *pb.Trade {
+	return &pb.Trade{
+		Figi:          "figi",
+		InstrumentUid: "uid",
+		Price:         pb.NewQuotation(price, 0),
+		Quantity:      quantity,
+		Time:          timestamppb.New(ts),
+	}
+}
+
+// closedCandles - свечи из событий CandleClosed, уже отправленных агрегатором
+func closedCandles(a *CandleAggregator) []AggregatedCandle {
+	var candles []AggregatedCandle
+	for {
+		select {
+		case e := <-a.Events():
+			if e.Type == CandleClosed {
+				candles = append(candles, e.Candle)
+			}
+		default:
+			return candles
+		}
+	}
+}
+
+func TestCandleAggregatorSessionAlignment(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	a, err := NewCandleAggregator(CandleAggregatorOptions{
+		Bars:       []BarSpec{TimeBars(90 * time.Minute)},
+		Session:    SessionSchedule{Location: msk, Open: 10 * time.Hour, Close: 18*time.Hour + 45*time.Minute},
+		ClosedOnly: true,
+		BufferSize: 16,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2023, 3, 1, 0, 0, 0, 0, msk)
+	at := func(h, m int) time.Time {
+		return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute)
+	}
+
+	a.AddTrade(testTrade(100, 1, at(9, 59)))  // до начала сессии
+	a.AddTrade(testTrade(101, 2, at(10, 5)))  // интервал 10:00-11:30
+	a.AddTrade(testTrade(103, 1, at(11, 29))) // тот же интервал
+	a.AddTrade(testTrade(102, 5, at(11, 30))) // закрывает 10:00-11:30
+	a.AddTrade(testTrade(104, 1, at(18, 40))) // последний интервал сессии обрезан концом сессии
+	a.Advance(at(18, 44))
+	a.AddTrade(testTrade(105, 1, at(18, 50))) // после окончания сессии
+	a.Advance(at(18, 45))
+
+	got := closedCandles(a)
+	want := []struct {
+		start, end        time.Time
+		open, close, high int64
+		volume            int64
+	}{
+		{at(10, 0), at(11, 30), 101, 103, 103, 3},
+		{at(11, 30), at(13, 0), 102, 102, 102, 5},
+		{at(17, 30), at(18, 45), 104, 104, 104, 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("%v closed candles, want %v: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		c := got[i]
+		if !c.Start.Equal(w.start) || !c.End.Equal(w.end) {
+			t.Errorf("candle %v: %v-%v, want %v-%v", i, c.Start, c.End, w.start, w.end)
+		}
+		if c.Open.Cmp(pb.NewQuotation(w.open, 0)) != 0 || c.Close.Cmp(pb.NewQuotation(w.close, 0)) != 0 ||
+			c.High.Cmp(pb.NewQuotation(w.high, 0)) != 0 || c.Volume != w.volume {
+			t.Errorf("candle %v: open %v close %v high %v volume %v", i, c.Open.ToString(), c.Close.ToString(),
+				c.High.ToString(), c.Volume)
+		}
+	}
+}
+
+func TestCandleAggregatorOvernightSession(t *testing.T) {
+	a, err := NewCandleAggregator(CandleAggregatorOptions{
+		Bars:       []BarSpec{TimeBars(7 * time.Minute), VolumeBars(10)},
+		Session:    SessionSchedule{Open: 22 * time.Hour, Close: 2 * time.Hour},
+		ClosedOnly: true,
+		BufferSize: 16,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2023, 3, 1, 22, 0, 0, 0, time.UTC)
+
+	// сессия, начавшаяся накануне, продолжается после полуночи, интервалы отсчитываются от ее начала
+	a.AddTrade(testTrade(100, 4, start.Add(2*time.Hour+time.Minute)))
+	a.AddTrade(testTrade(100, 4, start.Add(2*time.Hour+4*time.Minute)))
+	a.Advance(start.Add(4 * time.Hour))
+
+	got := closedCandles(a)
+	if len(got) != 2 {
+		t.Fatalf("%v closed candles, want 2: %+v", len(got), got)
+	}
+	timeBar, volumeBar := got[0], got[1]
+	if timeBar.Bar.Type != BarTime {
+		timeBar, volumeBar = volumeBar, timeBar
+	}
+	// 121 минута от начала сессии попадает в интервал 119-126 минут
+	if want := start.Add(119 * time.Minute); !timeBar.Start.Equal(want) {
+		t.Errorf("time bar start %v, want %v", timeBar.Start, want)
+	}
+	// свеча по объему не набрала порог и закрыта в конце сессии
+	if volumeBar.Volume != 8 || !volumeBar.Start.Equal(start.Add(2*time.Hour+time.Minute)) {
+		t.Errorf("volume bar %+v, want 8 lots from the first trade", volumeBar)
+	}
+}
+
+func TestCandleAggregatorThresholdBars(t *testing.T) {
+	a, err := NewCandleAggregator(CandleAggregatorOptions{
+		Bars:       []BarSpec{TickBars(2), TurnoverBars(pb.NewQuotation(700, 0))},
+		ClosedOnly: true,
+		BufferSize: 16,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	a.AddTrade(testTrade(100, 3, ts))
+	a.AddTrade(testTrade(110, 4, ts.Add(time.Second)))
+	a.AddTrade(testTrade(120, 1, ts.Add(2*time.Second)))
+
+	got := closedCandles(a)
+	if len(got) != 2 {
+		t.Fatalf("%v closed candles, want 2: %+v", len(got), got)
+	}
+	for _, c := range got {
+		// обе свечи закрываются второй сделкой: 2 сделки и оборот 300 + 440
+		if c.Trades != 2 || c.Volume != 7 || c.Turnover.Cmp(pb.NewQuotation(740, 0)) != 0 {
+			t.Errorf("%v bar: trades %v volume %v turnover %v", c.Bar.Type, c.Trades, c.Volume, c.Turnover.ToString())
+		}
+	}
+}
+
+func TestCandleAggregatorCloseWithSlowConsumer(t *testing.T) {
+	a, err := NewCandleAggregator(CandleAggregatorOptions{
+		Bars:       []BarSpec{TickBars(1)},
+		BufferSize: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	added := make(chan struct{})
+	go func() {
+		defer close(added)
+		// канал событий не читается, после заполнения буфера AddTrade блокируется на отправке события под мьютексом
+		for i := 0; i < 3; i++ {
+			a.AddTrade(testTrade(100, 1, ts.Add(time.Duration(i)*time.Second)))
+		}
+	}()
+	for len(a.events) < cap(a.events) {
+		time.Sleep(time.Millisecond)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		a.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close is blocked by the slow consumer")
+	}
+	select {
+	case <-added:
+	case <-time.After(time.Second):
+		t.Fatal("AddTrade is blocked after Close")
+	}
+	n := 0
+	for range a.Events() {
+		n++
+	}
+	if n != 1 {
+		t.Errorf("%v events after Close, want 1 buffered", n)
+	}
+}
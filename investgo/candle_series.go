@@ -0,0 +1,403 @@
+package investgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+const (
+	// DefaultCandleSeriesWindow - количество закрытых свечей серии по умолчанию
+	DefaultCandleSeriesWindow = 100
+	// DefaultCandleSeriesLookback - глубина поиска исторических свечей по умолчанию
+	DefaultCandleSeriesLookback = 7 * 24 * time.Hour
+)
+
+// candlesRequestRange - максимальный период одного запроса GetCandles для минутных и пятиминутных свечей
+const candlesRequestRange = 24 * time.Hour
+
+// CandleSeriesOptions - параметры серии свечей
+type CandleSeriesOptions struct {
+	// InstrumentId - figi или instrument_uid инструмента
+	InstrumentId string
+	Interval     pb.SubscriptionInterval
+	// Window - количество хранимых закрытых свечей, 0 - DefaultCandleSeriesWindow
+	Window int
+	// Lookback - глубина поиска исторических свечей при загрузке истории, 0 - DefaultCandleSeriesLookback
+	Lookback time.Duration
+	// OnClosed - вызывается для каждой закрытой свечи, полученной из стрима или при заполнении пропуска,
+	// но не при загрузке истории
+	OnClosed func(c *pb.HistoricCandle)
+}
+
+// CandleSeries - непрерывная серия свечей инструмента из исторических свечей и свечей из стрима. Хранит
+// Window последних закрытых свечей и формирующуюся свечу. Свечи с одинаковым временем заменяются,
+// а пропуски между историей и стримом, например после переподключения, заполняются запросом GetCandles
+type CandleSeries struct {
+	md       MarketDataService
+	opts     CandleSeriesOptions
+	step     time.Duration
+	interval pb.CandleInterval
+
+	mu sync.RWMutex
+	// closed - закрытые свечи по возрастанию времени
+	closed  []*pb.HistoricCandle
+	forming *pb.HistoricCandle
+	// gapFrom, gapTo - пропуск, который не удалось заполнить, повторяется при следующей свече из стрима
+	gapFrom, gapTo time.Time
+	// notified - закрытые свечи для OnClosed
+	notified []*pb.HistoricCandle
+}
+
+// NewCandleSeries - создание серии свечей, поддерживаются минутные и пятиминутные свечи
+func NewCandleSeries(md MarketDataService, opts CandleSeriesOptions) (*CandleSeries, error) {
+	s := &CandleSeries{md: md, opts: opts}
+	switch opts.Interval {
+	case pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE:
+		s.step, s.interval = time.Minute, pb.CandleInterval_CANDLE_INTERVAL_1_MIN
+	case pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_FIVE_MINUTES:
+		s.step, s.interval = 5*time.Minute, pb.CandleInterval_CANDLE_INTERVAL_5_MIN
+	default:
+		return nil, fmt.Errorf("unsupported candle series interval %v", opts.Interval)
+	}
+	if s.opts.Window <= 0 {
+		s.opts.Window = DefaultCandleSeriesWindow
+	}
+	if s.opts.Lookback <= 0 {
+		s.opts.Lookback = DefaultCandleSeriesLookback
+	}
+	return s, nil
+}
+
+// CandleSubscriber - подписка на свечи для CandleSeries, реализуется MarketDataStreamer и MarketDataPooler
+type CandleSubscriber interface {
+	SubscribeCandleAsync(ids []string, interval pb.SubscriptionInterval) (*SubscriptionFuture, error)
+}
+
+// Attach - подписка на свечи инструмента в sub, загрузка истории и обработка свечей из src, например
+// mds.Candles() или канала потребителя MDDispatcher. История загружается после ответа сервера на подписку,
+// поэтому свечи между историей и стримом не теряются, для получения ответа стрим должен слушаться.
+// Пока загружается история, свечи из src читаются в буфер и обрабатываются после нее, так что общий
+// слушатель стрима не блокируется. Ошибка подписки по инструменту возвращается как *SubscriptionError.
+// Завершается после закрытия src или отмены ctx
+func (s *CandleSeries) Attach(ctx context.Context, sub CandleSubscriber, src <-chan *pb.Candle) error {
+	future, err := sub.SubscribeCandleAsync([]string{s.opts.InstrumentId}, s.opts.Interval)
+	if err != nil {
+		return err
+	}
+	buf := s.buffer(src)
+	res, err := future.Wait(ctx)
+	if err == nil {
+		err = res.Err()
+	}
+	if err == nil {
+		err = s.Backfill(ctx)
+	}
+	candles, srcClosed := buf.stop()
+	if err != nil {
+		return err
+	}
+	for _, c := range candles {
+		s.handle(ctx, c)
+	}
+	if srcClosed {
+		return nil
+	}
+	return s.Run(ctx, src)
+}
+
+// candleBuffer - свечи серии, прочитанные из src до начала Run
+type candleBuffer struct {
+	candles   []*pb.Candle
+	srcClosed bool
+	done      chan struct{}
+	stopped   chan struct{}
+}
+
+// buffer - чтение свечей серии из src в буфер до вызова stop
+func (s *CandleSeries) buffer(src <-chan *pb.Candle) *candleBuffer {
+	b := &candleBuffer{done: make(chan struct{}), stopped: make(chan struct{})}
+	go func() {
+		defer close(b.stopped)
+		for {
+			select {
+			case <-b.done:
+				return
+			case c, ok := <-src:
+				if !ok {
+					b.srcClosed = true
+					return
+				}
+				if s.match(c) {
+					b.candles = append(b.candles, c)
+				}
+			}
+		}
+	}()
+	return b
+}
+
+// stop - остановка чтения src, возвращает прочитанные свечи и true, если src закрыт
+func (b *candleBuffer) stop() ([]*pb.Candle, bool) {
+	close(b.done)
+	<-b.stopped
+	return b.candles, b.srcClosed
+}
+
+// Backfill - загрузка исторических свечей, начиная с последних, пока не наберется Window закрытых свечей
+// или не будет пройден период Lookback
+func (s *CandleSeries) Backfill(ctx context.Context) error {
+	to := time.Now()
+	limit := to.Add(-s.opts.Lookback)
+	candles := make([]*pb.HistoricCandle, 0)
+	for to.After(limit) && len(candles) <= s.opts.Window {
+		from := to.Add(-candlesRequestRange)
+		if from.Before(limit) {
+			from = limit
+		}
+		chunk, err := s.history(ctx, from, to)
+		if err != nil {
+			return err
+		}
+		candles = append(chunk, candles...)
+		to = from
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range candles {
+		s.merge(c, false)
+	}
+	return nil
+}
+
+// Run - обработка свечей из src до его закрытия или отмены ctx. Свечи других инструментов и интервалов
+// пропускаются. Формирующаяся свеча закрывается при получении свечи следующего интервала, минутные свечи
+// приходят из стрима уже закрытыми (WaitingClose)
+func (s *CandleSeries) Run(ctx context.Context, src <-chan *pb.Candle) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case c, ok := <-src:
+			if !ok {
+				return nil
+			}
+			if !s.match(c) {
+				continue
+			}
+			s.handle(ctx, c)
+		}
+	}
+}
+
+// handle - добавление свечи из стрима в серию
+func (s *CandleSeries) handle(ctx context.Context, c *pb.Candle) {
+	s.fillGap(ctx, c.GetTime().AsTime())
+	s.mu.Lock()
+	s.merge(&pb.HistoricCandle{
+		Open:       c.GetOpen(),
+		High:       c.GetHigh(),
+		Low:        c.GetLow(),
+		Close:      c.GetClose(),
+		Volume:     c.GetVolume(),
+		Time:       c.GetTime(),
+		IsComplete: waitingClose(s.opts.Interval),
+	}, true)
+	s.mu.Unlock()
+	s.notify()
+}
+
+// Closed - закрытые свечи по возрастанию времени
+func (s *CandleSeries) Closed() []*pb.HistoricCandle {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]*pb.HistoricCandle(nil), s.closed...)
+}
+
+// Forming - формирующаяся свеча, nil если ее нет
+func (s *CandleSeries) Forming() *pb.HistoricCandle {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.forming
+}
+
+// Candles - закрытые свечи и формирующаяся свеча по возрастанию времени
+func (s *CandleSeries) Candles() []*pb.HistoricCandle {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	candles := append(make([]*pb.HistoricCandle, 0, len(s.closed)+1), s.closed...)
+	if s.forming != nil {
+		candles = append(candles, s.forming)
+	}
+	return candles
+}
+
+func (s *CandleSeries) match(c *pb.Candle) bool {
+	if c.GetInterval() != s.opts.Interval {
+		return false
+	}
+	return c.GetFigi() == s.opts.InstrumentId || c.GetInstrumentUid() == s.opts.InstrumentId
+}
+
+// fillGap - загрузка исторических свечей между последней свечой серии и свечой из стрима со временем t
+func (s *CandleSeries) fillGap(ctx context.Context, t time.Time) {
+	s.mu.Lock()
+	from, to := s.gapFrom, s.gapTo
+	if last := s.last(); !last.IsZero() && t.Sub(last) > s.step {
+		// последняя свеча серии запрашивается повторно, так как могла быть получена незакрытой
+		if from.IsZero() || last.Before(from) {
+			from = last
+		}
+		if t.After(to) {
+			to = t
+		}
+	}
+	s.mu.Unlock()
+	if from.IsZero() {
+		return
+	}
+	candles, err := s.history(ctx, from, to)
+	s.mu.Lock()
+	if err != nil {
+		s.gapFrom, s.gapTo = from, to
+		s.mu.Unlock()
+		return
+	}
+	s.gapFrom, s.gapTo = time.Time{}, time.Time{}
+	for _, c := range candles {
+		s.merge(c, true)
+	}
+	s.mu.Unlock()
+	s.notify()
+}
+
+// history - исторические свечи за период [from, to) по возрастанию времени
+func (s *CandleSeries) history(ctx context.Context, from, to time.Time) ([]*pb.HistoricCandle, error) {
+	candles := make([]*pb.HistoricCandle, 0)
+	for start := from; start.Before(to); start = start.Add(candlesRequestRange) {
+		end := start.Add(candlesRequestRange)
+		if end.After(to) {
+			end = to
+		}
+		resp, err := s.md.GetCandlesCtx(ctx, s.opts.InstrumentId, s.interval, start, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range resp.GetCandles() {
+			if ts := c.GetTime().AsTime(); !ts.Before(from) && ts.Before(to) {
+				candles = append(candles, c)
+			}
+		}
+	}
+	sort.Slice(candles, func(i, j int) bool {
+		return candles[i].GetTime().AsTime().Before(candles[j].GetTime().AsTime())
+	})
+	return candles, nil
+}
+
+// last - время последней свечи серии, вызывается под mu
+func (s *CandleSeries) last() time.Time {
+	if s.forming != nil {
+		return s.forming.GetTime().AsTime()
+	}
+	if len(s.closed) > 0 {
+		return s.closed[len(s.closed)-1].GetTime().AsTime()
+	}
+	return time.Time{}
+}
+
+// merge - добавление свечи в серию, вызывается под mu. Свеча с временем существующей свечи заменяет ее,
+// свеча новее формирующейся закрывает ее. notify - вызывать OnClosed для новых закрытых свечей
+func (s *CandleSeries) merge(c *pb.HistoricCandle, notify bool) {
+	t := c.GetTime().AsTime()
+	if s.forming != nil {
+		ft := s.forming.GetTime().AsTime()
+		switch {
+		case t.Equal(ft):
+			if !c.GetIsComplete() {
+				s.forming = c
+				return
+			}
+			s.forming = nil
+			s.appendClosed(c, notify)
+			return
+		case t.After(ft):
+			forming := s.forming
+			s.forming = nil
+			s.appendClosed(forming, notify)
+		default:
+			// свеча старше формирующейся уже закрыта
+			s.insertClosed(c, notify)
+			return
+		}
+	}
+	if len(s.closed) == 0 || t.After(s.closed[len(s.closed)-1].GetTime().AsTime()) {
+		if c.GetIsComplete() {
+			s.appendClosed(c, notify)
+		} else {
+			s.forming = c
+		}
+		return
+	}
+	s.insertClosed(c, notify)
+}
+
+// insertClosed - добавление закрытой свечи в окно, вызывается под mu
+func (s *CandleSeries) insertClosed(c *pb.HistoricCandle, notify bool) {
+	t := c.GetTime().AsTime()
+	if len(s.closed) == 0 || t.After(s.closed[len(s.closed)-1].GetTime().AsTime()) {
+		s.appendClosed(c, notify)
+		return
+	}
+	// свеча внутри окна: замена свечи с тем же временем или вставка пропущенной
+	i := sort.Search(len(s.closed), func(i int) bool {
+		return !s.closed[i].GetTime().AsTime().Before(t)
+	})
+	if s.closed[i].GetTime().AsTime().Equal(t) {
+		// завершенная историческая свеча не заменяется запоздавшим обновлением из стрима
+		if !s.closed[i].GetIsComplete() || c.GetIsComplete() {
+			s.closed[i] = c
+		}
+		return
+	}
+	if i == 0 && len(s.closed) >= s.opts.Window {
+		return
+	}
+	s.closed = append(s.closed[:i], append([]*pb.HistoricCandle{c}, s.closed[i:]...)...)
+	s.trim()
+	if notify {
+		s.notified = append(s.notified, c)
+	}
+}
+
+func (s *CandleSeries) appendClosed(c *pb.HistoricCandle, notify bool) {
+	s.closed = append(s.closed, c)
+	s.trim()
+	if notify {
+		s.notified = append(s.notified, c)
+	}
+}
+
+// notify - вызов OnClosed для закрытых свечей, добавленных в merge, вызывается без mu
+func (s *CandleSeries) notify() {
+	s.mu.Lock()
+	notified := s.notified
+	s.notified = nil
+	s.mu.Unlock()
+	if s.opts.OnClosed == nil {
+		return
+	}
+	for _, c := range notified {
+		s.opts.OnClosed(c)
+	}
+}
+
+func (s *CandleSeries) trim() {
+	if extra := len(s.closed) - s.opts.Window; extra > 0 {
+		s.closed = append(s.closed[:0:0], s.closed[extra:]...)
+	}
+}
@@ -0,0 +1,283 @@
+package investgo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/therox/invest-api-go-sdk/investgo"
+	"github.com/therox/invest-api-go-sdk/investgo/investgomock"
+	pb "github.com/therox/invest-api-go-sdk/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// testExchange - исторические свечи по минутам, завершенные до available
+type testExchange struct {
+	available time.Time
+}
+
+func (e *testExchange) service() *investgomock.MarketDataServiceMock {
+	return &investgomock.MarketDataServiceMock{
+		GetCandlesCtxFunc: func(ctx context.Context, instrumentId string, interval pb.CandleInterval, from, to time.Time) (*investgo.GetCandlesResponse, error) {
+			resp := &pb.GetCandlesResponse{}
+			for t := from.Truncate(time.Minute); t.Before(to) && t.Before(e.available); t = t.Add(time.Minute) {
+				if t.Before(from) {
+					continue
+				}
+				resp.Candles = append(resp.Candles, &pb.HistoricCandle{
+					Close:      pb.NewQuotation(1, 0),
+					Time:       timestamppb.New(t),
+					IsComplete: true,
+				})
+			}
+			return &investgo.GetCandlesResponse{GetCandlesResponse: resp}, nil
+		},
+	}
+}
+
+func streamCandle(ts time.Time, close int64) *pb.Candle {
+	return &pb.Candle{
+		Figi:     "figi",
+		Interval: pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE,
+		Close:    pb.NewQuotation(close, 0),
+		Time:     timestamppb.New(ts),
+	}
+}
+
+func TestCandleSeriesGapFill(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	now := time.Now().Truncate(time.Minute)
+	minute := func(n int) time.Time {
+		return now.Add(time.Duration(n) * time.Minute)
+	}
+	exchange := &testExchange{available: minute(-5)}
+	var notified []time.Time
+	series, err := investgo.NewCandleSeries(exchange.service(), investgo.CandleSeriesOptions{
+		InstrumentId: "figi",
+		Interval:     pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE,
+		Window:       10,
+		Lookback:     time.Hour,
+		OnClosed: func(c *pb.HistoricCandle) {
+			notified = append(notified, c.GetTime().AsTime())
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := series.Backfill(ctx); err != nil {
+		t.Fatal(err)
+	}
+	closed := series.Closed()
+	if len(closed) != 10 || !closed[9].GetTime().AsTime().Equal(minute(-6)) {
+		t.Fatalf("%v candles after backfill, last %v, want 10 up to %v", len(closed), closed[len(closed)-1].GetTime().AsTime(), minute(-6))
+	}
+
+	// пока стрим переподключался, прошли минуты -5, -4 и -3, они загружаются запросом истории
+	exchange.available = minute(-2)
+	src := make(chan *pb.Candle, 4)
+	src <- streamCandle(minute(-2), 2)
+	src <- streamCandle(minute(-2), 3) // повторная закрытая свеча заменяет предыдущую
+	src <- streamCandle(minute(-2), 4)
+	src <- streamCandle(minute(-1), 5)
+	close(src)
+	if err := series.Run(ctx, src); err != nil {
+		t.Fatal(err)
+	}
+
+	// минутные свечи приходят из стрима закрытыми (WaitingClose), формирующейся свечи нет
+	closed = series.Closed()
+	if len(closed) != 10 || series.Forming() != nil {
+		t.Fatalf("%v closed candles, forming %v, want 10 closed", len(closed), series.Forming())
+	}
+	for i, c := range closed {
+		if want := minute(i - 10); !c.GetTime().AsTime().Equal(want) {
+			t.Errorf("candle %v at %v, want %v", i, c.GetTime().AsTime(), want)
+		}
+	}
+	if c := closed[8]; c.GetClose().Cmp(pb.NewQuotation(4, 0)) != 0 {
+		t.Errorf("candle -2 close %v, want the last update 4", c.GetClose().ToString())
+	}
+	want := []time.Time{minute(-5), minute(-4), minute(-3), minute(-2), minute(-1)}
+	if len(notified) != len(want) {
+		t.Fatalf("OnClosed for %v, want %v", notified, want)
+	}
+	for i := range want {
+		if !notified[i].Equal(want[i]) {
+			t.Errorf("OnClosed %v: %v, want %v", i, notified[i], want[i])
+		}
+	}
+}
+
+func TestCandleSeriesClosesOnNextInterval(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	interval := pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_FIVE_MINUTES
+	var notified []*pb.HistoricCandle
+	series, err := investgo.NewCandleSeries(&investgomock.MarketDataServiceMock{}, investgo.CandleSeriesOptions{
+		InstrumentId: "figi",
+		Interval:     interval,
+		OnClosed: func(c *pb.HistoricCandle) {
+			notified = append(notified, c)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now().Truncate(5 * time.Minute)
+	candle := func(ts time.Time, close int64) *pb.Candle {
+		c := streamCandle(ts, close)
+		c.Interval = interval
+		return c
+	}
+
+	src := make(chan *pb.Candle, 3)
+	src <- candle(start, 1)
+	src <- candle(start, 2) // обновление формирующейся свечи
+	close(src)
+	if err := series.Run(ctx, src); err != nil {
+		t.Fatal(err)
+	}
+	if f := series.Forming(); f == nil || f.GetClose().Cmp(pb.NewQuotation(2, 0)) != 0 || len(series.Closed()) != 0 {
+		t.Fatalf("forming %v, %v closed, want forming candle with close 2", f, len(series.Closed()))
+	}
+
+	src = make(chan *pb.Candle, 1)
+	src <- candle(start.Add(5*time.Minute), 3)
+	close(src)
+	if err := series.Run(ctx, src); err != nil {
+		t.Fatal(err)
+	}
+	closed := series.Closed()
+	if len(closed) != 1 || !closed[0].GetTime().AsTime().Equal(start) || closed[0].GetClose().Cmp(pb.NewQuotation(2, 0)) != 0 {
+		t.Fatalf("closed %v, want candle %v with close 2", closed, start)
+	}
+	if f := series.Forming(); f == nil || !f.GetTime().AsTime().Equal(start.Add(5*time.Minute)) {
+		t.Errorf("forming %v, want %v", f, start.Add(5*time.Minute))
+	}
+	if len(notified) != 1 || notified[0] != closed[0] {
+		t.Errorf("OnClosed for %v, want the closed candle", notified)
+	}
+}
+
+func TestCandleSeriesAttachFailedSubscription(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	md := &investgomock.MarketDataServiceMock{}
+	series, err := investgo.NewCandleSeries(md, investgo.CandleSeriesOptions{
+		InstrumentId: "unknown",
+		Interval:     pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream := &investgomock.MarketDataStreamerMock{
+		SubscribeCandleAsyncFunc: func(ids []string, interval pb.SubscriptionInterval) (*investgo.SubscriptionFuture, error) {
+			return investgo.CompletedSubscriptionFuture(&investgo.SubscriptionResult{
+				Type: investgo.SubscriptionCandles,
+				Failed: []*investgo.SubscriptionError{{
+					Type:         investgo.SubscriptionCandles,
+					InstrumentId: ids[0],
+					Status:       pb.SubscriptionStatus_SUBSCRIPTION_STATUS_INSTRUMENT_NOT_FOUND,
+				}},
+			}, nil), nil
+		},
+	}
+
+	err = series.Attach(ctx, stream, make(chan *pb.Candle))
+	var subErr *investgo.SubscriptionError
+	if !errors.As(err, &subErr) || subErr.InstrumentId != "unknown" {
+		t.Fatalf("Attach error %v, want SubscriptionError for unknown", err)
+	}
+	if calls := md.CallsTo("GetCandlesCtx"); len(calls) != 0 {
+		t.Errorf("%v history requests after failed subscription", len(calls))
+	}
+}
+
+func TestCandleSeriesAttachToPool(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	exchange := &testExchange{available: time.Now().Truncate(time.Minute)}
+	series, err := investgo.NewCandleSeries(exchange.service(), investgo.CandleSeriesOptions{
+		InstrumentId: "figi",
+		Interval:     pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE,
+		Window:       5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := &investgomock.MarketDataPoolerMock{
+		SubscribeCandleAsyncFunc: func(ids []string, interval pb.SubscriptionInterval) (*investgo.SubscriptionFuture, error) {
+			return investgo.CompletedSubscriptionFuture(&investgo.SubscriptionResult{
+				Type:      investgo.SubscriptionCandles,
+				Succeeded: ids,
+			}, nil), nil
+		},
+	}
+
+	src := make(chan *pb.Candle)
+	close(src)
+	if err := series.Attach(ctx, pool, src); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(series.Closed()); n != 5 {
+		t.Errorf("%v closed candles, want 5", n)
+	}
+}
+
+func TestCandleSeriesAttachBuffersDuringBackfill(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	now := time.Now().Truncate(time.Minute)
+	exchange := &testExchange{available: now}
+	src := make(chan *pb.Candle)
+	md := exchange.service()
+	history := md.GetCandlesCtxFunc
+	sent := make(chan struct{})
+	md.GetCandlesCtxFunc = func(ctx context.Context, instrumentId string, interval pb.CandleInterval, from, to time.Time) (*investgo.GetCandlesResponse, error) {
+		if len(md.CallsTo("GetCandlesCtx")) == 1 {
+			// слушатель стрима не должен блокироваться, пока загружается история
+			select {
+			case src <- streamCandle(now, 7):
+			case <-time.After(testTimeout / 2):
+				t.Error("src is not drained during backfill")
+			}
+			close(sent)
+		}
+		return history(ctx, instrumentId, interval, from, to)
+	}
+	series, err := investgo.NewCandleSeries(md, investgo.CandleSeriesOptions{
+		InstrumentId: "figi",
+		Interval:     pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE,
+		Window:       5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := &investgomock.MarketDataPoolerMock{
+		SubscribeCandleAsyncFunc: func(ids []string, interval pb.SubscriptionInterval) (*investgo.SubscriptionFuture, error) {
+			return investgo.CompletedSubscriptionFuture(&investgo.SubscriptionResult{
+				Type:      investgo.SubscriptionCandles,
+				Succeeded: ids,
+			}, nil), nil
+		},
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- series.Attach(ctx, pool, src)
+	}()
+	<-sent
+	close(src)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	closed := series.Closed()
+	if len(closed) != 5 {
+		t.Fatalf("%v closed candles, want 5", len(closed))
+	}
+	if last := closed[4]; !last.GetTime().AsTime().Equal(now) || last.GetClose().Cmp(pb.NewQuotation(7, 0)) != 0 {
+		t.Errorf("last candle %v, want buffered candle %v with close 7", last, now)
+	}
+}
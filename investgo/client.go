@@ -10,17 +10,21 @@ import (
 	"golang.org/x/oauth2"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/credentials/oauth"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
 )
 
 type ctxKey string
 
 type Client struct {
-	conn   *grpc.ClientConn
-	Config Config
-	Logger Logger
-	ctx    context.Context
+	conn    *grpc.ClientConn
+	Config  Config
+	Logger  Logger
+	ctx     context.Context
+	limiter *rateLimiter
+	streams *streamRegistry
 }
 
 // NewClient - создание клиента для API Тинькофф инвестиций
@@ -34,33 +38,125 @@ func NewClient(ctx context.Context, cnf Config, l Logger) (*Client, error) {
 	ctx = context.WithValue(ctx, authKey, fmt.Sprintf("Bearer %s", cnf.Token))
 	ctx = metadata.AppendToOutgoingContext(ctx, "x-app-name", cnf.AppName)
 
-	var retryPolicy = `{
-		"methodConfig": [{
-		  "waitForReady": true,
-		  "retryPolicy": {
-			  "MaxAttempts": 5,
-			  "InitialBackoff": "1s",
-			  "MaxBackoff": "1s",
-			  "BackoffMultiplier": 1.0,
-			  "RetryableStatusCodes": [ "UNAVAILABLE" ]
-		  }
-		}]}`
-	conn, err := grpc.Dial(cnf.EndPoint,
-		grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{})),
-		grpc.WithPerRPCCredentials(oauth.TokenSource{TokenSource: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: cnf.Token})}),
-		grpc.WithDefaultServiceConfig(retryPolicy))
+	cnf, err := cnf.resolve()
+	if err != nil {
+		return nil, err
+	}
+	err = cnf.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	limiter := newRateLimiter(l)
+	opts, err := dialOptions(cnf)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts,
+		grpc.WithChainUnaryInterceptor(appNameUnaryInterceptor(cnf.AppName), limiter.unaryInterceptor),
+		grpc.WithChainStreamInterceptor(appNameStreamInterceptor(cnf.AppName)))
+	opts = append(opts, cnf.DialOptions...)
+
+	dialCtx := ctx
+	if cnf.DialTimeout > 0 {
+		var cancel context.CancelFunc
+		dialCtx, cancel = context.WithTimeout(ctx, cnf.DialTimeout)
+		defer cancel()
+		opts = append(opts, grpc.WithBlock())
+	}
+	conn, err := grpc.DialContext(dialCtx, cnf.EndPoint, opts...)
 	if err != nil {
 		return nil, err
 	}
 
+	// лимиты на unary-запросы берутся из тарифа пользователя, при ошибке или таймауте запроса тарифа
+	// запросы не ограничиваются
+	if !cnf.DisableRateLimiter {
+		err = limiter.load(ctx, pb.NewUsersServiceClient(conn))
+		if err != nil {
+			l.Errorf("rate limiter: get user tariff error %v", err.Error())
+		}
+	}
+
 	return &Client{
-		conn:   conn,
-		Config: cnf,
-		Logger: l,
-		ctx:    ctx,
+		conn:    conn,
+		Config:  cnf,
+		Logger:  l,
+		ctx:     ctx,
+		limiter: limiter,
+		streams: newStreamRegistry(),
 	}, nil
 }
 
+// appNameUnaryInterceptor - добавляет x-app-name в метаданные запросов, выполненных с пользовательским контекстом
+func appNameUnaryInterceptor(appName string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(withAppName(ctx, appName), method, req, reply, cc, opts...)
+	}
+}
+
+// appNameStreamInterceptor - добавляет x-app-name в метаданные стримов, открытых с пользовательским контекстом
+func appNameStreamInterceptor(appName string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(withAppName(ctx, appName), desc, cc, method, opts...)
+	}
+}
+
+func withAppName(ctx context.Context, appName string) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if len(md.Get("x-app-name")) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "x-app-name", appName)
+}
+
+// dialOptions - опции grpc соединения из конфигурации
+func dialOptions(cnf Config) ([]grpc.DialOption, error) {
+	serviceConfig, err := cnf.RetryPolicy.serviceConfig()
+	if err != nil {
+		return nil, err
+	}
+	opts := []grpc.DialOption{grpc.WithDefaultServiceConfig(serviceConfig)}
+	if cnf.Insecure {
+		opts = append(opts,
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithPerRPCCredentials(insecureTokenCredentials(cnf.Token)))
+	} else {
+		opts = append(opts,
+			grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{})),
+			grpc.WithPerRPCCredentials(oauth.TokenSource{TokenSource: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: cnf.Token})}))
+	}
+	if cnf.KeepAlive.Time > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                cnf.KeepAlive.Time,
+			Timeout:             cnf.KeepAlive.Timeout,
+			PermitWithoutStream: cnf.KeepAlive.PermitWithoutStream,
+		}))
+	}
+	var callOpts []grpc.CallOption
+	if cnf.MaxRecvMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallRecvMsgSize(cnf.MaxRecvMsgSize))
+	}
+	if cnf.MaxSendMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallSendMsgSize(cnf.MaxSendMsgSize))
+	}
+	if len(callOpts) > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(callOpts...))
+	}
+	return opts, nil
+}
+
+// insecureTokenCredentials - передача токена без TLS, используется только с Config.Insecure
+type insecureTokenCredentials string
+
+func (t insecureTokenCredentials) GetRequestMetadata(_ context.Context, _ ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t insecureTokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
 type Logger interface {
 	Infof(template string, args ...any)
 	Errorf(template string, args ...any)
@@ -76,6 +172,7 @@ func (c *Client) NewMDStreamClient() *MDStreamClient {
 		logger:   c.Logger,
 		ctx:      c.ctx,
 		pbClient: pbClient,
+		streams:  c.streams,
 	}
 }
 
@@ -100,6 +197,7 @@ func (c *Client) NewMarketDataServiceClient() *MarketDataServiceClient {
 		logger:   c.Logger,
 		ctx:      c.ctx,
 		pbClient: pbClient,
+		limiter:  c.limiter,
 	}
 }
 
@@ -172,6 +270,7 @@ func (c *Client) NewOrdersStreamClient() *OrdersStreamClient {
 		logger:   c.Logger,
 		ctx:      c.ctx,
 		pbClient: pbClient,
+		streams:  c.streams,
 	}
 }
 
@@ -184,12 +283,41 @@ func (c *Client) NewOperationsStreamClient() *OperationsStreamClient {
 		logger:   c.Logger,
 		ctx:      c.ctx,
 		pbClient: pbClient,
+		streams:  c.streams,
 	}
 }
 
-// Stop - корректное завершение работы клиента
+// Stop - корректное завершение работы клиента, аналог Shutdown без ограничения по времени
 func (c *Client) Stop() error {
-	// TODO add some stop options
+	return c.Shutdown(context.Background())
+}
+
+// Shutdown - корректное завершение работы клиента: отписка стримов маркетдаты от всех инструментов с ожиданием
+// ответа сервера, остановка всех открытых клиентом стримов, ожидание завершения их Listen и закрытия каналов,
+// затем закрытие соединения. При завершении ctx соединение закрывается не дожидаясь стримов
+func (c *Client) Shutdown(ctx context.Context) error {
 	c.Logger.Infof("stop client")
-	return c.conn.Close()
+	streams := c.streams.list()
+	for _, s := range streams {
+		if mds, ok := s.(*MDStream); ok {
+			err := mds.unSubscribeAllWait(ctx)
+			if err != nil {
+				c.Logger.Errorf("market data stream unsubscribe error %v", err.Error())
+			}
+		}
+		s.Stop()
+	}
+	var waitErr error
+	for _, s := range streams {
+		waitErr = s.wait(ctx)
+		if waitErr != nil {
+			c.Logger.Errorf("streams shutdown error %v", waitErr.Error())
+			break
+		}
+	}
+	err := c.conn.Close()
+	if waitErr != nil {
+		return waitErr
+	}
+	return err
 }
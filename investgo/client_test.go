@@ -0,0 +1,416 @@
+package investgo_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/therox/invest-api-go-sdk/investgo"
+	"github.com/therox/invest-api-go-sdk/investgo/investgotest"
+	pb "github.com/therox/invest-api-go-sdk/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestServiceCtxMetadata(t *testing.T) {
+	client, server := investgotest.NewClient(t)
+	type call struct {
+		md       metadata.MD
+		deadline time.Time
+	}
+	calls := make(chan call, 1)
+	server.Handle("MarketDataService/GetLastPrices", func(ctx context.Context, req proto.Message) (proto.Message, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		deadline, _ := ctx.Deadline()
+		calls <- call{md: md, deadline: deadline}
+		return &pb.GetLastPricesResponse{}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-request-id", "request-1")
+	if _, err := client.NewMarketDataServiceClient().GetLastPricesCtx(ctx, []string{"figi"}); err != nil {
+		t.Fatal(err)
+	}
+	c := <-calls
+	// метаданные вызова дополняются метаданными клиента
+	for key, want := range map[string]string{
+		"x-app-name":    "investgotest",
+		"authorization": "Bearer test-token",
+		"x-request-id":  "request-1",
+	} {
+		if got := c.md.Get(key); len(got) != 1 || got[0] != want {
+			t.Errorf("%v = %v, want %v", key, got, want)
+		}
+	}
+	// deadline передается в grpc-timeout, поэтому на сервере он может незначительно отличаться
+	if want, _ := ctx.Deadline(); c.deadline.IsZero() || c.deadline.Sub(want).Abs() > time.Second {
+		t.Errorf("server deadline %v, want caller deadline %v", c.deadline, want)
+	}
+}
+
+func TestServiceCtxCancel(t *testing.T) {
+	client, server := investgotest.NewClient(t)
+	server.Handle("MarketDataService/GetLastPrices", func(ctx context.Context, req proto.Message) (proto.Message, error) {
+		<-ctx.Done()
+		return nil, status.FromContextError(ctx.Err()).Err()
+	})
+	md := client.NewMarketDataServiceClient()
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := md.GetLastPricesCtx(canceled, []string{"figi"}); status.Code(err) != codes.Canceled {
+		t.Errorf("canceled context: %v, want Canceled", err)
+	}
+	if n := len(server.Requests("MarketDataService/GetLastPrices")); n != 0 {
+		t.Errorf("%v requests sent with canceled context", n)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := md.GetLastPricesCtx(ctx, []string{"figi"})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("expired context: %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > testTimeout/2 {
+		t.Errorf("call returned after %v, deadline is not propagated", elapsed)
+	}
+	// контекст клиента не отменен, вызовы без Ctx продолжают работать
+	server.Handle("MarketDataService/GetLastPrices", func(ctx context.Context, req proto.Message) (proto.Message, error) {
+		return &pb.GetLastPricesResponse{}, nil
+	})
+	if _, err := md.GetLastPrices([]string{"figi"}); err != nil {
+		t.Errorf("call after canceled Ctx call: %v", err)
+	}
+}
+
+func TestStreamCtxCancel(t *testing.T) {
+	client, server := investgotest.NewClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	streamCtx, stopStreams := context.WithCancel(ctx)
+	portfolios, err := client.NewOperationsStreamClient().PortfolioStreamCtx(streamCtx, []string{"account"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mds, err := client.NewMDStreamClient().MarketDataStreamCtx(streamCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 2)
+	go func() {
+		done <- portfolios.Listen()
+	}()
+	go func() {
+		done <- mds.Listen()
+	}()
+	if err := server.WaitStreams(ctx, investgotest.PortfolioStream, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mds.SubscribeLastPrice([]string{"figi"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.WaitStreams(ctx, investgotest.MarketDataStream, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// отмена контекста вызова завершает стримы без ошибки и закрывает каналы
+	stopStreams()
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-done:
+			if err != nil && !errors.Is(err, context.Canceled) {
+				t.Errorf("Listen: %v", err)
+			}
+		case <-ctx.Done():
+			t.Fatal("Listen is not finished after context cancel")
+		}
+	}
+	if _, ok := <-portfolios.Portfolios(); ok {
+		t.Error("portfolios channel is not closed")
+	}
+	if _, ok := <-mds.LastPrices(); ok {
+		t.Error("last prices channel is not closed")
+	}
+	for _, method := range []string{investgotest.PortfolioStream, investgotest.MarketDataStream} {
+		if err := server.WaitStreams(ctx, method, 0); err != nil {
+			t.Errorf("%v: %v", method, err)
+		}
+	}
+
+	// клиент продолжает работать после отмены контекста стримов
+	if _, err := client.NewOperationsStreamClient().PortfolioStream([]string{"account"}); err != nil {
+		t.Errorf("new stream after cancel: %v", err)
+	}
+}
+
+// newConfigClient - клиент тестового сервера с измененной конфигурацией
+func newConfigClient(t *testing.T, modify func(c *investgo.Config)) (*investgo.Client, *investgotest.Server, error) {
+	t.Helper()
+	server := investgotest.NewServer()
+	t.Cleanup(server.Close)
+	cnf := server.Config()
+	modify(&cnf)
+	client, err := investgo.NewClient(context.Background(), cnf, investgotest.NewLogger(t))
+	if err == nil {
+		t.Cleanup(func() { _ = client.Stop() })
+	}
+	return client, server, err
+}
+
+func TestNewClientRetryPolicy(t *testing.T) {
+	const method = "MarketDataService/GetLastPrices"
+	tests := []struct {
+		name     string
+		policy   investgo.RetryConfig
+		failures int
+		code     codes.Code
+		requests int
+	}{
+		{
+			name:     "retried",
+			policy:   investgo.RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+			failures: 2,
+			code:     codes.OK,
+			requests: 3,
+		},
+		{
+			name:     "attempts exhausted",
+			policy:   investgo.RetryConfig{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+			failures: 2,
+			code:     codes.Unavailable,
+			requests: 2,
+		},
+		{
+			name:     "retries disabled",
+			policy:   investgo.RetryConfig{MaxAttempts: 1},
+			failures: 1,
+			code:     codes.Unavailable,
+			requests: 1,
+		},
+		{
+			name:     "status code is not retryable",
+			policy:   investgo.RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, RetryableStatusCodes: []string{"internal"}},
+			failures: 1,
+			code:     codes.Unavailable,
+			requests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server, err := newConfigClient(t, func(c *investgo.Config) {
+				c.RetryPolicy = tt.policy
+				c.DisableRateLimiter = true
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			server.InjectError(method, status.Error(codes.Unavailable, "unavailable"), tt.failures)
+			_, err = client.NewMarketDataServiceClient().GetLastPrices([]string{"figi"})
+			if status.Code(err) != tt.code {
+				t.Errorf("GetLastPrices: %v, want %v", err, tt.code)
+			}
+			if n := len(server.Requests(method)); n != tt.requests {
+				t.Errorf("%v requests, want %v", n, tt.requests)
+			}
+		})
+	}
+}
+
+func TestNewClientConfigErrors(t *testing.T) {
+	_, _, err := newConfigClient(t, func(c *investgo.Config) {
+		c.Token = ""
+		c.MaxRecvMsgSize = -1
+	})
+	if err == nil || !strings.Contains(err.Error(), "APIToken is empty") || !strings.Contains(err.Error(), "MaxRecvMsgSize") {
+		t.Errorf("invalid config: %v", err)
+	}
+
+	// с DialTimeout NewClient ждет установки соединения
+	start := time.Now()
+	_, _, err = newConfigClient(t, func(c *investgo.Config) {
+		c.DialTimeout = 100 * time.Millisecond
+		c.DialOptions = []grpc.DialOption{grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return nil, errors.New("connection refused")
+		})}
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unreachable server: %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > testTimeout/2 {
+		t.Errorf("NewClient returned after %v", elapsed)
+	}
+
+	client, _, err := newConfigClient(t, func(c *investgo.Config) {
+		c.DialTimeout = testTimeout
+		c.KeepAlive = investgo.KeepAliveConfig{Time: time.Minute, Timeout: time.Second}
+	})
+	if err != nil {
+		t.Fatalf("reachable server: %v", err)
+	}
+	if _, err := client.NewUsersServiceClient().GetAccounts(); err != nil {
+		t.Errorf("GetAccounts: %v", err)
+	}
+}
+
+func TestNewClientMaxMessageSize(t *testing.T) {
+	client, server, err := newConfigClient(t, func(c *investgo.Config) {
+		c.MaxRecvMsgSize = 64
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastPrices := &pb.GetLastPricesResponse{}
+	for i := 0; i < 10; i++ {
+		lastPrices.LastPrices = append(lastPrices.LastPrices, &pb.LastPrice{Figi: "BBG000B9XRY4", InstrumentUid: "instrument"})
+	}
+	server.SetResponse("MarketDataService/GetLastPrices", lastPrices)
+	_, err = client.NewMarketDataServiceClient().GetLastPrices([]string{"figi"})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("response over MaxRecvMsgSize: %v, want ResourceExhausted", err)
+	}
+}
+
+func TestClientShutdown(t *testing.T) {
+	client, server := investgotest.NewClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	mds, err := client.NewMDStreamClient().MarketDataStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	portfolios, err := client.NewOperationsStreamClient().PortfolioStream([]string{"account"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	positions, err := client.NewOperationsStreamClient().PositionsStream([]string{"account"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	trades, err := client.NewOrdersStreamClient().TradesStream([]string{"account"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// стрим без Listen тоже закрывается в Shutdown
+	idle, err := client.NewOperationsStreamClient().PositionsStream([]string{"account"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listeners := []func() error{mds.Listen, portfolios.Listen, positions.Listen, trades.Listen}
+	done := make(chan error, len(listeners))
+	for _, listen := range listeners {
+		listen := listen
+		go func() {
+			done <- listen()
+		}()
+	}
+	// grpc стрим открывается при создании, до Listen
+	for method, n := range map[string]int{investgotest.PortfolioStream: 1, investgotest.PositionsStream: 2, investgotest.TradesStream: 1} {
+		if err := server.WaitStreams(ctx, method, n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := mds.SubscribeLastPriceAsync([]string{"instrument"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// Shutdown возвращается только после завершения всех Listen
+	for range listeners {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("Listen: %v", err)
+			}
+		default:
+			t.Fatal("Listen is not finished before Shutdown returns")
+		}
+	}
+	closed := map[string]bool{}
+	_, ok := <-mds.LastPrices()
+	closed["last prices"] = !ok
+	_, ok = <-portfolios.Portfolios()
+	closed["portfolios"] = !ok
+	_, ok = <-positions.Positions()
+	closed["positions"] = !ok
+	_, ok = <-trades.Trades()
+	closed["trades"] = !ok
+	_, ok = <-idle.Positions()
+	closed["idle positions"] = !ok
+	for name, closed := range closed {
+		if !closed {
+			t.Errorf("%v channel is not closed", name)
+		}
+	}
+
+	// отписка отправляется до закрытия стрима
+	reqs := server.Requests(investgotest.MarketDataStream)
+	last, _ := reqs[len(reqs)-1].(*pb.MarketDataRequest)
+	if last.GetSubscribeLastPriceRequest().GetSubscriptionAction() != pb.SubscriptionAction_SUBSCRIPTION_ACTION_UNSUBSCRIBE {
+		t.Errorf("last market data request %v, want last price unsubscribe", last)
+	}
+	for _, method := range []string{investgotest.MarketDataStream, investgotest.PortfolioStream, investgotest.PositionsStream, investgotest.TradesStream} {
+		if err := server.WaitStreams(ctx, method, 0); err != nil {
+			t.Errorf("%v: %v", method, err)
+		}
+	}
+	// соединение закрывается последним
+	if _, err := client.NewUsersServiceClient().GetAccounts(); status.Code(err) != codes.Canceled {
+		t.Errorf("call after Shutdown: %v, want Canceled", err)
+	}
+}
+
+func TestClientShutdownDeadline(t *testing.T) {
+	client, server := investgotest.NewClient(t)
+	portfolios, err := client.NewOperationsStreamClient().PortfolioStream([]string{"account"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- portfolios.Listen()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	if err := server.WaitStreams(ctx, investgotest.PortfolioStream, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	expired, cancelExpired := context.WithCancel(context.Background())
+	cancelExpired()
+	start := time.Now()
+	if err := client.Shutdown(expired); err != nil && !errors.Is(err, context.Canceled) {
+		t.Errorf("Shutdown: %v, want nil or context canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > testTimeout/2 {
+		t.Errorf("Shutdown returned after %v", elapsed)
+	}
+	// соединение закрыто и при истекшем контексте, стрим завершается
+	if _, err := client.NewUsersServiceClient().GetAccounts(); status.Code(err) != codes.Canceled {
+		t.Errorf("call after Shutdown: %v, want Canceled", err)
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal("Listen is not finished after Shutdown")
+	}
+	if _, ok := <-portfolios.Portfolios(); ok {
+		t.Error("portfolios channel is not closed")
+	}
+}
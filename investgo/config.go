@@ -1,28 +1,350 @@
 package investgo
 
 import (
-	yaml "gopkg.in/yaml.v3"
-	"log"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	yaml "gopkg.in/yaml.v3"
+)
+
+const (
+	// EndPointProd - адрес INVEST API
+	EndPointProd = "invest-public-api.tinkoff.ru:443"
+	// EndPointSandbox - адрес песочницы INVEST API
+	EndPointSandbox = "sandbox-invest-public-api.tinkoff.ru:443"
+)
+
+// Переменные окружения, значения которых переопределяют конфигурацию из файла
+const (
+	EnvProfile   = "INVEST_PROFILE"
+	EnvToken     = "INVEST_TOKEN"
+	EnvTokenFile = "INVEST_TOKEN_FILE"
+	EnvEndPoint  = "INVEST_ENDPOINT"
+	EnvAppName   = "INVEST_APP_NAME"
+	EnvAccountId = "INVEST_ACCOUNT_ID"
+	EnvSandbox   = "INVEST_SANDBOX"
 )
 
 // Config - структура для кофигурации SDK
 type Config struct {
-	EndPoint  string `yaml:"EndPoint"`
-	Token     string `yaml:"APIToken"`
+	// EndPoint - адрес API, если не задан, выбирается по флагу Sandbox
+	EndPoint string `yaml:"EndPoint"`
+	Token    string `yaml:"APIToken"`
+	// TokenFile - путь к файлу с токеном, используется если APIToken не задан
+	TokenFile string `yaml:"TokenFile"`
+	// Sandbox - работа с песочницей
+	Sandbox   bool   `yaml:"Sandbox"`
 	AppName   string `yaml:"AppName"`
 	AccountId string `yaml:"AccountId"`
+	// DisableRateLimiter - отключение клиентского ограничения частоты unary-запросов по тарифу пользователя
+	DisableRateLimiter bool `yaml:"DisableRateLimiter"`
+	// RetryPolicy - политика повтора unary-запросов на стороне grpc
+	RetryPolicy RetryConfig `yaml:"RetryPolicy"`
+	// KeepAlive - параметры keepalive соединения, при нулевом Time не используются
+	KeepAlive KeepAliveConfig `yaml:"KeepAlive"`
+	// MaxRecvMsgSize, MaxSendMsgSize - максимальный размер сообщения в байтах, 0 - значение grpc по умолчанию
+	MaxRecvMsgSize int `yaml:"MaxRecvMsgSize"`
+	MaxSendMsgSize int `yaml:"MaxSendMsgSize"`
+	// DialTimeout - время ожидания установки соединения в NewClient, 0 - соединение устанавливается в фоне
+	DialTimeout time.Duration `yaml:"DialTimeout"`
+	// Insecure - соединение без TLS, например для локального тестового сервера
+	Insecure bool `yaml:"Insecure"`
+	// DialOptions - дополнительные опции grpc соединения, применяются после опций из конфигурации
+	DialOptions []grpc.DialOption `yaml:"-"`
+}
+
+// RetryConfig - политика повтора unary-запросов, нулевые поля заменяются значениями по умолчанию
+type RetryConfig struct {
+	// MaxAttempts - общее количество попыток, включая первую, 1 - без повторов. grpc ограничивает значение пятью
+	MaxAttempts          int           `yaml:"MaxAttempts"`
+	InitialBackoff       time.Duration `yaml:"InitialBackoff"`
+	MaxBackoff           time.Duration `yaml:"MaxBackoff"`
+	BackoffMultiplier    float64       `yaml:"BackoffMultiplier"`
+	RetryableStatusCodes []string      `yaml:"RetryableStatusCodes"`
 }
 
+// KeepAliveConfig - параметры keepalive соединения
+type KeepAliveConfig struct {
+	// Time - интервал отправки ping при отсутствии активности
+	Time time.Duration `yaml:"Time"`
+	// Timeout - время ожидания ответа на ping
+	Timeout time.Duration `yaml:"Timeout"`
+	// PermitWithoutStream - отправлять ping при отсутствии активных запросов
+	PermitWithoutStream bool `yaml:"PermitWithoutStream"`
+}
+
+// configFile - структура yaml файла конфигурации: общие параметры и именованные профили,
+// параметры профиля переопределяют общие
+type configFile struct {
+	Config   `yaml:",inline"`
+	Profiles map[string]yaml.Node `yaml:"Profiles"`
+}
+
+// LoadConfig - загрузка конфигурации из yaml файла. Профиль выбирается переменной окружения INVEST_PROFILE,
+// затем применяются переопределения из переменных окружения INVEST_*
 func LoadConfig(filename string) (Config, error) {
-	var c Config
+	return LoadConfigProfile(filename, os.Getenv(EnvProfile))
+}
+
+// LoadConfigProfile - загрузка профиля profile из yaml файла, пустое имя профиля - только общие параметры.
+// После профиля применяются переопределения из переменных окружения INVEST_*
+func LoadConfigProfile(filename, profile string) (Config, error) {
 	input, err := os.ReadFile(filename)
 	if err != nil {
 		return Config{}, err
 	}
-	err = yaml.Unmarshal(input, &c)
+	var file configFile
+	err = yaml.Unmarshal(input, &file)
 	if err != nil {
-		log.Println(err)
+		return Config{}, fmt.Errorf("config %v: %w", filename, err)
+	}
+	c := file.Config
+	if profile != "" {
+		node, ok := file.Profiles[profile]
+		if !ok {
+			return Config{}, fmt.Errorf("config %v: profile %q not found, available profiles: %v", filename, profile, profileNames(file.Profiles))
+		}
+		err = node.Decode(&c)
+		if err != nil {
+			return Config{}, fmt.Errorf("config %v: profile %q: %w", filename, profile, err)
+		}
+		// профиль с флагом Sandbox без своего адреса не наследует общий адрес, адрес выбирается по флагу
+		if hasKey(&node, "Sandbox") && !hasKey(&node, "EndPoint") {
+			c.EndPoint = ""
+		}
+	}
+	err = c.loadEnv()
+	if err != nil {
+		return Config{}, err
+	}
+	c, err = c.resolve()
+	if err != nil {
+		return Config{}, fmt.Errorf("config %v: %w", filename, err)
+	}
+	err = c.Validate()
+	if err != nil {
+		return Config{}, fmt.Errorf("config %v: %w", filename, err)
+	}
+	return c, nil
+}
+
+// LoadConfigFromEnv - конфигурация только из переменных окружения INVEST_*
+func LoadConfigFromEnv() (Config, error) {
+	var c Config
+	err := c.loadEnv()
+	if err != nil {
+		return Config{}, err
+	}
+	c, err = c.resolve()
+	if err != nil {
+		return Config{}, err
+	}
+	return c, c.Validate()
+}
+
+// hasKey - true, если в yaml mapping задан ключ key
+func hasKey(node *yaml.Node, key string) bool {
+	if node.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return true
+		}
+	}
+	return false
+}
+
+func profileNames(profiles map[string]yaml.Node) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// loadEnv - переопределение параметров из переменных окружения. Токен из INVEST_TOKEN_FILE заменяет
+// токен из файла конфигурации, INVEST_TOKEN заменяет оба. INVEST_SANDBOX без INVEST_ENDPOINT сбрасывает
+// адрес из файла, чтобы адрес был выбран по флагу
+func (c *Config) loadEnv() error {
+	if v, ok := os.LookupEnv(EnvTokenFile); ok {
+		c.TokenFile = v
+		c.Token = ""
+	}
+	if v, ok := os.LookupEnv(EnvToken); ok {
+		c.Token = v
+	}
+	if v, ok := os.LookupEnv(EnvEndPoint); ok {
+		c.EndPoint = v
+	}
+	if v, ok := os.LookupEnv(EnvAppName); ok {
+		c.AppName = v
+	}
+	if v, ok := os.LookupEnv(EnvAccountId); ok {
+		c.AccountId = v
+	}
+	if v, ok := os.LookupEnv(EnvSandbox); ok {
+		sandbox, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("%v: %w", EnvSandbox, err)
+		}
+		c.Sandbox = sandbox
+		if _, ok := os.LookupEnv(EnvEndPoint); !ok {
+			c.EndPoint = ""
+		}
+	}
+	return nil
+}
+
+// resolve - чтение токена из TokenFile и выбор адреса по флагу Sandbox
+func (c Config) resolve() (Config, error) {
+	if c.Token == "" && c.TokenFile != "" {
+		token, err := os.ReadFile(os.ExpandEnv(c.TokenFile))
+		if err != nil {
+			return Config{}, fmt.Errorf("read token file: %w", err)
+		}
+		c.Token = strings.TrimSpace(string(token))
+	}
+	if c.EndPoint == "" {
+		if c.Sandbox {
+			c.EndPoint = EndPointSandbox
+		} else {
+			c.EndPoint = EndPointProd
+		}
 	}
 	return c, nil
 }
+
+// Validate - проверка корректности конфигурации
+func (c Config) Validate() error {
+	var errs []error
+	if c.EndPoint == "" {
+		errs = append(errs, errors.New("EndPoint is empty"))
+	}
+	if c.Token == "" {
+		errs = append(errs, errors.New("APIToken is empty"))
+	}
+	if err := c.checkSandbox(); err != nil {
+		errs = append(errs, err)
+	}
+	if c.MaxRecvMsgSize < 0 {
+		errs = append(errs, fmt.Errorf("MaxRecvMsgSize must not be negative, got %v", c.MaxRecvMsgSize))
+	}
+	if c.MaxSendMsgSize < 0 {
+		errs = append(errs, fmt.Errorf("MaxSendMsgSize must not be negative, got %v", c.MaxSendMsgSize))
+	}
+	if c.DialTimeout < 0 {
+		errs = append(errs, fmt.Errorf("DialTimeout must not be negative, got %v", c.DialTimeout))
+	}
+	if c.KeepAlive.Time < 0 || c.KeepAlive.Timeout < 0 {
+		errs = append(errs, errors.New("KeepAlive durations must not be negative"))
+	}
+	if err := c.RetryPolicy.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("RetryPolicy: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// checkSandbox - проверка соответствия адреса флагу Sandbox, чтобы запросы песочницы не ушли в продуктовый контур
+func (c Config) checkSandbox() error {
+	if c.Sandbox && c.EndPoint == EndPointProd {
+		return fmt.Errorf("Sandbox is set but EndPoint is %v", EndPointProd)
+	}
+	return nil
+}
+
+func (r RetryConfig) validate() error {
+	var errs []error
+	if r.MaxAttempts < 0 {
+		errs = append(errs, fmt.Errorf("MaxAttempts must not be negative, got %v", r.MaxAttempts))
+	}
+	if r.InitialBackoff < 0 || r.MaxBackoff < 0 {
+		errs = append(errs, errors.New("backoff durations must not be negative"))
+	}
+	if r.InitialBackoff > 0 && r.MaxBackoff > 0 && r.MaxBackoff < r.InitialBackoff {
+		errs = append(errs, fmt.Errorf("MaxBackoff %v is less than InitialBackoff %v", r.MaxBackoff, r.InitialBackoff))
+	}
+	if r.BackoffMultiplier < 0 {
+		errs = append(errs, fmt.Errorf("BackoffMultiplier must not be negative, got %v", r.BackoffMultiplier))
+	}
+	for _, name := range r.RetryableStatusCodes {
+		var code codes.Code
+		if err := code.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(name)))); err != nil {
+			errs = append(errs, fmt.Errorf("unknown retryable status code %q", name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// withDefaults - заполнение нулевых полей значениями по умолчанию
+func (r RetryConfig) withDefaults() RetryConfig {
+	if r.MaxAttempts == 0 {
+		r.MaxAttempts = 5
+	}
+	if r.InitialBackoff == 0 {
+		r.InitialBackoff = time.Second
+	}
+	if r.MaxBackoff == 0 {
+		r.MaxBackoff = r.InitialBackoff
+	}
+	if r.BackoffMultiplier == 0 {
+		r.BackoffMultiplier = 1
+	}
+	if len(r.RetryableStatusCodes) == 0 {
+		r.RetryableStatusCodes = []string{"UNAVAILABLE"}
+	}
+	return r
+}
+
+// serviceConfig - json конфигурация сервиса grpc с политикой повторов
+func (r RetryConfig) serviceConfig() (string, error) {
+	r = r.withDefaults()
+	type retryPolicy struct {
+		MaxAttempts          int
+		InitialBackoff       string
+		MaxBackoff           string
+		BackoffMultiplier    float64
+		RetryableStatusCodes []string
+	}
+	type methodConfig struct {
+		Name         []struct{}   `json:"name"`
+		WaitForReady bool         `json:"waitForReady"`
+		RetryPolicy  *retryPolicy `json:"retryPolicy,omitempty"`
+	}
+	mc := methodConfig{
+		Name:         []struct{}{{}},
+		WaitForReady: true,
+	}
+	if r.MaxAttempts > 1 {
+		statusCodes := make([]string, 0, len(r.RetryableStatusCodes))
+		for _, c := range r.RetryableStatusCodes {
+			statusCodes = append(statusCodes, strings.ToUpper(c))
+		}
+		mc.RetryPolicy = &retryPolicy{
+			MaxAttempts:          r.MaxAttempts,
+			InitialBackoff:       durationString(r.InitialBackoff),
+			MaxBackoff:           durationString(r.MaxBackoff),
+			BackoffMultiplier:    r.BackoffMultiplier,
+			RetryableStatusCodes: statusCodes,
+		}
+	}
+	sc, err := json.Marshal(map[string][]methodConfig{"methodConfig": {mc}})
+	if err != nil {
+		return "", err
+	}
+	return string(sc), nil
+}
+
+// durationString - длительность в формате google.protobuf.Duration для json
+func durationString(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
@@ -0,0 +1,255 @@
+package investgo
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testConfig = `EndPoint: invest-public-api.tinkoff.ru:443
+APIToken: base-token
+Profiles:
+  sandbox:
+    Sandbox: true
+  local:
+    Sandbox: true
+    EndPoint: localhost:8080
+  prod-sandbox:
+    Sandbox: true
+    EndPoint: invest-public-api.tinkoff.ru:443
+`
+
+func writeTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigProfileSandboxEndPoint(t *testing.T) {
+	path := writeTestConfig(t, testConfig)
+	tests := []struct {
+		profile  string
+		endPoint string
+	}{
+		{profile: "", endPoint: EndPointProd},
+		{profile: "sandbox", endPoint: EndPointSandbox},
+		{profile: "local", endPoint: "localhost:8080"},
+	}
+	for _, tt := range tests {
+		c, err := LoadConfigProfile(path, tt.profile)
+		if err != nil {
+			t.Fatalf("profile %q: %v", tt.profile, err)
+		}
+		if c.EndPoint != tt.endPoint {
+			t.Errorf("profile %q: EndPoint = %v, want %v", tt.profile, c.EndPoint, tt.endPoint)
+		}
+	}
+	if _, err := LoadConfigProfile(path, "prod-sandbox"); err == nil {
+		t.Error("profile with Sandbox and production EndPoint: expected error")
+	}
+}
+
+func TestLoadConfigEnvSandbox(t *testing.T) {
+	path := writeTestConfig(t, testConfig)
+	t.Setenv(EnvSandbox, "true")
+	c, err := LoadConfigProfile(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.EndPoint != EndPointSandbox {
+		t.Errorf("EndPoint = %v, want %v", c.EndPoint, EndPointSandbox)
+	}
+}
+
+func TestLoadConfigEnvTokenFile(t *testing.T) {
+	path := writeTestConfig(t, testConfig)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvTokenFile, tokenFile)
+	c, err := LoadConfigProfile(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Token != "file-token" {
+		t.Errorf("Token = %q, want token from %v", c.Token, EnvTokenFile)
+	}
+
+	t.Setenv(EnvToken, "env-token")
+	c, err = LoadConfigProfile(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Token != "env-token" {
+		t.Errorf("Token = %q, want token from %v", c.Token, EnvToken)
+	}
+}
+
+func TestLoadConfigConnectionOptions(t *testing.T) {
+	path := writeTestConfig(t, `EndPoint: localhost:8080
+APIToken: token
+RetryPolicy:
+  MaxAttempts: 3
+  InitialBackoff: 100ms
+  MaxBackoff: 2s
+  BackoffMultiplier: 1.5
+  RetryableStatusCodes: [unavailable, RESOURCE_EXHAUSTED]
+KeepAlive:
+  Time: 30s
+  Timeout: 5s
+  PermitWithoutStream: true
+MaxRecvMsgSize: 8388608
+MaxSendMsgSize: 1048576
+DialTimeout: 3s
+Insecure: true
+`)
+	c, err := LoadConfigProfile(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRetry := RetryConfig{
+		MaxAttempts:          3,
+		InitialBackoff:       100 * time.Millisecond,
+		MaxBackoff:           2 * time.Second,
+		BackoffMultiplier:    1.5,
+		RetryableStatusCodes: []string{"unavailable", "RESOURCE_EXHAUSTED"},
+	}
+	if !reflect.DeepEqual(c.RetryPolicy, wantRetry) {
+		t.Errorf("RetryPolicy = %+v, want %+v", c.RetryPolicy, wantRetry)
+	}
+	if c.KeepAlive != (KeepAliveConfig{Time: 30 * time.Second, Timeout: 5 * time.Second, PermitWithoutStream: true}) {
+		t.Errorf("KeepAlive = %+v", c.KeepAlive)
+	}
+	if c.MaxRecvMsgSize != 8<<20 || c.MaxSendMsgSize != 1<<20 || c.DialTimeout != 3*time.Second || !c.Insecure {
+		t.Errorf("config %+v", c)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{name: "invalid yaml", config: "EndPoint: [localhost", want: "yaml"},
+		{name: "invalid duration", config: "APIToken: token\nDialTimeout: soon\n", want: "cannot unmarshal !!str `soon` into time.Duration"},
+		{name: "no token", config: "EndPoint: localhost:8080\n", want: "APIToken is empty"},
+		{name: "negative message size", config: "APIToken: token\nMaxRecvMsgSize: -1\n", want: "MaxRecvMsgSize must not be negative"},
+		{name: "unknown status code", config: "APIToken: token\nRetryPolicy:\n  RetryableStatusCodes: [SOMETIMES]\n", want: `unknown retryable status code "SOMETIMES"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestConfig(t, tt.config)
+			_, err := LoadConfigProfile(path, "")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{EndPoint: "localhost:8080", Token: "token"}
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   []string
+	}{
+		{name: "valid", modify: func(c *Config) {}},
+		{
+			name:   "empty",
+			modify: func(c *Config) { *c = Config{} },
+			want:   []string{"EndPoint is empty", "APIToken is empty"},
+		},
+		{
+			name:   "sandbox with production endpoint",
+			modify: func(c *Config) { c.Sandbox, c.EndPoint = true, EndPointProd },
+			want:   []string{"Sandbox is set"},
+		},
+		{
+			name: "negative connection options",
+			modify: func(c *Config) {
+				c.MaxSendMsgSize = -1
+				c.DialTimeout = -time.Second
+				c.KeepAlive.Timeout = -time.Second
+			},
+			want: []string{"MaxSendMsgSize must not be negative", "DialTimeout must not be negative", "KeepAlive durations must not be negative"},
+		},
+		{
+			name: "invalid retry policy",
+			modify: func(c *Config) {
+				c.RetryPolicy = RetryConfig{MaxAttempts: -1, InitialBackoff: time.Second, MaxBackoff: time.Millisecond, BackoffMultiplier: -2}
+			},
+			want: []string{"RetryPolicy: MaxAttempts must not be negative", "MaxBackoff 1ms is less than InitialBackoff 1s", "BackoffMultiplier must not be negative"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid
+			tt.modify(&c)
+			err := c.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Errorf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate: no error, want %v", tt.want)
+			}
+			// все ошибки конфигурации возвращаются вместе
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryConfigServiceConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryConfig
+		want   string
+	}{
+		{
+			name: "defaults",
+			want: `{"methodConfig":[{"name":[{}],"waitForReady":true,"retryPolicy":{"MaxAttempts":5,"InitialBackoff":"1s","MaxBackoff":"1s","BackoffMultiplier":1,"RetryableStatusCodes":["UNAVAILABLE"]}}]}`,
+		},
+		{
+			name: "custom",
+			policy: RetryConfig{
+				MaxAttempts:          3,
+				InitialBackoff:       100 * time.Millisecond,
+				MaxBackoff:           2 * time.Second,
+				BackoffMultiplier:    1.5,
+				RetryableStatusCodes: []string{"unavailable", "internal"},
+			},
+			want: `{"methodConfig":[{"name":[{}],"waitForReady":true,"retryPolicy":{"MaxAttempts":3,"InitialBackoff":"0.1s","MaxBackoff":"2s","BackoffMultiplier":1.5,"RetryableStatusCodes":["UNAVAILABLE","INTERNAL"]}}]}`,
+		},
+		{
+			name:   "no retries",
+			policy: RetryConfig{MaxAttempts: 1},
+			want:   `{"methodConfig":[{"name":[{}],"waitForReady":true}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.policy.serviceConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("serviceConfig\n got %v\nwant %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package investgo
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorCategory - категория ошибки INVEST API
+type ErrorCategory int
+
+const (
+	// ErrorCategoryUnknown - категория не определена
+	ErrorCategoryUnknown ErrorCategory = iota
+	// ErrorCategoryInvalidArgument - некорректные параметры запроса
+	ErrorCategoryInvalidArgument
+	// ErrorCategoryUnauthenticated - токен не найден или не активен
+	ErrorCategoryUnauthenticated
+	// ErrorCategoryPermissionDenied - недостаточно прав для операции
+	ErrorCategoryPermissionDenied
+	// ErrorCategoryNotFound - запрашиваемый объект не найден
+	ErrorCategoryNotFound
+	// ErrorCategoryInsufficientFunds - недостаточно средств или активов
+	ErrorCategoryInsufficientFunds
+	// ErrorCategoryInstrumentNotTradable - торговля инструментом недоступна
+	ErrorCategoryInstrumentNotTradable
+	// ErrorCategoryRateLimit - превышен лимит запросов
+	ErrorCategoryRateLimit
+	// ErrorCategoryInternal - внутренняя ошибка сервиса
+	ErrorCategoryInternal
+	// ErrorCategoryUnavailable - сервис недоступен
+	ErrorCategoryUnavailable
+)
+
+func (c ErrorCategory) String() string {
+	switch c {
+	case ErrorCategoryInvalidArgument:
+		return "invalid argument"
+	case ErrorCategoryUnauthenticated:
+		return "unauthenticated"
+	case ErrorCategoryPermissionDenied:
+		return "permission denied"
+	case ErrorCategoryNotFound:
+		return "not found"
+	case ErrorCategoryInsufficientFunds:
+		return "insufficient funds"
+	case ErrorCategoryInstrumentNotTradable:
+		return "instrument not tradable"
+	case ErrorCategoryRateLimit:
+		return "rate limit"
+	case ErrorCategoryInternal:
+		return "internal"
+	case ErrorCategoryUnavailable:
+		return "unavailable"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrorCodeInfo - описание кода ошибки INVEST API
+type ErrorCodeInfo struct {
+	Description string
+	Category    ErrorCategory
+	Retryable   bool
+}
+
+// ErrorCodes - коды ошибок INVEST API, подробнее: https://tinkoff.github.io/investAPI/errors/
+var ErrorCodes = map[int]ErrorCodeInfo{
+	30001: {Description: "Некорректный входной параметр", Category: ErrorCategoryInvalidArgument},
+	30002: {Description: "Некорректный параметр from", Category: ErrorCategoryInvalidArgument},
+	30003: {Description: "Некорректный параметр to", Category: ErrorCategoryInvalidArgument},
+	30004: {Description: "Некорректный интервал свечей", Category: ErrorCategoryInvalidArgument},
+	30008: {Description: "Не указан идентификатор счета", Category: ErrorCategoryInvalidArgument},
+	30014: {Description: "Превышен максимальный период запроса для данного интервала свечи", Category: ErrorCategoryInvalidArgument},
+	30017: {Description: "Превышено максимальное количество инструментов в запросе", Category: ErrorCategoryInvalidArgument},
+	30034: {Description: "Недостаточно средств для совершения сделки", Category: ErrorCategoryInsufficientFunds},
+	30042: {Description: "Недостаточно активов для маржинальной сделки", Category: ErrorCategoryInsufficientFunds},
+	30052: {Description: "Для данного инструмента недоступна торговля через API", Category: ErrorCategoryInstrumentNotTradable},
+	30059: {Description: "Ошибка отмены заявки", Category: ErrorCategoryInvalidArgument},
+	30079: {Description: "Инструмент недоступен для торгов", Category: ErrorCategoryInstrumentNotTradable},
+	30081: {Description: "Счет закрыт", Category: ErrorCategoryPermissionDenied},
+	40002: {Description: "Недостаточно прав для совершения операции", Category: ErrorCategoryPermissionDenied},
+	40003: {Description: "Токен доступа не найден или не активен", Category: ErrorCategoryUnauthenticated},
+	40004: {Description: "Выставление заявок недоступно с текущего аккаунта", Category: ErrorCategoryPermissionDenied},
+	50002: {Description: "Инструмент не найден", Category: ErrorCategoryNotFound},
+	50004: {Description: "Счет не найден", Category: ErrorCategoryNotFound},
+	50005: {Description: "Заявка не найдена", Category: ErrorCategoryNotFound},
+	50006: {Description: "Стоп-заявка не найдена", Category: ErrorCategoryNotFound},
+	70001: {Description: "Внутренняя ошибка сервиса", Category: ErrorCategoryInternal, Retryable: true},
+	70002: {Description: "Внутренняя ошибка сети", Category: ErrorCategoryInternal, Retryable: true},
+	70003: {Description: "Сервис временно недоступен, попробуйте выполнить запрос позднее", Category: ErrorCategoryUnavailable, Retryable: true},
+	80001: {Description: "Превышен лимит одновременных открытых потоков", Category: ErrorCategoryRateLimit, Retryable: true},
+	80002: {Description: "Превышен лимит запросов в минуту", Category: ErrorCategoryRateLimit, Retryable: true},
+	90001: {Description: "Требуется подтверждение операции", Category: ErrorCategoryInvalidArgument},
+	90002: {Description: "Торговля этим инструментом доступна только квалифицированным инвесторам", Category: ErrorCategoryPermissionDenied},
+}
+
+// RateLimit - информация о лимите запросов из заголовков ответа, -1 если значение не передано
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     int
+}
+
+// APIError - ошибка, возвращаемая методами сервисов
+type APIError struct {
+	// Code - код ошибки INVEST API, 0 если код не удалось определить
+	Code int
+	// Status - grpc статус ответа
+	Status codes.Code
+	// TrackingId - идентификатор запроса из заголовка x-tracking-id
+	TrackingId string
+	// Message - описание ошибки из заголовка message
+	Message   string
+	RateLimit RateLimit
+	Category  ErrorCategory
+	Retryable bool
+
+	err error
+}
+
+// newAPIError - преобразование grpc ошибки в *APIError, md - заголовки (трейлеры) ответа
+func newAPIError(err error, md metadata.MD) error {
+	if err == nil {
+		return nil
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return err
+	}
+	st := status.Convert(err)
+	e := &APIError{
+		Status:     st.Code(),
+		TrackingId: TrackingIdFromHeader(md),
+		Message:    MessageFromHeader(md),
+		RateLimit: RateLimit{
+			Limit:     LimitFromHeader(md),
+			Remaining: RemainingLimitFromHeader(md),
+			Reset:     ResetLimitFromHeader(md),
+		},
+		err: err,
+	}
+	if code, convErr := strconv.Atoi(strings.TrimSpace(st.Message())); convErr == nil {
+		e.Code = code
+	}
+	if info, ok := ErrorCodes[e.Code]; ok {
+		e.Category = info.Category
+		e.Retryable = info.Retryable
+		if e.Message == "" {
+			e.Message = info.Description
+		}
+	} else {
+		e.Category, e.Retryable = categoryFromStatus(e.Status)
+	}
+	if e.Message == "" {
+		e.Message = st.Message()
+	}
+	return e
+}
+
+func categoryFromStatus(code codes.Code) (ErrorCategory, bool) {
+	switch code {
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return ErrorCategoryInvalidArgument, false
+	case codes.Unauthenticated:
+		return ErrorCategoryUnauthenticated, false
+	case codes.PermissionDenied:
+		return ErrorCategoryPermissionDenied, false
+	case codes.NotFound:
+		return ErrorCategoryNotFound, false
+	case codes.ResourceExhausted:
+		return ErrorCategoryRateLimit, true
+	case codes.Internal:
+		return ErrorCategoryInternal, true
+	case codes.Unavailable:
+		return ErrorCategoryUnavailable, true
+	default:
+		return ErrorCategoryUnknown, false
+	}
+}
+
+func (e *APIError) Error() string {
+	if e.Code != 0 {
+		return fmt.Sprintf("invest api error %v (%v): %v, tracking id = %v", e.Code, e.Status, e.Message, e.TrackingId)
+	}
+	return fmt.Sprintf("invest api error (%v): %v, tracking id = %v", e.Status, e.Message, e.TrackingId)
+}
+
+// Unwrap - исходная grpc ошибка
+func (e *APIError) Unwrap() error {
+	return e.err
+}
+
+// GRPCStatus - grpc статус ошибки, позволяет использовать status.Code(err)
+func (e *APIError) GRPCStatus() *status.Status {
+	return status.Convert(e.err)
+}
+
+// AsAPIError - приведение ошибки к *APIError
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// IsRetryable - true, если запрос с такой ошибкой имеет смысл повторить
+func IsRetryable(err error) bool {
+	if apiErr, ok := AsAPIError(err); ok {
+		return apiErr.Retryable
+	}
+	_, retryable := categoryFromStatus(status.Code(err))
+	return retryable
+}
+
+// IsRateLimitExceeded - true, если превышен лимит запросов
+func IsRateLimitExceeded(err error) bool {
+	return categoryOf(err) == ErrorCategoryRateLimit
+}
+
+// IsInsufficientFunds - true, если недостаточно средств или активов для операции
+func IsInsufficientFunds(err error) bool {
+	return categoryOf(err) == ErrorCategoryInsufficientFunds
+}
+
+// IsInstrumentNotTradable - true, если торговля инструментом недоступна
+func IsInstrumentNotTradable(err error) bool {
+	return categoryOf(err) == ErrorCategoryInstrumentNotTradable
+}
+
+func categoryOf(err error) ErrorCategory {
+	if apiErr, ok := AsAPIError(err); ok {
+		return apiErr.Category
+	}
+	return ErrorCategoryUnknown
+}
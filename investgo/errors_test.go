@@ -0,0 +1,121 @@
+package investgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestNewAPIError(t *testing.T) {
+	md := metadata.Pairs(
+		"x-tracking-id", "track-1",
+		"message", "Недостаточно средств",
+		"x-ratelimit-limit", "200, 200;w=60",
+		"x-ratelimit-remaining", "150",
+		"x-ratelimit-reset", "30",
+	)
+	grpcErr := status.Error(codes.InvalidArgument, "30034")
+
+	err := newAPIError(grpcErr, md)
+	apiErr, ok := AsAPIError(err)
+	if !ok {
+		t.Fatalf("%v is not *APIError", err)
+	}
+	if apiErr.Code != 30034 || apiErr.Status != codes.InvalidArgument {
+		t.Errorf("code %v status %v, want 30034 InvalidArgument", apiErr.Code, apiErr.Status)
+	}
+	if apiErr.TrackingId != "track-1" || apiErr.Message != "Недостаточно средств" {
+		t.Errorf("tracking id %q message %q", apiErr.TrackingId, apiErr.Message)
+	}
+	if apiErr.RateLimit != (RateLimit{Limit: 200, Remaining: 150, Reset: 30}) {
+		t.Errorf("rate limit %+v", apiErr.RateLimit)
+	}
+	if apiErr.Category != ErrorCategoryInsufficientFunds || apiErr.Retryable {
+		t.Errorf("category %v retryable %v", apiErr.Category, apiErr.Retryable)
+	}
+	if !IsInsufficientFunds(err) {
+		t.Error("IsInsufficientFunds = false")
+	}
+	if !errors.Is(err, grpcErr) || status.Code(err) != codes.InvalidArgument {
+		t.Error("grpc error is not preserved")
+	}
+	if newAPIError(err, nil) != err {
+		t.Error("*APIError is wrapped twice")
+	}
+	if newAPIError(nil, md) != nil {
+		t.Error("nil error is converted")
+	}
+}
+
+func TestNewAPIErrorWithoutMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		code     int
+		message  string
+		category ErrorCategory
+	}{
+		{
+			name:     "known code",
+			err:      status.Error(codes.NotFound, "50002"),
+			code:     50002,
+			message:  ErrorCodes[50002].Description,
+			category: ErrorCategoryNotFound,
+		},
+		{
+			name:     "unknown code",
+			err:      status.Error(codes.PermissionDenied, "49999"),
+			code:     49999,
+			message:  "49999",
+			category: ErrorCategoryPermissionDenied,
+		},
+		{
+			name:     "no code",
+			err:      status.Error(codes.Unavailable, "connection refused"),
+			message:  "connection refused",
+			category: ErrorCategoryUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr, _ := AsAPIError(newAPIError(tt.err, nil))
+			if apiErr.Code != tt.code || apiErr.Message != tt.message || apiErr.Category != tt.category {
+				t.Errorf("got code %v message %q category %v, want %v %q %v",
+					apiErr.Code, apiErr.Message, apiErr.Category, tt.code, tt.message, tt.category)
+			}
+			if apiErr.RateLimit != (RateLimit{Limit: -1, Remaining: -1, Reset: -1}) {
+				t.Errorf("rate limit %+v, want -1", apiErr.RateLimit)
+			}
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "internal code", err: newAPIError(status.Error(codes.Internal, "70001"), nil), want: true},
+		{name: "rate limit code", err: newAPIError(status.Error(codes.ResourceExhausted, "80002"), nil), want: true},
+		{name: "insufficient funds code", err: newAPIError(status.Error(codes.InvalidArgument, "30034"), nil), want: false},
+		{name: "unknown code unavailable", err: newAPIError(status.Error(codes.Unavailable, "1"), nil), want: true},
+		{name: "wrapped api error", err: fmt.Errorf("post order: %w", newAPIError(status.Error(codes.Internal, "70002"), nil)), want: true},
+		{name: "grpc unavailable", err: status.Error(codes.Unavailable, "unavailable"), want: true},
+		{name: "grpc unauthenticated", err: status.Error(codes.Unauthenticated, "40003"), want: false},
+		{name: "context canceled", err: context.Canceled, want: false},
+		{name: "plain error", err: errors.New("fail"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package investgo
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/google/uuid"
 	"google.golang.org/grpc/metadata"
-	"strconv"
 )
 
 // CreateUid - возвращает строку - уникальный идентификатор длинной 16 байт
@@ -33,3 +35,41 @@ func RemainingLimitFromHeader(md metadata.MD) int {
 	}
 	return -1
 }
+
+// ResetLimitFromHeader - Метод извлечения времени до обнуления лимита запросов в секундах из заголовка, возвращает -1 при ошибке
+func ResetLimitFromHeader(md metadata.MD) int {
+	resets := md.Get("x-ratelimit-reset")
+	if len(resets) > 0 {
+		reset := resets[0]
+		resetAsNum, err := strconv.Atoi(reset)
+		if err != nil {
+			return -1
+		}
+		return resetAsNum
+	}
+	return -1
+}
+
+// LimitFromHeader - Метод извлечения лимита запросов из заголовка, возвращает -1 при ошибке
+func LimitFromHeader(md metadata.MD) int {
+	limits := md.Get("x-ratelimit-limit")
+	if len(limits) > 0 {
+		// значение может содержать описание окна, например "200, 200;w=60"
+		lim, _, _ := strings.Cut(limits[0], ",")
+		limAsNum, err := strconv.Atoi(strings.TrimSpace(lim))
+		if err != nil {
+			return -1
+		}
+		return limAsNum
+	}
+	return -1
+}
+
+// TrackingIdFromHeader - Метод извлечения идентификатора запроса из заголовка
+func TrackingIdFromHeader(md metadata.MD) string {
+	ids := md.Get("x-tracking-id")
+	if len(ids) > 0 {
+		return ids[0]
+	}
+	return ""
+}
@@ -0,0 +1,179 @@
+package investgo
+
+import (
+	"errors"
+	"fmt"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+// PriceRounding - направление округления цены до шага цены инструмента
+type PriceRounding int
+
+const (
+	// PriceRoundNearest - до ближайшего шага цены, середина округляется вверх
+	PriceRoundNearest PriceRounding = iota
+	// PriceRoundDown - до шага цены не выше исходной цены, например для заявки на покупку
+	PriceRoundDown
+	// PriceRoundUp - до шага цены не ниже исходной цены, например для заявки на продажу
+	PriceRoundUp
+)
+
+func (r PriceRounding) mode() pb.RoundingMode {
+	switch r {
+	case PriceRoundDown:
+		return pb.RoundFloor
+	case PriceRoundUp:
+		return pb.RoundCeiling
+	default:
+		return pb.RoundHalfUp
+	}
+}
+
+// TradableInstrument - общие поля pb.Share, pb.Bond, pb.Etf, pb.Future, pb.Currency и pb.Instrument
+type TradableInstrument interface {
+	GetFigi() string
+	GetUid() string
+	GetTicker() string
+	GetClassCode() string
+	GetCurrency() string
+	GetLot() int32
+	GetMinPriceIncrement() *pb.Quotation
+}
+
+// InstrumentParams - параметры инструмента для нормализации цены и количества в заявках
+type InstrumentParams struct {
+	Figi      string
+	Uid       string
+	Ticker    string
+	ClassCode string
+	Currency  string
+	// InstrumentType - тип инструмента: share, bond, etf, future, currency
+	InstrumentType string
+	// Lot - количество бумаг в лоте
+	Lot int32
+	// MinPriceIncrement - шаг цены, для облигаций в процентах от номинала
+	MinPriceIncrement *pb.Quotation
+	// Nominal - номинал, для облигаций используется при пересчете цены из процентов
+	Nominal *pb.MoneyValue
+}
+
+// NewInstrumentParams - параметры инструмента из ответа сервиса инструментов, например
+// NewInstrumentParams(resp.GetInstrument()) для ShareByFigi
+func NewInstrumentParams(i TradableInstrument) *InstrumentParams {
+	p := &InstrumentParams{
+		Figi:              i.GetFigi(),
+		Uid:               i.GetUid(),
+		Ticker:            i.GetTicker(),
+		ClassCode:         i.GetClassCode(),
+		Currency:          i.GetCurrency(),
+		Lot:               i.GetLot(),
+		MinPriceIncrement: i.GetMinPriceIncrement(),
+	}
+	switch v := i.(type) {
+	case *pb.Share:
+		p.InstrumentType = "share"
+		p.Nominal = v.GetNominal()
+	case *pb.Bond:
+		p.InstrumentType = "bond"
+		p.Nominal = v.GetNominal()
+	case *pb.Etf:
+		p.InstrumentType = "etf"
+	case *pb.Future:
+		p.InstrumentType = "future"
+	case *pb.Currency:
+		p.InstrumentType = "currency"
+		p.Nominal = v.GetNominal()
+	case *pb.Instrument:
+		p.InstrumentType = v.GetInstrumentType()
+	}
+	return p
+}
+
+// InstrumentId - идентификатор инструмента для запросов, uid или figi если uid не задан
+func (p *InstrumentParams) InstrumentId() string {
+	if p.Uid != "" {
+		return p.Uid
+	}
+	return p.Figi
+}
+
+// RoundPrice - округление цены до шага цены инструмента, при нулевом шаге цена не изменяется
+func (p *InstrumentParams) RoundPrice(price *pb.Quotation, rounding PriceRounding) *pb.Quotation {
+	return price.RoundToStep(p.MinPriceIncrement, rounding.mode())
+}
+
+// IsValidPrice - true, если цена кратна шагу цены инструмента
+func (p *InstrumentParams) IsValidPrice(price *pb.Quotation) bool {
+	return price.IsMultipleOf(p.MinPriceIncrement)
+}
+
+// PiecesToLots - количество целых лотов в pieces бумагах и остаток в бумагах, не кратный лоту
+func (p *InstrumentParams) PiecesToLots(pieces int64) (lots int64, rest int64) {
+	if p.Lot <= 0 {
+		return pieces, 0
+	}
+	return pieces / int64(p.Lot), pieces % int64(p.Lot)
+}
+
+// LotsToPieces - количество бумаг в lots лотах
+func (p *InstrumentParams) LotsToPieces(lots int64) int64 {
+	if p.Lot <= 0 {
+		return lots
+	}
+	return lots * int64(p.Lot)
+}
+
+// BondPrice - цена одной облигации в валюте номинала по цене в процентах от номинала
+func (p *InstrumentParams) BondPrice(percent *pb.Quotation) (*pb.MoneyValue, error) {
+	if p.Nominal.IsZero() {
+		return nil, fmt.Errorf("instrument %v has no nominal", p.InstrumentId())
+	}
+	hundred := &pb.Quotation{Units: 100}
+	share, err := percent.DivRound(hundred, pb.RoundHalfUp)
+	if err != nil {
+		return nil, err
+	}
+	return p.Nominal.Mul(share)
+}
+
+// BondPercent - цена облигации в процентах от номинала по цене в валюте, округляется до шага цены
+func (p *InstrumentParams) BondPercent(price *pb.MoneyValue, rounding PriceRounding) (*pb.Quotation, error) {
+	if p.Nominal.IsZero() {
+		return nil, fmt.Errorf("instrument %v has no nominal", p.InstrumentId())
+	}
+	ratio, err := price.Ratio(p.Nominal, pb.RoundHalfUp)
+	if err != nil {
+		return nil, err
+	}
+	percent, err := ratio.MulInt(100)
+	if err != nil {
+		return nil, err
+	}
+	return p.RoundPrice(percent, rounding), nil
+}
+
+// PostOrderRequest - заявка на pieces бумаг по цене price, округленной до шага цены с rounding.
+// При nil price создается рыночная заявка. Количество должно быть кратно лоту, OrderId заполняется
+// новым идентификатором CreateUid
+func (p *InstrumentParams) PostOrderRequest(direction pb.OrderDirection, pieces int64, price *pb.Quotation, rounding PriceRounding) (*PostOrderRequest, error) {
+	lots, rest := p.PiecesToLots(pieces)
+	if rest != 0 {
+		return nil, fmt.Errorf("quantity %v is not a multiple of lot %v", pieces, p.Lot)
+	}
+	if lots <= 0 {
+		return nil, errors.New("quantity must be positive")
+	}
+	req := &PostOrderRequest{
+		InstrumentId: p.InstrumentId(),
+		Quantity:     lots,
+		Direction:    direction,
+		OrderType:    pb.OrderType_ORDER_TYPE_MARKET,
+		OrderId:      CreateUid(),
+	}
+	if price != nil {
+		req.Price = p.RoundPrice(price, rounding)
+		req.OrderType = pb.OrderType_ORDER_TYPE_LIMIT
+	}
+	return req, nil
+}
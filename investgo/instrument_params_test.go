@@ -0,0 +1,136 @@
+package investgo_test
+
+import (
+	"testing"
+
+	"github.com/therox/invest-api-go-sdk/investgo"
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+func testShareParams() *investgo.InstrumentParams {
+	return investgo.NewInstrumentParams(&pb.Share{
+		Figi:              "figi",
+		Uid:               "uid",
+		Currency:          "rub",
+		Lot:               10,
+		MinPriceIncrement: pb.NewQuotation(0, 50000000),
+	})
+}
+
+func TestInstrumentParamsRoundPrice(t *testing.T) {
+	p := testShareParams()
+	tests := []struct {
+		price    string
+		rounding investgo.PriceRounding
+		want     string
+	}{
+		{price: "100.07", rounding: investgo.PriceRoundNearest, want: "100.05"},
+		{price: "100.075", rounding: investgo.PriceRoundNearest, want: "100.1"},
+		{price: "100.07", rounding: investgo.PriceRoundDown, want: "100.05"},
+		{price: "100.01", rounding: investgo.PriceRoundUp, want: "100.05"},
+		{price: "100.05", rounding: investgo.PriceRoundUp, want: "100.05"},
+		{price: "-0.07", rounding: investgo.PriceRoundDown, want: "-0.1"},
+	}
+	for _, tt := range tests {
+		price, err := pb.QuotationFromString(tt.price)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := pb.QuotationFromString(tt.want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := p.RoundPrice(price, tt.rounding)
+		if got.Cmp(want) != 0 {
+			t.Errorf("RoundPrice(%v, %v) = %v, want %v", tt.price, tt.rounding, got.ToString(), tt.want)
+		}
+		if !p.IsValidPrice(got) {
+			t.Errorf("rounded price %v is not a multiple of the price increment", got.ToString())
+		}
+	}
+	if p.IsValidPrice(pb.NewQuotation(100, 70000000)) {
+		t.Error("100.07 is valid with price increment 0.05")
+	}
+
+	// без шага цены цена не округляется
+	p.MinPriceIncrement = nil
+	if got := p.RoundPrice(pb.NewQuotation(100, 70000000), investgo.PriceRoundUp); got.Cmp(pb.NewQuotation(100, 70000000)) != 0 {
+		t.Errorf("price %v without price increment, want 100.07", got.ToString())
+	}
+}
+
+func TestInstrumentParamsLots(t *testing.T) {
+	p := testShareParams()
+	if lots, rest := p.PiecesToLots(25); lots != 2 || rest != 5 {
+		t.Errorf("25 pieces: %v lots and %v rest, want 2 and 5", lots, rest)
+	}
+	if n := p.LotsToPieces(3); n != 30 {
+		t.Errorf("3 lots: %v pieces, want 30", n)
+	}
+	if p.InstrumentId() != "uid" || p.InstrumentType != "share" {
+		t.Errorf("instrument id %v, type %v", p.InstrumentId(), p.InstrumentType)
+	}
+}
+
+func TestInstrumentParamsBondPrice(t *testing.T) {
+	p := investgo.NewInstrumentParams(&pb.Bond{
+		Figi:              "bond",
+		Lot:               1,
+		MinPriceIncrement: pb.NewQuotation(0, 10000000),
+		Nominal:           &pb.MoneyValue{Currency: "rub", Units: 1000},
+	})
+	price, err := p.BondPrice(pb.NewQuotation(98, 750000000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price.GetCurrency() != "rub" || price.ToQuotation().Cmp(pb.NewQuotation(987, 500000000)) != 0 {
+		t.Errorf("price of 98.75%% is %v %v, want 987.5 rub", price.ToString(), price.GetCurrency())
+	}
+
+	// 987.456 rub = 98.7456% округляется до шага 0.01%
+	percent, err := p.BondPercent(&pb.MoneyValue{Currency: "rub", Units: 987, Nano: 456000000}, investgo.PriceRoundDown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if percent.Cmp(pb.NewQuotation(98, 740000000)) != 0 {
+		t.Errorf("percent %v, want 98.74", percent.ToString())
+	}
+
+	share := testShareParams()
+	share.Nominal = nil
+	if _, err := share.BondPrice(pb.NewQuotation(100, 0)); err == nil {
+		t.Error("BondPrice without nominal is not an error")
+	}
+}
+
+func TestInstrumentParamsPostOrderRequest(t *testing.T) {
+	p := testShareParams()
+	req, err := p.PostOrderRequest(pb.OrderDirection_ORDER_DIRECTION_BUY, 30, pb.NewQuotation(100, 70000000), investgo.PriceRoundDown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.InstrumentId != "uid" || req.Quantity != 3 || req.OrderType != pb.OrderType_ORDER_TYPE_LIMIT ||
+		req.Price.Cmp(pb.NewQuotation(100, 50000000)) != 0 {
+		t.Errorf("limit order %+v, want 3 lots at 100.05", req)
+	}
+	limitOrderId := req.OrderId
+
+	req, err = p.PostOrderRequest(pb.OrderDirection_ORDER_DIRECTION_SELL, 10, nil, investgo.PriceRoundNearest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.OrderType != pb.OrderType_ORDER_TYPE_MARKET || req.Price != nil || req.Quantity != 1 {
+		t.Errorf("market order %+v, want 1 lot without price", req)
+	}
+	// каждая заявка получает свой идентификатор для идемпотентного выставления
+	if limitOrderId == "" || req.OrderId == "" || req.OrderId == limitOrderId {
+		t.Errorf("order ids %q and %q, want unique non-empty ids", limitOrderId, req.OrderId)
+	}
+
+	if _, err := p.PostOrderRequest(pb.OrderDirection_ORDER_DIRECTION_BUY, 15, nil, investgo.PriceRoundNearest); err == nil {
+		t.Error("quantity not a multiple of lot is not an error")
+	}
+	if _, err := p.PostOrderRequest(pb.OrderDirection_ORDER_DIRECTION_BUY, 0, nil, investgo.PriceRoundNearest); err == nil {
+		t.Error("zero quantity is not an error")
+	}
+}
@@ -1,10 +1,10 @@
 package investgo
 
 import (
+	"context"
 	"time"
 
 	pb "github.com/therox/invest-api-go-sdk/proto"
-	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 )
@@ -19,14 +19,20 @@ type InstrumentsServiceClient struct {
 
 // TradingSchedules - Метод получения расписания торгов торговых площадок
 func (is *InstrumentsServiceClient) TradingSchedules(exchange string, from, to time.Time) (*TradingSchedulesResponse, error) {
+	return is.TradingSchedulesCtx(is.ctx, exchange, from, to)
+}
+
+// TradingSchedulesCtx - TradingSchedules с контекстом запроса
+func (is *InstrumentsServiceClient) TradingSchedulesCtx(ctx context.Context, exchange string, from, to time.Time) (*TradingSchedulesResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.TradingSchedules(is.ctx, &pb.TradingSchedulesRequest{
+	resp, err := is.pbClient.TradingSchedules(ctx, &pb.TradingSchedulesRequest{
 		Exchange: exchange,
 		From:     TimeToTimestamp(from),
 		To:       TimeToTimestamp(to),
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &TradingSchedulesResponse{
 		TradingSchedulesResponse: resp,
@@ -36,33 +42,54 @@ func (is *InstrumentsServiceClient) TradingSchedules(exchange string, from, to t
 
 // BondByFigi - Метод получения облигации по figi
 func (is *InstrumentsServiceClient) BondByFigi(id string) (*BondResponse, error) {
-	return is.bondBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_FIGI, "")
+	return is.BondByFigiCtx(is.ctx, id)
+}
+
+// BondByFigiCtx - BondByFigi с контекстом запроса
+func (is *InstrumentsServiceClient) BondByFigiCtx(ctx context.Context, id string) (*BondResponse, error) {
+	return is.bondBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_FIGI, "")
 }
 
 // BondByTicker - Метод получения облигации по Ticker
 func (is *InstrumentsServiceClient) BondByTicker(id string, classCode string) (*BondResponse, error) {
-	return is.bondBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_TICKER, classCode)
+	return is.BondByTickerCtx(is.ctx, id, classCode)
+}
+
+// BondByTickerCtx - BondByTicker с контекстом запроса
+func (is *InstrumentsServiceClient) BondByTickerCtx(ctx context.Context, id string, classCode string) (*BondResponse, error) {
+	return is.bondBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_TICKER, classCode)
 }
 
 // BondByUid - Метод получения облигации по Uid
 func (is *InstrumentsServiceClient) BondByUid(id string) (*BondResponse, error) {
-	return is.bondBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_UID, "")
+	return is.BondByUidCtx(is.ctx, id)
+}
+
+// BondByUidCtx - BondByUid с контекстом запроса
+func (is *InstrumentsServiceClient) BondByUidCtx(ctx context.Context, id string) (*BondResponse, error) {
+	return is.bondBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_UID, "")
 }
 
 // BondByPositionUid - Метод получения облигации по PositionUid
 func (is *InstrumentsServiceClient) BondByPositionUid(id string) (*BondResponse, error) {
-	return is.bondBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_POSITION_UID, "")
+	return is.BondByPositionUidCtx(is.ctx, id)
+}
+
+// BondByPositionUidCtx - BondByPositionUid с контекстом запроса
+func (is *InstrumentsServiceClient) BondByPositionUidCtx(ctx context.Context, id string) (*BondResponse, error) {
+	return is.bondBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_POSITION_UID, "")
 }
 
-func (is *InstrumentsServiceClient) bondBy(id string, idType pb.InstrumentIdType, classCode string) (*BondResponse, error) {
+func (is *InstrumentsServiceClient) bondBy(ctx context.Context, id string, idType pb.InstrumentIdType, classCode string) (*BondResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.BondBy(is.ctx, &pb.InstrumentRequest{
+	resp, err := is.pbClient.BondBy(ctx, &pb.InstrumentRequest{
 		IdType:    idType,
 		ClassCode: classCode,
 		Id:        id,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &BondResponse{
 		BondResponse: resp,
@@ -72,12 +99,18 @@ func (is *InstrumentsServiceClient) bondBy(id string, idType pb.InstrumentIdType
 
 // Bonds - Метод получения списка облигаций
 func (is *InstrumentsServiceClient) Bonds(status pb.InstrumentStatus) (*BondsResponse, error) {
+	return is.BondsCtx(is.ctx, status)
+}
+
+// BondsCtx - Bonds с контекстом запроса
+func (is *InstrumentsServiceClient) BondsCtx(ctx context.Context, status pb.InstrumentStatus) (*BondsResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.Bonds(is.ctx, &pb.InstrumentsRequest{
+	resp, err := is.pbClient.Bonds(ctx, &pb.InstrumentsRequest{
 		InstrumentStatus: status,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &BondsResponse{
 		BondsResponse: resp,
@@ -87,14 +120,20 @@ func (is *InstrumentsServiceClient) Bonds(status pb.InstrumentStatus) (*BondsRes
 
 // GetBondCoupons - Метод получения графика выплат купонов по облигации
 func (is *InstrumentsServiceClient) GetBondCoupons(figi string, from, to time.Time) (*GetBondCouponsResponse, error) {
+	return is.GetBondCouponsCtx(is.ctx, figi, from, to)
+}
+
+// GetBondCouponsCtx - GetBondCoupons с контекстом запроса
+func (is *InstrumentsServiceClient) GetBondCouponsCtx(ctx context.Context, figi string, from, to time.Time) (*GetBondCouponsResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.GetBondCoupons(is.ctx, &pb.GetBondCouponsRequest{
+	resp, err := is.pbClient.GetBondCoupons(ctx, &pb.GetBondCouponsRequest{
 		Figi: figi,
 		From: TimeToTimestamp(from),
 		To:   TimeToTimestamp(to),
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetBondCouponsResponse{
 		GetBondCouponsResponse: resp,
@@ -104,33 +143,54 @@ func (is *InstrumentsServiceClient) GetBondCoupons(figi string, from, to time.Ti
 
 // CurrencyByFigi - Метод получения валюты по Figi
 func (is *InstrumentsServiceClient) CurrencyByFigi(id string) (*CurrencyResponse, error) {
-	return is.currenceBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_FIGI, "")
+	return is.CurrencyByFigiCtx(is.ctx, id)
+}
+
+// CurrencyByFigiCtx - CurrencyByFigi с контекстом запроса
+func (is *InstrumentsServiceClient) CurrencyByFigiCtx(ctx context.Context, id string) (*CurrencyResponse, error) {
+	return is.currenceBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_FIGI, "")
 }
 
 // CurrencyByTicker - Метод получения валюты по Ticker
 func (is *InstrumentsServiceClient) CurrencyByTicker(id string, classCode string) (*CurrencyResponse, error) {
-	return is.currenceBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_TICKER, classCode)
+	return is.CurrencyByTickerCtx(is.ctx, id, classCode)
+}
+
+// CurrencyByTickerCtx - CurrencyByTicker с контекстом запроса
+func (is *InstrumentsServiceClient) CurrencyByTickerCtx(ctx context.Context, id string, classCode string) (*CurrencyResponse, error) {
+	return is.currenceBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_TICKER, classCode)
 }
 
 // CurrencyByUid - Метод получения валюты по Uid
 func (is *InstrumentsServiceClient) CurrencyByUid(id string) (*CurrencyResponse, error) {
-	return is.currenceBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_UID, "")
+	return is.CurrencyByUidCtx(is.ctx, id)
+}
+
+// CurrencyByUidCtx - CurrencyByUid с контекстом запроса
+func (is *InstrumentsServiceClient) CurrencyByUidCtx(ctx context.Context, id string) (*CurrencyResponse, error) {
+	return is.currenceBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_UID, "")
 }
 
 // CurrencyByPositionUid - Метод получения валюты по PositionUid
 func (is *InstrumentsServiceClient) CurrencyByPositionUid(id string) (*CurrencyResponse, error) {
-	return is.currenceBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_POSITION_UID, "")
+	return is.CurrencyByPositionUidCtx(is.ctx, id)
 }
 
-func (is *InstrumentsServiceClient) currenceBy(id string, idType pb.InstrumentIdType, classCode string) (*CurrencyResponse, error) {
+// CurrencyByPositionUidCtx - CurrencyByPositionUid с контекстом запроса
+func (is *InstrumentsServiceClient) CurrencyByPositionUidCtx(ctx context.Context, id string) (*CurrencyResponse, error) {
+	return is.currenceBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_POSITION_UID, "")
+}
+
+func (is *InstrumentsServiceClient) currenceBy(ctx context.Context, id string, idType pb.InstrumentIdType, classCode string) (*CurrencyResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.CurrencyBy(is.ctx, &pb.InstrumentRequest{
+	resp, err := is.pbClient.CurrencyBy(ctx, &pb.InstrumentRequest{
 		IdType:    idType,
 		ClassCode: classCode,
 		Id:        id,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &CurrencyResponse{
 		CurrencyResponse: resp,
@@ -140,12 +200,18 @@ func (is *InstrumentsServiceClient) currenceBy(id string, idType pb.InstrumentId
 
 // Currencies - Метод получения списка валют
 func (is *InstrumentsServiceClient) Currencies(status pb.InstrumentStatus) (*CurrenciesResponse, error) {
+	return is.CurrenciesCtx(is.ctx, status)
+}
+
+// CurrenciesCtx - Currencies с контекстом запроса
+func (is *InstrumentsServiceClient) CurrenciesCtx(ctx context.Context, status pb.InstrumentStatus) (*CurrenciesResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.Currencies(is.ctx, &pb.InstrumentsRequest{
+	resp, err := is.pbClient.Currencies(ctx, &pb.InstrumentsRequest{
 		InstrumentStatus: status,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &CurrenciesResponse{
 		CurrenciesResponse: resp,
@@ -155,33 +221,54 @@ func (is *InstrumentsServiceClient) Currencies(status pb.InstrumentStatus) (*Cur
 
 // EtfByFigi - Метод получения инвестиционного фонда по Figi
 func (is *InstrumentsServiceClient) EtfByFigi(id string) (*EtfResponse, error) {
-	return is.etfBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_FIGI, "")
+	return is.EtfByFigiCtx(is.ctx, id)
+}
+
+// EtfByFigiCtx - EtfByFigi с контекстом запроса
+func (is *InstrumentsServiceClient) EtfByFigiCtx(ctx context.Context, id string) (*EtfResponse, error) {
+	return is.etfBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_FIGI, "")
 }
 
 // EtfByTicker - Метод получения инвестиционного фонда по Ticker
 func (is *InstrumentsServiceClient) EtfByTicker(id string, classCode string) (*EtfResponse, error) {
-	return is.etfBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_TICKER, classCode)
+	return is.EtfByTickerCtx(is.ctx, id, classCode)
+}
+
+// EtfByTickerCtx - EtfByTicker с контекстом запроса
+func (is *InstrumentsServiceClient) EtfByTickerCtx(ctx context.Context, id string, classCode string) (*EtfResponse, error) {
+	return is.etfBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_TICKER, classCode)
 }
 
 // EtfByUid - Метод получения инвестиционного фонда по Uid
 func (is *InstrumentsServiceClient) EtfByUid(id string) (*EtfResponse, error) {
-	return is.etfBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_UID, "")
+	return is.EtfByUidCtx(is.ctx, id)
+}
+
+// EtfByUidCtx - EtfByUid с контекстом запроса
+func (is *InstrumentsServiceClient) EtfByUidCtx(ctx context.Context, id string) (*EtfResponse, error) {
+	return is.etfBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_UID, "")
 }
 
 // EtfByPositionUid - Метод получения инвестиционного фонда по PositionUid
 func (is *InstrumentsServiceClient) EtfByPositionUid(id string) (*EtfResponse, error) {
-	return is.etfBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_POSITION_UID, "")
+	return is.EtfByPositionUidCtx(is.ctx, id)
+}
+
+// EtfByPositionUidCtx - EtfByPositionUid с контекстом запроса
+func (is *InstrumentsServiceClient) EtfByPositionUidCtx(ctx context.Context, id string) (*EtfResponse, error) {
+	return is.etfBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_POSITION_UID, "")
 }
 
-func (is *InstrumentsServiceClient) etfBy(id string, idType pb.InstrumentIdType, classCode string) (*EtfResponse, error) {
+func (is *InstrumentsServiceClient) etfBy(ctx context.Context, id string, idType pb.InstrumentIdType, classCode string) (*EtfResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.EtfBy(is.ctx, &pb.InstrumentRequest{
+	resp, err := is.pbClient.EtfBy(ctx, &pb.InstrumentRequest{
 		IdType:    idType,
 		ClassCode: classCode,
 		Id:        id,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &EtfResponse{
 		EtfResponse: resp,
@@ -191,12 +278,18 @@ func (is *InstrumentsServiceClient) etfBy(id string, idType pb.InstrumentIdType,
 
 // Etfs - Метод получения списка инвестиционных фондов
 func (is *InstrumentsServiceClient) Etfs(status pb.InstrumentStatus) (*EtfsResponse, error) {
+	return is.EtfsCtx(is.ctx, status)
+}
+
+// EtfsCtx - Etfs с контекстом запроса
+func (is *InstrumentsServiceClient) EtfsCtx(ctx context.Context, status pb.InstrumentStatus) (*EtfsResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.Etfs(is.ctx, &pb.InstrumentsRequest{
+	resp, err := is.pbClient.Etfs(ctx, &pb.InstrumentsRequest{
 		InstrumentStatus: status,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &EtfsResponse{
 		EtfsResponse: resp,
@@ -206,33 +299,54 @@ func (is *InstrumentsServiceClient) Etfs(status pb.InstrumentStatus) (*EtfsRespo
 
 // FutureByFigi - Метод получения фьючерса по Figi
 func (is *InstrumentsServiceClient) FutureByFigi(id string) (*FutureResponse, error) {
-	return is.futureBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_FIGI, "")
+	return is.FutureByFigiCtx(is.ctx, id)
+}
+
+// FutureByFigiCtx - FutureByFigi с контекстом запроса
+func (is *InstrumentsServiceClient) FutureByFigiCtx(ctx context.Context, id string) (*FutureResponse, error) {
+	return is.futureBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_FIGI, "")
 }
 
 // FutureByTicker - Метод получения фьючерса по Ticker
 func (is *InstrumentsServiceClient) FutureByTicker(id string, classCode string) (*FutureResponse, error) {
-	return is.futureBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_TICKER, classCode)
+	return is.FutureByTickerCtx(is.ctx, id, classCode)
+}
+
+// FutureByTickerCtx - FutureByTicker с контекстом запроса
+func (is *InstrumentsServiceClient) FutureByTickerCtx(ctx context.Context, id string, classCode string) (*FutureResponse, error) {
+	return is.futureBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_TICKER, classCode)
 }
 
 // FutureByUid - Метод получения фьючерса по Uid
 func (is *InstrumentsServiceClient) FutureByUid(id string) (*FutureResponse, error) {
-	return is.futureBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_UID, "")
+	return is.FutureByUidCtx(is.ctx, id)
+}
+
+// FutureByUidCtx - FutureByUid с контекстом запроса
+func (is *InstrumentsServiceClient) FutureByUidCtx(ctx context.Context, id string) (*FutureResponse, error) {
+	return is.futureBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_UID, "")
 }
 
 // FutureByPositionUid - Метод получения фьючерса по PositionUid
 func (is *InstrumentsServiceClient) FutureByPositionUid(id string) (*FutureResponse, error) {
-	return is.futureBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_POSITION_UID, "")
+	return is.FutureByPositionUidCtx(is.ctx, id)
 }
 
-func (is *InstrumentsServiceClient) futureBy(id string, idType pb.InstrumentIdType, classCode string) (*FutureResponse, error) {
+// FutureByPositionUidCtx - FutureByPositionUid с контекстом запроса
+func (is *InstrumentsServiceClient) FutureByPositionUidCtx(ctx context.Context, id string) (*FutureResponse, error) {
+	return is.futureBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_POSITION_UID, "")
+}
+
+func (is *InstrumentsServiceClient) futureBy(ctx context.Context, id string, idType pb.InstrumentIdType, classCode string) (*FutureResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.FutureBy(is.ctx, &pb.InstrumentRequest{
+	resp, err := is.pbClient.FutureBy(ctx, &pb.InstrumentRequest{
 		IdType:    idType,
 		ClassCode: classCode,
 		Id:        id,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &FutureResponse{
 		FutureResponse: resp,
@@ -242,12 +356,18 @@ func (is *InstrumentsServiceClient) futureBy(id string, idType pb.InstrumentIdTy
 
 // Futures - Метод получения списка фьючерсов
 func (is *InstrumentsServiceClient) Futures(status pb.InstrumentStatus) (*FuturesResponse, error) {
+	return is.FuturesCtx(is.ctx, status)
+}
+
+// FuturesCtx - Futures с контекстом запроса
+func (is *InstrumentsServiceClient) FuturesCtx(ctx context.Context, status pb.InstrumentStatus) (*FuturesResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.Futures(is.ctx, &pb.InstrumentsRequest{
+	resp, err := is.pbClient.Futures(ctx, &pb.InstrumentsRequest{
 		InstrumentStatus: status,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &FuturesResponse{
 		FuturesResponse: resp,
@@ -257,28 +377,44 @@ func (is *InstrumentsServiceClient) Futures(status pb.InstrumentStatus) (*Future
 
 // OptionByTicker - Метод получения опциона по Ticker
 func (is *InstrumentsServiceClient) OptionByTicker(id string, classCode string) (*OptionResponse, error) {
-	return is.optionBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_TICKER, classCode)
+	return is.OptionByTickerCtx(is.ctx, id, classCode)
+}
+
+// OptionByTickerCtx - OptionByTicker с контекстом запроса
+func (is *InstrumentsServiceClient) OptionByTickerCtx(ctx context.Context, id string, classCode string) (*OptionResponse, error) {
+	return is.optionBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_TICKER, classCode)
 }
 
 // OptionByUid - Метод получения опциона по Uid
 func (is *InstrumentsServiceClient) OptionByUid(id string) (*OptionResponse, error) {
-	return is.optionBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_UID, "")
+	return is.OptionByUidCtx(is.ctx, id)
+}
+
+// OptionByUidCtx - OptionByUid с контекстом запроса
+func (is *InstrumentsServiceClient) OptionByUidCtx(ctx context.Context, id string) (*OptionResponse, error) {
+	return is.optionBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_UID, "")
 }
 
 // OptionByPositionUid - Метод получения опциона по PositionUid
 func (is *InstrumentsServiceClient) OptionByPositionUid(id string) (*OptionResponse, error) {
-	return is.optionBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_POSITION_UID, "")
+	return is.OptionByPositionUidCtx(is.ctx, id)
+}
+
+// OptionByPositionUidCtx - OptionByPositionUid с контекстом запроса
+func (is *InstrumentsServiceClient) OptionByPositionUidCtx(ctx context.Context, id string) (*OptionResponse, error) {
+	return is.optionBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_POSITION_UID, "")
 }
 
-func (is *InstrumentsServiceClient) optionBy(id string, idType pb.InstrumentIdType, classCode string) (*OptionResponse, error) {
+func (is *InstrumentsServiceClient) optionBy(ctx context.Context, id string, idType pb.InstrumentIdType, classCode string) (*OptionResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.OptionBy(is.ctx, &pb.InstrumentRequest{
+	resp, err := is.pbClient.OptionBy(ctx, &pb.InstrumentRequest{
 		IdType:    idType,
 		ClassCode: classCode,
 		Id:        id,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &OptionResponse{
 		OptionResponse: resp,
@@ -288,12 +424,18 @@ func (is *InstrumentsServiceClient) optionBy(id string, idType pb.InstrumentIdTy
 
 // Options - Метод получения списка опционов
 func (is *InstrumentsServiceClient) Options(status pb.InstrumentStatus) (*OptionsResponse, error) {
+	return is.OptionsCtx(is.ctx, status)
+}
+
+// OptionsCtx - Options с контекстом запроса
+func (is *InstrumentsServiceClient) OptionsCtx(ctx context.Context, status pb.InstrumentStatus) (*OptionsResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.Options(is.ctx, &pb.InstrumentsRequest{
+	resp, err := is.pbClient.Options(ctx, &pb.InstrumentsRequest{
 		InstrumentStatus: status,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &OptionsResponse{
 		OptionsResponse: resp,
@@ -303,33 +445,54 @@ func (is *InstrumentsServiceClient) Options(status pb.InstrumentStatus) (*Option
 
 // ShareByFigi - Метод получения акции по Figi
 func (is *InstrumentsServiceClient) ShareByFigi(id string) (*ShareResponse, error) {
-	return is.shareBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_FIGI, "")
+	return is.ShareByFigiCtx(is.ctx, id)
+}
+
+// ShareByFigiCtx - ShareByFigi с контекстом запроса
+func (is *InstrumentsServiceClient) ShareByFigiCtx(ctx context.Context, id string) (*ShareResponse, error) {
+	return is.shareBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_FIGI, "")
 }
 
 // ShareByTicker - Метод получения акции по Ticker
 func (is *InstrumentsServiceClient) ShareByTicker(id string, classCode string) (*ShareResponse, error) {
-	return is.shareBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_TICKER, classCode)
+	return is.ShareByTickerCtx(is.ctx, id, classCode)
+}
+
+// ShareByTickerCtx - ShareByTicker с контекстом запроса
+func (is *InstrumentsServiceClient) ShareByTickerCtx(ctx context.Context, id string, classCode string) (*ShareResponse, error) {
+	return is.shareBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_TICKER, classCode)
 }
 
 // ShareByUid - Метод получения акции по Uid
 func (is *InstrumentsServiceClient) ShareByUid(id string) (*ShareResponse, error) {
-	return is.shareBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_UID, "")
+	return is.ShareByUidCtx(is.ctx, id)
+}
+
+// ShareByUidCtx - ShareByUid с контекстом запроса
+func (is *InstrumentsServiceClient) ShareByUidCtx(ctx context.Context, id string) (*ShareResponse, error) {
+	return is.shareBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_UID, "")
 }
 
 // ShareByPositionUid - Метод получения акции по PositionUid
 func (is *InstrumentsServiceClient) ShareByPositionUid(id string) (*ShareResponse, error) {
-	return is.shareBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_POSITION_UID, "")
+	return is.ShareByPositionUidCtx(is.ctx, id)
 }
 
-func (is *InstrumentsServiceClient) shareBy(id string, idType pb.InstrumentIdType, classCode string) (*ShareResponse, error) {
+// ShareByPositionUidCtx - ShareByPositionUid с контекстом запроса
+func (is *InstrumentsServiceClient) ShareByPositionUidCtx(ctx context.Context, id string) (*ShareResponse, error) {
+	return is.shareBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_POSITION_UID, "")
+}
+
+func (is *InstrumentsServiceClient) shareBy(ctx context.Context, id string, idType pb.InstrumentIdType, classCode string) (*ShareResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.ShareBy(is.ctx, &pb.InstrumentRequest{
+	resp, err := is.pbClient.ShareBy(ctx, &pb.InstrumentRequest{
 		IdType:    idType,
 		ClassCode: classCode,
 		Id:        id,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &ShareResponse{
 		ShareResponse: resp,
@@ -339,12 +502,18 @@ func (is *InstrumentsServiceClient) shareBy(id string, idType pb.InstrumentIdTyp
 
 // Shares - Метод получения списка акций
 func (is *InstrumentsServiceClient) Shares(status pb.InstrumentStatus) (*SharesResponse, error) {
+	return is.SharesCtx(is.ctx, status)
+}
+
+// SharesCtx - Shares с контекстом запроса
+func (is *InstrumentsServiceClient) SharesCtx(ctx context.Context, status pb.InstrumentStatus) (*SharesResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.Shares(is.ctx, &pb.InstrumentsRequest{
+	resp, err := is.pbClient.Shares(ctx, &pb.InstrumentsRequest{
 		InstrumentStatus: status,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &SharesResponse{
 		SharesResponse: resp,
@@ -354,33 +523,54 @@ func (is *InstrumentsServiceClient) Shares(status pb.InstrumentStatus) (*SharesR
 
 // InstrumentByFigi - Метод получения основной информации об инструменте
 func (is *InstrumentsServiceClient) InstrumentByFigi(id string) (*InstrumentResponse, error) {
-	return is.instrumentBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_FIGI, "")
+	return is.InstrumentByFigiCtx(is.ctx, id)
+}
+
+// InstrumentByFigiCtx - InstrumentByFigi с контекстом запроса
+func (is *InstrumentsServiceClient) InstrumentByFigiCtx(ctx context.Context, id string) (*InstrumentResponse, error) {
+	return is.instrumentBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_FIGI, "")
 }
 
 // InstrumentByTicker - Метод получения основной информации об инструменте
 func (is *InstrumentsServiceClient) InstrumentByTicker(id string, classCode string) (*InstrumentResponse, error) {
-	return is.instrumentBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_TICKER, classCode)
+	return is.InstrumentByTickerCtx(is.ctx, id, classCode)
+}
+
+// InstrumentByTickerCtx - InstrumentByTicker с контекстом запроса
+func (is *InstrumentsServiceClient) InstrumentByTickerCtx(ctx context.Context, id string, classCode string) (*InstrumentResponse, error) {
+	return is.instrumentBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_TICKER, classCode)
 }
 
 // InstrumentByUid - Метод получения основной информации об инструменте
 func (is *InstrumentsServiceClient) InstrumentByUid(id string) (*InstrumentResponse, error) {
-	return is.instrumentBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_UID, "")
+	return is.InstrumentByUidCtx(is.ctx, id)
+}
+
+// InstrumentByUidCtx - InstrumentByUid с контекстом запроса
+func (is *InstrumentsServiceClient) InstrumentByUidCtx(ctx context.Context, id string) (*InstrumentResponse, error) {
+	return is.instrumentBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_UID, "")
 }
 
 // InstrumentByPositionUid - Метод получения основной информации об инструменте
 func (is *InstrumentsServiceClient) InstrumentByPositionUid(id string) (*InstrumentResponse, error) {
-	return is.instrumentBy(id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_POSITION_UID, "")
+	return is.InstrumentByPositionUidCtx(is.ctx, id)
+}
+
+// InstrumentByPositionUidCtx - InstrumentByPositionUid с контекстом запроса
+func (is *InstrumentsServiceClient) InstrumentByPositionUidCtx(ctx context.Context, id string) (*InstrumentResponse, error) {
+	return is.instrumentBy(ctx, id, pb.InstrumentIdType_INSTRUMENT_ID_TYPE_POSITION_UID, "")
 }
 
-func (is *InstrumentsServiceClient) instrumentBy(id string, idType pb.InstrumentIdType, classCode string) (*InstrumentResponse, error) {
+func (is *InstrumentsServiceClient) instrumentBy(ctx context.Context, id string, idType pb.InstrumentIdType, classCode string) (*InstrumentResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.GetInstrumentBy(is.ctx, &pb.InstrumentRequest{
+	resp, err := is.pbClient.GetInstrumentBy(ctx, &pb.InstrumentRequest{
 		IdType:    idType,
 		ClassCode: classCode,
 		Id:        id,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &InstrumentResponse{
 		InstrumentResponse: resp,
@@ -390,14 +580,20 @@ func (is *InstrumentsServiceClient) instrumentBy(id string, idType pb.Instrument
 
 // GetAccruedInterests - Метод получения накопленного купонного дохода по облигации
 func (is *InstrumentsServiceClient) GetAccruedInterests(figi string, from, to time.Time) (*GetAccruedInterestsResponse, error) {
+	return is.GetAccruedInterestsCtx(is.ctx, figi, from, to)
+}
+
+// GetAccruedInterestsCtx - GetAccruedInterests с контекстом запроса
+func (is *InstrumentsServiceClient) GetAccruedInterestsCtx(ctx context.Context, figi string, from, to time.Time) (*GetAccruedInterestsResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.GetAccruedInterests(is.ctx, &pb.GetAccruedInterestsRequest{
+	resp, err := is.pbClient.GetAccruedInterests(ctx, &pb.GetAccruedInterestsRequest{
 		Figi: figi,
 		From: TimeToTimestamp(from),
 		To:   TimeToTimestamp(to),
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetAccruedInterestsResponse{
 		GetAccruedInterestsResponse: resp,
@@ -407,12 +603,18 @@ func (is *InstrumentsServiceClient) GetAccruedInterests(figi string, from, to ti
 
 // GetFuturesMargin - Метод получения размера гарантийного обеспечения по фьючерсам
 func (is *InstrumentsServiceClient) GetFuturesMargin(figi string) (*GetFuturesMarginResponse, error) {
+	return is.GetFuturesMarginCtx(is.ctx, figi)
+}
+
+// GetFuturesMarginCtx - GetFuturesMargin с контекстом запроса
+func (is *InstrumentsServiceClient) GetFuturesMarginCtx(ctx context.Context, figi string) (*GetFuturesMarginResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.GetFuturesMargin(is.ctx, &pb.GetFuturesMarginRequest{
+	resp, err := is.pbClient.GetFuturesMargin(ctx, &pb.GetFuturesMarginRequest{
 		Figi: figi,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetFuturesMarginResponse{
 		GetFuturesMarginResponse: resp,
@@ -422,14 +624,20 @@ func (is *InstrumentsServiceClient) GetFuturesMargin(figi string) (*GetFuturesMa
 
 // GetDividents - Метод для получения событий выплаты дивидендов по инструменту
 func (is *InstrumentsServiceClient) GetDividents(figi string, from, to time.Time) (*GetDividendsResponse, error) {
+	return is.GetDividentsCtx(is.ctx, figi, from, to)
+}
+
+// GetDividentsCtx - GetDividents с контекстом запроса
+func (is *InstrumentsServiceClient) GetDividentsCtx(ctx context.Context, figi string, from, to time.Time) (*GetDividendsResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.GetDividends(is.ctx, &pb.GetDividendsRequest{
+	resp, err := is.pbClient.GetDividends(ctx, &pb.GetDividendsRequest{
 		Figi: figi,
 		From: TimeToTimestamp(from),
 		To:   TimeToTimestamp(to),
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetDividendsResponse{
 		GetDividendsResponse: resp,
@@ -439,12 +647,18 @@ func (is *InstrumentsServiceClient) GetDividents(figi string, from, to time.Time
 
 // GetAssetBy - Метод получения актива по его uid идентификатору.
 func (is *InstrumentsServiceClient) GetAssetBy(id string) (*AssetResponse, error) {
+	return is.GetAssetByCtx(is.ctx, id)
+}
+
+// GetAssetByCtx - GetAssetBy с контекстом запроса
+func (is *InstrumentsServiceClient) GetAssetByCtx(ctx context.Context, id string) (*AssetResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.GetAssetBy(is.ctx, &pb.AssetRequest{
+	resp, err := is.pbClient.GetAssetBy(ctx, &pb.AssetRequest{
 		Id: id,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &AssetResponse{
 		AssetResponse: resp,
@@ -454,10 +668,16 @@ func (is *InstrumentsServiceClient) GetAssetBy(id string) (*AssetResponse, error
 
 // GetAssets - Метод получения списка активов
 func (is *InstrumentsServiceClient) GetAssets() (*AssetsResponse, error) {
+	return is.GetAssetsCtx(is.ctx)
+}
+
+// GetAssetsCtx - GetAssets с контекстом запроса
+func (is *InstrumentsServiceClient) GetAssetsCtx(ctx context.Context) (*AssetsResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.GetAssets(is.ctx, &pb.AssetsRequest{}, grpc.Header(&header), grpc.Trailer(&trailer))
+	resp, err := is.pbClient.GetAssets(ctx, &pb.AssetsRequest{}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &AssetsResponse{
 		AssetsResponse: resp,
@@ -467,10 +687,16 @@ func (is *InstrumentsServiceClient) GetAssets() (*AssetsResponse, error) {
 
 // GetFavorites - Метод получения списка избранных инструментов
 func (is *InstrumentsServiceClient) GetFavorites() (*GetFavoritesResponse, error) {
+	return is.GetFavoritesCtx(is.ctx)
+}
+
+// GetFavoritesCtx - GetFavorites с контекстом запроса
+func (is *InstrumentsServiceClient) GetFavoritesCtx(ctx context.Context) (*GetFavoritesResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.GetFavorites(is.ctx, &pb.GetFavoritesRequest{}, grpc.Header(&header), grpc.Trailer(&trailer))
+	resp, err := is.pbClient.GetFavorites(ctx, &pb.GetFavoritesRequest{}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetFavoritesResponse{
 		GetFavoritesResponse: resp,
@@ -480,17 +706,23 @@ func (is *InstrumentsServiceClient) GetFavorites() (*GetFavoritesResponse, error
 
 // EditFavorites - Метод редактирования списка избранных инструментов
 func (is *InstrumentsServiceClient) EditFavorites(instruments []string, actionType pb.EditFavoritesActionType) (*EditFavoritesResponse, error) {
+	return is.EditFavoritesCtx(is.ctx, instruments, actionType)
+}
+
+// EditFavoritesCtx - EditFavorites с контекстом запроса
+func (is *InstrumentsServiceClient) EditFavoritesCtx(ctx context.Context, instruments []string, actionType pb.EditFavoritesActionType) (*EditFavoritesResponse, error) {
 	var header, trailer metadata.MD
 	ids := make([]*pb.EditFavoritesRequestInstrument, 0, len(instruments))
 	for _, id := range instruments {
 		ids = append(ids, &pb.EditFavoritesRequestInstrument{Figi: id})
 	}
-	resp, err := is.pbClient.EditFavorites(is.ctx, &pb.EditFavoritesRequest{
+	resp, err := is.pbClient.EditFavorites(ctx, &pb.EditFavoritesRequest{
 		Instruments: ids,
 		ActionType:  actionType,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &EditFavoritesResponse{
 		EditFavoritesResponse: resp,
@@ -500,10 +732,16 @@ func (is *InstrumentsServiceClient) EditFavorites(instruments []string, actionTy
 
 // GetCountries - Метод получения списка стран
 func (is *InstrumentsServiceClient) GetCountries() (*GetCountriesResponse, error) {
+	return is.GetCountriesCtx(is.ctx)
+}
+
+// GetCountriesCtx - GetCountries с контекстом запроса
+func (is *InstrumentsServiceClient) GetCountriesCtx(ctx context.Context) (*GetCountriesResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.GetCountries(is.ctx, &pb.GetCountriesRequest{}, grpc.Header(&header), grpc.Trailer(&trailer))
+	resp, err := is.pbClient.GetCountries(ctx, &pb.GetCountriesRequest{}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetCountriesResponse{
 		GetCountriesResponse: resp,
@@ -513,10 +751,16 @@ func (is *InstrumentsServiceClient) GetCountries() (*GetCountriesResponse, error
 
 // GetBrands - Метод получения списка брендов
 func (is *InstrumentsServiceClient) GetBrands() (*GetBrandsResponse, error) {
+	return is.GetBrandsCtx(is.ctx)
+}
+
+// GetBrandsCtx - GetBrands с контекстом запроса
+func (is *InstrumentsServiceClient) GetBrandsCtx(ctx context.Context) (*GetBrandsResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.GetBrands(is.ctx, &pb.GetBrandsRequest{}, grpc.Header(&header), grpc.Trailer(&trailer))
+	resp, err := is.pbClient.GetBrands(ctx, &pb.GetBrandsRequest{}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetBrandsResponse{
 		GetBrandsResponse: resp,
@@ -526,12 +770,18 @@ func (is *InstrumentsServiceClient) GetBrands() (*GetBrandsResponse, error) {
 
 // GetBrandBy - Метод получения бренда по его uid идентификатору
 func (is *InstrumentsServiceClient) GetBrandBy(id string) (*Brand, error) {
+	return is.GetBrandByCtx(is.ctx, id)
+}
+
+// GetBrandByCtx - GetBrandBy с контекстом запроса
+func (is *InstrumentsServiceClient) GetBrandByCtx(ctx context.Context, id string) (*Brand, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.GetBrandBy(is.ctx, &pb.GetBrandRequest{
+	resp, err := is.pbClient.GetBrandBy(ctx, &pb.GetBrandRequest{
 		Id: id,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &Brand{
 		Brand:  resp,
@@ -541,12 +791,18 @@ func (is *InstrumentsServiceClient) GetBrandBy(id string) (*Brand, error) {
 
 // FindInstrument - Метод поиска инструмента, например по тикеру или названию компании
 func (is *InstrumentsServiceClient) FindInstrument(query string) (*FindInstrumentResponse, error) {
+	return is.FindInstrumentCtx(is.ctx, query)
+}
+
+// FindInstrumentCtx - FindInstrument с контекстом запроса
+func (is *InstrumentsServiceClient) FindInstrumentCtx(ctx context.Context, query string) (*FindInstrumentResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := is.pbClient.FindInstrument(is.ctx, &pb.FindInstrumentRequest{
+	resp, err := is.pbClient.FindInstrument(ctx, &pb.FindInstrumentRequest{
 		Query: query,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &FindInstrumentResponse{
 		FindInstrumentResponse: resp,
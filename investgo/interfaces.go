@@ -0,0 +1,581 @@
+package investgo
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+// Интерфейсы клиентов сервисов и стримов. Конструкторы Client возвращают конкретные типы, которые
+// реализуют эти интерфейсы, в коде приложения достаточно зависеть от интерфейса и подставлять
+// в тестах реализации из пакета investgomock. Клиенты стримов возвращают конкретные типы стримов,
+// интерфейсы фабрик стримов реализуют обертки AsMarketDataStreamService, AsOperationsStreamService
+// и AsOrdersStreamService
+
+// InstrumentsService - методы сервиса инструментов, реализуется *InstrumentsServiceClient
+type InstrumentsService interface {
+	// TradingSchedules - Метод получения расписания торгов торговых площадок
+	TradingSchedules(exchange string, from, to time.Time) (*TradingSchedulesResponse, error)
+	TradingSchedulesCtx(ctx context.Context, exchange string, from, to time.Time) (*TradingSchedulesResponse, error)
+	// BondByFigi - Метод получения облигации по figi
+	BondByFigi(id string) (*BondResponse, error)
+	BondByFigiCtx(ctx context.Context, id string) (*BondResponse, error)
+	// BondByTicker - Метод получения облигации по Ticker
+	BondByTicker(id string, classCode string) (*BondResponse, error)
+	BondByTickerCtx(ctx context.Context, id string, classCode string) (*BondResponse, error)
+	// BondByUid - Метод получения облигации по Uid
+	BondByUid(id string) (*BondResponse, error)
+	BondByUidCtx(ctx context.Context, id string) (*BondResponse, error)
+	// BondByPositionUid - Метод получения облигации по PositionUid
+	BondByPositionUid(id string) (*BondResponse, error)
+	BondByPositionUidCtx(ctx context.Context, id string) (*BondResponse, error)
+	// Bonds - Метод получения списка облигаций
+	Bonds(status pb.InstrumentStatus) (*BondsResponse, error)
+	BondsCtx(ctx context.Context, status pb.InstrumentStatus) (*BondsResponse, error)
+	// GetBondCoupons - Метод получения графика выплат купонов по облигации
+	GetBondCoupons(figi string, from, to time.Time) (*GetBondCouponsResponse, error)
+	GetBondCouponsCtx(ctx context.Context, figi string, from, to time.Time) (*GetBondCouponsResponse, error)
+	// CurrencyByFigi - Метод получения валюты по Figi
+	CurrencyByFigi(id string) (*CurrencyResponse, error)
+	CurrencyByFigiCtx(ctx context.Context, id string) (*CurrencyResponse, error)
+	// CurrencyByTicker - Метод получения валюты по Ticker
+	CurrencyByTicker(id string, classCode string) (*CurrencyResponse, error)
+	CurrencyByTickerCtx(ctx context.Context, id string, classCode string) (*CurrencyResponse, error)
+	// CurrencyByUid - Метод получения валюты по Uid
+	CurrencyByUid(id string) (*CurrencyResponse, error)
+	CurrencyByUidCtx(ctx context.Context, id string) (*CurrencyResponse, error)
+	// CurrencyByPositionUid - Метод получения валюты по PositionUid
+	CurrencyByPositionUid(id string) (*CurrencyResponse, error)
+	CurrencyByPositionUidCtx(ctx context.Context, id string) (*CurrencyResponse, error)
+	// Currencies - Метод получения списка валют
+	Currencies(status pb.InstrumentStatus) (*CurrenciesResponse, error)
+	CurrenciesCtx(ctx context.Context, status pb.InstrumentStatus) (*CurrenciesResponse, error)
+	// EtfByFigi - Метод получения инвестиционного фонда по Figi
+	EtfByFigi(id string) (*EtfResponse, error)
+	EtfByFigiCtx(ctx context.Context, id string) (*EtfResponse, error)
+	// EtfByTicker - Метод получения инвестиционного фонда по Ticker
+	EtfByTicker(id string, classCode string) (*EtfResponse, error)
+	EtfByTickerCtx(ctx context.Context, id string, classCode string) (*EtfResponse, error)
+	// EtfByUid - Метод получения инвестиционного фонда по Uid
+	EtfByUid(id string) (*EtfResponse, error)
+	EtfByUidCtx(ctx context.Context, id string) (*EtfResponse, error)
+	// EtfByPositionUid - Метод получения инвестиционного фонда по PositionUid
+	EtfByPositionUid(id string) (*EtfResponse, error)
+	EtfByPositionUidCtx(ctx context.Context, id string) (*EtfResponse, error)
+	// Etfs - Метод получения списка инвестиционных фондов
+	Etfs(status pb.InstrumentStatus) (*EtfsResponse, error)
+	EtfsCtx(ctx context.Context, status pb.InstrumentStatus) (*EtfsResponse, error)
+	// FutureByFigi - Метод получения фьючерса по Figi
+	FutureByFigi(id string) (*FutureResponse, error)
+	FutureByFigiCtx(ctx context.Context, id string) (*FutureResponse, error)
+	// FutureByTicker - Метод получения фьючерса по Ticker
+	FutureByTicker(id string, classCode string) (*FutureResponse, error)
+	FutureByTickerCtx(ctx context.Context, id string, classCode string) (*FutureResponse, error)
+	// FutureByUid - Метод получения фьючерса по Uid
+	FutureByUid(id string) (*FutureResponse, error)
+	FutureByUidCtx(ctx context.Context, id string) (*FutureResponse, error)
+	// FutureByPositionUid - Метод получения фьючерса по PositionUid
+	FutureByPositionUid(id string) (*FutureResponse, error)
+	FutureByPositionUidCtx(ctx context.Context, id string) (*FutureResponse, error)
+	// Futures - Метод получения списка фьючерсов
+	Futures(status pb.InstrumentStatus) (*FuturesResponse, error)
+	FuturesCtx(ctx context.Context, status pb.InstrumentStatus) (*FuturesResponse, error)
+	// OptionByTicker - Метод получения опциона по Ticker
+	OptionByTicker(id string, classCode string) (*OptionResponse, error)
+	OptionByTickerCtx(ctx context.Context, id string, classCode string) (*OptionResponse, error)
+	// OptionByUid - Метод получения опциона по Uid
+	OptionByUid(id string) (*OptionResponse, error)
+	OptionByUidCtx(ctx context.Context, id string) (*OptionResponse, error)
+	// OptionByPositionUid - Метод получения опциона по PositionUid
+	OptionByPositionUid(id string) (*OptionResponse, error)
+	OptionByPositionUidCtx(ctx context.Context, id string) (*OptionResponse, error)
+	// Options - Метод получения списка опционов
+	Options(status pb.InstrumentStatus) (*OptionsResponse, error)
+	OptionsCtx(ctx context.Context, status pb.InstrumentStatus) (*OptionsResponse, error)
+	// ShareByFigi - Метод получения акции по Figi
+	ShareByFigi(id string) (*ShareResponse, error)
+	ShareByFigiCtx(ctx context.Context, id string) (*ShareResponse, error)
+	// ShareByTicker - Метод получения акции по Ticker
+	ShareByTicker(id string, classCode string) (*ShareResponse, error)
+	ShareByTickerCtx(ctx context.Context, id string, classCode string) (*ShareResponse, error)
+	// ShareByUid - Метод получения акции по Uid
+	ShareByUid(id string) (*ShareResponse, error)
+	ShareByUidCtx(ctx context.Context, id string) (*ShareResponse, error)
+	// ShareByPositionUid - Метод получения акции по PositionUid
+	ShareByPositionUid(id string) (*ShareResponse, error)
+	ShareByPositionUidCtx(ctx context.Context, id string) (*ShareResponse, error)
+	// Shares - Метод получения списка акций
+	Shares(status pb.InstrumentStatus) (*SharesResponse, error)
+	SharesCtx(ctx context.Context, status pb.InstrumentStatus) (*SharesResponse, error)
+	// InstrumentByFigi - Метод получения основной информации об инструменте
+	InstrumentByFigi(id string) (*InstrumentResponse, error)
+	InstrumentByFigiCtx(ctx context.Context, id string) (*InstrumentResponse, error)
+	// InstrumentByTicker - Метод получения основной информации об инструменте
+	InstrumentByTicker(id string, classCode string) (*InstrumentResponse, error)
+	InstrumentByTickerCtx(ctx context.Context, id string, classCode string) (*InstrumentResponse, error)
+	// InstrumentByUid - Метод получения основной информации об инструменте
+	InstrumentByUid(id string) (*InstrumentResponse, error)
+	InstrumentByUidCtx(ctx context.Context, id string) (*InstrumentResponse, error)
+	// InstrumentByPositionUid - Метод получения основной информации об инструменте
+	InstrumentByPositionUid(id string) (*InstrumentResponse, error)
+	InstrumentByPositionUidCtx(ctx context.Context, id string) (*InstrumentResponse, error)
+	// GetAccruedInterests - Метод получения накопленного купонного дохода по облигации
+	GetAccruedInterests(figi string, from, to time.Time) (*GetAccruedInterestsResponse, error)
+	GetAccruedInterestsCtx(ctx context.Context, figi string, from, to time.Time) (*GetAccruedInterestsResponse, error)
+	// GetFuturesMargin - Метод получения размера гарантийного обеспечения по фьючерсам
+	GetFuturesMargin(figi string) (*GetFuturesMarginResponse, error)
+	GetFuturesMarginCtx(ctx context.Context, figi string) (*GetFuturesMarginResponse, error)
+	// GetDividents - Метод для получения событий выплаты дивидендов по инструменту
+	GetDividents(figi string, from, to time.Time) (*GetDividendsResponse, error)
+	GetDividentsCtx(ctx context.Context, figi string, from, to time.Time) (*GetDividendsResponse, error)
+	// GetAssetBy - Метод получения актива по его uid идентификатору.
+	GetAssetBy(id string) (*AssetResponse, error)
+	GetAssetByCtx(ctx context.Context, id string) (*AssetResponse, error)
+	// GetAssets - Метод получения списка активов
+	GetAssets() (*AssetsResponse, error)
+	GetAssetsCtx(ctx context.Context) (*AssetsResponse, error)
+	// GetFavorites - Метод получения списка избранных инструментов
+	GetFavorites() (*GetFavoritesResponse, error)
+	GetFavoritesCtx(ctx context.Context) (*GetFavoritesResponse, error)
+	// EditFavorites - Метод редактирования списка избранных инструментов
+	EditFavorites(instruments []string, actionType pb.EditFavoritesActionType) (*EditFavoritesResponse, error)
+	EditFavoritesCtx(ctx context.Context, instruments []string, actionType pb.EditFavoritesActionType) (*EditFavoritesResponse, error)
+	// GetCountries - Метод получения списка стран
+	GetCountries() (*GetCountriesResponse, error)
+	GetCountriesCtx(ctx context.Context) (*GetCountriesResponse, error)
+	// GetBrands - Метод получения списка брендов
+	GetBrands() (*GetBrandsResponse, error)
+	GetBrandsCtx(ctx context.Context) (*GetBrandsResponse, error)
+	// GetBrandBy - Метод получения бренда по его uid идентификатору
+	GetBrandBy(id string) (*Brand, error)
+	GetBrandByCtx(ctx context.Context, id string) (*Brand, error)
+	// FindInstrument - Метод поиска инструмента, например по тикеру или названию компании
+	FindInstrument(query string) (*FindInstrumentResponse, error)
+	FindInstrumentCtx(ctx context.Context, query string) (*FindInstrumentResponse, error)
+}
+
+// MarketDataService - методы сервиса маркетдаты, реализуется *MarketDataServiceClient
+type MarketDataService interface {
+	// GetCandles - Метод запроса исторических свечей по инструменту
+	GetCandles(instrumentId string, interval pb.CandleInterval, from, to time.Time) (*GetCandlesResponse, error)
+	GetCandlesCtx(ctx context.Context, instrumentId string, interval pb.CandleInterval, from, to time.Time) (*GetCandlesResponse, error)
+	// GetLastPrices - Метод запроса цен последних сделок по инструментам
+	GetLastPrices(instrumentIds []string) (*GetLastPricesResponse, error)
+	GetLastPricesCtx(ctx context.Context, instrumentIds []string) (*GetLastPricesResponse, error)
+	// GetOrderBook - Метод получения стакана по инструменту
+	GetOrderBook(instrumentId string, depth int32) (*GetOrderBookResponse, error)
+	GetOrderBookCtx(ctx context.Context, instrumentId string, depth int32) (*GetOrderBookResponse, error)
+	// GetTradingStatus - Метод запроса статуса торгов по инструменту
+	GetTradingStatus(instrumentId string) (*GetTradingStatusResponse, error)
+	GetTradingStatusCtx(ctx context.Context, instrumentId string) (*GetTradingStatusResponse, error)
+	// GetTradingStatuses - Метод запроса статуса торгов по инструментам
+	GetTradingStatuses(instrumentIds []string) (*GetTradingStatusesResponse, error)
+	GetTradingStatusesCtx(ctx context.Context, instrumentIds []string) (*GetTradingStatusesResponse, error)
+	// GetLastTrades - Метод запроса обезличенных сделок за последний час
+	GetLastTrades(instrumentId string, from, to time.Time) (*GetLastTradesResponse, error)
+	GetLastTradesCtx(ctx context.Context, instrumentId string, from, to time.Time) (*GetLastTradesResponse, error)
+	// GetClosePrices - Метод запроса цен закрытия торговой сессии по инструментам
+	GetClosePrices(instrumentIds []string) (*GetClosePricesResponse, error)
+	GetClosePricesCtx(ctx context.Context, instrumentIds []string) (*GetClosePricesResponse, error)
+	// GetHistoricCandles - Метод загрузки исторических свечей.
+	// Если указать File = true, то создастся .csv файл с записями
+	// свечей в формате: instrumentId;time;open;close;high;low;volume.
+	// Имя файла по умолчанию: "candles hh:mm:ss"
+	GetHistoricCandles(req *GetHistoricCandlesRequest) ([]*pb.HistoricCandle, error)
+	GetHistoricCandlesCtx(ctx context.Context, req *GetHistoricCandlesRequest) ([]*pb.HistoricCandle, error)
+	// GetAllHistoricCandles - Метод получения всех свечей по инструменту, поля from, to игнорируются
+	GetAllHistoricCandles(req *GetHistoricCandlesRequest) ([]*pb.HistoricCandle, error)
+	GetAllHistoricCandlesCtx(ctx context.Context, req *GetHistoricCandlesRequest) ([]*pb.HistoricCandle, error)
+}
+
+// OperationsService - методы сервиса операций, реализуется *OperationsServiceClient
+type OperationsService interface {
+	// GetOperations - Метод получения списка операций по счёту
+	GetOperations(req *GetOperationsRequest) (*OperationsResponse, error)
+	GetOperationsCtx(ctx context.Context, req *GetOperationsRequest) (*OperationsResponse, error)
+	// GetPortfolio - Метод получения портфеля по счёту
+	GetPortfolio(accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*PortfolioResponse, error)
+	GetPortfolioCtx(ctx context.Context, accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*PortfolioResponse, error)
+	// GetPositions - Метод получения списка позиций по счёту
+	GetPositions(accountId string) (*PositionsResponse, error)
+	GetPositionsCtx(ctx context.Context, accountId string) (*PositionsResponse, error)
+	// GetWithdrawLimits - Метод получения доступного остатка для вывода средств
+	GetWithdrawLimits(accountId string) (*WithdrawLimitsResponse, error)
+	GetWithdrawLimitsCtx(ctx context.Context, accountId string) (*WithdrawLimitsResponse, error)
+	// GetBrokerReport - Метод получения брокерского отчёта
+	GetBrokerReport(taskId string, page int32) (*GetBrokerReportResponse, error)
+	GetBrokerReportCtx(ctx context.Context, taskId string, page int32) (*GetBrokerReportResponse, error)
+	// GenerateBrokerReport - Метод получения брокерского отчёта
+	GenerateBrokerReport(accountId string, from, to time.Time) (*GenerateBrokerReportResponse, error)
+	GenerateBrokerReportCtx(ctx context.Context, accountId string, from, to time.Time) (*GenerateBrokerReportResponse, error)
+	// GetDividentsForeignIssuer - Метод получения отчёта "Справка о доходах за пределами РФ"
+	GetDividentsForeignIssuer(taskId string, page int32) (*GetDividendsForeignIssuerResponse, error)
+	GetDividentsForeignIssuerCtx(ctx context.Context, taskId string, page int32) (*GetDividendsForeignIssuerResponse, error)
+	// GenerateDividentsForeignIssuer - Метод получения отчёта "Справка о доходах за пределами РФ"
+	GenerateDividentsForeignIssuer(accountId string, from, to time.Time) (*GetDividendsForeignIssuerResponse, error)
+	GenerateDividentsForeignIssuerCtx(ctx context.Context, accountId string, from, to time.Time) (*GetDividendsForeignIssuerResponse, error)
+	// GetOperationsByCursorShort - Метод получения списка операций по счёту с пагинацией
+	GetOperationsByCursorShort(accountId string) (*GetOperationsByCursorResponse, error)
+	GetOperationsByCursorShortCtx(ctx context.Context, accountId string) (*GetOperationsByCursorResponse, error)
+	// GetOperationsByCursor - Метод получения списка операций по счёту с пагинацией
+	GetOperationsByCursor(req *GetOperationsByCursorRequest) (*GetOperationsByCursorResponse, error)
+	GetOperationsByCursorCtx(ctx context.Context, req *GetOperationsByCursorRequest) (*GetOperationsByCursorResponse, error)
+}
+
+// OrdersService - методы сервиса торговых поручений, реализуется *OrdersServiceClient
+type OrdersService interface {
+	// PostOrder - Метод выставления биржевой заявки
+	PostOrder(req *PostOrderRequest) (*PostOrderResponse, error)
+	PostOrderCtx(ctx context.Context, req *PostOrderRequest) (*PostOrderResponse, error)
+	// Buy - Метод выставления поручения на покупку инструмента
+	Buy(req *PostOrderRequestShort) (*PostOrderResponse, error)
+	BuyCtx(ctx context.Context, req *PostOrderRequestShort) (*PostOrderResponse, error)
+	// Sell - Метод выставления поручения на продажу инструмента
+	Sell(req *PostOrderRequestShort) (*PostOrderResponse, error)
+	SellCtx(ctx context.Context, req *PostOrderRequestShort) (*PostOrderResponse, error)
+	// CancelOrder - Метод отмены биржевой заявки
+	CancelOrder(accountId, orderId string) (*CancelOrderResponse, error)
+	CancelOrderCtx(ctx context.Context, accountId, orderId string) (*CancelOrderResponse, error)
+	// GetOrderState - Метод получения статуса торгового поручения
+	GetOrderState(accountId, orderId string) (*GetOrderStateResponse, error)
+	GetOrderStateCtx(ctx context.Context, accountId, orderId string) (*GetOrderStateResponse, error)
+	// GetOrders - Метод получения списка активных заявок по счёту
+	GetOrders(accountId string) (*GetOrdersResponse, error)
+	GetOrdersCtx(ctx context.Context, accountId string) (*GetOrdersResponse, error)
+	// ReplaceOrder - Метод изменения выставленной заявки
+	ReplaceOrder(req *ReplaceOrderRequest) (*PostOrderResponse, error)
+	ReplaceOrderCtx(ctx context.Context, req *ReplaceOrderRequest) (*PostOrderResponse, error)
+}
+
+// StopOrdersService - методы сервиса стоп-заявок, реализуется *StopOrdersServiceClient
+type StopOrdersService interface {
+	// PostStopOrder - Метод выставления стоп-заявки
+	PostStopOrder(req *PostStopOrderRequest) (*PostStopOrderResponse, error)
+	PostStopOrderCtx(ctx context.Context, req *PostStopOrderRequest) (*PostStopOrderResponse, error)
+	// GetStopOrders - Метод получения списка активных стоп заявок по счёту
+	GetStopOrders(accountId string) (*GetStopOrdersResponse, error)
+	GetStopOrdersCtx(ctx context.Context, accountId string) (*GetStopOrdersResponse, error)
+	// CancelStopOrder - Метод отмены стоп-заявки
+	CancelStopOrder(accountId, stopOrderId string) (*CancelStopOrderResponse, error)
+	CancelStopOrderCtx(ctx context.Context, accountId, stopOrderId string) (*CancelStopOrderResponse, error)
+}
+
+// UsersService - методы сервиса пользователей, реализуется *UsersServiceClient
+type UsersService interface {
+	// GetAccounts - Метод получения счетов пользователя
+	GetAccounts() (*GetAccountsResponse, error)
+	GetAccountsCtx(ctx context.Context) (*GetAccountsResponse, error)
+	// GetMarginAttributes - Расчёт маржинальных показателей по счёту
+	GetMarginAttributes(accountId string) (*GetMarginAttributesResponse, error)
+	GetMarginAttributesCtx(ctx context.Context, accountId string) (*GetMarginAttributesResponse, error)
+	// GetUserTariff - Запрос тарифа пользователя
+	GetUserTariff() (*GetUserTariffResponse, error)
+	GetUserTariffCtx(ctx context.Context) (*GetUserTariffResponse, error)
+	// GetInfo - Метод получения информации о пользователе
+	GetInfo() (*GetInfoResponse, error)
+	GetInfoCtx(ctx context.Context) (*GetInfoResponse, error)
+}
+
+// SandboxService - методы сервиса песочницы, реализуется *SandboxServiceClient
+type SandboxService interface {
+	// OpenSandboxAccount - Метод регистрации счёта в песочнице
+	OpenSandboxAccount() (*OpenSandboxAccountResponse, error)
+	OpenSandboxAccountCtx(ctx context.Context) (*OpenSandboxAccountResponse, error)
+	// GetSandboxAccounts - Метод получения счетов в песочнице
+	GetSandboxAccounts() (*GetAccountsResponse, error)
+	GetSandboxAccountsCtx(ctx context.Context) (*GetAccountsResponse, error)
+	// CloseSandboxAccount - Метод закрытия счёта в песочнице
+	CloseSandboxAccount(accountId string) (*CloseSandboxAccountResponse, error)
+	CloseSandboxAccountCtx(ctx context.Context, accountId string) (*CloseSandboxAccountResponse, error)
+	// PostSandboxOrder - Метод выставления торгового поручения в песочнице
+	PostSandboxOrder(req *PostOrderRequest) (*PostOrderResponse, error)
+	PostSandboxOrderCtx(ctx context.Context, req *PostOrderRequest) (*PostOrderResponse, error)
+	// ReplaceSandboxOrder - Метод изменения выставленной заявки
+	ReplaceSandboxOrder(req *ReplaceOrderRequest) (*PostOrderResponse, error)
+	ReplaceSandboxOrderCtx(ctx context.Context, req *ReplaceOrderRequest) (*PostOrderResponse, error)
+	// GetSandboxOrders - Метод получения списка активных заявок по счёту в песочнице
+	GetSandboxOrders(accountId string) (*GetOrdersResponse, error)
+	GetSandboxOrdersCtx(ctx context.Context, accountId string) (*GetOrdersResponse, error)
+	// CancelSandboxOrder - Метод отмены торгового поручения в песочнице
+	CancelSandboxOrder(accountId, orderId string) (*CancelOrderResponse, error)
+	CancelSandboxOrderCtx(ctx context.Context, accountId, orderId string) (*CancelOrderResponse, error)
+	// GetSandboxOrderState - Метод получения статуса заявки в песочнице
+	GetSandboxOrderState(accountId, orderId string) (*GetOrderStateResponse, error)
+	GetSandboxOrderStateCtx(ctx context.Context, accountId, orderId string) (*GetOrderStateResponse, error)
+	// GetSandboxPositions - Метод получения позиций по виртуальному счёту песочницы
+	GetSandboxPositions(accountId string) (*PositionsResponse, error)
+	GetSandboxPositionsCtx(ctx context.Context, accountId string) (*PositionsResponse, error)
+	// GetSandboxOperations - Метод получения операций в песочнице по номеру счёта
+	GetSandboxOperations(req *GetOperationsRequest) (*OperationsResponse, error)
+	GetSandboxOperationsCtx(ctx context.Context, req *GetOperationsRequest) (*OperationsResponse, error)
+	// GetSandboxOperationsByCursor - Метод получения операций в песочнице по номеру счета с пагинацией
+	GetSandboxOperationsByCursor(req *GetOperationsByCursorRequest) (*GetOperationsByCursorResponse, error)
+	GetSandboxOperationsByCursorCtx(ctx context.Context, req *GetOperationsByCursorRequest) (*GetOperationsByCursorResponse, error)
+	// GetSandboxPortfolio - Метод получения портфолио в песочнице
+	GetSandboxPortfolio(accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*PortfolioResponse, error)
+	GetSandboxPortfolioCtx(ctx context.Context, accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*PortfolioResponse, error)
+	// GetSandboxWithdrawLimits - Метод получения доступного остатка для вывода средств в песочнице
+	GetSandboxWithdrawLimits(accountId string) (*WithdrawLimitsResponse, error)
+	GetSandboxWithdrawLimitsCtx(ctx context.Context, accountId string) (*WithdrawLimitsResponse, error)
+	// SandboxPayIn - Метод пополнения счёта в песочнице
+	SandboxPayIn(req *SandboxPayInRequest) (*SandboxPayInResponse, error)
+	SandboxPayInCtx(ctx context.Context, req *SandboxPayInRequest) (*SandboxPayInResponse, error)
+}
+
+// MarketDataStreamService - создание стримов маркетдаты, реализуется AsMarketDataStreamService(*MDStreamClient)
+type MarketDataStreamService interface {
+	// MarketDataStream - метод возвращает стрим биржевой информации
+	MarketDataStream() (MarketDataStreamer, error)
+	MarketDataStreamCtx(ctx context.Context) (MarketDataStreamer, error)
+	// ServerSideStream - метод возвращает server-side стрим биржевой информации с набором подписок subs
+	ServerSideStream(subs ServerSideSubscriptions) (ServerSideStreamer, error)
+	ServerSideStreamCtx(ctx context.Context, subs ServerSideSubscriptions) (ServerSideStreamer, error)
+	// MarketDataPool - метод возвращает пул стримов биржевой информации
+	MarketDataPool(opts PoolOptions) (MarketDataPooler, error)
+	MarketDataPoolCtx(ctx context.Context, opts PoolOptions) (MarketDataPooler, error)
+}
+
+// OperationsStreamService - создание стримов портфеля и позиций, реализуется
+// AsOperationsStreamService(*OperationsStreamClient)
+type OperationsStreamService interface {
+	// PortfolioStream - Server-side stream обновлений портфеля
+	PortfolioStream(accounts []string) (PortfolioStreamer, error)
+	PortfolioStreamCtx(ctx context.Context, accounts []string) (PortfolioStreamer, error)
+	// PositionsStream - Server-side stream обновлений информации по изменению позиций портфеля
+	PositionsStream(accounts []string) (PositionsStreamer, error)
+	PositionsStreamCtx(ctx context.Context, accounts []string) (PositionsStreamer, error)
+}
+
+// OrdersStreamService - создание стримов сделок, реализуется AsOrdersStreamService(*OrdersStreamClient)
+type OrdersStreamService interface {
+	// TradesStream - Стрим сделок по запрашиваемым аккаунтам
+	TradesStream(accounts []string) (TradesStreamer, error)
+	TradesStreamCtx(ctx context.Context, accounts []string) (TradesStreamer, error)
+}
+
+// MarketDataStreamer - стрим маркетдаты, реализуется *MDStream
+type MarketDataStreamer interface {
+	// SubscribeCandle - Метод подписки на свечи с заданным интервалом
+	SubscribeCandle(ids []string, interval pb.SubscriptionInterval) (<-chan *pb.Candle, error)
+	// UnSubscribeCandle - Метод отписки от свечей
+	UnSubscribeCandle(ids []string, interval pb.SubscriptionInterval) error
+	// SubscribeOrderBook - метод подписки на стаканы инструментов с одинаковой глубиной
+	SubscribeOrderBook(ids []string, depth int32) (<-chan *pb.OrderBook, error)
+	// UnSubscribeOrderBook - метод отдписки от стаканов инструментов
+	UnSubscribeOrderBook(ids []string) error
+	// SubscribeTrade - метод подписки на ленту обезличенных сделок
+	SubscribeTrade(ids []string) (<-chan *pb.Trade, error)
+	// UnSubscribeTrade - метод отписки от ленты обезличенных сделок
+	UnSubscribeTrade(ids []string) error
+	// SubscribeInfo - метод подписки на торговые статусы инструментов
+	SubscribeInfo(ids []string) (<-chan *pb.TradingStatus, error)
+	// UnSubscribeInfo - метод отписки от торговых статусов инструментов
+	UnSubscribeInfo(ids []string) error
+	// SubscribeLastPrice - метод подписки на последние цены инструментов
+	SubscribeLastPrice(ids []string) (<-chan *pb.LastPrice, error)
+	// UnSubscribeLastPrice - метод отписки от последних цен инструментов
+	UnSubscribeLastPrice(ids []string) error
+	// SubscribeCandleAsync - подписка на свечи с ожиданием ответа сервера
+	SubscribeCandleAsync(ids []string, interval pb.SubscriptionInterval) (*SubscriptionFuture, error)
+	// SubscribeOrderBookAsync - подписка на стаканы с ожиданием ответа сервера
+	SubscribeOrderBookAsync(ids []string, depth int32) (*SubscriptionFuture, error)
+	// SubscribeTradeAsync - подписка на ленту обезличенных сделок с ожиданием ответа сервера
+	SubscribeTradeAsync(ids []string) (*SubscriptionFuture, error)
+	// SubscribeInfoAsync - подписка на торговые статусы с ожиданием ответа сервера
+	SubscribeInfoAsync(ids []string) (*SubscriptionFuture, error)
+	// SubscribeLastPriceAsync - подписка на последние цены с ожиданием ответа сервера
+	SubscribeLastPriceAsync(ids []string) (*SubscriptionFuture, error)
+	// Candles, OrderBooks, Trades, TradingStatuses, LastPrices - каналы данных по подпискам стрима
+	Candles() <-chan *pb.Candle
+	OrderBooks() <-chan *pb.OrderBook
+	Trades() <-chan *pb.Trade
+	TradingStatuses() <-chan *pb.TradingStatus
+	LastPrices() <-chan *pb.LastPrice
+	// GetMySubscriptions - метод получения подписок в рамках данного стрима, ответ сервера не возвращается.
+	// Для получения подписок используйте MySubscriptions
+	GetMySubscriptions() error
+	// MySubscriptions - получение активных подписок стрима от сервера. Ответ приходит в Listen,
+	// поэтому стрим должен слушаться. Если сервер не ответил за 30 секунд, возвращается ErrSubscriptionsTimeout
+	MySubscriptions(ctx context.Context) (*SubscriptionsSnapshot, error)
+	// ReconcileSubscriptions - получение подписок от сервера и сравнение их с подписками стрима
+	ReconcileSubscriptions(ctx context.Context) (*SubscriptionsDiff, error)
+	// CompareSubscriptions - сравнение подписок стрима с подписками на сервере. Инструмент стрима
+	// совпадает с подпиской на сервере по figi или instrument_uid
+	CompareSubscriptions(snapshot *SubscriptionsSnapshot) *SubscriptionsDiff
+	// SetReconnectPolicy - метод установки политики переподключения стрима, вызывается до Listen
+	SetReconnectPolicy(p ReconnectPolicy)
+	// Listen - метод начинает слушать стрим и отправлять информацию в каналы. При обрыве соединения
+	// стрим переоткрывается согласно ReconnectPolicy, все подписки восстанавливаются, каналы не закрываются
+	Listen() error
+	// Stop - Завершение работы стрима
+	Stop()
+	// UnSubscribeAll - Метод отписки от всей информации, отслеживаемой на данный момент
+	UnSubscribeAll() error
+	// SetWatchdog - установка проверки поступления Ping, вызывается до Listen
+	SetWatchdog(opts WatchdogOptions)
+	// Health - статистика активности стрима
+	Health() StreamHealth
+	// SetRecorder - запись всех сообщений стрима, вызывается до Listen
+	SetRecorder(r *Recorder)
+}
+
+// PortfolioStreamer - стрим обновлений портфеля, реализуется *PortfolioStream
+type PortfolioStreamer interface {
+	// Portfolios - Метод возвращает канал для чтения обновлений портфеля
+	Portfolios() <-chan *pb.PortfolioResponse
+	// Listen - метод начинает слушать стрим и отправлять информацию в канал, для получения канала: Portfolios()
+	Listen() error
+	// Stop - Завершение работы стрима
+	Stop()
+	// SetReconnectPolicy - установка политики принудительного переподключения зависшего стрима, вызывается до Listen
+	SetReconnectPolicy(policy ReconnectPolicy)
+	// SetWatchdog - установка проверки поступления Ping, вызывается до Listen
+	SetWatchdog(opts WatchdogOptions)
+	// Health - статистика активности стрима
+	Health() StreamHealth
+	// SetRecorder - запись всех сообщений стрима, вызывается до Listen
+	SetRecorder(r *Recorder)
+}
+
+// PositionsStreamer - стрим изменений позиций, реализуется *PositionsStream
+type PositionsStreamer interface {
+	// Positions -  Метод возвращает канал для чтения обновлений информации по изменению позиций портфеля
+	Positions() <-chan *pb.PositionData
+	// Listen - метод начинает слушать стрим и отправлять информацию в канал, для получения канала: Positions()
+	Listen() error
+	// Stop - Завершение работы стрима
+	Stop()
+	// SetReconnectPolicy - установка политики принудительного переподключения зависшего стрима, вызывается до Listen
+	SetReconnectPolicy(policy ReconnectPolicy)
+	// SetWatchdog - установка проверки поступления Ping, вызывается до Listen
+	SetWatchdog(opts WatchdogOptions)
+	// Health - статистика активности стрима
+	Health() StreamHealth
+	// SetRecorder - запись всех сообщений стрима, вызывается до Listen
+	SetRecorder(r *Recorder)
+}
+
+// TradesStreamer - стрим сделок по заявкам, реализуется *TradesStream
+type TradesStreamer interface {
+	// Trades - Метод возвращает канал для чтения информации о торговых поручениях
+	Trades() <-chan *pb.OrderTrades
+	// Listen - метод начинает слушать стрим и отправлять информацию в канал, для получения канала: Trades()
+	Listen() error
+	// Stop - Завершение работы стрима
+	Stop()
+	// SetReconnectPolicy - установка политики принудительного переподключения зависшего стрима, вызывается до Listen
+	SetReconnectPolicy(policy ReconnectPolicy)
+	// SetWatchdog - установка проверки поступления Ping, вызывается до Listen
+	SetWatchdog(opts WatchdogOptions)
+	// Health - статистика активности стрима
+	Health() StreamHealth
+	// SetRecorder - запись всех сообщений стрима, вызывается до Listen
+	SetRecorder(r *Recorder)
+}
+
+// ServerSideStreamer - server-side стрим маркетдаты, реализуется *ServerSideStream
+type ServerSideStreamer interface {
+	// Candles - канал свечей по подпискам стрима
+	Candles() <-chan *pb.Candle
+	// OrderBooks - канал стаканов по подпискам стрима
+	OrderBooks() <-chan *pb.OrderBook
+	// Trades - канал обезличенных сделок по подпискам стрима
+	Trades() <-chan *pb.Trade
+	// TradingStatuses - канал торговых статусов по подпискам стрима
+	TradingStatuses() <-chan *pb.TradingStatus
+	// LastPrices - канал последних цен по подпискам стрима
+	LastPrices() <-chan *pb.LastPrice
+	// Failed - инструменты с ошибкой подписки по ответу сервера на текущее подключение
+	Failed() []*SubscriptionError
+	// SetReconnectPolicy - метод установки политики переподключения стрима, применяется со следующего переподключения
+	SetReconnectPolicy(p ReconnectPolicy)
+	// Listen - метод начинает слушать стрим и отправлять информацию в каналы. При обрыве соединения
+	// стрим переоткрывается согласно ReconnectPolicy, каналы не закрываются
+	Listen() error
+	// Stop - Завершение работы стрима
+	Stop()
+	// SetWatchdog - установка проверки поступления Ping, вызывается до Listen
+	SetWatchdog(opts WatchdogOptions)
+	// Health - статистика активности стрима
+	Health() StreamHealth
+	// SetRecorder - запись всех сообщений стрима, вызывается до Listen
+	SetRecorder(r *Recorder)
+}
+
+// MarketDataPooler - пул стримов маркетдаты, реализуется *MarketDataPool
+type MarketDataPooler interface {
+	// SubscribeCandle - подписка на свечи с заданным интервалом, свечи приходят в общий канал пула
+	SubscribeCandle(ids []string, interval pb.SubscriptionInterval) (<-chan *pb.Candle, error)
+	// SubscribeOrderBook - подписка на стаканы с заданной глубиной, стаканы приходят в общий канал пула
+	SubscribeOrderBook(ids []string, depth int32) (<-chan *pb.OrderBook, error)
+	// SubscribeTrade - подписка на обезличенные сделки, сделки приходят в общий канал пула
+	SubscribeTrade(ids []string) (<-chan *pb.Trade, error)
+	// SubscribeInfo - подписка на торговые статусы, статусы приходят в общий канал пула
+	SubscribeInfo(ids []string) (<-chan *pb.TradingStatus, error)
+	// SubscribeLastPrice - подписка на последние цены, цены приходят в общий канал пула
+	SubscribeLastPrice(ids []string) (<-chan *pb.LastPrice, error)
+	// SubscribeCandleAsync, SubscribeOrderBookAsync, SubscribeTradeAsync, SubscribeInfoAsync,
+	// SubscribeLastPriceAsync - подписка с ожиданием ответов стримов, на которые размещены подписки.
+	// При ErrPoolFull возвращается ожидание ответов по размещенным инструментам
+	SubscribeCandleAsync(ids []string, interval pb.SubscriptionInterval) (*SubscriptionFuture, error)
+	SubscribeOrderBookAsync(ids []string, depth int32) (*SubscriptionFuture, error)
+	SubscribeTradeAsync(ids []string) (*SubscriptionFuture, error)
+	SubscribeInfoAsync(ids []string) (*SubscriptionFuture, error)
+	SubscribeLastPriceAsync(ids []string) (*SubscriptionFuture, error)
+	// UnSubscribeCandle - отписка от свечей
+	UnSubscribeCandle(ids []string) error
+	// UnSubscribeOrderBook - отписка от стаканов
+	UnSubscribeOrderBook(ids []string) error
+	// UnSubscribeTrade - отписка от обезличенных сделок
+	UnSubscribeTrade(ids []string) error
+	// UnSubscribeInfo - отписка от торговых статусов
+	UnSubscribeInfo(ids []string) error
+	// UnSubscribeLastPrice - отписка от последних цен
+	UnSubscribeLastPrice(ids []string) error
+	// Candles, OrderBooks, Trades, TradingStatuses, LastPrices - общие каналы данных всех стримов пула
+	Candles() <-chan *pb.Candle
+	OrderBooks() <-chan *pb.OrderBook
+	Trades() <-chan *pb.Trade
+	TradingStatuses() <-chan *pb.TradingStatus
+	LastPrices() <-chan *pb.LastPrice
+	// SetReconnectPolicy - установка политики переподключения для всех стримов пула, в том числе будущих
+	SetReconnectPolicy(policy ReconnectPolicy)
+	// SetWatchdog - установка проверки поступления Ping для стримов пула, вызывается до первой подписки
+	SetWatchdog(opts WatchdogOptions)
+	// SetRecorder - запись всех сообщений стримов пула, вызывается до первой подписки
+	SetRecorder(r *Recorder)
+	// Health - статистика активности каждого открытого стрима пула
+	Health() []StreamHealth
+	// StreamLoads - количество подписок на каждом открытом стриме пула
+	StreamLoads() []int
+	// Stop - завершение работы всех стримов пула, после их завершения закрываются общие каналы
+	Stop()
+}
+
+var (
+	_ InstrumentsService      = (*InstrumentsServiceClient)(nil)
+	_ MarketDataService       = (*MarketDataServiceClient)(nil)
+	_ OperationsService       = (*OperationsServiceClient)(nil)
+	_ OrdersService           = (*OrdersServiceClient)(nil)
+	_ StopOrdersService       = (*StopOrdersServiceClient)(nil)
+	_ UsersService            = (*UsersServiceClient)(nil)
+	_ SandboxService          = (*SandboxServiceClient)(nil)
+	_ MarketDataStreamService = mdStreamService{}
+	_ OperationsStreamService = operationsStreamService{}
+	_ OrdersStreamService     = ordersStreamService{}
+	_ MarketDataStreamer      = (*MDStream)(nil)
+	_ MarketDataChannels      = (*MDStream)(nil)
+	_ ServerSideStreamer      = (*ServerSideStream)(nil)
+	_ MarketDataChannels      = (*ServerSideStream)(nil)
+	_ MarketDataPooler        = (*MarketDataPool)(nil)
+	_ MarketDataChannels      = (*MarketDataPool)(nil)
+	_ MarketDataChannels      = (*Replayer)(nil)
+	_ LastPriceSubscriber     = (*MDStream)(nil)
+	_ LastPriceSubscriber     = (*MarketDataPool)(nil)
+	_ CandleSubscriber        = (*MDStream)(nil)
+	_ CandleSubscriber        = (*MarketDataPool)(nil)
+	_ PortfolioStreamer       = (*PortfolioStream)(nil)
+	_ PositionsStreamer       = (*PositionsStream)(nil)
+	_ TradesStreamer          = (*TradesStream)(nil)
+)
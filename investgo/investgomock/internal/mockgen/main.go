@@ -0,0 +1,219 @@
+// mockgen - генерация моков для интерфейсов пакета investgo.
+//
+// Для каждого интерфейса создается структура <Interface>Mock с полями <Method>Func,
+// вызовы записываются и доступны через Calls и CallsTo.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"strings"
+)
+
+func main() {
+	src := flag.String("src", "../interfaces.go", "файлы с интерфейсами через запятую")
+	out := flag.String("out", "mocks.go", "файл с моками")
+	pkg := flag.String("pkg", "investgomock", "имя пакета моков")
+	flag.Parse()
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	names := make([]string, 0)
+	for _, path := range strings.Split(*src, ",") {
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			log.Fatal(err)
+		}
+		files = append(files, file)
+		names = append(names, baseName(path))
+	}
+	g := &generator{fset: fset, srcPkg: files[0].Name.Name}
+	g.printf("// Code generated by mockgen from %v. DO NOT EDIT.\n\n", strings.Join(names, ", "))
+	g.printf("package %v\n\n", *pkg)
+	g.printf("import (\n\t\"context\"\n\t\"sync\"\n\t\"time\"\n\n")
+	g.printf("\t\"github.com/therox/invest-api-go-sdk/investgo\"\n")
+	g.printf("\tpb \"github.com/therox/invest-api-go-sdk/proto\"\n)\n\n")
+	g.printf("var (\n\t_ = context.Background\n\t_ = time.Now\n\t_ pb.SubscriptionInterval\n)\n\n")
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				it, ok := ts.Type.(*ast.InterfaceType)
+				if !ok || !ts.Name.IsExported() {
+					continue
+				}
+				g.mock(ts.Name.Name, it)
+			}
+		}
+	}
+
+	res, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		log.Fatalf("format: %v\n%s", err, g.buf.Bytes())
+	}
+	if err := os.WriteFile(*out, res, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type generator struct {
+	fset   *token.FileSet
+	srcPkg string
+	buf    bytes.Buffer
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// param - параметр или результат метода
+type param struct {
+	name string
+	typ  string
+}
+
+func (g *generator) mock(name string, it *ast.InterfaceType) {
+	mock := name + "Mock"
+	g.printf("// %v - мок investgo.%v, методы вызывают соответствующие поля <Method>Func\n", mock, name)
+	g.printf("type %v struct {\n", mock)
+	type method struct {
+		name    string
+		params  []param
+		results []param
+	}
+	methods := make([]method, 0, len(it.Methods.List))
+	for _, field := range it.Methods.List {
+		ft, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) == 0 {
+			log.Fatalf("%v: embedded interfaces are not supported", name)
+		}
+		m := method{name: field.Names[0].Name, params: g.fields(ft.Params, "arg"), results: g.fields(ft.Results, "")}
+		methods = append(methods, m)
+		g.printf("\t%vFunc func(%v) %v\n", m.name, signature(m.params), results(m.results))
+	}
+	g.printf("\n\tmu    sync.Mutex\n\tcalls []Call\n}\n\n")
+	g.printf("var _ investgo.%v = (*%v)(nil)\n\n", name, mock)
+
+	for _, m := range methods {
+		args := make([]string, 0, len(m.params))
+		for _, p := range m.params {
+			args = append(args, p.name)
+		}
+		g.printf("func (m *%v) %v(%v) %v {\n", mock, m.name, signature(m.params), results(m.results))
+		g.printf("\tif m.%vFunc == nil {\n", m.name)
+		g.printf("\t\tpanic(\"%v.%vFunc: method is nil but %v was just called\")\n\t}\n", mock, m.name, m.name)
+		g.printf("\tm.record(\"%v\", []any{%v})\n", m.name, strings.Join(args, ", "))
+		callArgs := strings.Join(args, ", ")
+		if n := len(m.params); n > 0 && strings.HasPrefix(m.params[n-1].typ, "...") {
+			callArgs += "..."
+		}
+		call := fmt.Sprintf("m.%vFunc(%v)", m.name, callArgs)
+		if len(m.results) > 0 {
+			g.printf("\treturn %v\n}\n\n", call)
+		} else {
+			g.printf("\t%v\n}\n\n", call)
+		}
+	}
+
+	g.printf("func (m *%v) record(method string, args []any) {\n", mock)
+	g.printf("\tm.mu.Lock()\n\tdefer m.mu.Unlock()\n")
+	g.printf("\tm.calls = append(m.calls, Call{Method: method, Args: args})\n}\n\n")
+	g.printf("// Calls - все вызовы методов мока в порядке вызова\n")
+	g.printf("func (m *%v) Calls() []Call {\n", mock)
+	g.printf("\tm.mu.Lock()\n\tdefer m.mu.Unlock()\n")
+	g.printf("\treturn append([]Call(nil), m.calls...)\n}\n\n")
+	g.printf("// CallsTo - вызовы метода method в порядке вызова\n")
+	g.printf("func (m *%v) CallsTo(method string) []Call {\n", mock)
+	g.printf("\treturn filterCalls(m.Calls(), method)\n}\n\n")
+}
+
+// fields - параметры или результаты метода, безымянным параметрам присваивается имя prefix<N>
+func (g *generator) fields(fl *ast.FieldList, prefix string) []param {
+	if fl == nil {
+		return nil
+	}
+	params := make([]param, 0, len(fl.List))
+	for _, f := range fl.List {
+		typ := g.typeString(f.Type)
+		if len(f.Names) == 0 {
+			name := ""
+			if prefix != "" {
+				name = fmt.Sprintf("%v%v", prefix, len(params))
+			}
+			params = append(params, param{name: name, typ: typ})
+			continue
+		}
+		for _, n := range f.Names {
+			params = append(params, param{name: n.Name, typ: typ})
+		}
+	}
+	return params
+}
+
+// typeString - тип с квалификатором пакета investgo для собственных типов пакета
+func (g *generator) typeString(expr ast.Expr) string {
+	expr = qualify(expr, g.srcPkg)
+	var b bytes.Buffer
+	if err := printer.Fprint(&b, g.fset, expr); err != nil {
+		log.Fatal(err)
+	}
+	return b.String()
+}
+
+func qualify(expr ast.Expr, pkg string) ast.Expr {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if ast.IsExported(t.Name) {
+			return &ast.SelectorExpr{X: ast.NewIdent(pkg), Sel: ast.NewIdent(t.Name)}
+		}
+		return t
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: qualify(t.X, pkg)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: t.Len, Elt: qualify(t.Elt, pkg)}
+	case *ast.MapType:
+		return &ast.MapType{Key: qualify(t.Key, pkg), Value: qualify(t.Value, pkg)}
+	case *ast.ChanType:
+		return &ast.ChanType{Dir: t.Dir, Value: qualify(t.Value, pkg)}
+	case *ast.Ellipsis:
+		return &ast.Ellipsis{Elt: qualify(t.Elt, pkg)}
+	default:
+		return expr
+	}
+}
+
+func signature(params []param) string {
+	s := make([]string, 0, len(params))
+	for _, p := range params {
+		s = append(s, strings.TrimSpace(p.name+" "+p.typ))
+	}
+	return strings.Join(s, ", ")
+}
+
+func results(params []param) string {
+	switch len(params) {
+	case 0:
+		return ""
+	case 1:
+		return params[0].typ
+	default:
+		return "(" + signature(params) + ")"
+	}
+}
+
+func baseName(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
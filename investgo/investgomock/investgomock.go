@@ -0,0 +1,23 @@
+// Package investgomock - моки интерфейсов клиентов сервисов и стримов пакета investgo.
+//
+// Поведение мока задается полями <Method>Func, вызов метода с незаданной функцией приводит к панике.
+// Моки сгенерированы из investgo/interfaces.go и investgo/trader.go, после изменения интерфейсов нужно выполнить go generate.
+package investgomock
+
+//go:generate go run ./internal/mockgen -src ../interfaces.go,../trader.go -out mocks.go
+
+// Call - вызов метода мока
+type Call struct {
+	Method string
+	Args   []any
+}
+
+func filterCalls(calls []Call, method string) []Call {
+	res := make([]Call, 0)
+	for _, c := range calls {
+		if c.Method == method {
+			res = append(res, c)
+		}
+	}
+	return res
+}
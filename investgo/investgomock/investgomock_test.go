@@ -0,0 +1,38 @@
+package investgomock
+
+import (
+	"testing"
+
+	"github.com/therox/invest-api-go-sdk/investgo"
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+func TestStreamServiceMockReturnsStreamMock(t *testing.T) {
+	candles := make(chan *pb.Candle, 1)
+	stream := &MarketDataStreamerMock{
+		SubscribeCandleFunc: func(ids []string, interval pb.SubscriptionInterval) (<-chan *pb.Candle, error) {
+			return candles, nil
+		},
+	}
+	var streams investgo.MarketDataStreamService = &MarketDataStreamServiceMock{
+		MarketDataStreamFunc: func() (investgo.MarketDataStreamer, error) {
+			return stream, nil
+		},
+	}
+
+	mds, err := streams.MarketDataStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, err := mds.SubscribeCandle([]string{"figi"}, pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	candles <- &pb.Candle{Figi: "figi"}
+	if c := <-ch; c.GetFigi() != "figi" {
+		t.Errorf("candle figi = %v, want figi", c.GetFigi())
+	}
+	if calls := stream.CallsTo("SubscribeCandle"); len(calls) != 1 {
+		t.Errorf("SubscribeCandle calls = %v, want 1", len(calls))
+	}
+}
@@ -0,0 +1,3285 @@
+// Code generated by mockgen from interfaces.go, trader.go. DO NOT EDIT.
+
+package investgomock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/therox/invest-api-go-sdk/investgo"
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+var (
+	_ = context.Background
+	_ = time.Now
+	_ pb.SubscriptionInterval
+)
+
+// InstrumentsServiceMock - мок investgo.InstrumentsService, методы вызывают соответствующие поля <Method>Func
+type InstrumentsServiceMock struct {
+	TradingSchedulesFunc           func(exchange string, from time.Time, to time.Time) (*investgo.TradingSchedulesResponse, error)
+	TradingSchedulesCtxFunc        func(ctx context.Context, exchange string, from time.Time, to time.Time) (*investgo.TradingSchedulesResponse, error)
+	BondByFigiFunc                 func(id string) (*investgo.BondResponse, error)
+	BondByFigiCtxFunc              func(ctx context.Context, id string) (*investgo.BondResponse, error)
+	BondByTickerFunc               func(id string, classCode string) (*investgo.BondResponse, error)
+	BondByTickerCtxFunc            func(ctx context.Context, id string, classCode string) (*investgo.BondResponse, error)
+	BondByUidFunc                  func(id string) (*investgo.BondResponse, error)
+	BondByUidCtxFunc               func(ctx context.Context, id string) (*investgo.BondResponse, error)
+	BondByPositionUidFunc          func(id string) (*investgo.BondResponse, error)
+	BondByPositionUidCtxFunc       func(ctx context.Context, id string) (*investgo.BondResponse, error)
+	BondsFunc                      func(status pb.InstrumentStatus) (*investgo.BondsResponse, error)
+	BondsCtxFunc                   func(ctx context.Context, status pb.InstrumentStatus) (*investgo.BondsResponse, error)
+	GetBondCouponsFunc             func(figi string, from time.Time, to time.Time) (*investgo.GetBondCouponsResponse, error)
+	GetBondCouponsCtxFunc          func(ctx context.Context, figi string, from time.Time, to time.Time) (*investgo.GetBondCouponsResponse, error)
+	CurrencyByFigiFunc             func(id string) (*investgo.CurrencyResponse, error)
+	CurrencyByFigiCtxFunc          func(ctx context.Context, id string) (*investgo.CurrencyResponse, error)
+	CurrencyByTickerFunc           func(id string, classCode string) (*investgo.CurrencyResponse, error)
+	CurrencyByTickerCtxFunc        func(ctx context.Context, id string, classCode string) (*investgo.CurrencyResponse, error)
+	CurrencyByUidFunc              func(id string) (*investgo.CurrencyResponse, error)
+	CurrencyByUidCtxFunc           func(ctx context.Context, id string) (*investgo.CurrencyResponse, error)
+	CurrencyByPositionUidFunc      func(id string) (*investgo.CurrencyResponse, error)
+	CurrencyByPositionUidCtxFunc   func(ctx context.Context, id string) (*investgo.CurrencyResponse, error)
+	CurrenciesFunc                 func(status pb.InstrumentStatus) (*investgo.CurrenciesResponse, error)
+	CurrenciesCtxFunc              func(ctx context.Context, status pb.InstrumentStatus) (*investgo.CurrenciesResponse, error)
+	EtfByFigiFunc                  func(id string) (*investgo.EtfResponse, error)
+	EtfByFigiCtxFunc               func(ctx context.Context, id string) (*investgo.EtfResponse, error)
+	EtfByTickerFunc                func(id string, classCode string) (*investgo.EtfResponse, error)
+	EtfByTickerCtxFunc             func(ctx context.Context, id string, classCode string) (*investgo.EtfResponse, error)
+	EtfByUidFunc                   func(id string) (*investgo.EtfResponse, error)
+	EtfByUidCtxFunc                func(ctx context.Context, id string) (*investgo.EtfResponse, error)
+	EtfByPositionUidFunc           func(id string) (*investgo.EtfResponse, error)
+	EtfByPositionUidCtxFunc        func(ctx context.Context, id string) (*investgo.EtfResponse, error)
+	EtfsFunc                       func(status pb.InstrumentStatus) (*investgo.EtfsResponse, error)
+	EtfsCtxFunc                    func(ctx context.Context, status pb.InstrumentStatus) (*investgo.EtfsResponse, error)
+	FutureByFigiFunc               func(id string) (*investgo.FutureResponse, error)
+	FutureByFigiCtxFunc            func(ctx context.Context, id string) (*investgo.FutureResponse, error)
+	FutureByTickerFunc             func(id string, classCode string) (*investgo.FutureResponse, error)
+	FutureByTickerCtxFunc          func(ctx context.Context, id string, classCode string) (*investgo.FutureResponse, error)
+	FutureByUidFunc                func(id string) (*investgo.FutureResponse, error)
+	FutureByUidCtxFunc             func(ctx context.Context, id string) (*investgo.FutureResponse, error)
+	FutureByPositionUidFunc        func(id string) (*investgo.FutureResponse, error)
+	FutureByPositionUidCtxFunc     func(ctx context.Context, id string) (*investgo.FutureResponse, error)
+	FuturesFunc                    func(status pb.InstrumentStatus) (*investgo.FuturesResponse, error)
+	FuturesCtxFunc                 func(ctx context.Context, status pb.InstrumentStatus) (*investgo.FuturesResponse, error)
+	OptionByTickerFunc             func(id string, classCode string) (*investgo.OptionResponse, error)
+	OptionByTickerCtxFunc          func(ctx context.Context, id string, classCode string) (*investgo.OptionResponse, error)
+	OptionByUidFunc                func(id string) (*investgo.OptionResponse, error)
+	OptionByUidCtxFunc             func(ctx context.Context, id string) (*investgo.OptionResponse, error)
+	OptionByPositionUidFunc        func(id string) (*investgo.OptionResponse, error)
+	OptionByPositionUidCtxFunc     func(ctx context.Context, id string) (*investgo.OptionResponse, error)
+	OptionsFunc                    func(status pb.InstrumentStatus) (*investgo.OptionsResponse, error)
+	OptionsCtxFunc                 func(ctx context.Context, status pb.InstrumentStatus) (*investgo.OptionsResponse, error)
+	ShareByFigiFunc                func(id string) (*investgo.ShareResponse, error)
+	ShareByFigiCtxFunc             func(ctx context.Context, id string) (*investgo.ShareResponse, error)
+	ShareByTickerFunc              func(id string, classCode string) (*investgo.ShareResponse, error)
+	ShareByTickerCtxFunc           func(ctx context.Context, id string, classCode string) (*investgo.ShareResponse, error)
+	ShareByUidFunc                 func(id string) (*investgo.ShareResponse, error)
+	ShareByUidCtxFunc              func(ctx context.Context, id string) (*investgo.ShareResponse, error)
+	ShareByPositionUidFunc         func(id string) (*investgo.ShareResponse, error)
+	ShareByPositionUidCtxFunc      func(ctx context.Context, id string) (*investgo.ShareResponse, error)
+	SharesFunc                     func(status pb.InstrumentStatus) (*investgo.SharesResponse, error)
+	SharesCtxFunc                  func(ctx context.Context, status pb.InstrumentStatus) (*investgo.SharesResponse, error)
+	InstrumentByFigiFunc           func(id string) (*investgo.InstrumentResponse, error)
+	InstrumentByFigiCtxFunc        func(ctx context.Context, id string) (*investgo.InstrumentResponse, error)
+	InstrumentByTickerFunc         func(id string, classCode string) (*investgo.InstrumentResponse, error)
+	InstrumentByTickerCtxFunc      func(ctx context.Context, id string, classCode string) (*investgo.InstrumentResponse, error)
+	InstrumentByUidFunc            func(id string) (*investgo.InstrumentResponse, error)
+	InstrumentByUidCtxFunc         func(ctx context.Context, id string) (*investgo.InstrumentResponse, error)
+	InstrumentByPositionUidFunc    func(id string) (*investgo.InstrumentResponse, error)
+	InstrumentByPositionUidCtxFunc func(ctx context.Context, id string) (*investgo.InstrumentResponse, error)
+	GetAccruedInterestsFunc        func(figi string, from time.Time, to time.Time) (*investgo.GetAccruedInterestsResponse, error)
+	GetAccruedInterestsCtxFunc     func(ctx context.Context, figi string, from time.Time, to time.Time) (*investgo.GetAccruedInterestsResponse, error)
+	GetFuturesMarginFunc           func(figi string) (*investgo.GetFuturesMarginResponse, error)
+	GetFuturesMarginCtxFunc        func(ctx context.Context, figi string) (*investgo.GetFuturesMarginResponse, error)
+	GetDividentsFunc               func(figi string, from time.Time, to time.Time) (*investgo.GetDividendsResponse, error)
+	GetDividentsCtxFunc            func(ctx context.Context, figi string, from time.Time, to time.Time) (*investgo.GetDividendsResponse, error)
+	GetAssetByFunc                 func(id string) (*investgo.AssetResponse, error)
+	GetAssetByCtxFunc              func(ctx context.Context, id string) (*investgo.AssetResponse, error)
+	GetAssetsFunc                  func() (*investgo.AssetsResponse, error)
+	GetAssetsCtxFunc               func(ctx context.Context) (*investgo.AssetsResponse, error)
+	GetFavoritesFunc               func() (*investgo.GetFavoritesResponse, error)
+	GetFavoritesCtxFunc            func(ctx context.Context) (*investgo.GetFavoritesResponse, error)
+	EditFavoritesFunc              func(instruments []string, actionType pb.EditFavoritesActionType) (*investgo.EditFavoritesResponse, error)
+	EditFavoritesCtxFunc           func(ctx context.Context, instruments []string, actionType pb.EditFavoritesActionType) (*investgo.EditFavoritesResponse, error)
+	GetCountriesFunc               func() (*investgo.GetCountriesResponse, error)
+	GetCountriesCtxFunc            func(ctx context.Context) (*investgo.GetCountriesResponse, error)
+	GetBrandsFunc                  func() (*investgo.GetBrandsResponse, error)
+	GetBrandsCtxFunc               func(ctx context.Context) (*investgo.GetBrandsResponse, error)
+	GetBrandByFunc                 func(id string) (*investgo.Brand, error)
+	GetBrandByCtxFunc              func(ctx context.Context, id string) (*investgo.Brand, error)
+	FindInstrumentFunc             func(query string) (*investgo.FindInstrumentResponse, error)
+	FindInstrumentCtxFunc          func(ctx context.Context, query string) (*investgo.FindInstrumentResponse, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ investgo.InstrumentsService = (*InstrumentsServiceMock)(nil)
+
+func (m *InstrumentsServiceMock) TradingSchedules(exchange string, from time.Time, to time.Time) (*investgo.TradingSchedulesResponse, error) {
+	if m.TradingSchedulesFunc == nil {
+		panic("InstrumentsServiceMock.TradingSchedulesFunc: method is nil but TradingSchedules was just called")
+	}
+	m.record("TradingSchedules", []any{exchange, from, to})
+	return m.TradingSchedulesFunc(exchange, from, to)
+}
+
+func (m *InstrumentsServiceMock) TradingSchedulesCtx(ctx context.Context, exchange string, from time.Time, to time.Time) (*investgo.TradingSchedulesResponse, error) {
+	if m.TradingSchedulesCtxFunc == nil {
+		panic("InstrumentsServiceMock.TradingSchedulesCtxFunc: method is nil but TradingSchedulesCtx was just called")
+	}
+	m.record("TradingSchedulesCtx", []any{ctx, exchange, from, to})
+	return m.TradingSchedulesCtxFunc(ctx, exchange, from, to)
+}
+
+func (m *InstrumentsServiceMock) BondByFigi(id string) (*investgo.BondResponse, error) {
+	if m.BondByFigiFunc == nil {
+		panic("InstrumentsServiceMock.BondByFigiFunc: method is nil but BondByFigi was just called")
+	}
+	m.record("BondByFigi", []any{id})
+	return m.BondByFigiFunc(id)
+}
+
+func (m *InstrumentsServiceMock) BondByFigiCtx(ctx context.Context, id string) (*investgo.BondResponse, error) {
+	if m.BondByFigiCtxFunc == nil {
+		panic("InstrumentsServiceMock.BondByFigiCtxFunc: method is nil but BondByFigiCtx was just called")
+	}
+	m.record("BondByFigiCtx", []any{ctx, id})
+	return m.BondByFigiCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) BondByTicker(id string, classCode string) (*investgo.BondResponse, error) {
+	if m.BondByTickerFunc == nil {
+		panic("InstrumentsServiceMock.BondByTickerFunc: method is nil but BondByTicker was just called")
+	}
+	m.record("BondByTicker", []any{id, classCode})
+	return m.BondByTickerFunc(id, classCode)
+}
+
+func (m *InstrumentsServiceMock) BondByTickerCtx(ctx context.Context, id string, classCode string) (*investgo.BondResponse, error) {
+	if m.BondByTickerCtxFunc == nil {
+		panic("InstrumentsServiceMock.BondByTickerCtxFunc: method is nil but BondByTickerCtx was just called")
+	}
+	m.record("BondByTickerCtx", []any{ctx, id, classCode})
+	return m.BondByTickerCtxFunc(ctx, id, classCode)
+}
+
+func (m *InstrumentsServiceMock) BondByUid(id string) (*investgo.BondResponse, error) {
+	if m.BondByUidFunc == nil {
+		panic("InstrumentsServiceMock.BondByUidFunc: method is nil but BondByUid was just called")
+	}
+	m.record("BondByUid", []any{id})
+	return m.BondByUidFunc(id)
+}
+
+func (m *InstrumentsServiceMock) BondByUidCtx(ctx context.Context, id string) (*investgo.BondResponse, error) {
+	if m.BondByUidCtxFunc == nil {
+		panic("InstrumentsServiceMock.BondByUidCtxFunc: method is nil but BondByUidCtx was just called")
+	}
+	m.record("BondByUidCtx", []any{ctx, id})
+	return m.BondByUidCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) BondByPositionUid(id string) (*investgo.BondResponse, error) {
+	if m.BondByPositionUidFunc == nil {
+		panic("InstrumentsServiceMock.BondByPositionUidFunc: method is nil but BondByPositionUid was just called")
+	}
+	m.record("BondByPositionUid", []any{id})
+	return m.BondByPositionUidFunc(id)
+}
+
+func (m *InstrumentsServiceMock) BondByPositionUidCtx(ctx context.Context, id string) (*investgo.BondResponse, error) {
+	if m.BondByPositionUidCtxFunc == nil {
+		panic("InstrumentsServiceMock.BondByPositionUidCtxFunc: method is nil but BondByPositionUidCtx was just called")
+	}
+	m.record("BondByPositionUidCtx", []any{ctx, id})
+	return m.BondByPositionUidCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) Bonds(status pb.InstrumentStatus) (*investgo.BondsResponse, error) {
+	if m.BondsFunc == nil {
+		panic("InstrumentsServiceMock.BondsFunc: method is nil but Bonds was just called")
+	}
+	m.record("Bonds", []any{status})
+	return m.BondsFunc(status)
+}
+
+func (m *InstrumentsServiceMock) BondsCtx(ctx context.Context, status pb.InstrumentStatus) (*investgo.BondsResponse, error) {
+	if m.BondsCtxFunc == nil {
+		panic("InstrumentsServiceMock.BondsCtxFunc: method is nil but BondsCtx was just called")
+	}
+	m.record("BondsCtx", []any{ctx, status})
+	return m.BondsCtxFunc(ctx, status)
+}
+
+func (m *InstrumentsServiceMock) GetBondCoupons(figi string, from time.Time, to time.Time) (*investgo.GetBondCouponsResponse, error) {
+	if m.GetBondCouponsFunc == nil {
+		panic("InstrumentsServiceMock.GetBondCouponsFunc: method is nil but GetBondCoupons was just called")
+	}
+	m.record("GetBondCoupons", []any{figi, from, to})
+	return m.GetBondCouponsFunc(figi, from, to)
+}
+
+func (m *InstrumentsServiceMock) GetBondCouponsCtx(ctx context.Context, figi string, from time.Time, to time.Time) (*investgo.GetBondCouponsResponse, error) {
+	if m.GetBondCouponsCtxFunc == nil {
+		panic("InstrumentsServiceMock.GetBondCouponsCtxFunc: method is nil but GetBondCouponsCtx was just called")
+	}
+	m.record("GetBondCouponsCtx", []any{ctx, figi, from, to})
+	return m.GetBondCouponsCtxFunc(ctx, figi, from, to)
+}
+
+func (m *InstrumentsServiceMock) CurrencyByFigi(id string) (*investgo.CurrencyResponse, error) {
+	if m.CurrencyByFigiFunc == nil {
+		panic("InstrumentsServiceMock.CurrencyByFigiFunc: method is nil but CurrencyByFigi was just called")
+	}
+	m.record("CurrencyByFigi", []any{id})
+	return m.CurrencyByFigiFunc(id)
+}
+
+func (m *InstrumentsServiceMock) CurrencyByFigiCtx(ctx context.Context, id string) (*investgo.CurrencyResponse, error) {
+	if m.CurrencyByFigiCtxFunc == nil {
+		panic("InstrumentsServiceMock.CurrencyByFigiCtxFunc: method is nil but CurrencyByFigiCtx was just called")
+	}
+	m.record("CurrencyByFigiCtx", []any{ctx, id})
+	return m.CurrencyByFigiCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) CurrencyByTicker(id string, classCode string) (*investgo.CurrencyResponse, error) {
+	if m.CurrencyByTickerFunc == nil {
+		panic("InstrumentsServiceMock.CurrencyByTickerFunc: method is nil but CurrencyByTicker was just called")
+	}
+	m.record("CurrencyByTicker", []any{id, classCode})
+	return m.CurrencyByTickerFunc(id, classCode)
+}
+
+func (m *InstrumentsServiceMock) CurrencyByTickerCtx(ctx context.Context, id string, classCode string) (*investgo.CurrencyResponse, error) {
+	if m.CurrencyByTickerCtxFunc == nil {
+		panic("InstrumentsServiceMock.CurrencyByTickerCtxFunc: method is nil but CurrencyByTickerCtx was just called")
+	}
+	m.record("CurrencyByTickerCtx", []any{ctx, id, classCode})
+	return m.CurrencyByTickerCtxFunc(ctx, id, classCode)
+}
+
+func (m *InstrumentsServiceMock) CurrencyByUid(id string) (*investgo.CurrencyResponse, error) {
+	if m.CurrencyByUidFunc == nil {
+		panic("InstrumentsServiceMock.CurrencyByUidFunc: method is nil but CurrencyByUid was just called")
+	}
+	m.record("CurrencyByUid", []any{id})
+	return m.CurrencyByUidFunc(id)
+}
+
+func (m *InstrumentsServiceMock) CurrencyByUidCtx(ctx context.Context, id string) (*investgo.CurrencyResponse, error) {
+	if m.CurrencyByUidCtxFunc == nil {
+		panic("InstrumentsServiceMock.CurrencyByUidCtxFunc: method is nil but CurrencyByUidCtx was just called")
+	}
+	m.record("CurrencyByUidCtx", []any{ctx, id})
+	return m.CurrencyByUidCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) CurrencyByPositionUid(id string) (*investgo.CurrencyResponse, error) {
+	if m.CurrencyByPositionUidFunc == nil {
+		panic("InstrumentsServiceMock.CurrencyByPositionUidFunc: method is nil but CurrencyByPositionUid was just called")
+	}
+	m.record("CurrencyByPositionUid", []any{id})
+	return m.CurrencyByPositionUidFunc(id)
+}
+
+func (m *InstrumentsServiceMock) CurrencyByPositionUidCtx(ctx context.Context, id string) (*investgo.CurrencyResponse, error) {
+	if m.CurrencyByPositionUidCtxFunc == nil {
+		panic("InstrumentsServiceMock.CurrencyByPositionUidCtxFunc: method is nil but CurrencyByPositionUidCtx was just called")
+	}
+	m.record("CurrencyByPositionUidCtx", []any{ctx, id})
+	return m.CurrencyByPositionUidCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) Currencies(status pb.InstrumentStatus) (*investgo.CurrenciesResponse, error) {
+	if m.CurrenciesFunc == nil {
+		panic("InstrumentsServiceMock.CurrenciesFunc: method is nil but Currencies was just called")
+	}
+	m.record("Currencies", []any{status})
+	return m.CurrenciesFunc(status)
+}
+
+func (m *InstrumentsServiceMock) CurrenciesCtx(ctx context.Context, status pb.InstrumentStatus) (*investgo.CurrenciesResponse, error) {
+	if m.CurrenciesCtxFunc == nil {
+		panic("InstrumentsServiceMock.CurrenciesCtxFunc: method is nil but CurrenciesCtx was just called")
+	}
+	m.record("CurrenciesCtx", []any{ctx, status})
+	return m.CurrenciesCtxFunc(ctx, status)
+}
+
+func (m *InstrumentsServiceMock) EtfByFigi(id string) (*investgo.EtfResponse, error) {
+	if m.EtfByFigiFunc == nil {
+		panic("InstrumentsServiceMock.EtfByFigiFunc: method is nil but EtfByFigi was just called")
+	}
+	m.record("EtfByFigi", []any{id})
+	return m.EtfByFigiFunc(id)
+}
+
+func (m *InstrumentsServiceMock) EtfByFigiCtx(ctx context.Context, id string) (*investgo.EtfResponse, error) {
+	if m.EtfByFigiCtxFunc == nil {
+		panic("InstrumentsServiceMock.EtfByFigiCtxFunc: method is nil but EtfByFigiCtx was just called")
+	}
+	m.record("EtfByFigiCtx", []any{ctx, id})
+	return m.EtfByFigiCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) EtfByTicker(id string, classCode string) (*investgo.EtfResponse, error) {
+	if m.EtfByTickerFunc == nil {
+		panic("InstrumentsServiceMock.EtfByTickerFunc: method is nil but EtfByTicker was just called")
+	}
+	m.record("EtfByTicker", []any{id, classCode})
+	return m.EtfByTickerFunc(id, classCode)
+}
+
+func (m *InstrumentsServiceMock) EtfByTickerCtx(ctx context.Context, id string, classCode string) (*investgo.EtfResponse, error) {
+	if m.EtfByTickerCtxFunc == nil {
+		panic("InstrumentsServiceMock.EtfByTickerCtxFunc: method is nil but EtfByTickerCtx was just called")
+	}
+	m.record("EtfByTickerCtx", []any{ctx, id, classCode})
+	return m.EtfByTickerCtxFunc(ctx, id, classCode)
+}
+
+func (m *InstrumentsServiceMock) EtfByUid(id string) (*investgo.EtfResponse, error) {
+	if m.EtfByUidFunc == nil {
+		panic("InstrumentsServiceMock.EtfByUidFunc: method is nil but EtfByUid was just called")
+	}
+	m.record("EtfByUid", []any{id})
+	return m.EtfByUidFunc(id)
+}
+
+func (m *InstrumentsServiceMock) EtfByUidCtx(ctx context.Context, id string) (*investgo.EtfResponse, error) {
+	if m.EtfByUidCtxFunc == nil {
+		panic("InstrumentsServiceMock.EtfByUidCtxFunc: method is nil but EtfByUidCtx was just called")
+	}
+	m.record("EtfByUidCtx", []any{ctx, id})
+	return m.EtfByUidCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) EtfByPositionUid(id string) (*investgo.EtfResponse, error) {
+	if m.EtfByPositionUidFunc == nil {
+		panic("InstrumentsServiceMock.EtfByPositionUidFunc: method is nil but EtfByPositionUid was just called")
+	}
+	m.record("EtfByPositionUid", []any{id})
+	return m.EtfByPositionUidFunc(id)
+}
+
+func (m *InstrumentsServiceMock) EtfByPositionUidCtx(ctx context.Context, id string) (*investgo.EtfResponse, error) {
+	if m.EtfByPositionUidCtxFunc == nil {
+		panic("InstrumentsServiceMock.EtfByPositionUidCtxFunc: method is nil but EtfByPositionUidCtx was just called")
+	}
+	m.record("EtfByPositionUidCtx", []any{ctx, id})
+	return m.EtfByPositionUidCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) Etfs(status pb.InstrumentStatus) (*investgo.EtfsResponse, error) {
+	if m.EtfsFunc == nil {
+		panic("InstrumentsServiceMock.EtfsFunc: method is nil but Etfs was just called")
+	}
+	m.record("Etfs", []any{status})
+	return m.EtfsFunc(status)
+}
+
+func (m *InstrumentsServiceMock) EtfsCtx(ctx context.Context, status pb.InstrumentStatus) (*investgo.EtfsResponse, error) {
+	if m.EtfsCtxFunc == nil {
+		panic("InstrumentsServiceMock.EtfsCtxFunc: method is nil but EtfsCtx was just called")
+	}
+	m.record("EtfsCtx", []any{ctx, status})
+	return m.EtfsCtxFunc(ctx, status)
+}
+
+func (m *InstrumentsServiceMock) FutureByFigi(id string) (*investgo.FutureResponse, error) {
+	if m.FutureByFigiFunc == nil {
+		panic("InstrumentsServiceMock.FutureByFigiFunc: method is nil but FutureByFigi was just called")
+	}
+	m.record("FutureByFigi", []any{id})
+	return m.FutureByFigiFunc(id)
+}
+
+func (m *InstrumentsServiceMock) FutureByFigiCtx(ctx context.Context, id string) (*investgo.FutureResponse, error) {
+	if m.FutureByFigiCtxFunc == nil {
+		panic("InstrumentsServiceMock.FutureByFigiCtxFunc: method is nil but FutureByFigiCtx was just called")
+	}
+	m.record("FutureByFigiCtx", []any{ctx, id})
+	return m.FutureByFigiCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) FutureByTicker(id string, classCode string) (*investgo.FutureResponse, error) {
+	if m.FutureByTickerFunc == nil {
+		panic("InstrumentsServiceMock.FutureByTickerFunc: method is nil but FutureByTicker was just called")
+	}
+	m.record("FutureByTicker", []any{id, classCode})
+	return m.FutureByTickerFunc(id, classCode)
+}
+
+func (m *InstrumentsServiceMock) FutureByTickerCtx(ctx context.Context, id string, classCode string) (*investgo.FutureResponse, error) {
+	if m.FutureByTickerCtxFunc == nil {
+		panic("InstrumentsServiceMock.FutureByTickerCtxFunc: method is nil but FutureByTickerCtx was just called")
+	}
+	m.record("FutureByTickerCtx", []any{ctx, id, classCode})
+	return m.FutureByTickerCtxFunc(ctx, id, classCode)
+}
+
+func (m *InstrumentsServiceMock) FutureByUid(id string) (*investgo.FutureResponse, error) {
+	if m.FutureByUidFunc == nil {
+		panic("InstrumentsServiceMock.FutureByUidFunc: method is nil but FutureByUid was just called")
+	}
+	m.record("FutureByUid", []any{id})
+	return m.FutureByUidFunc(id)
+}
+
+func (m *InstrumentsServiceMock) FutureByUidCtx(ctx context.Context, id string) (*investgo.FutureResponse, error) {
+	if m.FutureByUidCtxFunc == nil {
+		panic("InstrumentsServiceMock.FutureByUidCtxFunc: method is nil but FutureByUidCtx was just called")
+	}
+	m.record("FutureByUidCtx", []any{ctx, id})
+	return m.FutureByUidCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) FutureByPositionUid(id string) (*investgo.FutureResponse, error) {
+	if m.FutureByPositionUidFunc == nil {
+		panic("InstrumentsServiceMock.FutureByPositionUidFunc: method is nil but FutureByPositionUid was just called")
+	}
+	m.record("FutureByPositionUid", []any{id})
+	return m.FutureByPositionUidFunc(id)
+}
+
+func (m *InstrumentsServiceMock) FutureByPositionUidCtx(ctx context.Context, id string) (*investgo.FutureResponse, error) {
+	if m.FutureByPositionUidCtxFunc == nil {
+		panic("InstrumentsServiceMock.FutureByPositionUidCtxFunc: method is nil but FutureByPositionUidCtx was just called")
+	}
+	m.record("FutureByPositionUidCtx", []any{ctx, id})
+	return m.FutureByPositionUidCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) Futures(status pb.InstrumentStatus) (*investgo.FuturesResponse, error) {
+	if m.FuturesFunc == nil {
+		panic("InstrumentsServiceMock.FuturesFunc: method is nil but Futures was just called")
+	}
+	m.record("Futures", []any{status})
+	return m.FuturesFunc(status)
+}
+
+func (m *InstrumentsServiceMock) FuturesCtx(ctx context.Context, status pb.InstrumentStatus) (*investgo.FuturesResponse, error) {
+	if m.FuturesCtxFunc == nil {
+		panic("InstrumentsServiceMock.FuturesCtxFunc: method is nil but FuturesCtx was just called")
+	}
+	m.record("FuturesCtx", []any{ctx, status})
+	return m.FuturesCtxFunc(ctx, status)
+}
+
+func (m *InstrumentsServiceMock) OptionByTicker(id string, classCode string) (*investgo.OptionResponse, error) {
+	if m.OptionByTickerFunc == nil {
+		panic("InstrumentsServiceMock.OptionByTickerFunc: method is nil but OptionByTicker was just called")
+	}
+	m.record("OptionByTicker", []any{id, classCode})
+	return m.OptionByTickerFunc(id, classCode)
+}
+
+func (m *InstrumentsServiceMock) OptionByTickerCtx(ctx context.Context, id string, classCode string) (*investgo.OptionResponse, error) {
+	if m.OptionByTickerCtxFunc == nil {
+		panic("InstrumentsServiceMock.OptionByTickerCtxFunc: method is nil but OptionByTickerCtx was just called")
+	}
+	m.record("OptionByTickerCtx", []any{ctx, id, classCode})
+	return m.OptionByTickerCtxFunc(ctx, id, classCode)
+}
+
+func (m *InstrumentsServiceMock) OptionByUid(id string) (*investgo.OptionResponse, error) {
+	if m.OptionByUidFunc == nil {
+		panic("InstrumentsServiceMock.OptionByUidFunc: method is nil but OptionByUid was just called")
+	}
+	m.record("OptionByUid", []any{id})
+	return m.OptionByUidFunc(id)
+}
+
+func (m *InstrumentsServiceMock) OptionByUidCtx(ctx context.Context, id string) (*investgo.OptionResponse, error) {
+	if m.OptionByUidCtxFunc == nil {
+		panic("InstrumentsServiceMock.OptionByUidCtxFunc: method is nil but OptionByUidCtx was just called")
+	}
+	m.record("OptionByUidCtx", []any{ctx, id})
+	return m.OptionByUidCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) OptionByPositionUid(id string) (*investgo.OptionResponse, error) {
+	if m.OptionByPositionUidFunc == nil {
+		panic("InstrumentsServiceMock.OptionByPositionUidFunc: method is nil but OptionByPositionUid was just called")
+	}
+	m.record("OptionByPositionUid", []any{id})
+	return m.OptionByPositionUidFunc(id)
+}
+
+func (m *InstrumentsServiceMock) OptionByPositionUidCtx(ctx context.Context, id string) (*investgo.OptionResponse, error) {
+	if m.OptionByPositionUidCtxFunc == nil {
+		panic("InstrumentsServiceMock.OptionByPositionUidCtxFunc: method is nil but OptionByPositionUidCtx was just called")
+	}
+	m.record("OptionByPositionUidCtx", []any{ctx, id})
+	return m.OptionByPositionUidCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) Options(status pb.InstrumentStatus) (*investgo.OptionsResponse, error) {
+	if m.OptionsFunc == nil {
+		panic("InstrumentsServiceMock.OptionsFunc: method is nil but Options was just called")
+	}
+	m.record("Options", []any{status})
+	return m.OptionsFunc(status)
+}
+
+func (m *InstrumentsServiceMock) OptionsCtx(ctx context.Context, status pb.InstrumentStatus) (*investgo.OptionsResponse, error) {
+	if m.OptionsCtxFunc == nil {
+		panic("InstrumentsServiceMock.OptionsCtxFunc: method is nil but OptionsCtx was just called")
+	}
+	m.record("OptionsCtx", []any{ctx, status})
+	return m.OptionsCtxFunc(ctx, status)
+}
+
+func (m *InstrumentsServiceMock) ShareByFigi(id string) (*investgo.ShareResponse, error) {
+	if m.ShareByFigiFunc == nil {
+		panic("InstrumentsServiceMock.ShareByFigiFunc: method is nil but ShareByFigi was just called")
+	}
+	m.record("ShareByFigi", []any{id})
+	return m.ShareByFigiFunc(id)
+}
+
+func (m *InstrumentsServiceMock) ShareByFigiCtx(ctx context.Context, id string) (*investgo.ShareResponse, error) {
+	if m.ShareByFigiCtxFunc == nil {
+		panic("InstrumentsServiceMock.ShareByFigiCtxFunc: method is nil but ShareByFigiCtx was just called")
+	}
+	m.record("ShareByFigiCtx", []any{ctx, id})
+	return m.ShareByFigiCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) ShareByTicker(id string, classCode string) (*investgo.ShareResponse, error) {
+	if m.ShareByTickerFunc == nil {
+		panic("InstrumentsServiceMock.ShareByTickerFunc: method is nil but ShareByTicker was just called")
+	}
+	m.record("ShareByTicker", []any{id, classCode})
+	return m.ShareByTickerFunc(id, classCode)
+}
+
+func (m *InstrumentsServiceMock) ShareByTickerCtx(ctx context.Context, id string, classCode string) (*investgo.ShareResponse, error) {
+	if m.ShareByTickerCtxFunc == nil {
+		panic("InstrumentsServiceMock.ShareByTickerCtxFunc: method is nil but ShareByTickerCtx was just called")
+	}
+	m.record("ShareByTickerCtx", []any{ctx, id, classCode})
+	return m.ShareByTickerCtxFunc(ctx, id, classCode)
+}
+
+func (m *InstrumentsServiceMock) ShareByUid(id string) (*investgo.ShareResponse, error) {
+	if m.ShareByUidFunc == nil {
+		panic("InstrumentsServiceMock.ShareByUidFunc: method is nil but ShareByUid was just called")
+	}
+	m.record("ShareByUid", []any{id})
+	return m.ShareByUidFunc(id)
+}
+
+func (m *InstrumentsServiceMock) ShareByUidCtx(ctx context.Context, id string) (*investgo.ShareResponse, error) {
+	if m.ShareByUidCtxFunc == nil {
+		panic("InstrumentsServiceMock.ShareByUidCtxFunc: method is nil but ShareByUidCtx was just called")
+	}
+	m.record("ShareByUidCtx", []any{ctx, id})
+	return m.ShareByUidCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) ShareByPositionUid(id string) (*investgo.ShareResponse, error) {
+	if m.ShareByPositionUidFunc == nil {
+		panic("InstrumentsServiceMock.ShareByPositionUidFunc: method is nil but ShareByPositionUid was just called")
+	}
+	m.record("ShareByPositionUid", []any{id})
+	return m.ShareByPositionUidFunc(id)
+}
+
+func (m *InstrumentsServiceMock) ShareByPositionUidCtx(ctx context.Context, id string) (*investgo.ShareResponse, error) {
+	if m.ShareByPositionUidCtxFunc == nil {
+		panic("InstrumentsServiceMock.ShareByPositionUidCtxFunc: method is nil but ShareByPositionUidCtx was just called")
+	}
+	m.record("ShareByPositionUidCtx", []any{ctx, id})
+	return m.ShareByPositionUidCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) Shares(status pb.InstrumentStatus) (*investgo.SharesResponse, error) {
+	if m.SharesFunc == nil {
+		panic("InstrumentsServiceMock.SharesFunc: method is nil but Shares was just called")
+	}
+	m.record("Shares", []any{status})
+	return m.SharesFunc(status)
+}
+
+func (m *InstrumentsServiceMock) SharesCtx(ctx context.Context, status pb.InstrumentStatus) (*investgo.SharesResponse, error) {
+	if m.SharesCtxFunc == nil {
+		panic("InstrumentsServiceMock.SharesCtxFunc: method is nil but SharesCtx was just called")
+	}
+	m.record("SharesCtx", []any{ctx, status})
+	return m.SharesCtxFunc(ctx, status)
+}
+
+func (m *InstrumentsServiceMock) InstrumentByFigi(id string) (*investgo.InstrumentResponse, error) {
+	if m.InstrumentByFigiFunc == nil {
+		panic("InstrumentsServiceMock.InstrumentByFigiFunc: method is nil but InstrumentByFigi was just called")
+	}
+	m.record("InstrumentByFigi", []any{id})
+	return m.InstrumentByFigiFunc(id)
+}
+
+func (m *InstrumentsServiceMock) InstrumentByFigiCtx(ctx context.Context, id string) (*investgo.InstrumentResponse, error) {
+	if m.InstrumentByFigiCtxFunc == nil {
+		panic("InstrumentsServiceMock.InstrumentByFigiCtxFunc: method is nil but InstrumentByFigiCtx was just called")
+	}
+	m.record("InstrumentByFigiCtx", []any{ctx, id})
+	return m.InstrumentByFigiCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) InstrumentByTicker(id string, classCode string) (*investgo.InstrumentResponse, error) {
+	if m.InstrumentByTickerFunc == nil {
+		panic("InstrumentsServiceMock.InstrumentByTickerFunc: method is nil but InstrumentByTicker was just called")
+	}
+	m.record("InstrumentByTicker", []any{id, classCode})
+	return m.InstrumentByTickerFunc(id, classCode)
+}
+
+func (m *InstrumentsServiceMock) InstrumentByTickerCtx(ctx context.Context, id string, classCode string) (*investgo.InstrumentResponse, error) {
+	if m.InstrumentByTickerCtxFunc == nil {
+		panic("InstrumentsServiceMock.InstrumentByTickerCtxFunc: method is nil but InstrumentByTickerCtx was just called")
+	}
+	m.record("InstrumentByTickerCtx", []any{ctx, id, classCode})
+	return m.InstrumentByTickerCtxFunc(ctx, id, classCode)
+}
+
+func (m *InstrumentsServiceMock) InstrumentByUid(id string) (*investgo.InstrumentResponse, error) {
+	if m.InstrumentByUidFunc == nil {
+		panic("InstrumentsServiceMock.InstrumentByUidFunc: method is nil but InstrumentByUid was just called")
+	}
+	m.record("InstrumentByUid", []any{id})
+	return m.InstrumentByUidFunc(id)
+}
+
+func (m *InstrumentsServiceMock) InstrumentByUidCtx(ctx context.Context, id string) (*investgo.InstrumentResponse, error) {
+	if m.InstrumentByUidCtxFunc == nil {
+		panic("InstrumentsServiceMock.InstrumentByUidCtxFunc: method is nil but InstrumentByUidCtx was just called")
+	}
+	m.record("InstrumentByUidCtx", []any{ctx, id})
+	return m.InstrumentByUidCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) InstrumentByPositionUid(id string) (*investgo.InstrumentResponse, error) {
+	if m.InstrumentByPositionUidFunc == nil {
+		panic("InstrumentsServiceMock.InstrumentByPositionUidFunc: method is nil but InstrumentByPositionUid was just called")
+	}
+	m.record("InstrumentByPositionUid", []any{id})
+	return m.InstrumentByPositionUidFunc(id)
+}
+
+func (m *InstrumentsServiceMock) InstrumentByPositionUidCtx(ctx context.Context, id string) (*investgo.InstrumentResponse, error) {
+	if m.InstrumentByPositionUidCtxFunc == nil {
+		panic("InstrumentsServiceMock.InstrumentByPositionUidCtxFunc: method is nil but InstrumentByPositionUidCtx was just called")
+	}
+	m.record("InstrumentByPositionUidCtx", []any{ctx, id})
+	return m.InstrumentByPositionUidCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) GetAccruedInterests(figi string, from time.Time, to time.Time) (*investgo.GetAccruedInterestsResponse, error) {
+	if m.GetAccruedInterestsFunc == nil {
+		panic("InstrumentsServiceMock.GetAccruedInterestsFunc: method is nil but GetAccruedInterests was just called")
+	}
+	m.record("GetAccruedInterests", []any{figi, from, to})
+	return m.GetAccruedInterestsFunc(figi, from, to)
+}
+
+func (m *InstrumentsServiceMock) GetAccruedInterestsCtx(ctx context.Context, figi string, from time.Time, to time.Time) (*investgo.GetAccruedInterestsResponse, error) {
+	if m.GetAccruedInterestsCtxFunc == nil {
+		panic("InstrumentsServiceMock.GetAccruedInterestsCtxFunc: method is nil but GetAccruedInterestsCtx was just called")
+	}
+	m.record("GetAccruedInterestsCtx", []any{ctx, figi, from, to})
+	return m.GetAccruedInterestsCtxFunc(ctx, figi, from, to)
+}
+
+func (m *InstrumentsServiceMock) GetFuturesMargin(figi string) (*investgo.GetFuturesMarginResponse, error) {
+	if m.GetFuturesMarginFunc == nil {
+		panic("InstrumentsServiceMock.GetFuturesMarginFunc: method is nil but GetFuturesMargin was just called")
+	}
+	m.record("GetFuturesMargin", []any{figi})
+	return m.GetFuturesMarginFunc(figi)
+}
+
+func (m *InstrumentsServiceMock) GetFuturesMarginCtx(ctx context.Context, figi string) (*investgo.GetFuturesMarginResponse, error) {
+	if m.GetFuturesMarginCtxFunc == nil {
+		panic("InstrumentsServiceMock.GetFuturesMarginCtxFunc: method is nil but GetFuturesMarginCtx was just called")
+	}
+	m.record("GetFuturesMarginCtx", []any{ctx, figi})
+	return m.GetFuturesMarginCtxFunc(ctx, figi)
+}
+
+func (m *InstrumentsServiceMock) GetDividents(figi string, from time.Time, to time.Time) (*investgo.GetDividendsResponse, error) {
+	if m.GetDividentsFunc == nil {
+		panic("InstrumentsServiceMock.GetDividentsFunc: method is nil but GetDividents was just called")
+	}
+	m.record("GetDividents", []any{figi, from, to})
+	return m.GetDividentsFunc(figi, from, to)
+}
+
+func (m *InstrumentsServiceMock) GetDividentsCtx(ctx context.Context, figi string, from time.Time, to time.Time) (*investgo.GetDividendsResponse, error) {
+	if m.GetDividentsCtxFunc == nil {
+		panic("InstrumentsServiceMock.GetDividentsCtxFunc: method is nil but GetDividentsCtx was just called")
+	}
+	m.record("GetDividentsCtx", []any{ctx, figi, from, to})
+	return m.GetDividentsCtxFunc(ctx, figi, from, to)
+}
+
+func (m *InstrumentsServiceMock) GetAssetBy(id string) (*investgo.AssetResponse, error) {
+	if m.GetAssetByFunc == nil {
+		panic("InstrumentsServiceMock.GetAssetByFunc: method is nil but GetAssetBy was just called")
+	}
+	m.record("GetAssetBy", []any{id})
+	return m.GetAssetByFunc(id)
+}
+
+func (m *InstrumentsServiceMock) GetAssetByCtx(ctx context.Context, id string) (*investgo.AssetResponse, error) {
+	if m.GetAssetByCtxFunc == nil {
+		panic("InstrumentsServiceMock.GetAssetByCtxFunc: method is nil but GetAssetByCtx was just called")
+	}
+	m.record("GetAssetByCtx", []any{ctx, id})
+	return m.GetAssetByCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) GetAssets() (*investgo.AssetsResponse, error) {
+	if m.GetAssetsFunc == nil {
+		panic("InstrumentsServiceMock.GetAssetsFunc: method is nil but GetAssets was just called")
+	}
+	m.record("GetAssets", []any{})
+	return m.GetAssetsFunc()
+}
+
+func (m *InstrumentsServiceMock) GetAssetsCtx(ctx context.Context) (*investgo.AssetsResponse, error) {
+	if m.GetAssetsCtxFunc == nil {
+		panic("InstrumentsServiceMock.GetAssetsCtxFunc: method is nil but GetAssetsCtx was just called")
+	}
+	m.record("GetAssetsCtx", []any{ctx})
+	return m.GetAssetsCtxFunc(ctx)
+}
+
+func (m *InstrumentsServiceMock) GetFavorites() (*investgo.GetFavoritesResponse, error) {
+	if m.GetFavoritesFunc == nil {
+		panic("InstrumentsServiceMock.GetFavoritesFunc: method is nil but GetFavorites was just called")
+	}
+	m.record("GetFavorites", []any{})
+	return m.GetFavoritesFunc()
+}
+
+func (m *InstrumentsServiceMock) GetFavoritesCtx(ctx context.Context) (*investgo.GetFavoritesResponse, error) {
+	if m.GetFavoritesCtxFunc == nil {
+		panic("InstrumentsServiceMock.GetFavoritesCtxFunc: method is nil but GetFavoritesCtx was just called")
+	}
+	m.record("GetFavoritesCtx", []any{ctx})
+	return m.GetFavoritesCtxFunc(ctx)
+}
+
+func (m *InstrumentsServiceMock) EditFavorites(instruments []string, actionType pb.EditFavoritesActionType) (*investgo.EditFavoritesResponse, error) {
+	if m.EditFavoritesFunc == nil {
+		panic("InstrumentsServiceMock.EditFavoritesFunc: method is nil but EditFavorites was just called")
+	}
+	m.record("EditFavorites", []any{instruments, actionType})
+	return m.EditFavoritesFunc(instruments, actionType)
+}
+
+func (m *InstrumentsServiceMock) EditFavoritesCtx(ctx context.Context, instruments []string, actionType pb.EditFavoritesActionType) (*investgo.EditFavoritesResponse, error) {
+	if m.EditFavoritesCtxFunc == nil {
+		panic("InstrumentsServiceMock.EditFavoritesCtxFunc: method is nil but EditFavoritesCtx was just called")
+	}
+	m.record("EditFavoritesCtx", []any{ctx, instruments, actionType})
+	return m.EditFavoritesCtxFunc(ctx, instruments, actionType)
+}
+
+func (m *InstrumentsServiceMock) GetCountries() (*investgo.GetCountriesResponse, error) {
+	if m.GetCountriesFunc == nil {
+		panic("InstrumentsServiceMock.GetCountriesFunc: method is nil but GetCountries was just called")
+	}
+	m.record("GetCountries", []any{})
+	return m.GetCountriesFunc()
+}
+
+func (m *InstrumentsServiceMock) GetCountriesCtx(ctx context.Context) (*investgo.GetCountriesResponse, error) {
+	if m.GetCountriesCtxFunc == nil {
+		panic("InstrumentsServiceMock.GetCountriesCtxFunc: method is nil but GetCountriesCtx was just called")
+	}
+	m.record("GetCountriesCtx", []any{ctx})
+	return m.GetCountriesCtxFunc(ctx)
+}
+
+func (m *InstrumentsServiceMock) GetBrands() (*investgo.GetBrandsResponse, error) {
+	if m.GetBrandsFunc == nil {
+		panic("InstrumentsServiceMock.GetBrandsFunc: method is nil but GetBrands was just called")
+	}
+	m.record("GetBrands", []any{})
+	return m.GetBrandsFunc()
+}
+
+func (m *InstrumentsServiceMock) GetBrandsCtx(ctx context.Context) (*investgo.GetBrandsResponse, error) {
+	if m.GetBrandsCtxFunc == nil {
+		panic("InstrumentsServiceMock.GetBrandsCtxFunc: method is nil but GetBrandsCtx was just called")
+	}
+	m.record("GetBrandsCtx", []any{ctx})
+	return m.GetBrandsCtxFunc(ctx)
+}
+
+func (m *InstrumentsServiceMock) GetBrandBy(id string) (*investgo.Brand, error) {
+	if m.GetBrandByFunc == nil {
+		panic("InstrumentsServiceMock.GetBrandByFunc: method is nil but GetBrandBy was just called")
+	}
+	m.record("GetBrandBy", []any{id})
+	return m.GetBrandByFunc(id)
+}
+
+func (m *InstrumentsServiceMock) GetBrandByCtx(ctx context.Context, id string) (*investgo.Brand, error) {
+	if m.GetBrandByCtxFunc == nil {
+		panic("InstrumentsServiceMock.GetBrandByCtxFunc: method is nil but GetBrandByCtx was just called")
+	}
+	m.record("GetBrandByCtx", []any{ctx, id})
+	return m.GetBrandByCtxFunc(ctx, id)
+}
+
+func (m *InstrumentsServiceMock) FindInstrument(query string) (*investgo.FindInstrumentResponse, error) {
+	if m.FindInstrumentFunc == nil {
+		panic("InstrumentsServiceMock.FindInstrumentFunc: method is nil but FindInstrument was just called")
+	}
+	m.record("FindInstrument", []any{query})
+	return m.FindInstrumentFunc(query)
+}
+
+func (m *InstrumentsServiceMock) FindInstrumentCtx(ctx context.Context, query string) (*investgo.FindInstrumentResponse, error) {
+	if m.FindInstrumentCtxFunc == nil {
+		panic("InstrumentsServiceMock.FindInstrumentCtxFunc: method is nil but FindInstrumentCtx was just called")
+	}
+	m.record("FindInstrumentCtx", []any{ctx, query})
+	return m.FindInstrumentCtxFunc(ctx, query)
+}
+
+func (m *InstrumentsServiceMock) record(method string, args []any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls - все вызовы методов мока в порядке вызова
+func (m *InstrumentsServiceMock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo - вызовы метода method в порядке вызова
+func (m *InstrumentsServiceMock) CallsTo(method string) []Call {
+	return filterCalls(m.Calls(), method)
+}
+
+// MarketDataServiceMock - мок investgo.MarketDataService, методы вызывают соответствующие поля <Method>Func
+type MarketDataServiceMock struct {
+	GetCandlesFunc               func(instrumentId string, interval pb.CandleInterval, from time.Time, to time.Time) (*investgo.GetCandlesResponse, error)
+	GetCandlesCtxFunc            func(ctx context.Context, instrumentId string, interval pb.CandleInterval, from time.Time, to time.Time) (*investgo.GetCandlesResponse, error)
+	GetLastPricesFunc            func(instrumentIds []string) (*investgo.GetLastPricesResponse, error)
+	GetLastPricesCtxFunc         func(ctx context.Context, instrumentIds []string) (*investgo.GetLastPricesResponse, error)
+	GetOrderBookFunc             func(instrumentId string, depth int32) (*investgo.GetOrderBookResponse, error)
+	GetOrderBookCtxFunc          func(ctx context.Context, instrumentId string, depth int32) (*investgo.GetOrderBookResponse, error)
+	GetTradingStatusFunc         func(instrumentId string) (*investgo.GetTradingStatusResponse, error)
+	GetTradingStatusCtxFunc      func(ctx context.Context, instrumentId string) (*investgo.GetTradingStatusResponse, error)
+	GetTradingStatusesFunc       func(instrumentIds []string) (*investgo.GetTradingStatusesResponse, error)
+	GetTradingStatusesCtxFunc    func(ctx context.Context, instrumentIds []string) (*investgo.GetTradingStatusesResponse, error)
+	GetLastTradesFunc            func(instrumentId string, from time.Time, to time.Time) (*investgo.GetLastTradesResponse, error)
+	GetLastTradesCtxFunc         func(ctx context.Context, instrumentId string, from time.Time, to time.Time) (*investgo.GetLastTradesResponse, error)
+	GetClosePricesFunc           func(instrumentIds []string) (*investgo.GetClosePricesResponse, error)
+	GetClosePricesCtxFunc        func(ctx context.Context, instrumentIds []string) (*investgo.GetClosePricesResponse, error)
+	GetHistoricCandlesFunc       func(req *investgo.GetHistoricCandlesRequest) ([]*pb.HistoricCandle, error)
+	GetHistoricCandlesCtxFunc    func(ctx context.Context, req *investgo.GetHistoricCandlesRequest) ([]*pb.HistoricCandle, error)
+	GetAllHistoricCandlesFunc    func(req *investgo.GetHistoricCandlesRequest) ([]*pb.HistoricCandle, error)
+	GetAllHistoricCandlesCtxFunc func(ctx context.Context, req *investgo.GetHistoricCandlesRequest) ([]*pb.HistoricCandle, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ investgo.MarketDataService = (*MarketDataServiceMock)(nil)
+
+func (m *MarketDataServiceMock) GetCandles(instrumentId string, interval pb.CandleInterval, from time.Time, to time.Time) (*investgo.GetCandlesResponse, error) {
+	if m.GetCandlesFunc == nil {
+		panic("MarketDataServiceMock.GetCandlesFunc: method is nil but GetCandles was just called")
+	}
+	m.record("GetCandles", []any{instrumentId, interval, from, to})
+	return m.GetCandlesFunc(instrumentId, interval, from, to)
+}
+
+func (m *MarketDataServiceMock) GetCandlesCtx(ctx context.Context, instrumentId string, interval pb.CandleInterval, from time.Time, to time.Time) (*investgo.GetCandlesResponse, error) {
+	if m.GetCandlesCtxFunc == nil {
+		panic("MarketDataServiceMock.GetCandlesCtxFunc: method is nil but GetCandlesCtx was just called")
+	}
+	m.record("GetCandlesCtx", []any{ctx, instrumentId, interval, from, to})
+	return m.GetCandlesCtxFunc(ctx, instrumentId, interval, from, to)
+}
+
+func (m *MarketDataServiceMock) GetLastPrices(instrumentIds []string) (*investgo.GetLastPricesResponse, error) {
+	if m.GetLastPricesFunc == nil {
+		panic("MarketDataServiceMock.GetLastPricesFunc: method is nil but GetLastPrices was just called")
+	}
+	m.record("GetLastPrices", []any{instrumentIds})
+	return m.GetLastPricesFunc(instrumentIds)
+}
+
+func (m *MarketDataServiceMock) GetLastPricesCtx(ctx context.Context, instrumentIds []string) (*investgo.GetLastPricesResponse, error) {
+	if m.GetLastPricesCtxFunc == nil {
+		panic("MarketDataServiceMock.GetLastPricesCtxFunc: method is nil but GetLastPricesCtx was just called")
+	}
+	m.record("GetLastPricesCtx", []any{ctx, instrumentIds})
+	return m.GetLastPricesCtxFunc(ctx, instrumentIds)
+}
+
+func (m *MarketDataServiceMock) GetOrderBook(instrumentId string, depth int32) (*investgo.GetOrderBookResponse, error) {
+	if m.GetOrderBookFunc == nil {
+		panic("MarketDataServiceMock.GetOrderBookFunc: method is nil but GetOrderBook was just called")
+	}
+	m.record("GetOrderBook", []any{instrumentId, depth})
+	return m.GetOrderBookFunc(instrumentId, depth)
+}
+
+func (m *MarketDataServiceMock) GetOrderBookCtx(ctx context.Context, instrumentId string, depth int32) (*investgo.GetOrderBookResponse, error) {
+	if m.GetOrderBookCtxFunc == nil {
+		panic("MarketDataServiceMock.GetOrderBookCtxFunc: method is nil but GetOrderBookCtx was just called")
+	}
+	m.record("GetOrderBookCtx", []any{ctx, instrumentId, depth})
+	return m.GetOrderBookCtxFunc(ctx, instrumentId, depth)
+}
+
+func (m *MarketDataServiceMock) GetTradingStatus(instrumentId string) (*investgo.GetTradingStatusResponse, error) {
+	if m.GetTradingStatusFunc == nil {
+		panic("MarketDataServiceMock.GetTradingStatusFunc: method is nil but GetTradingStatus was just called")
+	}
+	m.record("GetTradingStatus", []any{instrumentId})
+	return m.GetTradingStatusFunc(instrumentId)
+}
+
+func (m *MarketDataServiceMock) GetTradingStatusCtx(ctx context.Context, instrumentId string) (*investgo.GetTradingStatusResponse, error) {
+	if m.GetTradingStatusCtxFunc == nil {
+		panic("MarketDataServiceMock.GetTradingStatusCtxFunc: method is nil but GetTradingStatusCtx was just called")
+	}
+	m.record("GetTradingStatusCtx", []any{ctx, instrumentId})
+	return m.GetTradingStatusCtxFunc(ctx, instrumentId)
+}
+
+func (m *MarketDataServiceMock) GetTradingStatuses(instrumentIds []string) (*investgo.GetTradingStatusesResponse, error) {
+	if m.GetTradingStatusesFunc == nil {
+		panic("MarketDataServiceMock.GetTradingStatusesFunc: method is nil but GetTradingStatuses was just called")
+	}
+	m.record("GetTradingStatuses", []any{instrumentIds})
+	return m.GetTradingStatusesFunc(instrumentIds)
+}
+
+func (m *MarketDataServiceMock) GetTradingStatusesCtx(ctx context.Context, instrumentIds []string) (*investgo.GetTradingStatusesResponse, error) {
+	if m.GetTradingStatusesCtxFunc == nil {
+		panic("MarketDataServiceMock.GetTradingStatusesCtxFunc: method is nil but GetTradingStatusesCtx was just called")
+	}
+	m.record("GetTradingStatusesCtx", []any{ctx, instrumentIds})
+	return m.GetTradingStatusesCtxFunc(ctx, instrumentIds)
+}
+
+func (m *MarketDataServiceMock) GetLastTrades(instrumentId string, from time.Time, to time.Time) (*investgo.GetLastTradesResponse, error) {
+	if m.GetLastTradesFunc == nil {
+		panic("MarketDataServiceMock.GetLastTradesFunc: method is nil but GetLastTrades was just called")
+	}
+	m.record("GetLastTrades", []any{instrumentId, from, to})
+	return m.GetLastTradesFunc(instrumentId, from, to)
+}
+
+func (m *MarketDataServiceMock) GetLastTradesCtx(ctx context.Context, instrumentId string, from time.Time, to time.Time) (*investgo.GetLastTradesResponse, error) {
+	if m.GetLastTradesCtxFunc == nil {
+		panic("MarketDataServiceMock.GetLastTradesCtxFunc: method is nil but GetLastTradesCtx was just called")
+	}
+	m.record("GetLastTradesCtx", []any{ctx, instrumentId, from, to})
+	return m.GetLastTradesCtxFunc(ctx, instrumentId, from, to)
+}
+
+func (m *MarketDataServiceMock) GetClosePrices(instrumentIds []string) (*investgo.GetClosePricesResponse, error) {
+	if m.GetClosePricesFunc == nil {
+		panic("MarketDataServiceMock.GetClosePricesFunc: method is nil but GetClosePrices was just called")
+	}
+	m.record("GetClosePrices", []any{instrumentIds})
+	return m.GetClosePricesFunc(instrumentIds)
+}
+
+func (m *MarketDataServiceMock) GetClosePricesCtx(ctx context.Context, instrumentIds []string) (*investgo.GetClosePricesResponse, error) {
+	if m.GetClosePricesCtxFunc == nil {
+		panic("MarketDataServiceMock.GetClosePricesCtxFunc: method is nil but GetClosePricesCtx was just called")
+	}
+	m.record("GetClosePricesCtx", []any{ctx, instrumentIds})
+	return m.GetClosePricesCtxFunc(ctx, instrumentIds)
+}
+
+func (m *MarketDataServiceMock) GetHistoricCandles(req *investgo.GetHistoricCandlesRequest) ([]*pb.HistoricCandle, error) {
+	if m.GetHistoricCandlesFunc == nil {
+		panic("MarketDataServiceMock.GetHistoricCandlesFunc: method is nil but GetHistoricCandles was just called")
+	}
+	m.record("GetHistoricCandles", []any{req})
+	return m.GetHistoricCandlesFunc(req)
+}
+
+func (m *MarketDataServiceMock) GetHistoricCandlesCtx(ctx context.Context, req *investgo.GetHistoricCandlesRequest) ([]*pb.HistoricCandle, error) {
+	if m.GetHistoricCandlesCtxFunc == nil {
+		panic("MarketDataServiceMock.GetHistoricCandlesCtxFunc: method is nil but GetHistoricCandlesCtx was just called")
+	}
+	m.record("GetHistoricCandlesCtx", []any{ctx, req})
+	return m.GetHistoricCandlesCtxFunc(ctx, req)
+}
+
+func (m *MarketDataServiceMock) GetAllHistoricCandles(req *investgo.GetHistoricCandlesRequest) ([]*pb.HistoricCandle, error) {
+	if m.GetAllHistoricCandlesFunc == nil {
+		panic("MarketDataServiceMock.GetAllHistoricCandlesFunc: method is nil but GetAllHistoricCandles was just called")
+	}
+	m.record("GetAllHistoricCandles", []any{req})
+	return m.GetAllHistoricCandlesFunc(req)
+}
+
+func (m *MarketDataServiceMock) GetAllHistoricCandlesCtx(ctx context.Context, req *investgo.GetHistoricCandlesRequest) ([]*pb.HistoricCandle, error) {
+	if m.GetAllHistoricCandlesCtxFunc == nil {
+		panic("MarketDataServiceMock.GetAllHistoricCandlesCtxFunc: method is nil but GetAllHistoricCandlesCtx was just called")
+	}
+	m.record("GetAllHistoricCandlesCtx", []any{ctx, req})
+	return m.GetAllHistoricCandlesCtxFunc(ctx, req)
+}
+
+func (m *MarketDataServiceMock) record(method string, args []any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls - все вызовы методов мока в порядке вызова
+func (m *MarketDataServiceMock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo - вызовы метода method в порядке вызова
+func (m *MarketDataServiceMock) CallsTo(method string) []Call {
+	return filterCalls(m.Calls(), method)
+}
+
+// OperationsServiceMock - мок investgo.OperationsService, методы вызывают соответствующие поля <Method>Func
+type OperationsServiceMock struct {
+	GetOperationsFunc                     func(req *investgo.GetOperationsRequest) (*investgo.OperationsResponse, error)
+	GetOperationsCtxFunc                  func(ctx context.Context, req *investgo.GetOperationsRequest) (*investgo.OperationsResponse, error)
+	GetPortfolioFunc                      func(accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*investgo.PortfolioResponse, error)
+	GetPortfolioCtxFunc                   func(ctx context.Context, accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*investgo.PortfolioResponse, error)
+	GetPositionsFunc                      func(accountId string) (*investgo.PositionsResponse, error)
+	GetPositionsCtxFunc                   func(ctx context.Context, accountId string) (*investgo.PositionsResponse, error)
+	GetWithdrawLimitsFunc                 func(accountId string) (*investgo.WithdrawLimitsResponse, error)
+	GetWithdrawLimitsCtxFunc              func(ctx context.Context, accountId string) (*investgo.WithdrawLimitsResponse, error)
+	GetBrokerReportFunc                   func(taskId string, page int32) (*investgo.GetBrokerReportResponse, error)
+	GetBrokerReportCtxFunc                func(ctx context.Context, taskId string, page int32) (*investgo.GetBrokerReportResponse, error)
+	GenerateBrokerReportFunc              func(accountId string, from time.Time, to time.Time) (*investgo.GenerateBrokerReportResponse, error)
+	GenerateBrokerReportCtxFunc           func(ctx context.Context, accountId string, from time.Time, to time.Time) (*investgo.GenerateBrokerReportResponse, error)
+	GetDividentsForeignIssuerFunc         func(taskId string, page int32) (*investgo.GetDividendsForeignIssuerResponse, error)
+	GetDividentsForeignIssuerCtxFunc      func(ctx context.Context, taskId string, page int32) (*investgo.GetDividendsForeignIssuerResponse, error)
+	GenerateDividentsForeignIssuerFunc    func(accountId string, from time.Time, to time.Time) (*investgo.GetDividendsForeignIssuerResponse, error)
+	GenerateDividentsForeignIssuerCtxFunc func(ctx context.Context, accountId string, from time.Time, to time.Time) (*investgo.GetDividendsForeignIssuerResponse, error)
+	GetOperationsByCursorShortFunc        func(accountId string) (*investgo.GetOperationsByCursorResponse, error)
+	GetOperationsByCursorShortCtxFunc     func(ctx context.Context, accountId string) (*investgo.GetOperationsByCursorResponse, error)
+	GetOperationsByCursorFunc             func(req *investgo.GetOperationsByCursorRequest) (*investgo.GetOperationsByCursorResponse, error)
+	GetOperationsByCursorCtxFunc          func(ctx context.Context, req *investgo.GetOperationsByCursorRequest) (*investgo.GetOperationsByCursorResponse, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ investgo.OperationsService = (*OperationsServiceMock)(nil)
+
+func (m *OperationsServiceMock) GetOperations(req *investgo.GetOperationsRequest) (*investgo.OperationsResponse, error) {
+	if m.GetOperationsFunc == nil {
+		panic("OperationsServiceMock.GetOperationsFunc: method is nil but GetOperations was just called")
+	}
+	m.record("GetOperations", []any{req})
+	return m.GetOperationsFunc(req)
+}
+
+func (m *OperationsServiceMock) GetOperationsCtx(ctx context.Context, req *investgo.GetOperationsRequest) (*investgo.OperationsResponse, error) {
+	if m.GetOperationsCtxFunc == nil {
+		panic("OperationsServiceMock.GetOperationsCtxFunc: method is nil but GetOperationsCtx was just called")
+	}
+	m.record("GetOperationsCtx", []any{ctx, req})
+	return m.GetOperationsCtxFunc(ctx, req)
+}
+
+func (m *OperationsServiceMock) GetPortfolio(accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*investgo.PortfolioResponse, error) {
+	if m.GetPortfolioFunc == nil {
+		panic("OperationsServiceMock.GetPortfolioFunc: method is nil but GetPortfolio was just called")
+	}
+	m.record("GetPortfolio", []any{accountId, currency})
+	return m.GetPortfolioFunc(accountId, currency)
+}
+
+func (m *OperationsServiceMock) GetPortfolioCtx(ctx context.Context, accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*investgo.PortfolioResponse, error) {
+	if m.GetPortfolioCtxFunc == nil {
+		panic("OperationsServiceMock.GetPortfolioCtxFunc: method is nil but GetPortfolioCtx was just called")
+	}
+	m.record("GetPortfolioCtx", []any{ctx, accountId, currency})
+	return m.GetPortfolioCtxFunc(ctx, accountId, currency)
+}
+
+func (m *OperationsServiceMock) GetPositions(accountId string) (*investgo.PositionsResponse, error) {
+	if m.GetPositionsFunc == nil {
+		panic("OperationsServiceMock.GetPositionsFunc: method is nil but GetPositions was just called")
+	}
+	m.record("GetPositions", []any{accountId})
+	return m.GetPositionsFunc(accountId)
+}
+
+func (m *OperationsServiceMock) GetPositionsCtx(ctx context.Context, accountId string) (*investgo.PositionsResponse, error) {
+	if m.GetPositionsCtxFunc == nil {
+		panic("OperationsServiceMock.GetPositionsCtxFunc: method is nil but GetPositionsCtx was just called")
+	}
+	m.record("GetPositionsCtx", []any{ctx, accountId})
+	return m.GetPositionsCtxFunc(ctx, accountId)
+}
+
+func (m *OperationsServiceMock) GetWithdrawLimits(accountId string) (*investgo.WithdrawLimitsResponse, error) {
+	if m.GetWithdrawLimitsFunc == nil {
+		panic("OperationsServiceMock.GetWithdrawLimitsFunc: method is nil but GetWithdrawLimits was just called")
+	}
+	m.record("GetWithdrawLimits", []any{accountId})
+	return m.GetWithdrawLimitsFunc(accountId)
+}
+
+func (m *OperationsServiceMock) GetWithdrawLimitsCtx(ctx context.Context, accountId string) (*investgo.WithdrawLimitsResponse, error) {
+	if m.GetWithdrawLimitsCtxFunc == nil {
+		panic("OperationsServiceMock.GetWithdrawLimitsCtxFunc: method is nil but GetWithdrawLimitsCtx was just called")
+	}
+	m.record("GetWithdrawLimitsCtx", []any{ctx, accountId})
+	return m.GetWithdrawLimitsCtxFunc(ctx, accountId)
+}
+
+func (m *OperationsServiceMock) GetBrokerReport(taskId string, page int32) (*investgo.GetBrokerReportResponse, error) {
+	if m.GetBrokerReportFunc == nil {
+		panic("OperationsServiceMock.GetBrokerReportFunc: method is nil but GetBrokerReport was just called")
+	}
+	m.record("GetBrokerReport", []any{taskId, page})
+	return m.GetBrokerReportFunc(taskId, page)
+}
+
+func (m *OperationsServiceMock) GetBrokerReportCtx(ctx context.Context, taskId string, page int32) (*investgo.GetBrokerReportResponse, error) {
+	if m.GetBrokerReportCtxFunc == nil {
+		panic("OperationsServiceMock.GetBrokerReportCtxFunc: method is nil but GetBrokerReportCtx was just called")
+	}
+	m.record("GetBrokerReportCtx", []any{ctx, taskId, page})
+	return m.GetBrokerReportCtxFunc(ctx, taskId, page)
+}
+
+func (m *OperationsServiceMock) GenerateBrokerReport(accountId string, from time.Time, to time.Time) (*investgo.GenerateBrokerReportResponse, error) {
+	if m.GenerateBrokerReportFunc == nil {
+		panic("OperationsServiceMock.GenerateBrokerReportFunc: method is nil but GenerateBrokerReport was just called")
+	}
+	m.record("GenerateBrokerReport", []any{accountId, from, to})
+	return m.GenerateBrokerReportFunc(accountId, from, to)
+}
+
+func (m *OperationsServiceMock) GenerateBrokerReportCtx(ctx context.Context, accountId string, from time.Time, to time.Time) (*investgo.GenerateBrokerReportResponse, error) {
+	if m.GenerateBrokerReportCtxFunc == nil {
+		panic("OperationsServiceMock.GenerateBrokerReportCtxFunc: method is nil but GenerateBrokerReportCtx was just called")
+	}
+	m.record("GenerateBrokerReportCtx", []any{ctx, accountId, from, to})
+	return m.GenerateBrokerReportCtxFunc(ctx, accountId, from, to)
+}
+
+func (m *OperationsServiceMock) GetDividentsForeignIssuer(taskId string, page int32) (*investgo.GetDividendsForeignIssuerResponse, error) {
+	if m.GetDividentsForeignIssuerFunc == nil {
+		panic("OperationsServiceMock.GetDividentsForeignIssuerFunc: method is nil but GetDividentsForeignIssuer was just called")
+	}
+	m.record("GetDividentsForeignIssuer", []any{taskId, page})
+	return m.GetDividentsForeignIssuerFunc(taskId, page)
+}
+
+func (m *OperationsServiceMock) GetDividentsForeignIssuerCtx(ctx context.Context, taskId string, page int32) (*investgo.GetDividendsForeignIssuerResponse, error) {
+	if m.GetDividentsForeignIssuerCtxFunc == nil {
+		panic("OperationsServiceMock.GetDividentsForeignIssuerCtxFunc: method is nil but GetDividentsForeignIssuerCtx was just called")
+	}
+	m.record("GetDividentsForeignIssuerCtx", []any{ctx, taskId, page})
+	return m.GetDividentsForeignIssuerCtxFunc(ctx, taskId, page)
+}
+
+func (m *OperationsServiceMock) GenerateDividentsForeignIssuer(accountId string, from time.Time, to time.Time) (*investgo.GetDividendsForeignIssuerResponse, error) {
+	if m.GenerateDividentsForeignIssuerFunc == nil {
+		panic("OperationsServiceMock.GenerateDividentsForeignIssuerFunc: method is nil but GenerateDividentsForeignIssuer was just called")
+	}
+	m.record("GenerateDividentsForeignIssuer", []any{accountId, from, to})
+	return m.GenerateDividentsForeignIssuerFunc(accountId, from, to)
+}
+
+func (m *OperationsServiceMock) GenerateDividentsForeignIssuerCtx(ctx context.Context, accountId string, from time.Time, to time.Time) (*investgo.GetDividendsForeignIssuerResponse, error) {
+	if m.GenerateDividentsForeignIssuerCtxFunc == nil {
+		panic("OperationsServiceMock.GenerateDividentsForeignIssuerCtxFunc: method is nil but GenerateDividentsForeignIssuerCtx was just called")
+	}
+	m.record("GenerateDividentsForeignIssuerCtx", []any{ctx, accountId, from, to})
+	return m.GenerateDividentsForeignIssuerCtxFunc(ctx, accountId, from, to)
+}
+
+func (m *OperationsServiceMock) GetOperationsByCursorShort(accountId string) (*investgo.GetOperationsByCursorResponse, error) {
+	if m.GetOperationsByCursorShortFunc == nil {
+		panic("OperationsServiceMock.GetOperationsByCursorShortFunc: method is nil but GetOperationsByCursorShort was just called")
+	}
+	m.record("GetOperationsByCursorShort", []any{accountId})
+	return m.GetOperationsByCursorShortFunc(accountId)
+}
+
+func (m *OperationsServiceMock) GetOperationsByCursorShortCtx(ctx context.Context, accountId string) (*investgo.GetOperationsByCursorResponse, error) {
+	if m.GetOperationsByCursorShortCtxFunc == nil {
+		panic("OperationsServiceMock.GetOperationsByCursorShortCtxFunc: method is nil but GetOperationsByCursorShortCtx was just called")
+	}
+	m.record("GetOperationsByCursorShortCtx", []any{ctx, accountId})
+	return m.GetOperationsByCursorShortCtxFunc(ctx, accountId)
+}
+
+func (m *OperationsServiceMock) GetOperationsByCursor(req *investgo.GetOperationsByCursorRequest) (*investgo.GetOperationsByCursorResponse, error) {
+	if m.GetOperationsByCursorFunc == nil {
+		panic("OperationsServiceMock.GetOperationsByCursorFunc: method is nil but GetOperationsByCursor was just called")
+	}
+	m.record("GetOperationsByCursor", []any{req})
+	return m.GetOperationsByCursorFunc(req)
+}
+
+func (m *OperationsServiceMock) GetOperationsByCursorCtx(ctx context.Context, req *investgo.GetOperationsByCursorRequest) (*investgo.GetOperationsByCursorResponse, error) {
+	if m.GetOperationsByCursorCtxFunc == nil {
+		panic("OperationsServiceMock.GetOperationsByCursorCtxFunc: method is nil but GetOperationsByCursorCtx was just called")
+	}
+	m.record("GetOperationsByCursorCtx", []any{ctx, req})
+	return m.GetOperationsByCursorCtxFunc(ctx, req)
+}
+
+func (m *OperationsServiceMock) record(method string, args []any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls - все вызовы методов мока в порядке вызова
+func (m *OperationsServiceMock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo - вызовы метода method в порядке вызова
+func (m *OperationsServiceMock) CallsTo(method string) []Call {
+	return filterCalls(m.Calls(), method)
+}
+
+// OrdersServiceMock - мок investgo.OrdersService, методы вызывают соответствующие поля <Method>Func
+type OrdersServiceMock struct {
+	PostOrderFunc        func(req *investgo.PostOrderRequest) (*investgo.PostOrderResponse, error)
+	PostOrderCtxFunc     func(ctx context.Context, req *investgo.PostOrderRequest) (*investgo.PostOrderResponse, error)
+	BuyFunc              func(req *investgo.PostOrderRequestShort) (*investgo.PostOrderResponse, error)
+	BuyCtxFunc           func(ctx context.Context, req *investgo.PostOrderRequestShort) (*investgo.PostOrderResponse, error)
+	SellFunc             func(req *investgo.PostOrderRequestShort) (*investgo.PostOrderResponse, error)
+	SellCtxFunc          func(ctx context.Context, req *investgo.PostOrderRequestShort) (*investgo.PostOrderResponse, error)
+	CancelOrderFunc      func(accountId string, orderId string) (*investgo.CancelOrderResponse, error)
+	CancelOrderCtxFunc   func(ctx context.Context, accountId string, orderId string) (*investgo.CancelOrderResponse, error)
+	GetOrderStateFunc    func(accountId string, orderId string) (*investgo.GetOrderStateResponse, error)
+	GetOrderStateCtxFunc func(ctx context.Context, accountId string, orderId string) (*investgo.GetOrderStateResponse, error)
+	GetOrdersFunc        func(accountId string) (*investgo.GetOrdersResponse, error)
+	GetOrdersCtxFunc     func(ctx context.Context, accountId string) (*investgo.GetOrdersResponse, error)
+	ReplaceOrderFunc     func(req *investgo.ReplaceOrderRequest) (*investgo.PostOrderResponse, error)
+	ReplaceOrderCtxFunc  func(ctx context.Context, req *investgo.ReplaceOrderRequest) (*investgo.PostOrderResponse, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ investgo.OrdersService = (*OrdersServiceMock)(nil)
+
+func (m *OrdersServiceMock) PostOrder(req *investgo.PostOrderRequest) (*investgo.PostOrderResponse, error) {
+	if m.PostOrderFunc == nil {
+		panic("OrdersServiceMock.PostOrderFunc: method is nil but PostOrder was just called")
+	}
+	m.record("PostOrder", []any{req})
+	return m.PostOrderFunc(req)
+}
+
+func (m *OrdersServiceMock) PostOrderCtx(ctx context.Context, req *investgo.PostOrderRequest) (*investgo.PostOrderResponse, error) {
+	if m.PostOrderCtxFunc == nil {
+		panic("OrdersServiceMock.PostOrderCtxFunc: method is nil but PostOrderCtx was just called")
+	}
+	m.record("PostOrderCtx", []any{ctx, req})
+	return m.PostOrderCtxFunc(ctx, req)
+}
+
+func (m *OrdersServiceMock) Buy(req *investgo.PostOrderRequestShort) (*investgo.PostOrderResponse, error) {
+	if m.BuyFunc == nil {
+		panic("OrdersServiceMock.BuyFunc: method is nil but Buy was just called")
+	}
+	m.record("Buy", []any{req})
+	return m.BuyFunc(req)
+}
+
+func (m *OrdersServiceMock) BuyCtx(ctx context.Context, req *investgo.PostOrderRequestShort) (*investgo.PostOrderResponse, error) {
+	if m.BuyCtxFunc == nil {
+		panic("OrdersServiceMock.BuyCtxFunc: method is nil but BuyCtx was just called")
+	}
+	m.record("BuyCtx", []any{ctx, req})
+	return m.BuyCtxFunc(ctx, req)
+}
+
+func (m *OrdersServiceMock) Sell(req *investgo.PostOrderRequestShort) (*investgo.PostOrderResponse, error) {
+	if m.SellFunc == nil {
+		panic("OrdersServiceMock.SellFunc: method is nil but Sell was just called")
+	}
+	m.record("Sell", []any{req})
+	return m.SellFunc(req)
+}
+
+func (m *OrdersServiceMock) SellCtx(ctx context.Context, req *investgo.PostOrderRequestShort) (*investgo.PostOrderResponse, error) {
+	if m.SellCtxFunc == nil {
+		panic("OrdersServiceMock.SellCtxFunc: method is nil but SellCtx was just called")
+	}
+	m.record("SellCtx", []any{ctx, req})
+	return m.SellCtxFunc(ctx, req)
+}
+
+func (m *OrdersServiceMock) CancelOrder(accountId string, orderId string) (*investgo.CancelOrderResponse, error) {
+	if m.CancelOrderFunc == nil {
+		panic("OrdersServiceMock.CancelOrderFunc: method is nil but CancelOrder was just called")
+	}
+	m.record("CancelOrder", []any{accountId, orderId})
+	return m.CancelOrderFunc(accountId, orderId)
+}
+
+func (m *OrdersServiceMock) CancelOrderCtx(ctx context.Context, accountId string, orderId string) (*investgo.CancelOrderResponse, error) {
+	if m.CancelOrderCtxFunc == nil {
+		panic("OrdersServiceMock.CancelOrderCtxFunc: method is nil but CancelOrderCtx was just called")
+	}
+	m.record("CancelOrderCtx", []any{ctx, accountId, orderId})
+	return m.CancelOrderCtxFunc(ctx, accountId, orderId)
+}
+
+func (m *OrdersServiceMock) GetOrderState(accountId string, orderId string) (*investgo.GetOrderStateResponse, error) {
+	if m.GetOrderStateFunc == nil {
+		panic("OrdersServiceMock.GetOrderStateFunc: method is nil but GetOrderState was just called")
+	}
+	m.record("GetOrderState", []any{accountId, orderId})
+	return m.GetOrderStateFunc(accountId, orderId)
+}
+
+func (m *OrdersServiceMock) GetOrderStateCtx(ctx context.Context, accountId string, orderId string) (*investgo.GetOrderStateResponse, error) {
+	if m.GetOrderStateCtxFunc == nil {
+		panic("OrdersServiceMock.GetOrderStateCtxFunc: method is nil but GetOrderStateCtx was just called")
+	}
+	m.record("GetOrderStateCtx", []any{ctx, accountId, orderId})
+	return m.GetOrderStateCtxFunc(ctx, accountId, orderId)
+}
+
+func (m *OrdersServiceMock) GetOrders(accountId string) (*investgo.GetOrdersResponse, error) {
+	if m.GetOrdersFunc == nil {
+		panic("OrdersServiceMock.GetOrdersFunc: method is nil but GetOrders was just called")
+	}
+	m.record("GetOrders", []any{accountId})
+	return m.GetOrdersFunc(accountId)
+}
+
+func (m *OrdersServiceMock) GetOrdersCtx(ctx context.Context, accountId string) (*investgo.GetOrdersResponse, error) {
+	if m.GetOrdersCtxFunc == nil {
+		panic("OrdersServiceMock.GetOrdersCtxFunc: method is nil but GetOrdersCtx was just called")
+	}
+	m.record("GetOrdersCtx", []any{ctx, accountId})
+	return m.GetOrdersCtxFunc(ctx, accountId)
+}
+
+func (m *OrdersServiceMock) ReplaceOrder(req *investgo.ReplaceOrderRequest) (*investgo.PostOrderResponse, error) {
+	if m.ReplaceOrderFunc == nil {
+		panic("OrdersServiceMock.ReplaceOrderFunc: method is nil but ReplaceOrder was just called")
+	}
+	m.record("ReplaceOrder", []any{req})
+	return m.ReplaceOrderFunc(req)
+}
+
+func (m *OrdersServiceMock) ReplaceOrderCtx(ctx context.Context, req *investgo.ReplaceOrderRequest) (*investgo.PostOrderResponse, error) {
+	if m.ReplaceOrderCtxFunc == nil {
+		panic("OrdersServiceMock.ReplaceOrderCtxFunc: method is nil but ReplaceOrderCtx was just called")
+	}
+	m.record("ReplaceOrderCtx", []any{ctx, req})
+	return m.ReplaceOrderCtxFunc(ctx, req)
+}
+
+func (m *OrdersServiceMock) record(method string, args []any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls - все вызовы методов мока в порядке вызова
+func (m *OrdersServiceMock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo - вызовы метода method в порядке вызова
+func (m *OrdersServiceMock) CallsTo(method string) []Call {
+	return filterCalls(m.Calls(), method)
+}
+
+// StopOrdersServiceMock - мок investgo.StopOrdersService, методы вызывают соответствующие поля <Method>Func
+type StopOrdersServiceMock struct {
+	PostStopOrderFunc      func(req *investgo.PostStopOrderRequest) (*investgo.PostStopOrderResponse, error)
+	PostStopOrderCtxFunc   func(ctx context.Context, req *investgo.PostStopOrderRequest) (*investgo.PostStopOrderResponse, error)
+	GetStopOrdersFunc      func(accountId string) (*investgo.GetStopOrdersResponse, error)
+	GetStopOrdersCtxFunc   func(ctx context.Context, accountId string) (*investgo.GetStopOrdersResponse, error)
+	CancelStopOrderFunc    func(accountId string, stopOrderId string) (*investgo.CancelStopOrderResponse, error)
+	CancelStopOrderCtxFunc func(ctx context.Context, accountId string, stopOrderId string) (*investgo.CancelStopOrderResponse, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ investgo.StopOrdersService = (*StopOrdersServiceMock)(nil)
+
+func (m *StopOrdersServiceMock) PostStopOrder(req *investgo.PostStopOrderRequest) (*investgo.PostStopOrderResponse, error) {
+	if m.PostStopOrderFunc == nil {
+		panic("StopOrdersServiceMock.PostStopOrderFunc: method is nil but PostStopOrder was just called")
+	}
+	m.record("PostStopOrder", []any{req})
+	return m.PostStopOrderFunc(req)
+}
+
+func (m *StopOrdersServiceMock) PostStopOrderCtx(ctx context.Context, req *investgo.PostStopOrderRequest) (*investgo.PostStopOrderResponse, error) {
+	if m.PostStopOrderCtxFunc == nil {
+		panic("StopOrdersServiceMock.PostStopOrderCtxFunc: method is nil but PostStopOrderCtx was just called")
+	}
+	m.record("PostStopOrderCtx", []any{ctx, req})
+	return m.PostStopOrderCtxFunc(ctx, req)
+}
+
+func (m *StopOrdersServiceMock) GetStopOrders(accountId string) (*investgo.GetStopOrdersResponse, error) {
+	if m.GetStopOrdersFunc == nil {
+		panic("StopOrdersServiceMock.GetStopOrdersFunc: method is nil but GetStopOrders was just called")
+	}
+	m.record("GetStopOrders", []any{accountId})
+	return m.GetStopOrdersFunc(accountId)
+}
+
+func (m *StopOrdersServiceMock) GetStopOrdersCtx(ctx context.Context, accountId string) (*investgo.GetStopOrdersResponse, error) {
+	if m.GetStopOrdersCtxFunc == nil {
+		panic("StopOrdersServiceMock.GetStopOrdersCtxFunc: method is nil but GetStopOrdersCtx was just called")
+	}
+	m.record("GetStopOrdersCtx", []any{ctx, accountId})
+	return m.GetStopOrdersCtxFunc(ctx, accountId)
+}
+
+func (m *StopOrdersServiceMock) CancelStopOrder(accountId string, stopOrderId string) (*investgo.CancelStopOrderResponse, error) {
+	if m.CancelStopOrderFunc == nil {
+		panic("StopOrdersServiceMock.CancelStopOrderFunc: method is nil but CancelStopOrder was just called")
+	}
+	m.record("CancelStopOrder", []any{accountId, stopOrderId})
+	return m.CancelStopOrderFunc(accountId, stopOrderId)
+}
+
+func (m *StopOrdersServiceMock) CancelStopOrderCtx(ctx context.Context, accountId string, stopOrderId string) (*investgo.CancelStopOrderResponse, error) {
+	if m.CancelStopOrderCtxFunc == nil {
+		panic("StopOrdersServiceMock.CancelStopOrderCtxFunc: method is nil but CancelStopOrderCtx was just called")
+	}
+	m.record("CancelStopOrderCtx", []any{ctx, accountId, stopOrderId})
+	return m.CancelStopOrderCtxFunc(ctx, accountId, stopOrderId)
+}
+
+func (m *StopOrdersServiceMock) record(method string, args []any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls - все вызовы методов мока в порядке вызова
+func (m *StopOrdersServiceMock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo - вызовы метода method в порядке вызова
+func (m *StopOrdersServiceMock) CallsTo(method string) []Call {
+	return filterCalls(m.Calls(), method)
+}
+
+// UsersServiceMock - мок investgo.UsersService, методы вызывают соответствующие поля <Method>Func
+type UsersServiceMock struct {
+	GetAccountsFunc            func() (*investgo.GetAccountsResponse, error)
+	GetAccountsCtxFunc         func(ctx context.Context) (*investgo.GetAccountsResponse, error)
+	GetMarginAttributesFunc    func(accountId string) (*investgo.GetMarginAttributesResponse, error)
+	GetMarginAttributesCtxFunc func(ctx context.Context, accountId string) (*investgo.GetMarginAttributesResponse, error)
+	GetUserTariffFunc          func() (*investgo.GetUserTariffResponse, error)
+	GetUserTariffCtxFunc       func(ctx context.Context) (*investgo.GetUserTariffResponse, error)
+	GetInfoFunc                func() (*investgo.GetInfoResponse, error)
+	GetInfoCtxFunc             func(ctx context.Context) (*investgo.GetInfoResponse, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ investgo.UsersService = (*UsersServiceMock)(nil)
+
+func (m *UsersServiceMock) GetAccounts() (*investgo.GetAccountsResponse, error) {
+	if m.GetAccountsFunc == nil {
+		panic("UsersServiceMock.GetAccountsFunc: method is nil but GetAccounts was just called")
+	}
+	m.record("GetAccounts", []any{})
+	return m.GetAccountsFunc()
+}
+
+func (m *UsersServiceMock) GetAccountsCtx(ctx context.Context) (*investgo.GetAccountsResponse, error) {
+	if m.GetAccountsCtxFunc == nil {
+		panic("UsersServiceMock.GetAccountsCtxFunc: method is nil but GetAccountsCtx was just called")
+	}
+	m.record("GetAccountsCtx", []any{ctx})
+	return m.GetAccountsCtxFunc(ctx)
+}
+
+func (m *UsersServiceMock) GetMarginAttributes(accountId string) (*investgo.GetMarginAttributesResponse, error) {
+	if m.GetMarginAttributesFunc == nil {
+		panic("UsersServiceMock.GetMarginAttributesFunc: method is nil but GetMarginAttributes was just called")
+	}
+	m.record("GetMarginAttributes", []any{accountId})
+	return m.GetMarginAttributesFunc(accountId)
+}
+
+func (m *UsersServiceMock) GetMarginAttributesCtx(ctx context.Context, accountId string) (*investgo.GetMarginAttributesResponse, error) {
+	if m.GetMarginAttributesCtxFunc == nil {
+		panic("UsersServiceMock.GetMarginAttributesCtxFunc: method is nil but GetMarginAttributesCtx was just called")
+	}
+	m.record("GetMarginAttributesCtx", []any{ctx, accountId})
+	return m.GetMarginAttributesCtxFunc(ctx, accountId)
+}
+
+func (m *UsersServiceMock) GetUserTariff() (*investgo.GetUserTariffResponse, error) {
+	if m.GetUserTariffFunc == nil {
+		panic("UsersServiceMock.GetUserTariffFunc: method is nil but GetUserTariff was just called")
+	}
+	m.record("GetUserTariff", []any{})
+	return m.GetUserTariffFunc()
+}
+
+func (m *UsersServiceMock) GetUserTariffCtx(ctx context.Context) (*investgo.GetUserTariffResponse, error) {
+	if m.GetUserTariffCtxFunc == nil {
+		panic("UsersServiceMock.GetUserTariffCtxFunc: method is nil but GetUserTariffCtx was just called")
+	}
+	m.record("GetUserTariffCtx", []any{ctx})
+	return m.GetUserTariffCtxFunc(ctx)
+}
+
+func (m *UsersServiceMock) GetInfo() (*investgo.GetInfoResponse, error) {
+	if m.GetInfoFunc == nil {
+		panic("UsersServiceMock.GetInfoFunc: method is nil but GetInfo was just called")
+	}
+	m.record("GetInfo", []any{})
+	return m.GetInfoFunc()
+}
+
+func (m *UsersServiceMock) GetInfoCtx(ctx context.Context) (*investgo.GetInfoResponse, error) {
+	if m.GetInfoCtxFunc == nil {
+		panic("UsersServiceMock.GetInfoCtxFunc: method is nil but GetInfoCtx was just called")
+	}
+	m.record("GetInfoCtx", []any{ctx})
+	return m.GetInfoCtxFunc(ctx)
+}
+
+func (m *UsersServiceMock) record(method string, args []any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls - все вызовы методов мока в порядке вызова
+func (m *UsersServiceMock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo - вызовы метода method в порядке вызова
+func (m *UsersServiceMock) CallsTo(method string) []Call {
+	return filterCalls(m.Calls(), method)
+}
+
+// SandboxServiceMock - мок investgo.SandboxService, методы вызывают соответствующие поля <Method>Func
+type SandboxServiceMock struct {
+	OpenSandboxAccountFunc              func() (*investgo.OpenSandboxAccountResponse, error)
+	OpenSandboxAccountCtxFunc           func(ctx context.Context) (*investgo.OpenSandboxAccountResponse, error)
+	GetSandboxAccountsFunc              func() (*investgo.GetAccountsResponse, error)
+	GetSandboxAccountsCtxFunc           func(ctx context.Context) (*investgo.GetAccountsResponse, error)
+	CloseSandboxAccountFunc             func(accountId string) (*investgo.CloseSandboxAccountResponse, error)
+	CloseSandboxAccountCtxFunc          func(ctx context.Context, accountId string) (*investgo.CloseSandboxAccountResponse, error)
+	PostSandboxOrderFunc                func(req *investgo.PostOrderRequest) (*investgo.PostOrderResponse, error)
+	PostSandboxOrderCtxFunc             func(ctx context.Context, req *investgo.PostOrderRequest) (*investgo.PostOrderResponse, error)
+	ReplaceSandboxOrderFunc             func(req *investgo.ReplaceOrderRequest) (*investgo.PostOrderResponse, error)
+	ReplaceSandboxOrderCtxFunc          func(ctx context.Context, req *investgo.ReplaceOrderRequest) (*investgo.PostOrderResponse, error)
+	GetSandboxOrdersFunc                func(accountId string) (*investgo.GetOrdersResponse, error)
+	GetSandboxOrdersCtxFunc             func(ctx context.Context, accountId string) (*investgo.GetOrdersResponse, error)
+	CancelSandboxOrderFunc              func(accountId string, orderId string) (*investgo.CancelOrderResponse, error)
+	CancelSandboxOrderCtxFunc           func(ctx context.Context, accountId string, orderId string) (*investgo.CancelOrderResponse, error)
+	GetSandboxOrderStateFunc            func(accountId string, orderId string) (*investgo.GetOrderStateResponse, error)
+	GetSandboxOrderStateCtxFunc         func(ctx context.Context, accountId string, orderId string) (*investgo.GetOrderStateResponse, error)
+	GetSandboxPositionsFunc             func(accountId string) (*investgo.PositionsResponse, error)
+	GetSandboxPositionsCtxFunc          func(ctx context.Context, accountId string) (*investgo.PositionsResponse, error)
+	GetSandboxOperationsFunc            func(req *investgo.GetOperationsRequest) (*investgo.OperationsResponse, error)
+	GetSandboxOperationsCtxFunc         func(ctx context.Context, req *investgo.GetOperationsRequest) (*investgo.OperationsResponse, error)
+	GetSandboxOperationsByCursorFunc    func(req *investgo.GetOperationsByCursorRequest) (*investgo.GetOperationsByCursorResponse, error)
+	GetSandboxOperationsByCursorCtxFunc func(ctx context.Context, req *investgo.GetOperationsByCursorRequest) (*investgo.GetOperationsByCursorResponse, error)
+	GetSandboxPortfolioFunc             func(accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*investgo.PortfolioResponse, error)
+	GetSandboxPortfolioCtxFunc          func(ctx context.Context, accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*investgo.PortfolioResponse, error)
+	GetSandboxWithdrawLimitsFunc        func(accountId string) (*investgo.WithdrawLimitsResponse, error)
+	GetSandboxWithdrawLimitsCtxFunc     func(ctx context.Context, accountId string) (*investgo.WithdrawLimitsResponse, error)
+	SandboxPayInFunc                    func(req *investgo.SandboxPayInRequest) (*investgo.SandboxPayInResponse, error)
+	SandboxPayInCtxFunc                 func(ctx context.Context, req *investgo.SandboxPayInRequest) (*investgo.SandboxPayInResponse, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ investgo.SandboxService = (*SandboxServiceMock)(nil)
+
+func (m *SandboxServiceMock) OpenSandboxAccount() (*investgo.OpenSandboxAccountResponse, error) {
+	if m.OpenSandboxAccountFunc == nil {
+		panic("SandboxServiceMock.OpenSandboxAccountFunc: method is nil but OpenSandboxAccount was just called")
+	}
+	m.record("OpenSandboxAccount", []any{})
+	return m.OpenSandboxAccountFunc()
+}
+
+func (m *SandboxServiceMock) OpenSandboxAccountCtx(ctx context.Context) (*investgo.OpenSandboxAccountResponse, error) {
+	if m.OpenSandboxAccountCtxFunc == nil {
+		panic("SandboxServiceMock.OpenSandboxAccountCtxFunc: method is nil but OpenSandboxAccountCtx was just called")
+	}
+	m.record("OpenSandboxAccountCtx", []any{ctx})
+	return m.OpenSandboxAccountCtxFunc(ctx)
+}
+
+func (m *SandboxServiceMock) GetSandboxAccounts() (*investgo.GetAccountsResponse, error) {
+	if m.GetSandboxAccountsFunc == nil {
+		panic("SandboxServiceMock.GetSandboxAccountsFunc: method is nil but GetSandboxAccounts was just called")
+	}
+	m.record("GetSandboxAccounts", []any{})
+	return m.GetSandboxAccountsFunc()
+}
+
+func (m *SandboxServiceMock) GetSandboxAccountsCtx(ctx context.Context) (*investgo.GetAccountsResponse, error) {
+	if m.GetSandboxAccountsCtxFunc == nil {
+		panic("SandboxServiceMock.GetSandboxAccountsCtxFunc: method is nil but GetSandboxAccountsCtx was just called")
+	}
+	m.record("GetSandboxAccountsCtx", []any{ctx})
+	return m.GetSandboxAccountsCtxFunc(ctx)
+}
+
+func (m *SandboxServiceMock) CloseSandboxAccount(accountId string) (*investgo.CloseSandboxAccountResponse, error) {
+	if m.CloseSandboxAccountFunc == nil {
+		panic("SandboxServiceMock.CloseSandboxAccountFunc: method is nil but CloseSandboxAccount was just called")
+	}
+	m.record("CloseSandboxAccount", []any{accountId})
+	return m.CloseSandboxAccountFunc(accountId)
+}
+
+func (m *SandboxServiceMock) CloseSandboxAccountCtx(ctx context.Context, accountId string) (*investgo.CloseSandboxAccountResponse, error) {
+	if m.CloseSandboxAccountCtxFunc == nil {
+		panic("SandboxServiceMock.CloseSandboxAccountCtxFunc: method is nil but CloseSandboxAccountCtx was just called")
+	}
+	m.record("CloseSandboxAccountCtx", []any{ctx, accountId})
+	return m.CloseSandboxAccountCtxFunc(ctx, accountId)
+}
+
+func (m *SandboxServiceMock) PostSandboxOrder(req *investgo.PostOrderRequest) (*investgo.PostOrderResponse, error) {
+	if m.PostSandboxOrderFunc == nil {
+		panic("SandboxServiceMock.PostSandboxOrderFunc: method is nil but PostSandboxOrder was just called")
+	}
+	m.record("PostSandboxOrder", []any{req})
+	return m.PostSandboxOrderFunc(req)
+}
+
+func (m *SandboxServiceMock) PostSandboxOrderCtx(ctx context.Context, req *investgo.PostOrderRequest) (*investgo.PostOrderResponse, error) {
+	if m.PostSandboxOrderCtxFunc == nil {
+		panic("SandboxServiceMock.PostSandboxOrderCtxFunc: method is nil but PostSandboxOrderCtx was just called")
+	}
+	m.record("PostSandboxOrderCtx", []any{ctx, req})
+	return m.PostSandboxOrderCtxFunc(ctx, req)
+}
+
+func (m *SandboxServiceMock) ReplaceSandboxOrder(req *investgo.ReplaceOrderRequest) (*investgo.PostOrderResponse, error) {
+	if m.ReplaceSandboxOrderFunc == nil {
+		panic("SandboxServiceMock.ReplaceSandboxOrderFunc: method is nil but ReplaceSandboxOrder was just called")
+	}
+	m.record("ReplaceSandboxOrder", []any{req})
+	return m.ReplaceSandboxOrderFunc(req)
+}
+
+func (m *SandboxServiceMock) ReplaceSandboxOrderCtx(ctx context.Context, req *investgo.ReplaceOrderRequest) (*investgo.PostOrderResponse, error) {
+	if m.ReplaceSandboxOrderCtxFunc == nil {
+		panic("SandboxServiceMock.ReplaceSandboxOrderCtxFunc: method is nil but ReplaceSandboxOrderCtx was just called")
+	}
+	m.record("ReplaceSandboxOrderCtx", []any{ctx, req})
+	return m.ReplaceSandboxOrderCtxFunc(ctx, req)
+}
+
+func (m *SandboxServiceMock) GetSandboxOrders(accountId string) (*investgo.GetOrdersResponse, error) {
+	if m.GetSandboxOrdersFunc == nil {
+		panic("SandboxServiceMock.GetSandboxOrdersFunc: method is nil but GetSandboxOrders was just called")
+	}
+	m.record("GetSandboxOrders", []any{accountId})
+	return m.GetSandboxOrdersFunc(accountId)
+}
+
+func (m *SandboxServiceMock) GetSandboxOrdersCtx(ctx context.Context, accountId string) (*investgo.GetOrdersResponse, error) {
+	if m.GetSandboxOrdersCtxFunc == nil {
+		panic("SandboxServiceMock.GetSandboxOrdersCtxFunc: method is nil but GetSandboxOrdersCtx was just called")
+	}
+	m.record("GetSandboxOrdersCtx", []any{ctx, accountId})
+	return m.GetSandboxOrdersCtxFunc(ctx, accountId)
+}
+
+func (m *SandboxServiceMock) CancelSandboxOrder(accountId string, orderId string) (*investgo.CancelOrderResponse, error) {
+	if m.CancelSandboxOrderFunc == nil {
+		panic("SandboxServiceMock.CancelSandboxOrderFunc: method is nil but CancelSandboxOrder was just called")
+	}
+	m.record("CancelSandboxOrder", []any{accountId, orderId})
+	return m.CancelSandboxOrderFunc(accountId, orderId)
+}
+
+func (m *SandboxServiceMock) CancelSandboxOrderCtx(ctx context.Context, accountId string, orderId string) (*investgo.CancelOrderResponse, error) {
+	if m.CancelSandboxOrderCtxFunc == nil {
+		panic("SandboxServiceMock.CancelSandboxOrderCtxFunc: method is nil but CancelSandboxOrderCtx was just called")
+	}
+	m.record("CancelSandboxOrderCtx", []any{ctx, accountId, orderId})
+	return m.CancelSandboxOrderCtxFunc(ctx, accountId, orderId)
+}
+
+func (m *SandboxServiceMock) GetSandboxOrderState(accountId string, orderId string) (*investgo.GetOrderStateResponse, error) {
+	if m.GetSandboxOrderStateFunc == nil {
+		panic("SandboxServiceMock.GetSandboxOrderStateFunc: method is nil but GetSandboxOrderState was just called")
+	}
+	m.record("GetSandboxOrderState", []any{accountId, orderId})
+	return m.GetSandboxOrderStateFunc(accountId, orderId)
+}
+
+func (m *SandboxServiceMock) GetSandboxOrderStateCtx(ctx context.Context, accountId string, orderId string) (*investgo.GetOrderStateResponse, error) {
+	if m.GetSandboxOrderStateCtxFunc == nil {
+		panic("SandboxServiceMock.GetSandboxOrderStateCtxFunc: method is nil but GetSandboxOrderStateCtx was just called")
+	}
+	m.record("GetSandboxOrderStateCtx", []any{ctx, accountId, orderId})
+	return m.GetSandboxOrderStateCtxFunc(ctx, accountId, orderId)
+}
+
+func (m *SandboxServiceMock) GetSandboxPositions(accountId string) (*investgo.PositionsResponse, error) {
+	if m.GetSandboxPositionsFunc == nil {
+		panic("SandboxServiceMock.GetSandboxPositionsFunc: method is nil but GetSandboxPositions was just called")
+	}
+	m.record("GetSandboxPositions", []any{accountId})
+	return m.GetSandboxPositionsFunc(accountId)
+}
+
+func (m *SandboxServiceMock) GetSandboxPositionsCtx(ctx context.Context, accountId string) (*investgo.PositionsResponse, error) {
+	if m.GetSandboxPositionsCtxFunc == nil {
+		panic("SandboxServiceMock.GetSandboxPositionsCtxFunc: method is nil but GetSandboxPositionsCtx was just called")
+	}
+	m.record("GetSandboxPositionsCtx", []any{ctx, accountId})
+	return m.GetSandboxPositionsCtxFunc(ctx, accountId)
+}
+
+func (m *SandboxServiceMock) GetSandboxOperations(req *investgo.GetOperationsRequest) (*investgo.OperationsResponse, error) {
+	if m.GetSandboxOperationsFunc == nil {
+		panic("SandboxServiceMock.GetSandboxOperationsFunc: method is nil but GetSandboxOperations was just called")
+	}
+	m.record("GetSandboxOperations", []any{req})
+	return m.GetSandboxOperationsFunc(req)
+}
+
+func (m *SandboxServiceMock) GetSandboxOperationsCtx(ctx context.Context, req *investgo.GetOperationsRequest) (*investgo.OperationsResponse, error) {
+	if m.GetSandboxOperationsCtxFunc == nil {
+		panic("SandboxServiceMock.GetSandboxOperationsCtxFunc: method is nil but GetSandboxOperationsCtx was just called")
+	}
+	m.record("GetSandboxOperationsCtx", []any{ctx, req})
+	return m.GetSandboxOperationsCtxFunc(ctx, req)
+}
+
+func (m *SandboxServiceMock) GetSandboxOperationsByCursor(req *investgo.GetOperationsByCursorRequest) (*investgo.GetOperationsByCursorResponse, error) {
+	if m.GetSandboxOperationsByCursorFunc == nil {
+		panic("SandboxServiceMock.GetSandboxOperationsByCursorFunc: method is nil but GetSandboxOperationsByCursor was just called")
+	}
+	m.record("GetSandboxOperationsByCursor", []any{req})
+	return m.GetSandboxOperationsByCursorFunc(req)
+}
+
+func (m *SandboxServiceMock) GetSandboxOperationsByCursorCtx(ctx context.Context, req *investgo.GetOperationsByCursorRequest) (*investgo.GetOperationsByCursorResponse, error) {
+	if m.GetSandboxOperationsByCursorCtxFunc == nil {
+		panic("SandboxServiceMock.GetSandboxOperationsByCursorCtxFunc: method is nil but GetSandboxOperationsByCursorCtx was just called")
+	}
+	m.record("GetSandboxOperationsByCursorCtx", []any{ctx, req})
+	return m.GetSandboxOperationsByCursorCtxFunc(ctx, req)
+}
+
+func (m *SandboxServiceMock) GetSandboxPortfolio(accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*investgo.PortfolioResponse, error) {
+	if m.GetSandboxPortfolioFunc == nil {
+		panic("SandboxServiceMock.GetSandboxPortfolioFunc: method is nil but GetSandboxPortfolio was just called")
+	}
+	m.record("GetSandboxPortfolio", []any{accountId, currency})
+	return m.GetSandboxPortfolioFunc(accountId, currency)
+}
+
+func (m *SandboxServiceMock) GetSandboxPortfolioCtx(ctx context.Context, accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*investgo.PortfolioResponse, error) {
+	if m.GetSandboxPortfolioCtxFunc == nil {
+		panic("SandboxServiceMock.GetSandboxPortfolioCtxFunc: method is nil but GetSandboxPortfolioCtx was just called")
+	}
+	m.record("GetSandboxPortfolioCtx", []any{ctx, accountId, currency})
+	return m.GetSandboxPortfolioCtxFunc(ctx, accountId, currency)
+}
+
+func (m *SandboxServiceMock) GetSandboxWithdrawLimits(accountId string) (*investgo.WithdrawLimitsResponse, error) {
+	if m.GetSandboxWithdrawLimitsFunc == nil {
+		panic("SandboxServiceMock.GetSandboxWithdrawLimitsFunc: method is nil but GetSandboxWithdrawLimits was just called")
+	}
+	m.record("GetSandboxWithdrawLimits", []any{accountId})
+	return m.GetSandboxWithdrawLimitsFunc(accountId)
+}
+
+func (m *SandboxServiceMock) GetSandboxWithdrawLimitsCtx(ctx context.Context, accountId string) (*investgo.WithdrawLimitsResponse, error) {
+	if m.GetSandboxWithdrawLimitsCtxFunc == nil {
+		panic("SandboxServiceMock.GetSandboxWithdrawLimitsCtxFunc: method is nil but GetSandboxWithdrawLimitsCtx was just called")
+	}
+	m.record("GetSandboxWithdrawLimitsCtx", []any{ctx, accountId})
+	return m.GetSandboxWithdrawLimitsCtxFunc(ctx, accountId)
+}
+
+func (m *SandboxServiceMock) SandboxPayIn(req *investgo.SandboxPayInRequest) (*investgo.SandboxPayInResponse, error) {
+	if m.SandboxPayInFunc == nil {
+		panic("SandboxServiceMock.SandboxPayInFunc: method is nil but SandboxPayIn was just called")
+	}
+	m.record("SandboxPayIn", []any{req})
+	return m.SandboxPayInFunc(req)
+}
+
+func (m *SandboxServiceMock) SandboxPayInCtx(ctx context.Context, req *investgo.SandboxPayInRequest) (*investgo.SandboxPayInResponse, error) {
+	if m.SandboxPayInCtxFunc == nil {
+		panic("SandboxServiceMock.SandboxPayInCtxFunc: method is nil but SandboxPayInCtx was just called")
+	}
+	m.record("SandboxPayInCtx", []any{ctx, req})
+	return m.SandboxPayInCtxFunc(ctx, req)
+}
+
+func (m *SandboxServiceMock) record(method string, args []any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls - все вызовы методов мока в порядке вызова
+func (m *SandboxServiceMock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo - вызовы метода method в порядке вызова
+func (m *SandboxServiceMock) CallsTo(method string) []Call {
+	return filterCalls(m.Calls(), method)
+}
+
+// MarketDataStreamServiceMock - мок investgo.MarketDataStreamService, методы вызывают соответствующие поля <Method>Func
+type MarketDataStreamServiceMock struct {
+	MarketDataStreamFunc    func() (investgo.MarketDataStreamer, error)
+	MarketDataStreamCtxFunc func(ctx context.Context) (investgo.MarketDataStreamer, error)
+	ServerSideStreamFunc    func(subs investgo.ServerSideSubscriptions) (investgo.ServerSideStreamer, error)
+	ServerSideStreamCtxFunc func(ctx context.Context, subs investgo.ServerSideSubscriptions) (investgo.ServerSideStreamer, error)
+	MarketDataPoolFunc      func(opts investgo.PoolOptions) (investgo.MarketDataPooler, error)
+	MarketDataPoolCtxFunc   func(ctx context.Context, opts investgo.PoolOptions) (investgo.MarketDataPooler, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ investgo.MarketDataStreamService = (*MarketDataStreamServiceMock)(nil)
+
+func (m *MarketDataStreamServiceMock) MarketDataStream() (investgo.MarketDataStreamer, error) {
+	if m.MarketDataStreamFunc == nil {
+		panic("MarketDataStreamServiceMock.MarketDataStreamFunc: method is nil but MarketDataStream was just called")
+	}
+	m.record("MarketDataStream", []any{})
+	return m.MarketDataStreamFunc()
+}
+
+func (m *MarketDataStreamServiceMock) MarketDataStreamCtx(ctx context.Context) (investgo.MarketDataStreamer, error) {
+	if m.MarketDataStreamCtxFunc == nil {
+		panic("MarketDataStreamServiceMock.MarketDataStreamCtxFunc: method is nil but MarketDataStreamCtx was just called")
+	}
+	m.record("MarketDataStreamCtx", []any{ctx})
+	return m.MarketDataStreamCtxFunc(ctx)
+}
+
+func (m *MarketDataStreamServiceMock) ServerSideStream(subs investgo.ServerSideSubscriptions) (investgo.ServerSideStreamer, error) {
+	if m.ServerSideStreamFunc == nil {
+		panic("MarketDataStreamServiceMock.ServerSideStreamFunc: method is nil but ServerSideStream was just called")
+	}
+	m.record("ServerSideStream", []any{subs})
+	return m.ServerSideStreamFunc(subs)
+}
+
+func (m *MarketDataStreamServiceMock) ServerSideStreamCtx(ctx context.Context, subs investgo.ServerSideSubscriptions) (investgo.ServerSideStreamer, error) {
+	if m.ServerSideStreamCtxFunc == nil {
+		panic("MarketDataStreamServiceMock.ServerSideStreamCtxFunc: method is nil but ServerSideStreamCtx was just called")
+	}
+	m.record("ServerSideStreamCtx", []any{ctx, subs})
+	return m.ServerSideStreamCtxFunc(ctx, subs)
+}
+
+func (m *MarketDataStreamServiceMock) MarketDataPool(opts investgo.PoolOptions) (investgo.MarketDataPooler, error) {
+	if m.MarketDataPoolFunc == nil {
+		panic("MarketDataStreamServiceMock.MarketDataPoolFunc: method is nil but MarketDataPool was just called")
+	}
+	m.record("MarketDataPool", []any{opts})
+	return m.MarketDataPoolFunc(opts)
+}
+
+func (m *MarketDataStreamServiceMock) MarketDataPoolCtx(ctx context.Context, opts investgo.PoolOptions) (investgo.MarketDataPooler, error) {
+	if m.MarketDataPoolCtxFunc == nil {
+		panic("MarketDataStreamServiceMock.MarketDataPoolCtxFunc: method is nil but MarketDataPoolCtx was just called")
+	}
+	m.record("MarketDataPoolCtx", []any{ctx, opts})
+	return m.MarketDataPoolCtxFunc(ctx, opts)
+}
+
+func (m *MarketDataStreamServiceMock) record(method string, args []any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls - все вызовы методов мока в порядке вызова
+func (m *MarketDataStreamServiceMock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo - вызовы метода method в порядке вызова
+func (m *MarketDataStreamServiceMock) CallsTo(method string) []Call {
+	return filterCalls(m.Calls(), method)
+}
+
+// OperationsStreamServiceMock - мок investgo.OperationsStreamService, методы вызывают соответствующие поля <Method>Func
+type OperationsStreamServiceMock struct {
+	PortfolioStreamFunc    func(accounts []string) (investgo.PortfolioStreamer, error)
+	PortfolioStreamCtxFunc func(ctx context.Context, accounts []string) (investgo.PortfolioStreamer, error)
+	PositionsStreamFunc    func(accounts []string) (investgo.PositionsStreamer, error)
+	PositionsStreamCtxFunc func(ctx context.Context, accounts []string) (investgo.PositionsStreamer, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ investgo.OperationsStreamService = (*OperationsStreamServiceMock)(nil)
+
+func (m *OperationsStreamServiceMock) PortfolioStream(accounts []string) (investgo.PortfolioStreamer, error) {
+	if m.PortfolioStreamFunc == nil {
+		panic("OperationsStreamServiceMock.PortfolioStreamFunc: method is nil but PortfolioStream was just called")
+	}
+	m.record("PortfolioStream", []any{accounts})
+	return m.PortfolioStreamFunc(accounts)
+}
+
+func (m *OperationsStreamServiceMock) PortfolioStreamCtx(ctx context.Context, accounts []string) (investgo.PortfolioStreamer, error) {
+	if m.PortfolioStreamCtxFunc == nil {
+		panic("OperationsStreamServiceMock.PortfolioStreamCtxFunc: method is nil but PortfolioStreamCtx was just called")
+	}
+	m.record("PortfolioStreamCtx", []any{ctx, accounts})
+	return m.PortfolioStreamCtxFunc(ctx, accounts)
+}
+
+func (m *OperationsStreamServiceMock) PositionsStream(accounts []string) (investgo.PositionsStreamer, error) {
+	if m.PositionsStreamFunc == nil {
+		panic("OperationsStreamServiceMock.PositionsStreamFunc: method is nil but PositionsStream was just called")
+	}
+	m.record("PositionsStream", []any{accounts})
+	return m.PositionsStreamFunc(accounts)
+}
+
+func (m *OperationsStreamServiceMock) PositionsStreamCtx(ctx context.Context, accounts []string) (investgo.PositionsStreamer, error) {
+	if m.PositionsStreamCtxFunc == nil {
+		panic("OperationsStreamServiceMock.PositionsStreamCtxFunc: method is nil but PositionsStreamCtx was just called")
+	}
+	m.record("PositionsStreamCtx", []any{ctx, accounts})
+	return m.PositionsStreamCtxFunc(ctx, accounts)
+}
+
+func (m *OperationsStreamServiceMock) record(method string, args []any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls - все вызовы методов мока в порядке вызова
+func (m *OperationsStreamServiceMock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo - вызовы метода method в порядке вызова
+func (m *OperationsStreamServiceMock) CallsTo(method string) []Call {
+	return filterCalls(m.Calls(), method)
+}
+
+// OrdersStreamServiceMock - мок investgo.OrdersStreamService, методы вызывают соответствующие поля <Method>Func
+type OrdersStreamServiceMock struct {
+	TradesStreamFunc    func(accounts []string) (investgo.TradesStreamer, error)
+	TradesStreamCtxFunc func(ctx context.Context, accounts []string) (investgo.TradesStreamer, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ investgo.OrdersStreamService = (*OrdersStreamServiceMock)(nil)
+
+func (m *OrdersStreamServiceMock) TradesStream(accounts []string) (investgo.TradesStreamer, error) {
+	if m.TradesStreamFunc == nil {
+		panic("OrdersStreamServiceMock.TradesStreamFunc: method is nil but TradesStream was just called")
+	}
+	m.record("TradesStream", []any{accounts})
+	return m.TradesStreamFunc(accounts)
+}
+
+func (m *OrdersStreamServiceMock) TradesStreamCtx(ctx context.Context, accounts []string) (investgo.TradesStreamer, error) {
+	if m.TradesStreamCtxFunc == nil {
+		panic("OrdersStreamServiceMock.TradesStreamCtxFunc: method is nil but TradesStreamCtx was just called")
+	}
+	m.record("TradesStreamCtx", []any{ctx, accounts})
+	return m.TradesStreamCtxFunc(ctx, accounts)
+}
+
+func (m *OrdersStreamServiceMock) record(method string, args []any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls - все вызовы методов мока в порядке вызова
+func (m *OrdersStreamServiceMock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo - вызовы метода method в порядке вызова
+func (m *OrdersStreamServiceMock) CallsTo(method string) []Call {
+	return filterCalls(m.Calls(), method)
+}
+
+// MarketDataStreamerMock - мок investgo.MarketDataStreamer, методы вызывают соответствующие поля <Method>Func
+type MarketDataStreamerMock struct {
+	SubscribeCandleFunc         func(ids []string, interval pb.SubscriptionInterval) (<-chan *pb.Candle, error)
+	UnSubscribeCandleFunc       func(ids []string, interval pb.SubscriptionInterval) error
+	SubscribeOrderBookFunc      func(ids []string, depth int32) (<-chan *pb.OrderBook, error)
+	UnSubscribeOrderBookFunc    func(ids []string) error
+	SubscribeTradeFunc          func(ids []string) (<-chan *pb.Trade, error)
+	UnSubscribeTradeFunc        func(ids []string) error
+	SubscribeInfoFunc           func(ids []string) (<-chan *pb.TradingStatus, error)
+	UnSubscribeInfoFunc         func(ids []string) error
+	SubscribeLastPriceFunc      func(ids []string) (<-chan *pb.LastPrice, error)
+	UnSubscribeLastPriceFunc    func(ids []string) error
+	SubscribeCandleAsyncFunc    func(ids []string, interval pb.SubscriptionInterval) (*investgo.SubscriptionFuture, error)
+	SubscribeOrderBookAsyncFunc func(ids []string, depth int32) (*investgo.SubscriptionFuture, error)
+	SubscribeTradeAsyncFunc     func(ids []string) (*investgo.SubscriptionFuture, error)
+	SubscribeInfoAsyncFunc      func(ids []string) (*investgo.SubscriptionFuture, error)
+	SubscribeLastPriceAsyncFunc func(ids []string) (*investgo.SubscriptionFuture, error)
+	CandlesFunc                 func() <-chan *pb.Candle
+	OrderBooksFunc              func() <-chan *pb.OrderBook
+	TradesFunc                  func() <-chan *pb.Trade
+	TradingStatusesFunc         func() <-chan *pb.TradingStatus
+	LastPricesFunc              func() <-chan *pb.LastPrice
+	GetMySubscriptionsFunc      func() error
+	MySubscriptionsFunc         func(ctx context.Context) (*investgo.SubscriptionsSnapshot, error)
+	ReconcileSubscriptionsFunc  func(ctx context.Context) (*investgo.SubscriptionsDiff, error)
+	CompareSubscriptionsFunc    func(snapshot *investgo.SubscriptionsSnapshot) *investgo.SubscriptionsDiff
+	SetReconnectPolicyFunc      func(p investgo.ReconnectPolicy)
+	ListenFunc                  func() error
+	StopFunc                    func()
+	UnSubscribeAllFunc          func() error
+	SetWatchdogFunc             func(opts investgo.WatchdogOptions)
+	HealthFunc                  func() investgo.StreamHealth
+	SetRecorderFunc             func(r *investgo.Recorder)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ investgo.MarketDataStreamer = (*MarketDataStreamerMock)(nil)
+
+func (m *MarketDataStreamerMock) SubscribeCandle(ids []string, interval pb.SubscriptionInterval) (<-chan *pb.Candle, error) {
+	if m.SubscribeCandleFunc == nil {
+		panic("MarketDataStreamerMock.SubscribeCandleFunc: method is nil but SubscribeCandle was just called")
+	}
+	m.record("SubscribeCandle", []any{ids, interval})
+	return m.SubscribeCandleFunc(ids, interval)
+}
+
+func (m *MarketDataStreamerMock) UnSubscribeCandle(ids []string, interval pb.SubscriptionInterval) error {
+	if m.UnSubscribeCandleFunc == nil {
+		panic("MarketDataStreamerMock.UnSubscribeCandleFunc: method is nil but UnSubscribeCandle was just called")
+	}
+	m.record("UnSubscribeCandle", []any{ids, interval})
+	return m.UnSubscribeCandleFunc(ids, interval)
+}
+
+func (m *MarketDataStreamerMock) SubscribeOrderBook(ids []string, depth int32) (<-chan *pb.OrderBook, error) {
+	if m.SubscribeOrderBookFunc == nil {
+		panic("MarketDataStreamerMock.SubscribeOrderBookFunc: method is nil but SubscribeOrderBook was just called")
+	}
+	m.record("SubscribeOrderBook", []any{ids, depth})
+	return m.SubscribeOrderBookFunc(ids, depth)
+}
+
+func (m *MarketDataStreamerMock) UnSubscribeOrderBook(ids []string) error {
+	if m.UnSubscribeOrderBookFunc == nil {
+		panic("MarketDataStreamerMock.UnSubscribeOrderBookFunc: method is nil but UnSubscribeOrderBook was just called")
+	}
+	m.record("UnSubscribeOrderBook", []any{ids})
+	return m.UnSubscribeOrderBookFunc(ids)
+}
+
+func (m *MarketDataStreamerMock) SubscribeTrade(ids []string) (<-chan *pb.Trade, error) {
+	if m.SubscribeTradeFunc == nil {
+		panic("MarketDataStreamerMock.SubscribeTradeFunc: method is nil but SubscribeTrade was just called")
+	}
+	m.record("SubscribeTrade", []any{ids})
+	return m.SubscribeTradeFunc(ids)
+}
+
+func (m *MarketDataStreamerMock) UnSubscribeTrade(ids []string) error {
+	if m.UnSubscribeTradeFunc == nil {
+		panic("MarketDataStreamerMock.UnSubscribeTradeFunc: method is nil but UnSubscribeTrade was just called")
+	}
+	m.record("UnSubscribeTrade", []any{ids})
+	return m.UnSubscribeTradeFunc(ids)
+}
+
+func (m *MarketDataStreamerMock) SubscribeInfo(ids []string) (<-chan *pb.TradingStatus, error) {
+	if m.SubscribeInfoFunc == nil {
+		panic("MarketDataStreamerMock.SubscribeInfoFunc: method is nil but SubscribeInfo was just called")
+	}
+	m.record("SubscribeInfo", []any{ids})
+	return m.SubscribeInfoFunc(ids)
+}
+
+func (m *MarketDataStreamerMock) UnSubscribeInfo(ids []string) error {
+	if m.UnSubscribeInfoFunc == nil {
+		panic("MarketDataStreamerMock.UnSubscribeInfoFunc: method is nil but UnSubscribeInfo was just called")
+	}
+	m.record("UnSubscribeInfo", []any{ids})
+	return m.UnSubscribeInfoFunc(ids)
+}
+
+func (m *MarketDataStreamerMock) SubscribeLastPrice(ids []string) (<-chan *pb.LastPrice, error) {
+	if m.SubscribeLastPriceFunc == nil {
+		panic("MarketDataStreamerMock.SubscribeLastPriceFunc: method is nil but SubscribeLastPrice was just called")
+	}
+	m.record("SubscribeLastPrice", []any{ids})
+	return m.SubscribeLastPriceFunc(ids)
+}
+
+func (m *MarketDataStreamerMock) UnSubscribeLastPrice(ids []string) error {
+	if m.UnSubscribeLastPriceFunc == nil {
+		panic("MarketDataStreamerMock.UnSubscribeLastPriceFunc: method is nil but UnSubscribeLastPrice was just called")
+	}
+	m.record("UnSubscribeLastPrice", []any{ids})
+	return m.UnSubscribeLastPriceFunc(ids)
+}
+
+func (m *MarketDataStreamerMock) SubscribeCandleAsync(ids []string, interval pb.SubscriptionInterval) (*investgo.SubscriptionFuture, error) {
+	if m.SubscribeCandleAsyncFunc == nil {
+		panic("MarketDataStreamerMock.SubscribeCandleAsyncFunc: method is nil but SubscribeCandleAsync was just called")
+	}
+	m.record("SubscribeCandleAsync", []any{ids, interval})
+	return m.SubscribeCandleAsyncFunc(ids, interval)
+}
+
+func (m *MarketDataStreamerMock) SubscribeOrderBookAsync(ids []string, depth int32) (*investgo.SubscriptionFuture, error) {
+	if m.SubscribeOrderBookAsyncFunc == nil {
+		panic("MarketDataStreamerMock.SubscribeOrderBookAsyncFunc: method is nil but SubscribeOrderBookAsync was just called")
+	}
+	m.record("SubscribeOrderBookAsync", []any{ids, depth})
+	return m.SubscribeOrderBookAsyncFunc(ids, depth)
+}
+
+func (m *MarketDataStreamerMock) SubscribeTradeAsync(ids []string) (*investgo.SubscriptionFuture, error) {
+	if m.SubscribeTradeAsyncFunc == nil {
+		panic("MarketDataStreamerMock.SubscribeTradeAsyncFunc: method is nil but SubscribeTradeAsync was just called")
+	}
+	m.record("SubscribeTradeAsync", []any{ids})
+	return m.SubscribeTradeAsyncFunc(ids)
+}
+
+func (m *MarketDataStreamerMock) SubscribeInfoAsync(ids []string) (*investgo.SubscriptionFuture, error) {
+	if m.SubscribeInfoAsyncFunc == nil {
+		panic("MarketDataStreamerMock.SubscribeInfoAsyncFunc: method is nil but SubscribeInfoAsync was just called")
+	}
+	m.record("SubscribeInfoAsync", []any{ids})
+	return m.SubscribeInfoAsyncFunc(ids)
+}
+
+func (m *MarketDataStreamerMock) SubscribeLastPriceAsync(ids []string) (*investgo.SubscriptionFuture, error) {
+	if m.SubscribeLastPriceAsyncFunc == nil {
+		panic("MarketDataStreamerMock.SubscribeLastPriceAsyncFunc: method is nil but SubscribeLastPriceAsync was just called")
+	}
+	m.record("SubscribeLastPriceAsync", []any{ids})
+	return m.SubscribeLastPriceAsyncFunc(ids)
+}
+
+func (m *MarketDataStreamerMock) Candles() <-chan *pb.Candle {
+	if m.CandlesFunc == nil {
+		panic("MarketDataStreamerMock.CandlesFunc: method is nil but Candles was just called")
+	}
+	m.record("Candles", []any{})
+	return m.CandlesFunc()
+}
+
+func (m *MarketDataStreamerMock) OrderBooks() <-chan *pb.OrderBook {
+	if m.OrderBooksFunc == nil {
+		panic("MarketDataStreamerMock.OrderBooksFunc: method is nil but OrderBooks was just called")
+	}
+	m.record("OrderBooks", []any{})
+	return m.OrderBooksFunc()
+}
+
+func (m *MarketDataStreamerMock) Trades() <-chan *pb.Trade {
+	if m.TradesFunc == nil {
+		panic("MarketDataStreamerMock.TradesFunc: method is nil but Trades was just called")
+	}
+	m.record("Trades", []any{})
+	return m.TradesFunc()
+}
+
+func (m *MarketDataStreamerMock) TradingStatuses() <-chan *pb.TradingStatus {
+	if m.TradingStatusesFunc == nil {
+		panic("MarketDataStreamerMock.TradingStatusesFunc: method is nil but TradingStatuses was just called")
+	}
+	m.record("TradingStatuses", []any{})
+	return m.TradingStatusesFunc()
+}
+
+func (m *MarketDataStreamerMock) LastPrices() <-chan *pb.LastPrice {
+	if m.LastPricesFunc == nil {
+		panic("MarketDataStreamerMock.LastPricesFunc: method is nil but LastPrices was just called")
+	}
+	m.record("LastPrices", []any{})
+	return m.LastPricesFunc()
+}
+
+func (m *MarketDataStreamerMock) GetMySubscriptions() error {
+	if m.GetMySubscriptionsFunc == nil {
+		panic("MarketDataStreamerMock.GetMySubscriptionsFunc: method is nil but GetMySubscriptions was just called")
+	}
+	m.record("GetMySubscriptions", []any{})
+	return m.GetMySubscriptionsFunc()
+}
+
+func (m *MarketDataStreamerMock) MySubscriptions(ctx context.Context) (*investgo.SubscriptionsSnapshot, error) {
+	if m.MySubscriptionsFunc == nil {
+		panic("MarketDataStreamerMock.MySubscriptionsFunc: method is nil but MySubscriptions was just called")
+	}
+	m.record("MySubscriptions", []any{ctx})
+	return m.MySubscriptionsFunc(ctx)
+}
+
+func (m *MarketDataStreamerMock) ReconcileSubscriptions(ctx context.Context) (*investgo.SubscriptionsDiff, error) {
+	if m.ReconcileSubscriptionsFunc == nil {
+		panic("MarketDataStreamerMock.ReconcileSubscriptionsFunc: method is nil but ReconcileSubscriptions was just called")
+	}
+	m.record("ReconcileSubscriptions", []any{ctx})
+	return m.ReconcileSubscriptionsFunc(ctx)
+}
+
+func (m *MarketDataStreamerMock) CompareSubscriptions(snapshot *investgo.SubscriptionsSnapshot) *investgo.SubscriptionsDiff {
+	if m.CompareSubscriptionsFunc == nil {
+		panic("MarketDataStreamerMock.CompareSubscriptionsFunc: method is nil but CompareSubscriptions was just called")
+	}
+	m.record("CompareSubscriptions", []any{snapshot})
+	return m.CompareSubscriptionsFunc(snapshot)
+}
+
+func (m *MarketDataStreamerMock) SetReconnectPolicy(p investgo.ReconnectPolicy) {
+	if m.SetReconnectPolicyFunc == nil {
+		panic("MarketDataStreamerMock.SetReconnectPolicyFunc: method is nil but SetReconnectPolicy was just called")
+	}
+	m.record("SetReconnectPolicy", []any{p})
+	m.SetReconnectPolicyFunc(p)
+}
+
+func (m *MarketDataStreamerMock) Listen() error {
+	if m.ListenFunc == nil {
+		panic("MarketDataStreamerMock.ListenFunc: method is nil but Listen was just called")
+	}
+	m.record("Listen", []any{})
+	return m.ListenFunc()
+}
+
+func (m *MarketDataStreamerMock) Stop() {
+	if m.StopFunc == nil {
+		panic("MarketDataStreamerMock.StopFunc: method is nil but Stop was just called")
+	}
+	m.record("Stop", []any{})
+	m.StopFunc()
+}
+
+func (m *MarketDataStreamerMock) UnSubscribeAll() error {
+	if m.UnSubscribeAllFunc == nil {
+		panic("MarketDataStreamerMock.UnSubscribeAllFunc: method is nil but UnSubscribeAll was just called")
+	}
+	m.record("UnSubscribeAll", []any{})
+	return m.UnSubscribeAllFunc()
+}
+
+func (m *MarketDataStreamerMock) SetWatchdog(opts investgo.WatchdogOptions) {
+	if m.SetWatchdogFunc == nil {
+		panic("MarketDataStreamerMock.SetWatchdogFunc: method is nil but SetWatchdog was just called")
+	}
+	m.record("SetWatchdog", []any{opts})
+	m.SetWatchdogFunc(opts)
+}
+
+func (m *MarketDataStreamerMock) Health() investgo.StreamHealth {
+	if m.HealthFunc == nil {
+		panic("MarketDataStreamerMock.HealthFunc: method is nil but Health was just called")
+	}
+	m.record("Health", []any{})
+	return m.HealthFunc()
+}
+
+func (m *MarketDataStreamerMock) SetRecorder(r *investgo.Recorder) {
+	if m.SetRecorderFunc == nil {
+		panic("MarketDataStreamerMock.SetRecorderFunc: method is nil but SetRecorder was just called")
+	}
+	m.record("SetRecorder", []any{r})
+	m.SetRecorderFunc(r)
+}
+
+func (m *MarketDataStreamerMock) record(method string, args []any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls - все вызовы методов мока в порядке вызова
+func (m *MarketDataStreamerMock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo - вызовы метода method в порядке вызова
+func (m *MarketDataStreamerMock) CallsTo(method string) []Call {
+	return filterCalls(m.Calls(), method)
+}
+
+// PortfolioStreamerMock - мок investgo.PortfolioStreamer, методы вызывают соответствующие поля <Method>Func
+type PortfolioStreamerMock struct {
+	PortfoliosFunc         func() <-chan *pb.PortfolioResponse
+	ListenFunc             func() error
+	StopFunc               func()
+	SetReconnectPolicyFunc func(policy investgo.ReconnectPolicy)
+	SetWatchdogFunc        func(opts investgo.WatchdogOptions)
+	HealthFunc             func() investgo.StreamHealth
+	SetRecorderFunc        func(r *investgo.Recorder)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ investgo.PortfolioStreamer = (*PortfolioStreamerMock)(nil)
+
+func (m *PortfolioStreamerMock) Portfolios() <-chan *pb.PortfolioResponse {
+	if m.PortfoliosFunc == nil {
+		panic("PortfolioStreamerMock.PortfoliosFunc: method is nil but Portfolios was just called")
+	}
+	m.record("Portfolios", []any{})
+	return m.PortfoliosFunc()
+}
+
+func (m *PortfolioStreamerMock) Listen() error {
+	if m.ListenFunc == nil {
+		panic("PortfolioStreamerMock.ListenFunc: method is nil but Listen was just called")
+	}
+	m.record("Listen", []any{})
+	return m.ListenFunc()
+}
+
+func (m *PortfolioStreamerMock) Stop() {
+	if m.StopFunc == nil {
+		panic("PortfolioStreamerMock.StopFunc: method is nil but Stop was just called")
+	}
+	m.record("Stop", []any{})
+	m.StopFunc()
+}
+
+func (m *PortfolioStreamerMock) SetReconnectPolicy(policy investgo.ReconnectPolicy) {
+	if m.SetReconnectPolicyFunc == nil {
+		panic("PortfolioStreamerMock.SetReconnectPolicyFunc: method is nil but SetReconnectPolicy was just called")
+	}
+	m.record("SetReconnectPolicy", []any{policy})
+	m.SetReconnectPolicyFunc(policy)
+}
+
+func (m *PortfolioStreamerMock) SetWatchdog(opts investgo.WatchdogOptions) {
+	if m.SetWatchdogFunc == nil {
+		panic("PortfolioStreamerMock.SetWatchdogFunc: method is nil but SetWatchdog was just called")
+	}
+	m.record("SetWatchdog", []any{opts})
+	m.SetWatchdogFunc(opts)
+}
+
+func (m *PortfolioStreamerMock) Health() investgo.StreamHealth {
+	if m.HealthFunc == nil {
+		panic("PortfolioStreamerMock.HealthFunc: method is nil but Health was just called")
+	}
+	m.record("Health", []any{})
+	return m.HealthFunc()
+}
+
+func (m *PortfolioStreamerMock) SetRecorder(r *investgo.Recorder) {
+	if m.SetRecorderFunc == nil {
+		panic("PortfolioStreamerMock.SetRecorderFunc: method is nil but SetRecorder was just called")
+	}
+	m.record("SetRecorder", []any{r})
+	m.SetRecorderFunc(r)
+}
+
+func (m *PortfolioStreamerMock) record(method string, args []any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls - все вызовы методов мока в порядке вызова
+func (m *PortfolioStreamerMock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo - вызовы метода method в порядке вызова
+func (m *PortfolioStreamerMock) CallsTo(method string) []Call {
+	return filterCalls(m.Calls(), method)
+}
+
+// PositionsStreamerMock - мок investgo.PositionsStreamer, методы вызывают соответствующие поля <Method>Func
+type PositionsStreamerMock struct {
+	PositionsFunc          func() <-chan *pb.PositionData
+	ListenFunc             func() error
+	StopFunc               func()
+	SetReconnectPolicyFunc func(policy investgo.ReconnectPolicy)
+	SetWatchdogFunc        func(opts investgo.WatchdogOptions)
+	HealthFunc             func() investgo.StreamHealth
+	SetRecorderFunc        func(r *investgo.Recorder)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ investgo.PositionsStreamer = (*PositionsStreamerMock)(nil)
+
+func (m *PositionsStreamerMock) Positions() <-chan *pb.PositionData {
+	if m.PositionsFunc == nil {
+		panic("PositionsStreamerMock.PositionsFunc: method is nil but Positions was just called")
+	}
+	m.record("Positions", []any{})
+	return m.PositionsFunc()
+}
+
+func (m *PositionsStreamerMock) Listen() error {
+	if m.ListenFunc == nil {
+		panic("PositionsStreamerMock.ListenFunc: method is nil but Listen was just called")
+	}
+	m.record("Listen", []any{})
+	return m.ListenFunc()
+}
+
+func (m *PositionsStreamerMock) Stop() {
+	if m.StopFunc == nil {
+		panic("PositionsStreamerMock.StopFunc: method is nil but Stop was just called")
+	}
+	m.record("Stop", []any{})
+	m.StopFunc()
+}
+
+func (m *PositionsStreamerMock) SetReconnectPolicy(policy investgo.ReconnectPolicy) {
+	if m.SetReconnectPolicyFunc == nil {
+		panic("PositionsStreamerMock.SetReconnectPolicyFunc: method is nil but SetReconnectPolicy was just called")
+	}
+	m.record("SetReconnectPolicy", []any{policy})
+	m.SetReconnectPolicyFunc(policy)
+}
+
+func (m *PositionsStreamerMock) SetWatchdog(opts investgo.WatchdogOptions) {
+	if m.SetWatchdogFunc == nil {
+		panic("PositionsStreamerMock.SetWatchdogFunc: method is nil but SetWatchdog was just called")
+	}
+	m.record("SetWatchdog", []any{opts})
+	m.SetWatchdogFunc(opts)
+}
+
+func (m *PositionsStreamerMock) Health() investgo.StreamHealth {
+	if m.HealthFunc == nil {
+		panic("PositionsStreamerMock.HealthFunc: method is nil but Health was just called")
+	}
+	m.record("Health", []any{})
+	return m.HealthFunc()
+}
+
+func (m *PositionsStreamerMock) SetRecorder(r *investgo.Recorder) {
+	if m.SetRecorderFunc == nil {
+		panic("PositionsStreamerMock.SetRecorderFunc: method is nil but SetRecorder was just called")
+	}
+	m.record("SetRecorder", []any{r})
+	m.SetRecorderFunc(r)
+}
+
+func (m *PositionsStreamerMock) record(method string, args []any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls - все вызовы методов мока в порядке вызова
+func (m *PositionsStreamerMock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo - вызовы метода method в порядке вызова
+func (m *PositionsStreamerMock) CallsTo(method string) []Call {
+	return filterCalls(m.Calls(), method)
+}
+
+// TradesStreamerMock - мок investgo.TradesStreamer, методы вызывают соответствующие поля <Method>Func
+type TradesStreamerMock struct {
+	TradesFunc             func() <-chan *pb.OrderTrades
+	ListenFunc             func() error
+	StopFunc               func()
+	SetReconnectPolicyFunc func(policy investgo.ReconnectPolicy)
+	SetWatchdogFunc        func(opts investgo.WatchdogOptions)
+	HealthFunc             func() investgo.StreamHealth
+	SetRecorderFunc        func(r *investgo.Recorder)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ investgo.TradesStreamer = (*TradesStreamerMock)(nil)
+
+func (m *TradesStreamerMock) Trades() <-chan *pb.OrderTrades {
+	if m.TradesFunc == nil {
+		panic("TradesStreamerMock.TradesFunc: method is nil but Trades was just called")
+	}
+	m.record("Trades", []any{})
+	return m.TradesFunc()
+}
+
+func (m *TradesStreamerMock) Listen() error {
+	if m.ListenFunc == nil {
+		panic("TradesStreamerMock.ListenFunc: method is nil but Listen was just called")
+	}
+	m.record("Listen", []any{})
+	return m.ListenFunc()
+}
+
+func (m *TradesStreamerMock) Stop() {
+	if m.StopFunc == nil {
+		panic("TradesStreamerMock.StopFunc: method is nil but Stop was just called")
+	}
+	m.record("Stop", []any{})
+	m.StopFunc()
+}
+
+func (m *TradesStreamerMock) SetReconnectPolicy(policy investgo.ReconnectPolicy) {
+	if m.SetReconnectPolicyFunc == nil {
+		panic("TradesStreamerMock.SetReconnectPolicyFunc: method is nil but SetReconnectPolicy was just called")
+	}
+	m.record("SetReconnectPolicy", []any{policy})
+	m.SetReconnectPolicyFunc(policy)
+}
+
+func (m *TradesStreamerMock) SetWatchdog(opts investgo.WatchdogOptions) {
+	if m.SetWatchdogFunc == nil {
+		panic("TradesStreamerMock.SetWatchdogFunc: method is nil but SetWatchdog was just called")
+	}
+	m.record("SetWatchdog", []any{opts})
+	m.SetWatchdogFunc(opts)
+}
+
+func (m *TradesStreamerMock) Health() investgo.StreamHealth {
+	if m.HealthFunc == nil {
+		panic("TradesStreamerMock.HealthFunc: method is nil but Health was just called")
+	}
+	m.record("Health", []any{})
+	return m.HealthFunc()
+}
+
+func (m *TradesStreamerMock) SetRecorder(r *investgo.Recorder) {
+	if m.SetRecorderFunc == nil {
+		panic("TradesStreamerMock.SetRecorderFunc: method is nil but SetRecorder was just called")
+	}
+	m.record("SetRecorder", []any{r})
+	m.SetRecorderFunc(r)
+}
+
+func (m *TradesStreamerMock) record(method string, args []any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls - все вызовы методов мока в порядке вызова
+func (m *TradesStreamerMock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo - вызовы метода method в порядке вызова
+func (m *TradesStreamerMock) CallsTo(method string) []Call {
+	return filterCalls(m.Calls(), method)
+}
+
+// ServerSideStreamerMock - мок investgo.ServerSideStreamer, методы вызывают соответствующие поля <Method>Func
+type ServerSideStreamerMock struct {
+	CandlesFunc            func() <-chan *pb.Candle
+	OrderBooksFunc         func() <-chan *pb.OrderBook
+	TradesFunc             func() <-chan *pb.Trade
+	TradingStatusesFunc    func() <-chan *pb.TradingStatus
+	LastPricesFunc         func() <-chan *pb.LastPrice
+	FailedFunc             func() []*investgo.SubscriptionError
+	SetReconnectPolicyFunc func(p investgo.ReconnectPolicy)
+	ListenFunc             func() error
+	StopFunc               func()
+	SetWatchdogFunc        func(opts investgo.WatchdogOptions)
+	HealthFunc             func() investgo.StreamHealth
+	SetRecorderFunc        func(r *investgo.Recorder)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ investgo.ServerSideStreamer = (*ServerSideStreamerMock)(nil)
+
+func (m *ServerSideStreamerMock) Candles() <-chan *pb.Candle {
+	if m.CandlesFunc == nil {
+		panic("ServerSideStreamerMock.CandlesFunc: method is nil but Candles was just called")
+	}
+	m.record("Candles", []any{})
+	return m.CandlesFunc()
+}
+
+func (m *ServerSideStreamerMock) OrderBooks() <-chan *pb.OrderBook {
+	if m.OrderBooksFunc == nil {
+		panic("ServerSideStreamerMock.OrderBooksFunc: method is nil but OrderBooks was just called")
+	}
+	m.record("OrderBooks", []any{})
+	return m.OrderBooksFunc()
+}
+
+func (m *ServerSideStreamerMock) Trades() <-chan *pb.Trade {
+	if m.TradesFunc == nil {
+		panic("ServerSideStreamerMock.TradesFunc: method is nil but Trades was just called")
+	}
+	m.record("Trades", []any{})
+	return m.TradesFunc()
+}
+
+func (m *ServerSideStreamerMock) TradingStatuses() <-chan *pb.TradingStatus {
+	if m.TradingStatusesFunc == nil {
+		panic("ServerSideStreamerMock.TradingStatusesFunc: method is nil but TradingStatuses was just called")
+	}
+	m.record("TradingStatuses", []any{})
+	return m.TradingStatusesFunc()
+}
+
+func (m *ServerSideStreamerMock) LastPrices() <-chan *pb.LastPrice {
+	if m.LastPricesFunc == nil {
+		panic("ServerSideStreamerMock.LastPricesFunc: method is nil but LastPrices was just called")
+	}
+	m.record("LastPrices", []any{})
+	return m.LastPricesFunc()
+}
+
+func (m *ServerSideStreamerMock) Failed() []*investgo.SubscriptionError {
+	if m.FailedFunc == nil {
+		panic("ServerSideStreamerMock.FailedFunc: method is nil but Failed was just called")
+	}
+	m.record("Failed", []any{})
+	return m.FailedFunc()
+}
+
+func (m *ServerSideStreamerMock) SetReconnectPolicy(p investgo.ReconnectPolicy) {
+	if m.SetReconnectPolicyFunc == nil {
+		panic("ServerSideStreamerMock.SetReconnectPolicyFunc: method is nil but SetReconnectPolicy was just called")
+	}
+	m.record("SetReconnectPolicy", []any{p})
+	m.SetReconnectPolicyFunc(p)
+}
+
+func (m *ServerSideStreamerMock) Listen() error {
+	if m.ListenFunc == nil {
+		panic("ServerSideStreamerMock.ListenFunc: method is nil but Listen was just called")
+	}
+	m.record("Listen", []any{})
+	return m.ListenFunc()
+}
+
+func (m *ServerSideStreamerMock) Stop() {
+	if m.StopFunc == nil {
+		panic("ServerSideStreamerMock.StopFunc: method is nil but Stop was just called")
+	}
+	m.record("Stop", []any{})
+	m.StopFunc()
+}
+
+func (m *ServerSideStreamerMock) SetWatchdog(opts investgo.WatchdogOptions) {
+	if m.SetWatchdogFunc == nil {
+		panic("ServerSideStreamerMock.SetWatchdogFunc: method is nil but SetWatchdog was just called")
+	}
+	m.record("SetWatchdog", []any{opts})
+	m.SetWatchdogFunc(opts)
+}
+
+func (m *ServerSideStreamerMock) Health() investgo.StreamHealth {
+	if m.HealthFunc == nil {
+		panic("ServerSideStreamerMock.HealthFunc: method is nil but Health was just called")
+	}
+	m.record("Health", []any{})
+	return m.HealthFunc()
+}
+
+func (m *ServerSideStreamerMock) SetRecorder(r *investgo.Recorder) {
+	if m.SetRecorderFunc == nil {
+		panic("ServerSideStreamerMock.SetRecorderFunc: method is nil but SetRecorder was just called")
+	}
+	m.record("SetRecorder", []any{r})
+	m.SetRecorderFunc(r)
+}
+
+func (m *ServerSideStreamerMock) record(method string, args []any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls - все вызовы методов мока в порядке вызова
+func (m *ServerSideStreamerMock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo - вызовы метода method в порядке вызова
+func (m *ServerSideStreamerMock) CallsTo(method string) []Call {
+	return filterCalls(m.Calls(), method)
+}
+
+// MarketDataPoolerMock - мок investgo.MarketDataPooler, методы вызывают соответствующие поля <Method>Func
+type MarketDataPoolerMock struct {
+	SubscribeCandleFunc         func(ids []string, interval pb.SubscriptionInterval) (<-chan *pb.Candle, error)
+	SubscribeOrderBookFunc      func(ids []string, depth int32) (<-chan *pb.OrderBook, error)
+	SubscribeTradeFunc          func(ids []string) (<-chan *pb.Trade, error)
+	SubscribeInfoFunc           func(ids []string) (<-chan *pb.TradingStatus, error)
+	SubscribeLastPriceFunc      func(ids []string) (<-chan *pb.LastPrice, error)
+	SubscribeCandleAsyncFunc    func(ids []string, interval pb.SubscriptionInterval) (*investgo.SubscriptionFuture, error)
+	SubscribeOrderBookAsyncFunc func(ids []string, depth int32) (*investgo.SubscriptionFuture, error)
+	SubscribeTradeAsyncFunc     func(ids []string) (*investgo.SubscriptionFuture, error)
+	SubscribeInfoAsyncFunc      func(ids []string) (*investgo.SubscriptionFuture, error)
+	SubscribeLastPriceAsyncFunc func(ids []string) (*investgo.SubscriptionFuture, error)
+	UnSubscribeCandleFunc       func(ids []string) error
+	UnSubscribeOrderBookFunc    func(ids []string) error
+	UnSubscribeTradeFunc        func(ids []string) error
+	UnSubscribeInfoFunc         func(ids []string) error
+	UnSubscribeLastPriceFunc    func(ids []string) error
+	CandlesFunc                 func() <-chan *pb.Candle
+	OrderBooksFunc              func() <-chan *pb.OrderBook
+	TradesFunc                  func() <-chan *pb.Trade
+	TradingStatusesFunc         func() <-chan *pb.TradingStatus
+	LastPricesFunc              func() <-chan *pb.LastPrice
+	SetReconnectPolicyFunc      func(policy investgo.ReconnectPolicy)
+	SetWatchdogFunc             func(opts investgo.WatchdogOptions)
+	SetRecorderFunc             func(r *investgo.Recorder)
+	HealthFunc                  func() []investgo.StreamHealth
+	StreamLoadsFunc             func() []int
+	StopFunc                    func()
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ investgo.MarketDataPooler = (*MarketDataPoolerMock)(nil)
+
+func (m *MarketDataPoolerMock) SubscribeCandle(ids []string, interval pb.SubscriptionInterval) (<-chan *pb.Candle, error) {
+	if m.SubscribeCandleFunc == nil {
+		panic("MarketDataPoolerMock.SubscribeCandleFunc: method is nil but SubscribeCandle was just called")
+	}
+	m.record("SubscribeCandle", []any{ids, interval})
+	return m.SubscribeCandleFunc(ids, interval)
+}
+
+func (m *MarketDataPoolerMock) SubscribeOrderBook(ids []string, depth int32) (<-chan *pb.OrderBook, error) {
+	if m.SubscribeOrderBookFunc == nil {
+		panic("MarketDataPoolerMock.SubscribeOrderBookFunc: method is nil but SubscribeOrderBook was just called")
+	}
+	m.record("SubscribeOrderBook", []any{ids, depth})
+	return m.SubscribeOrderBookFunc(ids, depth)
+}
+
+func (m *MarketDataPoolerMock) SubscribeTrade(ids []string) (<-chan *pb.Trade, error) {
+	if m.SubscribeTradeFunc == nil {
+		panic("MarketDataPoolerMock.SubscribeTradeFunc: method is nil but SubscribeTrade was just called")
+	}
+	m.record("SubscribeTrade", []any{ids})
+	return m.SubscribeTradeFunc(ids)
+}
+
+func (m *MarketDataPoolerMock) SubscribeInfo(ids []string) (<-chan *pb.TradingStatus, error) {
+	if m.SubscribeInfoFunc == nil {
+		panic("MarketDataPoolerMock.SubscribeInfoFunc: method is nil but SubscribeInfo was just called")
+	}
+	m.record("SubscribeInfo", []any{ids})
+	return m.SubscribeInfoFunc(ids)
+}
+
+func (m *MarketDataPoolerMock) SubscribeLastPrice(ids []string) (<-chan *pb.LastPrice, error) {
+	if m.SubscribeLastPriceFunc == nil {
+		panic("MarketDataPoolerMock.SubscribeLastPriceFunc: method is nil but SubscribeLastPrice was just called")
+	}
+	m.record("SubscribeLastPrice", []any{ids})
+	return m.SubscribeLastPriceFunc(ids)
+}
+
+func (m *MarketDataPoolerMock) SubscribeCandleAsync(ids []string, interval pb.SubscriptionInterval) (*investgo.SubscriptionFuture, error) {
+	if m.SubscribeCandleAsyncFunc == nil {
+		panic("MarketDataPoolerMock.SubscribeCandleAsyncFunc: method is nil but SubscribeCandleAsync was just called")
+	}
+	m.record("SubscribeCandleAsync", []any{ids, interval})
+	return m.SubscribeCandleAsyncFunc(ids, interval)
+}
+
+func (m *MarketDataPoolerMock) SubscribeOrderBookAsync(ids []string, depth int32) (*investgo.SubscriptionFuture, error) {
+	if m.SubscribeOrderBookAsyncFunc == nil {
+		panic("MarketDataPoolerMock.SubscribeOrderBookAsyncFunc: method is nil but SubscribeOrderBookAsync was just called")
+	}
+	m.record("SubscribeOrderBookAsync", []any{ids, depth})
+	return m.SubscribeOrderBookAsyncFunc(ids, depth)
+}
+
+func (m *MarketDataPoolerMock) SubscribeTradeAsync(ids []string) (*investgo.SubscriptionFuture, error) {
+	if m.SubscribeTradeAsyncFunc == nil {
+		panic("MarketDataPoolerMock.SubscribeTradeAsyncFunc: method is nil but SubscribeTradeAsync was just called")
+	}
+	m.record("SubscribeTradeAsync", []any{ids})
+	return m.SubscribeTradeAsyncFunc(ids)
+}
+
+func (m *MarketDataPoolerMock) SubscribeInfoAsync(ids []string) (*investgo.SubscriptionFuture, error) {
+	if m.SubscribeInfoAsyncFunc == nil {
+		panic("MarketDataPoolerMock.SubscribeInfoAsyncFunc: method is nil but SubscribeInfoAsync was just called")
+	}
+	m.record("SubscribeInfoAsync", []any{ids})
+	return m.SubscribeInfoAsyncFunc(ids)
+}
+
+func (m *MarketDataPoolerMock) SubscribeLastPriceAsync(ids []string) (*investgo.SubscriptionFuture, error) {
+	if m.SubscribeLastPriceAsyncFunc == nil {
+		panic("MarketDataPoolerMock.SubscribeLastPriceAsyncFunc: method is nil but SubscribeLastPriceAsync was just called")
+	}
+	m.record("SubscribeLastPriceAsync", []any{ids})
+	return m.SubscribeLastPriceAsyncFunc(ids)
+}
+
+func (m *MarketDataPoolerMock) UnSubscribeCandle(ids []string) error {
+	if m.UnSubscribeCandleFunc == nil {
+		panic("MarketDataPoolerMock.UnSubscribeCandleFunc: method is nil but UnSubscribeCandle was just called")
+	}
+	m.record("UnSubscribeCandle", []any{ids})
+	return m.UnSubscribeCandleFunc(ids)
+}
+
+func (m *MarketDataPoolerMock) UnSubscribeOrderBook(ids []string) error {
+	if m.UnSubscribeOrderBookFunc == nil {
+		panic("MarketDataPoolerMock.UnSubscribeOrderBookFunc: method is nil but UnSubscribeOrderBook was just called")
+	}
+	m.record("UnSubscribeOrderBook", []any{ids})
+	return m.UnSubscribeOrderBookFunc(ids)
+}
+
+func (m *MarketDataPoolerMock) UnSubscribeTrade(ids []string) error {
+	if m.UnSubscribeTradeFunc == nil {
+		panic("MarketDataPoolerMock.UnSubscribeTradeFunc: method is nil but UnSubscribeTrade was just called")
+	}
+	m.record("UnSubscribeTrade", []any{ids})
+	return m.UnSubscribeTradeFunc(ids)
+}
+
+func (m *MarketDataPoolerMock) UnSubscribeInfo(ids []string) error {
+	if m.UnSubscribeInfoFunc == nil {
+		panic("MarketDataPoolerMock.UnSubscribeInfoFunc: method is nil but UnSubscribeInfo was just called")
+	}
+	m.record("UnSubscribeInfo", []any{ids})
+	return m.UnSubscribeInfoFunc(ids)
+}
+
+func (m *MarketDataPoolerMock) UnSubscribeLastPrice(ids []string) error {
+	if m.UnSubscribeLastPriceFunc == nil {
+		panic("MarketDataPoolerMock.UnSubscribeLastPriceFunc: method is nil but UnSubscribeLastPrice was just called")
+	}
+	m.record("UnSubscribeLastPrice", []any{ids})
+	return m.UnSubscribeLastPriceFunc(ids)
+}
+
+func (m *MarketDataPoolerMock) Candles() <-chan *pb.Candle {
+	if m.CandlesFunc == nil {
+		panic("MarketDataPoolerMock.CandlesFunc: method is nil but Candles was just called")
+	}
+	m.record("Candles", []any{})
+	return m.CandlesFunc()
+}
+
+func (m *MarketDataPoolerMock) OrderBooks() <-chan *pb.OrderBook {
+	if m.OrderBooksFunc == nil {
+		panic("MarketDataPoolerMock.OrderBooksFunc: method is nil but OrderBooks was just called")
+	}
+	m.record("OrderBooks", []any{})
+	return m.OrderBooksFunc()
+}
+
+func (m *MarketDataPoolerMock) Trades() <-chan *pb.Trade {
+	if m.TradesFunc == nil {
+		panic("MarketDataPoolerMock.TradesFunc: method is nil but Trades was just called")
+	}
+	m.record("Trades", []any{})
+	return m.TradesFunc()
+}
+
+func (m *MarketDataPoolerMock) TradingStatuses() <-chan *pb.TradingStatus {
+	if m.TradingStatusesFunc == nil {
+		panic("MarketDataPoolerMock.TradingStatusesFunc: method is nil but TradingStatuses was just called")
+	}
+	m.record("TradingStatuses", []any{})
+	return m.TradingStatusesFunc()
+}
+
+func (m *MarketDataPoolerMock) LastPrices() <-chan *pb.LastPrice {
+	if m.LastPricesFunc == nil {
+		panic("MarketDataPoolerMock.LastPricesFunc: method is nil but LastPrices was just called")
+	}
+	m.record("LastPrices", []any{})
+	return m.LastPricesFunc()
+}
+
+func (m *MarketDataPoolerMock) SetReconnectPolicy(policy investgo.ReconnectPolicy) {
+	if m.SetReconnectPolicyFunc == nil {
+		panic("MarketDataPoolerMock.SetReconnectPolicyFunc: method is nil but SetReconnectPolicy was just called")
+	}
+	m.record("SetReconnectPolicy", []any{policy})
+	m.SetReconnectPolicyFunc(policy)
+}
+
+func (m *MarketDataPoolerMock) SetWatchdog(opts investgo.WatchdogOptions) {
+	if m.SetWatchdogFunc == nil {
+		panic("MarketDataPoolerMock.SetWatchdogFunc: method is nil but SetWatchdog was just called")
+	}
+	m.record("SetWatchdog", []any{opts})
+	m.SetWatchdogFunc(opts)
+}
+
+func (m *MarketDataPoolerMock) SetRecorder(r *investgo.Recorder) {
+	if m.SetRecorderFunc == nil {
+		panic("MarketDataPoolerMock.SetRecorderFunc: method is nil but SetRecorder was just called")
+	}
+	m.record("SetRecorder", []any{r})
+	m.SetRecorderFunc(r)
+}
+
+func (m *MarketDataPoolerMock) Health() []investgo.StreamHealth {
+	if m.HealthFunc == nil {
+		panic("MarketDataPoolerMock.HealthFunc: method is nil but Health was just called")
+	}
+	m.record("Health", []any{})
+	return m.HealthFunc()
+}
+
+func (m *MarketDataPoolerMock) StreamLoads() []int {
+	if m.StreamLoadsFunc == nil {
+		panic("MarketDataPoolerMock.StreamLoadsFunc: method is nil but StreamLoads was just called")
+	}
+	m.record("StreamLoads", []any{})
+	return m.StreamLoadsFunc()
+}
+
+func (m *MarketDataPoolerMock) Stop() {
+	if m.StopFunc == nil {
+		panic("MarketDataPoolerMock.StopFunc: method is nil but Stop was just called")
+	}
+	m.record("Stop", []any{})
+	m.StopFunc()
+}
+
+func (m *MarketDataPoolerMock) record(method string, args []any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls - все вызовы методов мока в порядке вызова
+func (m *MarketDataPoolerMock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo - вызовы метода method в порядке вызова
+func (m *MarketDataPoolerMock) CallsTo(method string) []Call {
+	return filterCalls(m.Calls(), method)
+}
+
+// TraderMock - мок investgo.Trader, методы вызывают соответствующие поля <Method>Func
+type TraderMock struct {
+	PostOrderFunc                func(req *investgo.PostOrderRequest) (*investgo.PostOrderResponse, error)
+	PostOrderCtxFunc             func(ctx context.Context, req *investgo.PostOrderRequest) (*investgo.PostOrderResponse, error)
+	BuyFunc                      func(req *investgo.PostOrderRequestShort) (*investgo.PostOrderResponse, error)
+	BuyCtxFunc                   func(ctx context.Context, req *investgo.PostOrderRequestShort) (*investgo.PostOrderResponse, error)
+	SellFunc                     func(req *investgo.PostOrderRequestShort) (*investgo.PostOrderResponse, error)
+	SellCtxFunc                  func(ctx context.Context, req *investgo.PostOrderRequestShort) (*investgo.PostOrderResponse, error)
+	ReplaceOrderFunc             func(req *investgo.ReplaceOrderRequest) (*investgo.PostOrderResponse, error)
+	ReplaceOrderCtxFunc          func(ctx context.Context, req *investgo.ReplaceOrderRequest) (*investgo.PostOrderResponse, error)
+	CancelOrderFunc              func(accountId string, orderId string) (*investgo.CancelOrderResponse, error)
+	CancelOrderCtxFunc           func(ctx context.Context, accountId string, orderId string) (*investgo.CancelOrderResponse, error)
+	GetOrderStateFunc            func(accountId string, orderId string) (*investgo.GetOrderStateResponse, error)
+	GetOrderStateCtxFunc         func(ctx context.Context, accountId string, orderId string) (*investgo.GetOrderStateResponse, error)
+	GetOrdersFunc                func(accountId string) (*investgo.GetOrdersResponse, error)
+	GetOrdersCtxFunc             func(ctx context.Context, accountId string) (*investgo.GetOrdersResponse, error)
+	GetPositionsFunc             func(accountId string) (*investgo.PositionsResponse, error)
+	GetPositionsCtxFunc          func(ctx context.Context, accountId string) (*investgo.PositionsResponse, error)
+	GetPortfolioFunc             func(accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*investgo.PortfolioResponse, error)
+	GetPortfolioCtxFunc          func(ctx context.Context, accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*investgo.PortfolioResponse, error)
+	GetOperationsFunc            func(req *investgo.GetOperationsRequest) (*investgo.OperationsResponse, error)
+	GetOperationsCtxFunc         func(ctx context.Context, req *investgo.GetOperationsRequest) (*investgo.OperationsResponse, error)
+	GetOperationsByCursorFunc    func(req *investgo.GetOperationsByCursorRequest) (*investgo.GetOperationsByCursorResponse, error)
+	GetOperationsByCursorCtxFunc func(ctx context.Context, req *investgo.GetOperationsByCursorRequest) (*investgo.GetOperationsByCursorResponse, error)
+	GetWithdrawLimitsFunc        func(accountId string) (*investgo.WithdrawLimitsResponse, error)
+	GetWithdrawLimitsCtxFunc     func(ctx context.Context, accountId string) (*investgo.WithdrawLimitsResponse, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ investgo.Trader = (*TraderMock)(nil)
+
+func (m *TraderMock) PostOrder(req *investgo.PostOrderRequest) (*investgo.PostOrderResponse, error) {
+	if m.PostOrderFunc == nil {
+		panic("TraderMock.PostOrderFunc: method is nil but PostOrder was just called")
+	}
+	m.record("PostOrder", []any{req})
+	return m.PostOrderFunc(req)
+}
+
+func (m *TraderMock) PostOrderCtx(ctx context.Context, req *investgo.PostOrderRequest) (*investgo.PostOrderResponse, error) {
+	if m.PostOrderCtxFunc == nil {
+		panic("TraderMock.PostOrderCtxFunc: method is nil but PostOrderCtx was just called")
+	}
+	m.record("PostOrderCtx", []any{ctx, req})
+	return m.PostOrderCtxFunc(ctx, req)
+}
+
+func (m *TraderMock) Buy(req *investgo.PostOrderRequestShort) (*investgo.PostOrderResponse, error) {
+	if m.BuyFunc == nil {
+		panic("TraderMock.BuyFunc: method is nil but Buy was just called")
+	}
+	m.record("Buy", []any{req})
+	return m.BuyFunc(req)
+}
+
+func (m *TraderMock) BuyCtx(ctx context.Context, req *investgo.PostOrderRequestShort) (*investgo.PostOrderResponse, error) {
+	if m.BuyCtxFunc == nil {
+		panic("TraderMock.BuyCtxFunc: method is nil but BuyCtx was just called")
+	}
+	m.record("BuyCtx", []any{ctx, req})
+	return m.BuyCtxFunc(ctx, req)
+}
+
+func (m *TraderMock) Sell(req *investgo.PostOrderRequestShort) (*investgo.PostOrderResponse, error) {
+	if m.SellFunc == nil {
+		panic("TraderMock.SellFunc: method is nil but Sell was just called")
+	}
+	m.record("Sell", []any{req})
+	return m.SellFunc(req)
+}
+
+func (m *TraderMock) SellCtx(ctx context.Context, req *investgo.PostOrderRequestShort) (*investgo.PostOrderResponse, error) {
+	if m.SellCtxFunc == nil {
+		panic("TraderMock.SellCtxFunc: method is nil but SellCtx was just called")
+	}
+	m.record("SellCtx", []any{ctx, req})
+	return m.SellCtxFunc(ctx, req)
+}
+
+func (m *TraderMock) ReplaceOrder(req *investgo.ReplaceOrderRequest) (*investgo.PostOrderResponse, error) {
+	if m.ReplaceOrderFunc == nil {
+		panic("TraderMock.ReplaceOrderFunc: method is nil but ReplaceOrder was just called")
+	}
+	m.record("ReplaceOrder", []any{req})
+	return m.ReplaceOrderFunc(req)
+}
+
+func (m *TraderMock) ReplaceOrderCtx(ctx context.Context, req *investgo.ReplaceOrderRequest) (*investgo.PostOrderResponse, error) {
+	if m.ReplaceOrderCtxFunc == nil {
+		panic("TraderMock.ReplaceOrderCtxFunc: method is nil but ReplaceOrderCtx was just called")
+	}
+	m.record("ReplaceOrderCtx", []any{ctx, req})
+	return m.ReplaceOrderCtxFunc(ctx, req)
+}
+
+func (m *TraderMock) CancelOrder(accountId string, orderId string) (*investgo.CancelOrderResponse, error) {
+	if m.CancelOrderFunc == nil {
+		panic("TraderMock.CancelOrderFunc: method is nil but CancelOrder was just called")
+	}
+	m.record("CancelOrder", []any{accountId, orderId})
+	return m.CancelOrderFunc(accountId, orderId)
+}
+
+func (m *TraderMock) CancelOrderCtx(ctx context.Context, accountId string, orderId string) (*investgo.CancelOrderResponse, error) {
+	if m.CancelOrderCtxFunc == nil {
+		panic("TraderMock.CancelOrderCtxFunc: method is nil but CancelOrderCtx was just called")
+	}
+	m.record("CancelOrderCtx", []any{ctx, accountId, orderId})
+	return m.CancelOrderCtxFunc(ctx, accountId, orderId)
+}
+
+func (m *TraderMock) GetOrderState(accountId string, orderId string) (*investgo.GetOrderStateResponse, error) {
+	if m.GetOrderStateFunc == nil {
+		panic("TraderMock.GetOrderStateFunc: method is nil but GetOrderState was just called")
+	}
+	m.record("GetOrderState", []any{accountId, orderId})
+	return m.GetOrderStateFunc(accountId, orderId)
+}
+
+func (m *TraderMock) GetOrderStateCtx(ctx context.Context, accountId string, orderId string) (*investgo.GetOrderStateResponse, error) {
+	if m.GetOrderStateCtxFunc == nil {
+		panic("TraderMock.GetOrderStateCtxFunc: method is nil but GetOrderStateCtx was just called")
+	}
+	m.record("GetOrderStateCtx", []any{ctx, accountId, orderId})
+	return m.GetOrderStateCtxFunc(ctx, accountId, orderId)
+}
+
+func (m *TraderMock) GetOrders(accountId string) (*investgo.GetOrdersResponse, error) {
+	if m.GetOrdersFunc == nil {
+		panic("TraderMock.GetOrdersFunc: method is nil but GetOrders was just called")
+	}
+	m.record("GetOrders", []any{accountId})
+	return m.GetOrdersFunc(accountId)
+}
+
+func (m *TraderMock) GetOrdersCtx(ctx context.Context, accountId string) (*investgo.GetOrdersResponse, error) {
+	if m.GetOrdersCtxFunc == nil {
+		panic("TraderMock.GetOrdersCtxFunc: method is nil but GetOrdersCtx was just called")
+	}
+	m.record("GetOrdersCtx", []any{ctx, accountId})
+	return m.GetOrdersCtxFunc(ctx, accountId)
+}
+
+func (m *TraderMock) GetPositions(accountId string) (*investgo.PositionsResponse, error) {
+	if m.GetPositionsFunc == nil {
+		panic("TraderMock.GetPositionsFunc: method is nil but GetPositions was just called")
+	}
+	m.record("GetPositions", []any{accountId})
+	return m.GetPositionsFunc(accountId)
+}
+
+func (m *TraderMock) GetPositionsCtx(ctx context.Context, accountId string) (*investgo.PositionsResponse, error) {
+	if m.GetPositionsCtxFunc == nil {
+		panic("TraderMock.GetPositionsCtxFunc: method is nil but GetPositionsCtx was just called")
+	}
+	m.record("GetPositionsCtx", []any{ctx, accountId})
+	return m.GetPositionsCtxFunc(ctx, accountId)
+}
+
+func (m *TraderMock) GetPortfolio(accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*investgo.PortfolioResponse, error) {
+	if m.GetPortfolioFunc == nil {
+		panic("TraderMock.GetPortfolioFunc: method is nil but GetPortfolio was just called")
+	}
+	m.record("GetPortfolio", []any{accountId, currency})
+	return m.GetPortfolioFunc(accountId, currency)
+}
+
+func (m *TraderMock) GetPortfolioCtx(ctx context.Context, accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*investgo.PortfolioResponse, error) {
+	if m.GetPortfolioCtxFunc == nil {
+		panic("TraderMock.GetPortfolioCtxFunc: method is nil but GetPortfolioCtx was just called")
+	}
+	m.record("GetPortfolioCtx", []any{ctx, accountId, currency})
+	return m.GetPortfolioCtxFunc(ctx, accountId, currency)
+}
+
+func (m *TraderMock) GetOperations(req *investgo.GetOperationsRequest) (*investgo.OperationsResponse, error) {
+	if m.GetOperationsFunc == nil {
+		panic("TraderMock.GetOperationsFunc: method is nil but GetOperations was just called")
+	}
+	m.record("GetOperations", []any{req})
+	return m.GetOperationsFunc(req)
+}
+
+func (m *TraderMock) GetOperationsCtx(ctx context.Context, req *investgo.GetOperationsRequest) (*investgo.OperationsResponse, error) {
+	if m.GetOperationsCtxFunc == nil {
+		panic("TraderMock.GetOperationsCtxFunc: method is nil but GetOperationsCtx was just called")
+	}
+	m.record("GetOperationsCtx", []any{ctx, req})
+	return m.GetOperationsCtxFunc(ctx, req)
+}
+
+func (m *TraderMock) GetOperationsByCursor(req *investgo.GetOperationsByCursorRequest) (*investgo.GetOperationsByCursorResponse, error) {
+	if m.GetOperationsByCursorFunc == nil {
+		panic("TraderMock.GetOperationsByCursorFunc: method is nil but GetOperationsByCursor was just called")
+	}
+	m.record("GetOperationsByCursor", []any{req})
+	return m.GetOperationsByCursorFunc(req)
+}
+
+func (m *TraderMock) GetOperationsByCursorCtx(ctx context.Context, req *investgo.GetOperationsByCursorRequest) (*investgo.GetOperationsByCursorResponse, error) {
+	if m.GetOperationsByCursorCtxFunc == nil {
+		panic("TraderMock.GetOperationsByCursorCtxFunc: method is nil but GetOperationsByCursorCtx was just called")
+	}
+	m.record("GetOperationsByCursorCtx", []any{ctx, req})
+	return m.GetOperationsByCursorCtxFunc(ctx, req)
+}
+
+func (m *TraderMock) GetWithdrawLimits(accountId string) (*investgo.WithdrawLimitsResponse, error) {
+	if m.GetWithdrawLimitsFunc == nil {
+		panic("TraderMock.GetWithdrawLimitsFunc: method is nil but GetWithdrawLimits was just called")
+	}
+	m.record("GetWithdrawLimits", []any{accountId})
+	return m.GetWithdrawLimitsFunc(accountId)
+}
+
+func (m *TraderMock) GetWithdrawLimitsCtx(ctx context.Context, accountId string) (*investgo.WithdrawLimitsResponse, error) {
+	if m.GetWithdrawLimitsCtxFunc == nil {
+		panic("TraderMock.GetWithdrawLimitsCtxFunc: method is nil but GetWithdrawLimitsCtx was just called")
+	}
+	m.record("GetWithdrawLimitsCtx", []any{ctx, accountId})
+	return m.GetWithdrawLimitsCtxFunc(ctx, accountId)
+}
+
+func (m *TraderMock) record(method string, args []any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls - все вызовы методов мока в порядке вызова
+func (m *TraderMock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo - вызовы метода method в порядке вызова
+func (m *TraderMock) CallsTo(method string) []Call {
+	return filterCalls(m.Calls(), method)
+}
@@ -0,0 +1,80 @@
+package investgotest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/therox/invest-api-go-sdk/investgo"
+	"google.golang.org/grpc"
+)
+
+// Config - конфигурация клиента для подключения к серверу
+func (s *Server) Config() investgo.Config {
+	return investgo.Config{
+		EndPoint:    "bufnet",
+		Token:       "test-token",
+		AppName:     "investgotest",
+		AccountId:   "test-account",
+		Insecure:    true,
+		DialOptions: []grpc.DialOption{s.DialOption()},
+	}
+}
+
+// Client - клиент SDK, подключенный к серверу
+func (s *Server) Client(ctx context.Context, l investgo.Logger) (*investgo.Client, error) {
+	return investgo.NewClient(ctx, s.Config(), l)
+}
+
+// NewClient - запуск сервера и подключенный к нему клиент SDK, сервер и клиент останавливаются
+// по завершении теста
+func NewClient(tb testing.TB) (*investgo.Client, *Server) {
+	tb.Helper()
+	s := NewServer()
+	tb.Cleanup(s.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	tb.Cleanup(cancel)
+	client, err := s.Client(ctx, NewLogger(tb))
+	if err != nil {
+		tb.Fatalf("investgotest: create client: %v", err)
+	}
+	tb.Cleanup(func() {
+		if err := client.Stop(); err != nil {
+			tb.Logf("investgotest: stop client: %v", err)
+		}
+	})
+	return client, s
+}
+
+// testLogger - логгер SDK, пишущий в лог теста
+type testLogger struct {
+	tb testing.TB
+}
+
+// NewLogger - логгер SDK, пишущий в лог теста
+func NewLogger(tb testing.TB) investgo.Logger {
+	return testLogger{tb: tb}
+}
+
+func (l testLogger) Infof(template string, args ...any) {
+	l.tb.Logf(template, args...)
+}
+
+func (l testLogger) Errorf(template string, args ...any) {
+	l.tb.Logf("ERROR "+template, args...)
+}
+
+func (l testLogger) Fatalf(template string, args ...any) {
+	l.tb.Fatalf(template, args...)
+}
+
+// NopLogger - логгер SDK без вывода
+type NopLogger struct{}
+
+func (NopLogger) Infof(string, ...any) {}
+
+func (NopLogger) Errorf(string, ...any) {}
+
+func (NopLogger) Fatalf(template string, args ...any) {
+	panic(fmt.Sprintf(template, args...))
+}
@@ -0,0 +1,285 @@
+// Package investgotest - in-memory сервер INVEST API для тестов клиентского кода.
+//
+// Сервер реализует все сервисы из пакета proto поверх bufconn. Unary-методы по умолчанию
+// возвращают пустой ответ нужного типа, ответы и ошибки задаются через Handle, SetResponse и InjectError.
+// Данные в стримы отправляются методами Push*.
+package investgotest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	pb "github.com/therox/invest-api-go-sdk/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const bufSize = 1024 * 1024
+
+// Имена стрим-методов для InjectError и StreamCount
+const (
+	MarketDataStream           = "MarketDataStreamService/MarketDataStream"
+	MarketDataServerSideStream = "MarketDataStreamService/MarketDataServerSideStream"
+	PortfolioStream            = "OperationsStreamService/PortfolioStream"
+	PositionsStream            = "OperationsStreamService/PositionsStream"
+	TradesStream               = "OrdersStreamService/TradesStream"
+)
+
+// UnaryHandler - обработчик unary-метода, должен вернуть ответ типа, объявленного в proto
+type UnaryHandler func(ctx context.Context, req proto.Message) (proto.Message, error)
+
+// Server - in-memory сервер INVEST API
+type Server struct {
+	listener   *bufconn.Listener
+	grpcServer *grpc.Server
+
+	mu        sync.Mutex
+	handlers  map[string]UnaryHandler
+	responses map[string]proto.Message
+	errors    map[string]*injectedError
+	requests  map[string][]proto.Message
+	statuses  map[string]pb.SubscriptionStatus
+
+	marketData *streamSet[*pb.MarketDataResponse]
+	portfolios *streamSet[*pb.PortfolioStreamResponse]
+	positions  *streamSet[*pb.PositionsStreamResponse]
+	trades     *streamSet[*pb.TradesStreamResponse]
+}
+
+type injectedError struct {
+	err     error
+	message string
+	times   int
+}
+
+// NewServer - создание и запуск сервера, по завершении теста нужно вызвать Close
+func NewServer() *Server {
+	s := &Server{
+		listener:   bufconn.Listen(bufSize),
+		handlers:   make(map[string]UnaryHandler, 0),
+		responses:  make(map[string]proto.Message, 0),
+		errors:     make(map[string]*injectedError, 0),
+		requests:   make(map[string][]proto.Message, 0),
+		statuses:   make(map[string]pb.SubscriptionStatus, 0),
+		marketData: newStreamSet[*pb.MarketDataResponse](),
+		portfolios: newStreamSet[*pb.PortfolioStreamResponse](),
+		positions:  newStreamSet[*pb.PositionsStreamResponse](),
+		trades:     newStreamSet[*pb.TradesStreamResponse](),
+	}
+	s.grpcServer = grpc.NewServer(
+		grpc.UnaryInterceptor(s.unaryInterceptor),
+		grpc.StreamInterceptor(s.streamInterceptor))
+
+	pb.RegisterInstrumentsServiceServer(s.grpcServer, pb.UnimplementedInstrumentsServiceServer{})
+	pb.RegisterMarketDataServiceServer(s.grpcServer, pb.UnimplementedMarketDataServiceServer{})
+	pb.RegisterOperationsServiceServer(s.grpcServer, pb.UnimplementedOperationsServiceServer{})
+	pb.RegisterOrdersServiceServer(s.grpcServer, pb.UnimplementedOrdersServiceServer{})
+	pb.RegisterStopOrdersServiceServer(s.grpcServer, pb.UnimplementedStopOrdersServiceServer{})
+	pb.RegisterUsersServiceServer(s.grpcServer, pb.UnimplementedUsersServiceServer{})
+	pb.RegisterSandboxServiceServer(s.grpcServer, pb.UnimplementedSandboxServiceServer{})
+	pb.RegisterMarketDataStreamServiceServer(s.grpcServer, &marketDataStreamServer{s: s})
+	pb.RegisterOperationsStreamServiceServer(s.grpcServer, &operationsStreamServer{s: s})
+	pb.RegisterOrdersStreamServiceServer(s.grpcServer, &ordersStreamServer{s: s})
+
+	go func() {
+		_ = s.grpcServer.Serve(s.listener)
+	}()
+	return s
+}
+
+// Close - остановка сервера, открытые стримы завершаются
+func (s *Server) Close() {
+	s.grpcServer.Stop()
+	_ = s.listener.Close()
+}
+
+// DialOption - опция grpc для подключения к серверу
+func (s *Server) DialOption() grpc.DialOption {
+	return grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return s.listener.DialContext(ctx)
+	})
+}
+
+// Dial - grpc соединение с сервером
+func (s *Server) Dial(ctx context.Context) (*grpc.ClientConn, error) {
+	return grpc.DialContext(ctx, "bufnet", s.DialOption(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
+
+// Handle - задание обработчика unary-метода. method - имя вида "OrdersService/PostOrder"
+// или полное имя grpc метода
+func (s *Server) Handle(method string, h UnaryHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[methodKey(method)] = h
+}
+
+// SetResponse - задание фиксированного ответа unary-метода
+func (s *Server) SetResponse(method string, resp proto.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[methodKey(method)] = resp
+}
+
+// InjectError - следующие times вызовов метода вернут err, times <= 0 - до вызова ClearError.
+// Для стрим-методов ошибка возвращается при открытии стрима
+func (s *Server) InjectError(method string, err error, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors[methodKey(method)] = &injectedError{err: err, times: times}
+}
+
+// InjectAPIError - ошибка в формате INVEST API: код ошибки в статусе grpc и описание в трейлере message
+func (s *Server) InjectAPIError(method string, code codes.Code, apiCode int, message string, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors[methodKey(method)] = &injectedError{
+		err:     status.Error(code, strconv.Itoa(apiCode)),
+		message: message,
+		times:   times,
+	}
+}
+
+// ClearError - отмена ошибки, заданной InjectError
+func (s *Server) ClearError(method string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.errors, methodKey(method))
+}
+
+// Requests - запросы, полученные методом, для стрима маркетдаты - отправленные клиентом MarketDataRequest
+func (s *Server) Requests(method string) []proto.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reqs := s.requests[methodKey(method)]
+	return append(make([]proto.Message, 0, len(reqs)), reqs...)
+}
+
+// SetSubscriptionStatus - статус, возвращаемый на подписку по инструменту в стриме маркетдаты,
+// по умолчанию SUBSCRIPTION_STATUS_SUCCESS
+func (s *Server) SetSubscriptionStatus(instrumentId string, st pb.SubscriptionStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses[instrumentId] = st
+}
+
+func (s *Server) subscriptionStatus(instrumentId string) pb.SubscriptionStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.statuses[instrumentId]; ok {
+		return st
+	}
+	return pb.SubscriptionStatus_SUBSCRIPTION_STATUS_SUCCESS
+}
+
+func (s *Server) record(key string, req proto.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests[key] = append(s.requests[key], req)
+}
+
+// takeError - ошибка для вызова метода, если она была задана
+func (s *Server) takeError(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inj, ok := s.errors[key]
+	if !ok {
+		return nil
+	}
+	if inj.times > 0 {
+		inj.times--
+		if inj.times == 0 {
+			delete(s.errors, key)
+		}
+	}
+	md := metadata.Pairs("x-tracking-id", uuid.NewString())
+	if inj.message != "" {
+		md.Set("message", inj.message)
+	}
+	_ = grpc.SetTrailer(ctx, md)
+	return inj.err
+}
+
+func (s *Server) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, _ grpc.UnaryHandler) (interface{}, error) {
+	key := methodKey(info.FullMethod)
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "unexpected request type %T", req)
+	}
+	s.record(key, msg)
+	if err := s.takeError(ctx, key); err != nil {
+		return nil, err
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs("x-tracking-id", uuid.NewString()))
+
+	s.mu.Lock()
+	h := s.handlers[key]
+	resp := s.responses[key]
+	s.mu.Unlock()
+	switch {
+	case h != nil:
+		return h(ctx, msg)
+	case resp != nil:
+		return proto.Clone(resp), nil
+	default:
+		return emptyResponse(info.FullMethod)
+	}
+}
+
+func (s *Server) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.takeError(ss.Context(), methodKey(info.FullMethod)); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// methodKey - приведение имени метода к виду Service/Method
+func methodKey(method string) string {
+	m := strings.TrimPrefix(method, "/")
+	i := strings.LastIndex(m, "/")
+	if i < 0 {
+		return m
+	}
+	service := m[:i]
+	if j := strings.LastIndex(service, "."); j >= 0 {
+		service = service[j+1:]
+	}
+	return service + m[i:]
+}
+
+// emptyResponse - пустой ответ типа, объявленного для метода в proto
+func emptyResponse(fullMethod string) (proto.Message, error) {
+	m := strings.TrimPrefix(fullMethod, "/")
+	i := strings.LastIndex(m, "/")
+	if i < 0 {
+		return nil, status.Errorf(codes.Unimplemented, "unknown method %v", fullMethod)
+	}
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(m[:i]))
+	if err != nil {
+		return nil, status.Errorf(codes.Unimplemented, "unknown service %v", m[:i])
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "unknown service %v", m[:i])
+	}
+	md := sd.Methods().ByName(protoreflect.Name(m[i+1:]))
+	if md == nil {
+		return nil, status.Errorf(codes.Unimplemented, "unknown method %v", fullMethod)
+	}
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(md.Output().FullName())
+	if err != nil {
+		return nil, status.Error(codes.Internal, fmt.Sprintf("response type %v: %v", md.Output().FullName(), err))
+	}
+	return mt.New().Interface(), nil
+}
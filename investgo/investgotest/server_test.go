@@ -0,0 +1,130 @@
+package investgotest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/therox/invest-api-go-sdk/investgo"
+	"github.com/therox/invest-api-go-sdk/investgo/investgotest"
+	pb "github.com/therox/invest-api-go-sdk/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestServerUnaryFixtures(t *testing.T) {
+	client, server := investgotest.NewClient(t)
+	users := client.NewUsersServiceClient()
+	md := client.NewMarketDataServiceClient()
+
+	// без фикстуры возвращается пустой ответ
+	accounts, err := users.GetAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts.GetAccounts()) != 0 {
+		t.Errorf("accounts %v without fixture", accounts.GetAccounts())
+	}
+
+	server.SetResponse("UsersService/GetAccounts", &pb.GetAccountsResponse{
+		Accounts: []*pb.Account{{Id: "account"}},
+	})
+	accounts, err = users.GetAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts.GetAccounts()) != 1 || accounts.GetAccounts()[0].GetId() != "account" {
+		t.Errorf("accounts %v, want fixture", accounts.GetAccounts())
+	}
+	if investgo.TrackingIdFromHeader(accounts.Header) == "" {
+		t.Error("response has no tracking id")
+	}
+
+	server.Handle("/tinkoff.public.invest.api.contract.v1.MarketDataService/GetLastPrices",
+		func(ctx context.Context, req proto.Message) (proto.Message, error) {
+			resp := &pb.GetLastPricesResponse{}
+			for _, id := range req.(*pb.GetLastPricesRequest).GetInstrumentId() {
+				resp.LastPrices = append(resp.LastPrices, &pb.LastPrice{Figi: id, Price: &pb.Quotation{Units: 10}})
+			}
+			return resp, nil
+		})
+	prices, err := md.GetLastPrices([]string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prices.GetLastPrices()) != 2 || prices.GetLastPrices()[1].GetFigi() != "b" {
+		t.Errorf("last prices %v, want a and b", prices.GetLastPrices())
+	}
+
+	reqs := server.Requests("MarketDataService/GetLastPrices")
+	if len(reqs) != 1 || len(reqs[0].(*pb.GetLastPricesRequest).GetInstrumentId()) != 2 {
+		t.Errorf("requests %v, want one request for 2 instruments", reqs)
+	}
+	if n := len(server.Requests("UsersService/GetAccounts")); n != 2 {
+		t.Errorf("%v GetAccounts requests, want 2", n)
+	}
+}
+
+func TestServerInjectedErrors(t *testing.T) {
+	client, server := investgotest.NewClient(t)
+	users := client.NewUsersServiceClient()
+
+	server.InjectAPIError("UsersService/GetInfo", codes.PermissionDenied, 40003, "no access", 1)
+	_, err := users.GetInfo()
+	apiErr, ok := investgo.AsAPIError(err)
+	if !ok {
+		t.Fatalf("error %v, want APIError", err)
+	}
+	if apiErr.Code != 40003 || apiErr.Status != codes.PermissionDenied || apiErr.Message != "no access" || apiErr.TrackingId == "" {
+		t.Errorf("api error %+v", apiErr)
+	}
+	// ошибка задана на один вызов
+	if _, err := users.GetInfo(); err != nil {
+		t.Errorf("second call error %v", err)
+	}
+
+	server.InjectError("UsersService/GetUserTariff", status.Error(codes.NotFound, "not found"), 0)
+	for i := 0; i < 2; i++ {
+		if _, err := users.GetUserTariff(); status.Code(err) != codes.NotFound {
+			t.Errorf("call %v: error %v, want NotFound until ClearError", i, err)
+		}
+	}
+	server.ClearError("UsersService/GetUserTariff")
+	if _, err := users.GetUserTariff(); err != nil {
+		t.Errorf("error %v after ClearError", err)
+	}
+}
+
+func TestServerRetriedUnavailable(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	server := investgotest.NewServer()
+	defer server.Close()
+	cfg := server.Config()
+	cfg.RetryPolicy = investgo.RetryConfig{MaxAttempts: 3, InitialBackoff: 10 * time.Millisecond}
+	client, err := investgo.NewClient(ctx, cfg, investgotest.NopLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Stop()
+	users := client.NewUsersServiceClient()
+
+	// две ошибки UNAVAILABLE повторяются grpc, третья попытка успешна
+	server.InjectError("UsersService/GetAccounts", status.Error(codes.Unavailable, "unavailable"), 2)
+	if _, err := users.GetAccountsCtx(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(server.Requests("UsersService/GetAccounts")); n != 3 {
+		t.Errorf("%v attempts, want 3", n)
+	}
+
+	server.InjectError("UsersService/GetAccounts", status.Error(codes.Unavailable, "unavailable"), 0)
+	_, err = users.GetAccountsCtx(ctx)
+	if status.Code(err) != codes.Unavailable || !investgo.IsRetryable(err) {
+		t.Errorf("error %v after retries, want retryable UNAVAILABLE", err)
+	}
+	if n := len(server.Requests("UsersService/GetAccounts")); n != 6 {
+		t.Errorf("%v attempts, want 3 more for the failed call", n)
+	}
+}
@@ -0,0 +1,484 @@
+package investgotest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/google/uuid"
+	pb "github.com/therox/invest-api-go-sdk/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const streamBufSize = 64
+
+// streamConn - открытый клиентом стрим, сообщения отправляются одной горутиной обработчика
+type streamConn[T any] struct {
+	ctx context.Context
+	out chan T
+	brk chan error
+}
+
+func (c *streamConn[T]) send(msg T) {
+	select {
+	case c.out <- msg:
+	case <-c.ctx.Done():
+	}
+}
+
+func (c *streamConn[T]) stop(err error) {
+	select {
+	case c.brk <- err:
+	default:
+	}
+}
+
+// serve - отправка сообщений в стрим до отмены контекста или вызова stop
+func (c *streamConn[T]) serve(send func(T) error) error {
+	for {
+		select {
+		case msg := <-c.out:
+			if err := send(msg); err != nil {
+				return err
+			}
+		case err := <-c.brk:
+			return err
+		case <-c.ctx.Done():
+			return nil
+		}
+	}
+}
+
+// streamSet - открытые стримы одного типа
+type streamSet[T any] struct {
+	mu      sync.Mutex
+	conns   map[*streamConn[T]]struct{}
+	changed chan struct{}
+}
+
+func newStreamSet[T any]() *streamSet[T] {
+	return &streamSet[T]{
+		conns:   make(map[*streamConn[T]]struct{}, 0),
+		changed: make(chan struct{}),
+	}
+}
+
+func (s *streamSet[T]) open(ctx context.Context) *streamConn[T] {
+	c := &streamConn[T]{
+		ctx: ctx,
+		out: make(chan T, streamBufSize),
+		brk: make(chan error, 1),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conns[c] = struct{}{}
+	s.notify()
+	return c
+}
+
+func (s *streamSet[T]) close(c *streamConn[T]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.conns, c)
+	s.notify()
+}
+
+// notify - сигнал ожидающим в WaitStreams, вызывается под s.mu
+func (s *streamSet[T]) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *streamSet[T]) list() []*streamConn[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conns := make([]*streamConn[T], 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	return conns
+}
+
+func (s *streamSet[T]) broadcast(msg T) {
+	for _, c := range s.list() {
+		c.send(msg)
+	}
+}
+
+func (s *streamSet[T]) stopAll(err error) {
+	for _, c := range s.list() {
+		c.stop(err)
+	}
+}
+
+func (s *streamSet[T]) state() (int, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.conns), s.changed
+}
+
+// streamCounter - общий интерфейс streamSet для StreamCount и WaitStreams
+type streamCounter interface {
+	state() (int, <-chan struct{})
+}
+
+func (s *Server) streamSet(method string) (streamCounter, error) {
+	switch methodKey(method) {
+	case MarketDataStream, MarketDataServerSideStream:
+		return s.marketData, nil
+	case PortfolioStream:
+		return s.portfolios, nil
+	case PositionsStream:
+		return s.positions, nil
+	case TradesStream:
+		return s.trades, nil
+	default:
+		return nil, fmt.Errorf("unknown stream method %v", method)
+	}
+}
+
+// StreamCount - количество открытых стримов, стримы маркетдаты (bidirectional и server-side) считаются вместе
+func (s *Server) StreamCount(method string) int {
+	set, err := s.streamSet(method)
+	if err != nil {
+		return 0
+	}
+	n, _ := set.state()
+	return n
+}
+
+// WaitStreams - ожидание, пока количество открытых стримов не станет равным n
+func (s *Server) WaitStreams(ctx context.Context, method string, n int) error {
+	set, err := s.streamSet(method)
+	if err != nil {
+		return err
+	}
+	for {
+		count, changed := set.state()
+		if count == n {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return fmt.Errorf("wait %v streams of %v, open %v: %w", n, method, count, ctx.Err())
+		}
+	}
+}
+
+// BreakStreams - завершение всех открытых стримов с ошибкой err, например status.Error(codes.Unavailable, "")
+// для проверки переподключения
+func (s *Server) BreakStreams(err error) {
+	s.marketData.stopAll(err)
+	s.portfolios.stopAll(err)
+	s.positions.stopAll(err)
+	s.trades.stopAll(err)
+}
+
+// PushMarketData - отправка сообщений во все открытые стримы маркетдаты
+func (s *Server) PushMarketData(resp ...*pb.MarketDataResponse) {
+	for _, r := range resp {
+		s.marketData.broadcast(r)
+	}
+}
+
+// PushCandle - отправка свечи во все открытые стримы маркетдаты
+func (s *Server) PushCandle(c *pb.Candle) {
+	s.PushMarketData(&pb.MarketDataResponse{Payload: &pb.MarketDataResponse_Candle{Candle: c}})
+}
+
+// PushTrade - отправка сделки во все открытые стримы маркетдаты
+func (s *Server) PushTrade(t *pb.Trade) {
+	s.PushMarketData(&pb.MarketDataResponse{Payload: &pb.MarketDataResponse_Trade{Trade: t}})
+}
+
+// PushOrderBook - отправка стакана во все открытые стримы маркетдаты
+func (s *Server) PushOrderBook(ob *pb.OrderBook) {
+	s.PushMarketData(&pb.MarketDataResponse{Payload: &pb.MarketDataResponse_Orderbook{Orderbook: ob}})
+}
+
+// PushLastPrice - отправка последней цены во все открытые стримы маркетдаты
+func (s *Server) PushLastPrice(lp *pb.LastPrice) {
+	s.PushMarketData(&pb.MarketDataResponse{Payload: &pb.MarketDataResponse_LastPrice{LastPrice: lp}})
+}
+
+// PushTradingStatus - отправка торгового статуса во все открытые стримы маркетдаты
+func (s *Server) PushTradingStatus(ts *pb.TradingStatus) {
+	s.PushMarketData(&pb.MarketDataResponse{Payload: &pb.MarketDataResponse_TradingStatus{TradingStatus: ts}})
+}
+
+// PushPing - отправка ping во все открытые стримы маркетдаты
+func (s *Server) PushPing() {
+	s.PushMarketData(&pb.MarketDataResponse{Payload: &pb.MarketDataResponse_Ping{Ping: &pb.Ping{Time: timestamppb.Now()}}})
+}
+
+// PushPortfolio - отправка сообщений во все открытые стримы портфеля
+func (s *Server) PushPortfolio(resp ...*pb.PortfolioStreamResponse) {
+	for _, r := range resp {
+		s.portfolios.broadcast(r)
+	}
+}
+
+// PushPositions - отправка сообщений во все открытые стримы позиций
+func (s *Server) PushPositions(resp ...*pb.PositionsStreamResponse) {
+	for _, r := range resp {
+		s.positions.broadcast(r)
+	}
+}
+
+// PushTrades - отправка сообщений во все открытые стримы сделок
+func (s *Server) PushTrades(resp ...*pb.TradesStreamResponse) {
+	for _, r := range resp {
+		s.trades.broadcast(r)
+	}
+}
+
+// marketDataStreamServer - стримы маркетдаты. На запросы подписки отвечает статусами из SetSubscriptionStatus,
+// идентификатор инструмента из запроса возвращается в полях figi и instrument_uid
+type marketDataStreamServer struct {
+	pb.UnimplementedMarketDataStreamServiceServer
+	s *Server
+}
+
+func (m *marketDataStreamServer) MarketDataStream(stream pb.MarketDataStreamService_MarketDataStreamServer) error {
+	conn := m.s.marketData.open(stream.Context())
+	defer m.s.marketData.close(conn)
+
+	go func() {
+		subs := newMDSubscriptions()
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				if err != io.EOF {
+					conn.stop(err)
+				}
+				return
+			}
+			m.s.record(MarketDataStream, req)
+			for _, resp := range m.handle(subs, req) {
+				conn.send(resp)
+			}
+		}
+	}()
+	return conn.serve(stream.Send)
+}
+
+func (m *marketDataStreamServer) MarketDataServerSideStream(req *pb.MarketDataServerSideStreamRequest, stream pb.MarketDataStreamService_MarketDataServerSideStreamServer) error {
+	m.s.record(MarketDataServerSideStream, req)
+	conn := m.s.marketData.open(stream.Context())
+	defer m.s.marketData.close(conn)
+
+	subs := newMDSubscriptions()
+	requests := []*pb.MarketDataRequest{
+		{Payload: &pb.MarketDataRequest_SubscribeCandlesRequest{SubscribeCandlesRequest: req.GetSubscribeCandlesRequest()}},
+		{Payload: &pb.MarketDataRequest_SubscribeOrderBookRequest{SubscribeOrderBookRequest: req.GetSubscribeOrderBookRequest()}},
+		{Payload: &pb.MarketDataRequest_SubscribeTradesRequest{SubscribeTradesRequest: req.GetSubscribeTradesRequest()}},
+		{Payload: &pb.MarketDataRequest_SubscribeInfoRequest{SubscribeInfoRequest: req.GetSubscribeInfoRequest()}},
+		{Payload: &pb.MarketDataRequest_SubscribeLastPriceRequest{SubscribeLastPriceRequest: req.GetSubscribeLastPriceRequest()}},
+	}
+	for _, r := range requests {
+		for _, resp := range m.handle(subs, r) {
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+		}
+	}
+	return conn.serve(stream.Send)
+}
+
+// mdSubscriptions - активные подписки одного стрима
+type mdSubscriptions struct {
+	candles    map[string]*pb.CandleSubscription
+	orderBooks map[string]*pb.OrderBookSubscription
+	trades     map[string]*pb.TradeSubscription
+	info       map[string]*pb.InfoSubscription
+	lastPrices map[string]*pb.LastPriceSubscription
+}
+
+func newMDSubscriptions() *mdSubscriptions {
+	return &mdSubscriptions{
+		candles:    make(map[string]*pb.CandleSubscription, 0),
+		orderBooks: make(map[string]*pb.OrderBookSubscription, 0),
+		trades:     make(map[string]*pb.TradeSubscription, 0),
+		info:       make(map[string]*pb.InfoSubscription, 0),
+		lastPrices: make(map[string]*pb.LastPriceSubscription, 0),
+	}
+}
+
+// handle - ответы на запрос в стриме маркетдаты
+func (m *marketDataStreamServer) handle(subs *mdSubscriptions, req *pb.MarketDataRequest) []*pb.MarketDataResponse {
+	trackingId := uuid.NewString()
+	switch {
+	case req.GetSubscribeCandlesRequest() != nil:
+		r := req.GetSubscribeCandlesRequest()
+		resp := &pb.SubscribeCandlesResponse{TrackingId: trackingId}
+		for _, inst := range r.GetInstruments() {
+			id := instrumentId(inst.GetInstrumentId(), inst.GetFigi())
+			sub := &pb.CandleSubscription{
+				Figi:               id,
+				InstrumentUid:      id,
+				Interval:           inst.GetInterval(),
+				SubscriptionStatus: m.s.subscriptionStatus(id),
+			}
+			key := fmt.Sprintf("%v:%v", id, inst.GetInterval())
+			update(subs.candles, key, sub, r.GetSubscriptionAction(), sub.GetSubscriptionStatus())
+			resp.CandlesSubscriptions = append(resp.CandlesSubscriptions, sub)
+		}
+		return []*pb.MarketDataResponse{{Payload: &pb.MarketDataResponse_SubscribeCandlesResponse{SubscribeCandlesResponse: resp}}}
+	case req.GetSubscribeOrderBookRequest() != nil:
+		r := req.GetSubscribeOrderBookRequest()
+		resp := &pb.SubscribeOrderBookResponse{TrackingId: trackingId}
+		for _, inst := range r.GetInstruments() {
+			id := instrumentId(inst.GetInstrumentId(), inst.GetFigi())
+			sub := &pb.OrderBookSubscription{
+				Figi:               id,
+				InstrumentUid:      id,
+				Depth:              inst.GetDepth(),
+				SubscriptionStatus: m.s.subscriptionStatus(id),
+			}
+			update(subs.orderBooks, id, sub, r.GetSubscriptionAction(), sub.GetSubscriptionStatus())
+			resp.OrderBookSubscriptions = append(resp.OrderBookSubscriptions, sub)
+		}
+		return []*pb.MarketDataResponse{{Payload: &pb.MarketDataResponse_SubscribeOrderBookResponse{SubscribeOrderBookResponse: resp}}}
+	case req.GetSubscribeTradesRequest() != nil:
+		r := req.GetSubscribeTradesRequest()
+		resp := &pb.SubscribeTradesResponse{TrackingId: trackingId}
+		for _, inst := range r.GetInstruments() {
+			id := instrumentId(inst.GetInstrumentId(), inst.GetFigi())
+			sub := &pb.TradeSubscription{
+				Figi:               id,
+				InstrumentUid:      id,
+				SubscriptionStatus: m.s.subscriptionStatus(id),
+			}
+			update(subs.trades, id, sub, r.GetSubscriptionAction(), sub.GetSubscriptionStatus())
+			resp.TradeSubscriptions = append(resp.TradeSubscriptions, sub)
+		}
+		return []*pb.MarketDataResponse{{Payload: &pb.MarketDataResponse_SubscribeTradesResponse{SubscribeTradesResponse: resp}}}
+	case req.GetSubscribeInfoRequest() != nil:
+		r := req.GetSubscribeInfoRequest()
+		resp := &pb.SubscribeInfoResponse{TrackingId: trackingId}
+		for _, inst := range r.GetInstruments() {
+			id := instrumentId(inst.GetInstrumentId(), inst.GetFigi())
+			sub := &pb.InfoSubscription{
+				Figi:               id,
+				InstrumentUid:      id,
+				SubscriptionStatus: m.s.subscriptionStatus(id),
+			}
+			update(subs.info, id, sub, r.GetSubscriptionAction(), sub.GetSubscriptionStatus())
+			resp.InfoSubscriptions = append(resp.InfoSubscriptions, sub)
+		}
+		return []*pb.MarketDataResponse{{Payload: &pb.MarketDataResponse_SubscribeInfoResponse{SubscribeInfoResponse: resp}}}
+	case req.GetSubscribeLastPriceRequest() != nil:
+		r := req.GetSubscribeLastPriceRequest()
+		resp := &pb.SubscribeLastPriceResponse{TrackingId: trackingId}
+		for _, inst := range r.GetInstruments() {
+			id := instrumentId(inst.GetInstrumentId(), inst.GetFigi())
+			sub := &pb.LastPriceSubscription{
+				Figi:               id,
+				InstrumentUid:      id,
+				SubscriptionStatus: m.s.subscriptionStatus(id),
+			}
+			update(subs.lastPrices, id, sub, r.GetSubscriptionAction(), sub.GetSubscriptionStatus())
+			resp.LastPriceSubscriptions = append(resp.LastPriceSubscriptions, sub)
+		}
+		return []*pb.MarketDataResponse{{Payload: &pb.MarketDataResponse_SubscribeLastPriceResponse{SubscribeLastPriceResponse: resp}}}
+	case req.GetGetMySubscriptions() != nil:
+		return []*pb.MarketDataResponse{
+			{Payload: &pb.MarketDataResponse_SubscribeCandlesResponse{SubscribeCandlesResponse: &pb.SubscribeCandlesResponse{
+				TrackingId: trackingId, CandlesSubscriptions: values(subs.candles)}}},
+			{Payload: &pb.MarketDataResponse_SubscribeOrderBookResponse{SubscribeOrderBookResponse: &pb.SubscribeOrderBookResponse{
+				TrackingId: trackingId, OrderBookSubscriptions: values(subs.orderBooks)}}},
+			{Payload: &pb.MarketDataResponse_SubscribeTradesResponse{SubscribeTradesResponse: &pb.SubscribeTradesResponse{
+				TrackingId: trackingId, TradeSubscriptions: values(subs.trades)}}},
+			{Payload: &pb.MarketDataResponse_SubscribeInfoResponse{SubscribeInfoResponse: &pb.SubscribeInfoResponse{
+				TrackingId: trackingId, InfoSubscriptions: values(subs.info)}}},
+			{Payload: &pb.MarketDataResponse_SubscribeLastPriceResponse{SubscribeLastPriceResponse: &pb.SubscribeLastPriceResponse{
+				TrackingId: trackingId, LastPriceSubscriptions: values(subs.lastPrices)}}},
+		}
+	default:
+		return nil
+	}
+}
+
+func instrumentId(id, figi string) string {
+	if id != "" {
+		return id
+	}
+	return figi
+}
+
+// update - изменение активных подписок стрима по действию из запроса
+func update[T any](subs map[string]T, key string, sub T, action pb.SubscriptionAction, st pb.SubscriptionStatus) {
+	switch {
+	case action == pb.SubscriptionAction_SUBSCRIPTION_ACTION_UNSUBSCRIBE:
+		delete(subs, key)
+	case st == pb.SubscriptionStatus_SUBSCRIPTION_STATUS_SUCCESS:
+		subs[key] = sub
+	}
+}
+
+func values[T any](m map[string]T) []T {
+	v := make([]T, 0, len(m))
+	for _, sub := range m {
+		v = append(v, sub)
+	}
+	return v
+}
+
+// operationsStreamServer - стримы портфеля и позиций, первым сообщением отправляется успешный статус подписки на счета
+type operationsStreamServer struct {
+	pb.UnimplementedOperationsStreamServiceServer
+	s *Server
+}
+
+func (o *operationsStreamServer) PortfolioStream(req *pb.PortfolioStreamRequest, stream pb.OperationsStreamService_PortfolioStreamServer) error {
+	o.s.record(PortfolioStream, req)
+	conn := o.s.portfolios.open(stream.Context())
+	defer o.s.portfolios.close(conn)
+
+	result := &pb.PortfolioSubscriptionResult{}
+	for _, id := range req.GetAccounts() {
+		result.Accounts = append(result.Accounts, &pb.AccountSubscriptionStatus{
+			AccountId:          id,
+			SubscriptionStatus: pb.PortfolioSubscriptionStatus_PORTFOLIO_SUBSCRIPTION_STATUS_SUCCESS,
+		})
+	}
+	err := stream.Send(&pb.PortfolioStreamResponse{Payload: &pb.PortfolioStreamResponse_Subscriptions{Subscriptions: result}})
+	if err != nil {
+		return err
+	}
+	return conn.serve(stream.Send)
+}
+
+func (o *operationsStreamServer) PositionsStream(req *pb.PositionsStreamRequest, stream pb.OperationsStreamService_PositionsStreamServer) error {
+	o.s.record(PositionsStream, req)
+	conn := o.s.positions.open(stream.Context())
+	defer o.s.positions.close(conn)
+
+	result := &pb.PositionsSubscriptionResult{}
+	for _, id := range req.GetAccounts() {
+		result.Accounts = append(result.Accounts, &pb.PositionsSubscriptionStatus{
+			AccountId:          id,
+			SubscriptionStatus: pb.PositionsAccountSubscriptionStatus_POSITIONS_SUBSCRIPTION_STATUS_SUCCESS,
+		})
+	}
+	err := stream.Send(&pb.PositionsStreamResponse{Payload: &pb.PositionsStreamResponse_Subscriptions{Subscriptions: result}})
+	if err != nil {
+		return err
+	}
+	return conn.serve(stream.Send)
+}
+
+// ordersStreamServer - стрим сделок
+type ordersStreamServer struct {
+	pb.UnimplementedOrdersStreamServiceServer
+	s *Server
+}
+
+func (o *ordersStreamServer) TradesStream(req *pb.TradesStreamRequest, stream pb.OrdersStreamService_TradesStreamServer) error {
+	o.s.record(TradesStream, req)
+	conn := o.s.trades.open(stream.Context())
+	defer o.s.trades.close(conn)
+	return conn.serve(stream.Send)
+}
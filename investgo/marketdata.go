@@ -18,12 +18,27 @@ type MarketDataServiceClient struct {
 	logger   Logger
 	ctx      context.Context
 	pbClient pb.MarketDataServiceClient
+	limiter  *rateLimiter
 }
 
+const (
+	// getCandlesMethod - метод GetCandles для поиска лимита тарифа
+	getCandlesMethod = "/tinkoff.public.invest.api.contract.v1.MarketDataService/GetCandles"
+	// historicCandlesBurst - количество запросов GetCandles подряд без лимитера, после которого выдерживается пауза
+	historicCandlesBurst = 299
+	// historicCandlesPause - пауза после historicCandlesBurst запросов без лимитера
+	historicCandlesPause = time.Minute
+)
+
 // GetCandles - Метод запроса исторических свечей по инструменту
 func (md *MarketDataServiceClient) GetCandles(instrumentId string, interval pb.CandleInterval, from, to time.Time) (*GetCandlesResponse, error) {
+	return md.GetCandlesCtx(md.ctx, instrumentId, interval, from, to)
+}
+
+// GetCandlesCtx - GetCandles с контекстом запроса
+func (md *MarketDataServiceClient) GetCandlesCtx(ctx context.Context, instrumentId string, interval pb.CandleInterval, from, to time.Time) (*GetCandlesResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := md.pbClient.GetCandles(md.ctx, &pb.GetCandlesRequest{
+	resp, err := md.pbClient.GetCandles(ctx, &pb.GetCandlesRequest{
 		From:         TimeToTimestamp(from),
 		To:           TimeToTimestamp(to),
 		Interval:     interval,
@@ -31,6 +46,7 @@ func (md *MarketDataServiceClient) GetCandles(instrumentId string, interval pb.C
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetCandlesResponse{
 		GetCandlesResponse: resp,
@@ -40,12 +56,18 @@ func (md *MarketDataServiceClient) GetCandles(instrumentId string, interval pb.C
 
 // GetLastPrices - Метод запроса цен последних сделок по инструментам
 func (md *MarketDataServiceClient) GetLastPrices(instrumentIds []string) (*GetLastPricesResponse, error) {
+	return md.GetLastPricesCtx(md.ctx, instrumentIds)
+}
+
+// GetLastPricesCtx - GetLastPrices с контекстом запроса
+func (md *MarketDataServiceClient) GetLastPricesCtx(ctx context.Context, instrumentIds []string) (*GetLastPricesResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := md.pbClient.GetLastPrices(md.ctx, &pb.GetLastPricesRequest{
+	resp, err := md.pbClient.GetLastPrices(ctx, &pb.GetLastPricesRequest{
 		InstrumentId: instrumentIds,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetLastPricesResponse{
 		GetLastPricesResponse: resp,
@@ -55,13 +77,19 @@ func (md *MarketDataServiceClient) GetLastPrices(instrumentIds []string) (*GetLa
 
 // GetOrderBook - Метод получения стакана по инструменту
 func (md *MarketDataServiceClient) GetOrderBook(instrumentId string, depth int32) (*GetOrderBookResponse, error) {
+	return md.GetOrderBookCtx(md.ctx, instrumentId, depth)
+}
+
+// GetOrderBookCtx - GetOrderBook с контекстом запроса
+func (md *MarketDataServiceClient) GetOrderBookCtx(ctx context.Context, instrumentId string, depth int32) (*GetOrderBookResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := md.pbClient.GetOrderBook(md.ctx, &pb.GetOrderBookRequest{
+	resp, err := md.pbClient.GetOrderBook(ctx, &pb.GetOrderBookRequest{
 		Depth:        depth,
 		InstrumentId: instrumentId,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetOrderBookResponse{
 		GetOrderBookResponse: resp,
@@ -71,12 +99,18 @@ func (md *MarketDataServiceClient) GetOrderBook(instrumentId string, depth int32
 
 // GetTradingStatus - Метод запроса статуса торгов по инструменту
 func (md *MarketDataServiceClient) GetTradingStatus(instrumentId string) (*GetTradingStatusResponse, error) {
+	return md.GetTradingStatusCtx(md.ctx, instrumentId)
+}
+
+// GetTradingStatusCtx - GetTradingStatus с контекстом запроса
+func (md *MarketDataServiceClient) GetTradingStatusCtx(ctx context.Context, instrumentId string) (*GetTradingStatusResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := md.pbClient.GetTradingStatus(md.ctx, &pb.GetTradingStatusRequest{
+	resp, err := md.pbClient.GetTradingStatus(ctx, &pb.GetTradingStatusRequest{
 		InstrumentId: instrumentId,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetTradingStatusResponse{
 		GetTradingStatusResponse: resp,
@@ -86,12 +120,18 @@ func (md *MarketDataServiceClient) GetTradingStatus(instrumentId string) (*GetTr
 
 // GetTradingStatuses - Метод запроса статуса торгов по инструментам
 func (md *MarketDataServiceClient) GetTradingStatuses(instrumentIds []string) (*GetTradingStatusesResponse, error) {
+	return md.GetTradingStatusesCtx(md.ctx, instrumentIds)
+}
+
+// GetTradingStatusesCtx - GetTradingStatuses с контекстом запроса
+func (md *MarketDataServiceClient) GetTradingStatusesCtx(ctx context.Context, instrumentIds []string) (*GetTradingStatusesResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := md.pbClient.GetTradingStatuses(md.ctx, &pb.GetTradingStatusesRequest{
+	resp, err := md.pbClient.GetTradingStatuses(ctx, &pb.GetTradingStatusesRequest{
 		InstrumentId: instrumentIds,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetTradingStatusesResponse{
 		GetTradingStatusesResponse: resp,
@@ -101,14 +141,20 @@ func (md *MarketDataServiceClient) GetTradingStatuses(instrumentIds []string) (*
 
 // GetLastTrades - Метод запроса обезличенных сделок за последний час
 func (md *MarketDataServiceClient) GetLastTrades(instrumentId string, from, to time.Time) (*GetLastTradesResponse, error) {
+	return md.GetLastTradesCtx(md.ctx, instrumentId, from, to)
+}
+
+// GetLastTradesCtx - GetLastTrades с контекстом запроса
+func (md *MarketDataServiceClient) GetLastTradesCtx(ctx context.Context, instrumentId string, from, to time.Time) (*GetLastTradesResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := md.pbClient.GetLastTrades(md.ctx, &pb.GetLastTradesRequest{
+	resp, err := md.pbClient.GetLastTrades(ctx, &pb.GetLastTradesRequest{
 		From:         TimeToTimestamp(from),
 		To:           TimeToTimestamp(to),
 		InstrumentId: instrumentId,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetLastTradesResponse{
 		GetLastTradesResponse: resp,
@@ -118,16 +164,22 @@ func (md *MarketDataServiceClient) GetLastTrades(instrumentId string, from, to t
 
 // GetClosePrices - Метод запроса цен закрытия торговой сессии по инструментам
 func (md *MarketDataServiceClient) GetClosePrices(instrumentIds []string) (*GetClosePricesResponse, error) {
+	return md.GetClosePricesCtx(md.ctx, instrumentIds)
+}
+
+// GetClosePricesCtx - GetClosePrices с контекстом запроса
+func (md *MarketDataServiceClient) GetClosePricesCtx(ctx context.Context, instrumentIds []string) (*GetClosePricesResponse, error) {
 	var header, trailer metadata.MD
 	instruments := make([]*pb.InstrumentClosePriceRequest, 0, len(instrumentIds))
 	for _, id := range instrumentIds {
 		instruments = append(instruments, &pb.InstrumentClosePriceRequest{InstrumentId: id})
 	}
-	resp, err := md.pbClient.GetClosePrices(md.ctx, &pb.GetClosePricesRequest{
+	resp, err := md.pbClient.GetClosePrices(ctx, &pb.GetClosePricesRequest{
 		Instruments: instruments,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetClosePricesResponse{
 		GetClosePricesResponse: resp,
@@ -140,6 +192,11 @@ func (md *MarketDataServiceClient) GetClosePrices(instrumentIds []string) (*GetC
 // свечей в формате: instrumentId;time;open;close;high;low;volume.
 // Имя файла по умолчанию: "candles hh:mm:ss"
 func (md *MarketDataServiceClient) GetHistoricCandles(req *GetHistoricCandlesRequest) ([]*pb.HistoricCandle, error) {
+	return md.GetHistoricCandlesCtx(md.ctx, req)
+}
+
+// GetHistoricCandlesCtx - GetHistoricCandles с контекстом запроса
+func (md *MarketDataServiceClient) GetHistoricCandlesCtx(ctx context.Context, req *GetHistoricCandlesRequest) ([]*pb.HistoricCandle, error) {
 	duration := selectDuration(req.Interval)
 	// если запрашиваемый интервал больше чем возможный, то нужно разделить его на несколько
 	intervals := make([]time.Time, 0)
@@ -155,22 +212,27 @@ func (md *MarketDataServiceClient) GetHistoricCandles(req *GetHistoricCandlesReq
 	}
 	// intervals = {to, ... , from}
 
+	// частота запросов ограничивается лимитером клиента, если он отключен или тариф не загружен -
+	// паузой после каждых historicCandlesBurst запросов
+	throttle := !md.limiter.limits(getCandlesMethod)
 	candles := make([]*pb.HistoricCandle, 0)
 	requests := 0
 	for i := len(intervals) - 1; i > 0; i-- {
 		// идем с конца слайса так как там более раннее время
 		// from - i элемент
 		// to - i-1 элемент
+		if throttle && requests == historicCandlesBurst {
+			if err := sleepCtx(ctx, historicCandlesPause); err != nil {
+				return nil, err
+			}
+			requests = 0
+		}
 		requests++
-		resp, err := md.GetCandles(req.Instrument, req.Interval, intervals[i], intervals[i-1])
+		resp, err := md.GetCandlesCtx(ctx, req.Instrument, req.Interval, intervals[i], intervals[i-1])
 		if err != nil {
 			return nil, err
 		}
 		candles = append(candles, resp.GetCandles()...)
-		if requests == 299 {
-			time.Sleep(time.Minute)
-			requests = 0
-		}
 	}
 
 	if req.File {
@@ -185,11 +247,16 @@ func (md *MarketDataServiceClient) GetHistoricCandles(req *GetHistoricCandlesReq
 
 // GetAllHistoricCandles - Метод получения всех свечей по инструменту, поля from, to игнорируются
 func (md *MarketDataServiceClient) GetAllHistoricCandles(req *GetHistoricCandlesRequest) ([]*pb.HistoricCandle, error) {
+	return md.GetAllHistoricCandlesCtx(md.ctx, req)
+}
+
+// GetAllHistoricCandlesCtx - GetAllHistoricCandles с контекстом запроса
+func (md *MarketDataServiceClient) GetAllHistoricCandlesCtx(ctx context.Context, req *GetHistoricCandlesRequest) ([]*pb.HistoricCandle, error) {
 	instrumentsService := &InstrumentsServiceClient{
 		conn:     md.conn,
 		config:   md.config,
 		logger:   md.logger,
-		ctx:      md.ctx,
+		ctx:      ctx,
 		pbClient: pb.NewInstrumentsServiceClient(md.conn),
 	}
 
@@ -210,7 +277,7 @@ func (md *MarketDataServiceClient) GetAllHistoricCandles(req *GetHistoricCandles
 		from = resp.GetInstruments()[0].GetFirst_1MinCandleDate().AsTime()
 	}
 
-	return md.GetHistoricCandles(&GetHistoricCandlesRequest{
+	return md.GetHistoricCandlesCtx(ctx, &GetHistoricCandlesRequest{
 		Instrument: req.Instrument,
 		Interval:   req.Interval,
 		From:       from,
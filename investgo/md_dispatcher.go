@@ -0,0 +1,360 @@
+package investgo
+
+import (
+	"sync"
+	"sync/atomic"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+// DefaultConsumerBufferSize - размер буфера канала потребителя по умолчанию
+const DefaultConsumerBufferSize = 100
+
+// OverflowPolicy - поведение при заполненном буфере потребителя
+type OverflowPolicy int
+
+const (
+	// OverflowBlock - ожидание, пока потребитель прочитает данные. Медленный потребитель задерживает
+	// остальных потребителей диспетчера
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest - удаление самого старого сообщения из буфера
+	OverflowDropOldest
+	// OverflowDropNewest - пропуск нового сообщения
+	OverflowDropNewest
+)
+
+// ConsumerOptions - параметры потребителя диспетчера
+type ConsumerOptions struct {
+	// BufferSize - размер буфера канала, 0 - DefaultConsumerBufferSize
+	BufferSize int
+	// Overflow - поведение при заполненном буфере
+	Overflow OverflowPolicy
+}
+
+// MarketDataChannels - источник данных для диспетчера, например MDStream
+type MarketDataChannels interface {
+	Candles() <-chan *pb.Candle
+	OrderBooks() <-chan *pb.OrderBook
+	Trades() <-chan *pb.Trade
+	TradingStatuses() <-chan *pb.TradingStatus
+	LastPrices() <-chan *pb.LastPrice
+}
+
+// DispatchHandle - потребитель диспетчера
+type DispatchHandle interface {
+	// Dropped - количество сообщений, пропущенных из-за заполненного буфера
+	Dropped() uint64
+	// Close - отключение потребителя от диспетчера
+	Close()
+}
+
+// MDDispatcher - распределение маркетдаты по потребителям. Каждый потребитель получает данные
+// выбранного типа по своим инструментам в свой канал или callback, поэтому медленный потребитель
+// с политикой OverflowDropOldest или OverflowDropNewest не задерживает остальных
+type MDDispatcher struct {
+	mu sync.RWMutex
+	// consumers - потребители по типу подписки и инструменту, "" - потребители всех инструментов
+	consumers map[SubscriptionType]map[string][]dispatchTarget
+	closed    bool
+}
+
+// dispatchTarget - потребитель сообщений одного типа
+type dispatchTarget interface {
+	deliver(msg any)
+	// stop - закрытие канала потребителя, вызывается под MDDispatcher.mu
+	stop()
+}
+
+// NewMDDispatcher - создание диспетчера, данные передаются в него методом Run
+func NewMDDispatcher() *MDDispatcher {
+	return &MDDispatcher{
+		consumers: make(map[SubscriptionType]map[string][]dispatchTarget, 0),
+	}
+}
+
+// Run - чтение всех каналов источника и распределение данных по потребителям. Каналы источника
+// не должны читаться кем-то еще. Завершается после закрытия всех каналов источника, после чего
+// закрываются каналы всех потребителей
+func (d *MDDispatcher) Run(src MarketDataChannels) {
+	defer d.closeAll()
+	candles, orderBooks, trades := src.Candles(), src.OrderBooks(), src.Trades()
+	tradingStatuses, lastPrices := src.TradingStatuses(), src.LastPrices()
+	for candles != nil || orderBooks != nil || trades != nil || tradingStatuses != nil || lastPrices != nil {
+		select {
+		case c, ok := <-candles:
+			if !ok {
+				candles = nil
+				continue
+			}
+			d.dispatch(SubscriptionCandles, c.GetFigi(), c.GetInstrumentUid(), c)
+		case ob, ok := <-orderBooks:
+			if !ok {
+				orderBooks = nil
+				continue
+			}
+			d.dispatch(SubscriptionOrderBooks, ob.GetFigi(), ob.GetInstrumentUid(), ob)
+		case t, ok := <-trades:
+			if !ok {
+				trades = nil
+				continue
+			}
+			d.dispatch(SubscriptionTrades, t.GetFigi(), t.GetInstrumentUid(), t)
+		case ts, ok := <-tradingStatuses:
+			if !ok {
+				tradingStatuses = nil
+				continue
+			}
+			d.dispatch(SubscriptionInfo, ts.GetFigi(), ts.GetInstrumentUid(), ts)
+		case lp, ok := <-lastPrices:
+			if !ok {
+				lastPrices = nil
+				continue
+			}
+			d.dispatch(SubscriptionLastPrices, lp.GetFigi(), lp.GetInstrumentUid(), lp)
+		}
+	}
+}
+
+// Candles - канал свечей по инструментам ids, при пустом ids - по всем инструментам
+func (d *MDDispatcher) Candles(ids []string, opts ConsumerOptions) *Consumer[*pb.Candle] {
+	return addConsumer[*pb.Candle](d, SubscriptionCandles, ids, opts)
+}
+
+// OrderBooks - канал стаканов по инструментам ids, при пустом ids - по всем инструментам
+func (d *MDDispatcher) OrderBooks(ids []string, opts ConsumerOptions) *Consumer[*pb.OrderBook] {
+	return addConsumer[*pb.OrderBook](d, SubscriptionOrderBooks, ids, opts)
+}
+
+// Trades - канал обезличенных сделок по инструментам ids, при пустом ids - по всем инструментам
+func (d *MDDispatcher) Trades(ids []string, opts ConsumerOptions) *Consumer[*pb.Trade] {
+	return addConsumer[*pb.Trade](d, SubscriptionTrades, ids, opts)
+}
+
+// TradingStatuses - канал торговых статусов по инструментам ids, при пустом ids - по всем инструментам
+func (d *MDDispatcher) TradingStatuses(ids []string, opts ConsumerOptions) *Consumer[*pb.TradingStatus] {
+	return addConsumer[*pb.TradingStatus](d, SubscriptionInfo, ids, opts)
+}
+
+// LastPrices - канал последних цен по инструментам ids, при пустом ids - по всем инструментам
+func (d *MDDispatcher) LastPrices(ids []string, opts ConsumerOptions) *Consumer[*pb.LastPrice] {
+	return addConsumer[*pb.LastPrice](d, SubscriptionLastPrices, ids, opts)
+}
+
+// OnCandle - вызов fn для свечей по инструментам ids в отдельной горутине потребителя
+func (d *MDDispatcher) OnCandle(ids []string, opts ConsumerOptions, fn func(*pb.Candle)) DispatchHandle {
+	return handle(d.Candles(ids, opts), fn)
+}
+
+// OnOrderBook - вызов fn для стаканов по инструментам ids в отдельной горутине потребителя
+func (d *MDDispatcher) OnOrderBook(ids []string, opts ConsumerOptions, fn func(*pb.OrderBook)) DispatchHandle {
+	return handle(d.OrderBooks(ids, opts), fn)
+}
+
+// OnTrade - вызов fn для обезличенных сделок по инструментам ids в отдельной горутине потребителя
+func (d *MDDispatcher) OnTrade(ids []string, opts ConsumerOptions, fn func(*pb.Trade)) DispatchHandle {
+	return handle(d.Trades(ids, opts), fn)
+}
+
+// OnTradingStatus - вызов fn для торговых статусов по инструментам ids в отдельной горутине потребителя
+func (d *MDDispatcher) OnTradingStatus(ids []string, opts ConsumerOptions, fn func(*pb.TradingStatus)) DispatchHandle {
+	return handle(d.TradingStatuses(ids, opts), fn)
+}
+
+// OnLastPrice - вызов fn для последних цен по инструментам ids в отдельной горутине потребителя
+func (d *MDDispatcher) OnLastPrice(ids []string, opts ConsumerOptions, fn func(*pb.LastPrice)) DispatchHandle {
+	return handle(d.LastPrices(ids, opts), fn)
+}
+
+// dispatch - отправка сообщения потребителям инструмента, инструмент потребителя совпадает по figi или instrument_uid
+func (d *MDDispatcher) dispatch(t SubscriptionType, figi, uid string, msg any) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	byId := d.consumers[t]
+	if len(byId) == 0 {
+		return
+	}
+	for _, c := range byId[""] {
+		c.deliver(msg)
+	}
+	for _, c := range byId[figi] {
+		c.deliver(msg)
+	}
+	if uid == figi {
+		return
+	}
+	for _, c := range byId[uid] {
+		// потребитель с figi и uid одного инструмента получает сообщение один раз
+		if figi != "" && contains(byId[figi], c) {
+			continue
+		}
+		c.deliver(msg)
+	}
+}
+
+func (d *MDDispatcher) add(t SubscriptionType, ids []string, c dispatchTarget) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		c.stop()
+		return
+	}
+	if d.consumers[t] == nil {
+		d.consumers[t] = make(map[string][]dispatchTarget, 0)
+	}
+	if len(ids) == 0 {
+		ids = []string{""}
+	}
+	for _, id := range ids {
+		if !contains(d.consumers[t][id], c) {
+			d.consumers[t][id] = append(d.consumers[t][id], c)
+		}
+	}
+}
+
+func (d *MDDispatcher) remove(c dispatchTarget) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	found := false
+	for _, byId := range d.consumers {
+		for id, targets := range byId {
+			for i, target := range targets {
+				if target != c {
+					continue
+				}
+				found = true
+				byId[id] = append(targets[:i:i], targets[i+1:]...)
+				if len(byId[id]) == 0 {
+					delete(byId, id)
+				}
+				break
+			}
+		}
+	}
+	if found {
+		c.stop()
+	}
+}
+
+// closeAll - закрытие каналов всех потребителей после завершения Run
+func (d *MDDispatcher) closeAll() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closed = true
+	stopped := make(map[dispatchTarget]struct{}, 0)
+	for _, byId := range d.consumers {
+		for _, targets := range byId {
+			for _, c := range targets {
+				if _, ok := stopped[c]; !ok {
+					stopped[c] = struct{}{}
+					c.stop()
+				}
+			}
+		}
+	}
+	d.consumers = make(map[SubscriptionType]map[string][]dispatchTarget, 0)
+}
+
+func contains(targets []dispatchTarget, c dispatchTarget) bool {
+	for _, target := range targets {
+		if target == c {
+			return true
+		}
+	}
+	return false
+}
+
+// Consumer - потребитель диспетчера с собственным каналом
+type Consumer[T any] struct {
+	d       *MDDispatcher
+	ch      chan T
+	policy  OverflowPolicy
+	dropped atomic.Uint64
+	// done - закрывается в Close, чтобы прервать ожидание отправки в канал при OverflowBlock
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func addConsumer[T any](d *MDDispatcher, t SubscriptionType, ids []string, opts ConsumerOptions) *Consumer[T] {
+	size := opts.BufferSize
+	if size <= 0 {
+		size = DefaultConsumerBufferSize
+	}
+	c := &Consumer[T]{
+		d:      d,
+		ch:     make(chan T, size),
+		policy: opts.Overflow,
+		done:   make(chan struct{}),
+	}
+	d.add(t, ids, c)
+	return c
+}
+
+// C - канал потребителя, закрывается после Close или завершения Run диспетчера
+func (c *Consumer[T]) C() <-chan T {
+	return c.ch
+}
+
+// Dropped - количество сообщений, пропущенных из-за заполненного буфера
+func (c *Consumer[T]) Dropped() uint64 {
+	return c.dropped.Load()
+}
+
+// Close - отключение потребителя от диспетчера, канал потребителя закрывается
+func (c *Consumer[T]) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+	c.d.remove(c)
+}
+
+func (c *Consumer[T]) deliver(msg any) {
+	v := msg.(T)
+	switch c.policy {
+	case OverflowDropNewest:
+		select {
+		case c.ch <- v:
+		default:
+			c.dropped.Add(1)
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case c.ch <- v:
+				return
+			default:
+			}
+			select {
+			case <-c.ch:
+				c.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case c.ch <- v:
+		case <-c.done:
+		}
+	}
+}
+
+func (c *Consumer[T]) stop() {
+	close(c.ch)
+}
+
+// handle - вызов fn для каждого сообщения потребителя c до его закрытия
+func handle[T any](c *Consumer[T], fn func(T)) DispatchHandle {
+	go func() {
+		for {
+			select {
+			case v, ok := <-c.ch:
+				if !ok {
+					return
+				}
+				fn(v)
+			case <-c.done:
+				return
+			}
+		}
+	}()
+	return c
+}
@@ -0,0 +1,187 @@
+package investgo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/therox/invest-api-go-sdk/investgo"
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+const testTimeout = 10 * time.Second
+
+// testChannels - источник маркетдаты для диспетчера, каналы закрываются в close
+type testChannels struct {
+	candles         chan *pb.Candle
+	orderBooks      chan *pb.OrderBook
+	trades          chan *pb.Trade
+	tradingStatuses chan *pb.TradingStatus
+	lastPrices      chan *pb.LastPrice
+}
+
+func newTestChannels() *testChannels {
+	return &testChannels{
+		candles:         make(chan *pb.Candle),
+		orderBooks:      make(chan *pb.OrderBook),
+		trades:          make(chan *pb.Trade),
+		tradingStatuses: make(chan *pb.TradingStatus),
+		lastPrices:      make(chan *pb.LastPrice),
+	}
+}
+
+func (c *testChannels) Candles() <-chan *pb.Candle                { return c.candles }
+func (c *testChannels) OrderBooks() <-chan *pb.OrderBook          { return c.orderBooks }
+func (c *testChannels) Trades() <-chan *pb.Trade                  { return c.trades }
+func (c *testChannels) TradingStatuses() <-chan *pb.TradingStatus { return c.tradingStatuses }
+func (c *testChannels) LastPrices() <-chan *pb.LastPrice          { return c.lastPrices }
+
+func (c *testChannels) close() {
+	close(c.candles)
+	close(c.orderBooks)
+	close(c.trades)
+	close(c.tradingStatuses)
+	close(c.lastPrices)
+}
+
+// runDispatcher - диспетчер, читающий src, и канал, закрываемый после завершения Run
+func runDispatcher(src *testChannels) (*investgo.MDDispatcher, <-chan struct{}) {
+	d := investgo.NewMDDispatcher()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.Run(src)
+	}()
+	return d, done
+}
+
+// candleVolumes - объемы свечей из канала до его закрытия
+func candleVolumes(ch <-chan *pb.Candle) []int64 {
+	var volumes []int64
+	for c := range ch {
+		volumes = append(volumes, c.GetVolume())
+	}
+	return volumes
+}
+
+func TestMDDispatcherOverflowPolicies(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  investgo.OverflowPolicy
+		want    []int64
+		dropped uint64
+	}{
+		{name: "drop newest", policy: investgo.OverflowDropNewest, want: []int64{1, 2}, dropped: 3},
+		{name: "drop oldest", policy: investgo.OverflowDropOldest, want: []int64{4, 5}, dropped: 3},
+	}
+	for _, tt := range tests {
+		src := newTestChannels()
+		d, done := runDispatcher(src)
+		c := d.Candles([]string{"figi"}, investgo.ConsumerOptions{BufferSize: 2, Overflow: tt.policy})
+		// потребитель не читает канал, пока источник не закроется
+		for v := int64(1); v <= 5; v++ {
+			src.candles <- &pb.Candle{Figi: "figi", Volume: v}
+		}
+		src.close()
+		<-done
+		got := candleVolumes(c.C())
+		if len(got) != len(tt.want) || got[0] != tt.want[0] || got[1] != tt.want[1] {
+			t.Errorf("%v: received %v, want %v", tt.name, got, tt.want)
+		}
+		if c.Dropped() != tt.dropped {
+			t.Errorf("%v: dropped %v, want %v", tt.name, c.Dropped(), tt.dropped)
+		}
+	}
+}
+
+func TestMDDispatcherBlockingConsumerClose(t *testing.T) {
+	src := newTestChannels()
+	d, done := runDispatcher(src)
+	slow := d.Candles(nil, investgo.ConsumerOptions{BufferSize: 1})
+	fast := d.Candles([]string{"figi"}, investgo.ConsumerOptions{BufferSize: 10})
+
+	src.candles <- &pb.Candle{Figi: "figi", Volume: 1}
+	// буфер медленного потребителя заполнен, диспетчер ожидает его на следующей свече
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		src.candles <- &pb.Candle{Figi: "figi", Volume: 2}
+		src.candles <- &pb.Candle{Figi: "figi", Volume: 3}
+	}()
+	select {
+	case <-sent:
+		t.Fatal("dispatcher is not blocked by the consumer with OverflowBlock")
+	case <-time.After(20 * time.Millisecond):
+	}
+	slow.Close()
+	select {
+	case <-sent:
+	case <-time.After(testTimeout):
+		t.Fatal("dispatcher is blocked after the slow consumer is closed")
+	}
+	src.close()
+	<-done
+
+	if got := candleVolumes(fast.C()); len(got) != 3 {
+		t.Errorf("fast consumer received %v, want 3 candles", got)
+	}
+	if got := candleVolumes(slow.C()); len(got) != 1 {
+		t.Errorf("slow consumer received %v, want 1 buffered candle", got)
+	}
+}
+
+func TestMDDispatcherInstrumentFilter(t *testing.T) {
+	src := newTestChannels()
+	d, done := runDispatcher(src)
+	// потребитель с figi и uid одного инструмента получает сообщение один раз
+	both := d.Trades([]string{"figi", "uid"}, investgo.ConsumerOptions{})
+	byUid := d.Trades([]string{"uid"}, investgo.ConsumerOptions{})
+	all := d.Trades(nil, investgo.ConsumerOptions{})
+	candles := d.Candles(nil, investgo.ConsumerOptions{})
+
+	src.trades <- &pb.Trade{Figi: "figi", InstrumentUid: "uid"}
+	src.trades <- &pb.Trade{Figi: "other", InstrumentUid: "other-uid"}
+	src.close()
+	<-done
+
+	count := func(c *investgo.Consumer[*pb.Trade]) int {
+		n := 0
+		for range c.C() {
+			n++
+		}
+		return n
+	}
+	if n := count(both); n != 1 {
+		t.Errorf("consumer by figi and uid received %v trades, want 1", n)
+	}
+	if n := count(byUid); n != 1 {
+		t.Errorf("consumer by uid received %v trades, want 1", n)
+	}
+	if n := count(all); n != 2 {
+		t.Errorf("consumer of all instruments received %v trades, want 2", n)
+	}
+	if got := candleVolumes(candles.C()); len(got) != 0 {
+		t.Errorf("candle consumer received %v", got)
+	}
+}
+
+func TestMDDispatcherCallback(t *testing.T) {
+	src := newTestChannels()
+	d, done := runDispatcher(src)
+	received := make(chan string, 1)
+	h := d.OnLastPrice([]string{"figi"}, investgo.ConsumerOptions{}, func(lp *pb.LastPrice) {
+		received <- lp.GetFigi()
+	})
+	defer h.Close()
+
+	src.lastPrices <- &pb.LastPrice{Figi: "figi"}
+	select {
+	case figi := <-received:
+		if figi != "figi" {
+			t.Errorf("callback for %v", figi)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("callback is not called")
+	}
+	src.close()
+	<-done
+}
@@ -0,0 +1,165 @@
+package investgo
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mdConn - grpc стрим маркетдаты, читаемый mdListener. Реализуется MDStream и ServerSideStream
+type mdConn interface {
+	// recv - получение ответа из текущего grpc стрима
+	recv() (*pb.MarketDataResponse, error)
+	// reopenStream - замена текущего grpc стрима новым с восстановлением подписок
+	reopenStream() error
+	// handleSubscriptionResponse - обработка ответа на подписку
+	handleSubscriptionResponse(t SubscriptionType, trackingId string, statuses []instrumentStatus)
+}
+
+// mdListener - общая часть bidirectional и server-side стримов маркетдаты: каналы данных, чтение стрима
+// с переподключением согласно ReconnectPolicy, проверка активности и запись сообщений
+type mdListener struct {
+	// name - название стрима для логов
+	name   string
+	logger Logger
+	ctx    context.Context
+
+	lifecycle streamLifecycle
+	watchdog  *streamWatchdog
+	recorder  *Recorder
+
+	candle        chan *pb.Candle
+	trade         chan *pb.Trade
+	orderBook     chan *pb.OrderBook
+	lastPrice     chan *pb.LastPrice
+	tradingStatus chan *pb.TradingStatus
+
+	// mu - защита политики переподключения
+	mu        sync.Mutex
+	reconnect ReconnectPolicy
+}
+
+func newMDListener(ctx context.Context, name string, logger Logger) *mdListener {
+	return &mdListener{
+		name:          name,
+		logger:        logger,
+		ctx:           ctx,
+		lifecycle:     newStreamLifecycle(),
+		watchdog:      newStreamWatchdog(),
+		candle:        make(chan *pb.Candle, 1),
+		trade:         make(chan *pb.Trade, 1),
+		orderBook:     make(chan *pb.OrderBook, 1),
+		lastPrice:     make(chan *pb.LastPrice, 1),
+		tradingStatus: make(chan *pb.TradingStatus, 1),
+		reconnect:     DefaultReconnectPolicy(),
+	}
+}
+
+// setReconnectPolicy - установка политики переподключения, применяется со следующего переподключения
+func (l *mdListener) setReconnectPolicy(p ReconnectPolicy) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.reconnect = p
+}
+
+// listen - чтение стрима conn до остановки стрима или ошибки, после которой переподключение невозможно
+func (l *mdListener) listen(conn mdConn) error {
+	go l.watchdog.run(l.ctx, l.logger, l.name)
+	for {
+		select {
+		case <-l.ctx.Done():
+			l.logger.Infof("%v: stop listening", l.name)
+			return nil
+		default:
+			resp, err := conn.recv()
+			if err != nil {
+				switch {
+				case status.Code(err) == codes.Unavailable || l.watchdog.reconnectRequested(l.ctx, err):
+					l.logger.Infof("%v lost, reconnecting: %v", l.name, err.Error())
+					err := l.restart(conn, err)
+					if err != nil {
+						if l.ctx.Err() != nil {
+							l.logger.Infof("%v: stop listening", l.name)
+							return nil
+						}
+						return err
+					}
+				case status.Code(err) == codes.Canceled:
+					l.logger.Infof("%v: stop listening", l.name)
+					return nil
+				default:
+					return err
+				}
+			} else {
+				l.recorder.record(RecordedMarketData, resp)
+				l.dispatch(conn, resp)
+			}
+		}
+	}
+}
+
+// restart - переоткрытие стрима согласно ReconnectPolicy, cause - ошибка, вызвавшая переподключение
+func (l *mdListener) restart(conn mdConn, cause error) error {
+	l.mu.Lock()
+	policy := l.reconnect
+	l.mu.Unlock()
+	return policy.retry(l.ctx, l.logger, l.name, cause, conn.reopenStream)
+}
+
+// dispatch - отправка данных в каналы и ответов на подписку в conn
+func (l *mdListener) dispatch(conn mdConn, resp *pb.MarketDataResponse) {
+	// отправка в канал прерывается при остановке стрима, чтобы Listen не блокировался на медленном читателе
+	switch resp.GetPayload().(type) {
+	case *pb.MarketDataResponse_Candle:
+		l.watchdog.data()
+		select {
+		case l.candle <- resp.GetCandle():
+		case <-l.ctx.Done():
+		}
+	case *pb.MarketDataResponse_Orderbook:
+		l.watchdog.data()
+		select {
+		case l.orderBook <- resp.GetOrderbook():
+		case <-l.ctx.Done():
+		}
+	case *pb.MarketDataResponse_Trade:
+		l.watchdog.data()
+		select {
+		case l.trade <- resp.GetTrade():
+		case <-l.ctx.Done():
+		}
+	case *pb.MarketDataResponse_LastPrice:
+		l.watchdog.data()
+		select {
+		case l.lastPrice <- resp.GetLastPrice():
+		case <-l.ctx.Done():
+		}
+	case *pb.MarketDataResponse_TradingStatus:
+		l.watchdog.data()
+		select {
+		case l.tradingStatus <- resp.GetTradingStatus():
+		case <-l.ctx.Done():
+		}
+	case *pb.MarketDataResponse_Ping:
+		l.watchdog.ping()
+	default:
+		if t, trackingId, statuses, ok := subscriptionStatuses(resp); ok {
+			conn.handleSubscriptionResponse(t, trackingId, statuses)
+			return
+		}
+		l.logger.Infof("Info from %v %v", l.name, resp.String())
+	}
+}
+
+func (l *mdListener) closeChannels() {
+	l.logger.Infof("Close %v", strings.ToLower(l.name))
+	close(l.candle)
+	close(l.trade)
+	close(l.lastPrice)
+	close(l.orderBook)
+	close(l.tradingStatus)
+}
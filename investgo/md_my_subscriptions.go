@@ -0,0 +1,345 @@
+package investgo
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+// ErrSubscriptionsTimeout - сервер не ответил на запрос GetMySubscriptions
+var ErrSubscriptionsTimeout = errors.New("market data stream: GetMySubscriptions response timeout")
+
+// mySubscriptionsTimeout - время ожидания ответа на GetMySubscriptions, после которого запрос
+// перестает ожидать ответ
+const mySubscriptionsTimeout = 30 * time.Second
+
+// snapshotRequest - запрос GetMySubscriptions, ожидающий ответа. Сервер отвечает на него ответами на подписку
+// каждого типа с общим tracking_id. Первый ответ сопоставляется с запросом по порядку отправки относительно
+// запросов подписки, остальные - по его tracking_id, поэтому ответы не смешиваются с очередями запросов подписки
+type snapshotRequest struct {
+	seq        uint64
+	trackingId string
+	statuses   map[SubscriptionType][]instrumentStatus
+	expires    time.Time
+
+	done chan struct{}
+	err  error
+}
+
+func newSnapshotRequest(seq uint64, expires time.Time) *snapshotRequest {
+	return &snapshotRequest{
+		seq:      seq,
+		statuses: make(map[SubscriptionType][]instrumentStatus, len(subscriptionTypes)),
+		expires:  expires,
+		done:     make(chan struct{}),
+	}
+}
+
+// complete - завершение ожидания, вызывается под mds.mu
+func (r *snapshotRequest) complete(err error) {
+	r.err = err
+	close(r.done)
+}
+
+// finished - ожидание завершено, вызывается под mds.mu
+func (r *snapshotRequest) finished() bool {
+	select {
+	case <-r.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// SubscribedInstrument - инструмент активной подписки на сервере
+type SubscribedInstrument struct {
+	Figi          string
+	InstrumentUid string
+}
+
+// InstrumentId - uid инструмента или figi, если uid не задан
+func (i SubscribedInstrument) InstrumentId() string {
+	if i.InstrumentUid != "" {
+		return i.InstrumentUid
+	}
+	return i.Figi
+}
+
+func (i SubscribedInstrument) matches(id string) bool {
+	return id == i.Figi || id == i.InstrumentUid
+}
+
+// CandleSubscriptionInfo - активная подписка на свечи
+type CandleSubscriptionInfo struct {
+	SubscribedInstrument
+	Interval pb.SubscriptionInterval
+}
+
+// OrderBookSubscriptionInfo - активная подписка на стакан
+type OrderBookSubscriptionInfo struct {
+	SubscribedInstrument
+	Depth int32
+}
+
+// SubscriptionsSnapshot - активные подписки стрима по данным сервера
+type SubscriptionsSnapshot struct {
+	Candles         []CandleSubscriptionInfo
+	OrderBooks      []OrderBookSubscriptionInfo
+	Trades          []SubscribedInstrument
+	TradingStatuses []SubscribedInstrument
+	LastPrices      []SubscribedInstrument
+}
+
+// SubscriptionKey - подписка на инструмент, Interval задан только для свечей, Depth только для стаканов
+type SubscriptionKey struct {
+	Type         SubscriptionType
+	InstrumentId string
+	Interval     pb.SubscriptionInterval
+	Depth        int32
+}
+
+// SubscriptionsDiff - расхождение подписок стрима с подписками на сервере. Подписка с другим
+// интервалом или глубиной попадает и в Missing, и в Unexpected
+type SubscriptionsDiff struct {
+	// Missing - подписки стрима, которых нет на сервере
+	Missing []SubscriptionKey
+	// Unexpected - подписки на сервере, которых нет в подписках стрима
+	Unexpected []SubscriptionKey
+}
+
+// InSync - true, если подписки стрима совпадают с подписками на сервере
+func (d *SubscriptionsDiff) InSync() bool {
+	return len(d.Missing) == 0 && len(d.Unexpected) == 0
+}
+
+// MySubscriptions - получение активных подписок стрима от сервера. Ответ приходит в Listen,
+// поэтому стрим должен слушаться. Если сервер не ответил за 30 секунд, возвращается ErrSubscriptionsTimeout
+func (mds *MDStream) MySubscriptions(ctx context.Context) (*SubscriptionsSnapshot, error) {
+	req, err := mds.requestMySubscriptions()
+	if err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(mySubscriptionsTimeout)
+	defer timer.Stop()
+	select {
+	case <-req.done:
+	case <-timer.C:
+		mds.mu.Lock()
+		mds.expireSnapshotLocked(req, time.Now())
+		mds.mu.Unlock()
+	case <-ctx.Done():
+		// запрос остается в ожидании до получения ответа или истечения времени ожидания
+		return nil, ctx.Err()
+	}
+	<-req.done
+	if req.err != nil {
+		return nil, req.err
+	}
+	snapshot := &SubscriptionsSnapshot{}
+	for _, t := range subscriptionTypes {
+		for _, s := range req.statuses[t] {
+			if s.status != pb.SubscriptionStatus_SUBSCRIPTION_STATUS_SUCCESS {
+				continue
+			}
+			snapshot.add(t, s)
+		}
+	}
+	return snapshot, nil
+}
+
+// handleSnapshotResponse - сопоставление ответа с запросом GetMySubscriptions, вызывается под mds.mu.
+// Возвращает false, если ответ относится к запросу подписки
+func (mds *MDStream) handleSnapshotResponse(t SubscriptionType, trackingId string, statuses []instrumentStatus) bool {
+	mds.expireSnapshotsLocked(time.Now())
+	var req *snapshotRequest
+	for _, r := range mds.snapshots {
+		if trackingId != "" && r.trackingId == trackingId {
+			req = r
+			break
+		}
+	}
+	if req == nil {
+		// первым ответом считается ответ на самый ранний запрос без tracking_id, если запрос отправлен
+		// раньше ожидающих запросов подписки этого типа
+		for _, r := range mds.snapshots {
+			if r.trackingId != "" {
+				continue
+			}
+			if len(mds.pending[t]) > 0 && mds.pending[t][0].seq < r.seq {
+				return false
+			}
+			req = r
+			req.trackingId = trackingId
+			break
+		}
+	}
+	if req == nil {
+		return false
+	}
+	if _, ok := req.statuses[t]; ok {
+		mds.mdsClient.logger.Infof("Duplicate %v subscriptions in GetMySubscriptions response, tracking id = %v", t, trackingId)
+		return true
+	}
+	req.statuses[t] = statuses
+	if len(req.statuses) == len(subscriptionTypes) {
+		mds.removeSnapshotLocked(req)
+		if !req.finished() {
+			req.complete(nil)
+		}
+	}
+	return true
+}
+
+// expireSnapshotsLocked - завершение запросов GetMySubscriptions с истекшим временем ожидания, вызывается под mds.mu
+func (mds *MDStream) expireSnapshotsLocked(now time.Time) {
+	for _, r := range append([]*snapshotRequest(nil), mds.snapshots...) {
+		if now.Before(r.expires) {
+			continue
+		}
+		mds.expireSnapshotLocked(r, now)
+	}
+}
+
+// expireSnapshotLocked - завершение запроса GetMySubscriptions с ErrSubscriptionsTimeout, вызывается под mds.mu.
+// Если часть ответа уже получена, запрос еще mySubscriptionsTimeout остается в ожидании, чтобы остальные
+// ответы с его tracking_id не были приняты за ответы на запросы подписки
+func (mds *MDStream) expireSnapshotLocked(r *snapshotRequest, now time.Time) {
+	if !r.finished() {
+		r.complete(ErrSubscriptionsTimeout)
+	}
+	if r.trackingId == "" || !now.Before(r.expires.Add(mySubscriptionsTimeout)) {
+		mds.removeSnapshotLocked(r)
+	}
+}
+
+// removeSnapshotLocked - удаление запроса GetMySubscriptions из ожидания, вызывается под mds.mu
+func (mds *MDStream) removeSnapshotLocked(r *snapshotRequest) {
+	for i, s := range mds.snapshots {
+		if s == r {
+			mds.snapshots = append(mds.snapshots[:i], mds.snapshots[i+1:]...)
+			return
+		}
+	}
+}
+
+// ReconcileSubscriptions - получение подписок от сервера и сравнение их с подписками стрима
+func (mds *MDStream) ReconcileSubscriptions(ctx context.Context) (*SubscriptionsDiff, error) {
+	snapshot, err := mds.MySubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return mds.CompareSubscriptions(snapshot), nil
+}
+
+// CompareSubscriptions - сравнение подписок стрима с подписками на сервере. Инструмент стрима
+// совпадает с подпиской на сервере по figi или instrument_uid
+func (mds *MDStream) CompareSubscriptions(snapshot *SubscriptionsSnapshot) *SubscriptionsDiff {
+	server := snapshot.keys()
+	matched := make([]bool, len(server))
+
+	diff := &SubscriptionsDiff{}
+	for _, key := range mds.subscriptionKeys() {
+		found := false
+		for i, s := range server {
+			if matched[i] || !s.matches(key) {
+				continue
+			}
+			matched[i] = true
+			found = true
+			break
+		}
+		if !found {
+			diff.Missing = append(diff.Missing, key)
+		}
+	}
+	for i, s := range server {
+		if !matched[i] {
+			diff.Unexpected = append(diff.Unexpected, s.SubscriptionKey)
+		}
+	}
+	return diff
+}
+
+// subscriptionKeys - подписки стрима, отсортированные по типу и инструменту
+func (mds *MDStream) subscriptionKeys() []SubscriptionKey {
+	mds.mu.Lock()
+	defer mds.mu.Unlock()
+	keys := make([]SubscriptionKey, 0)
+	for id, interval := range mds.subs.candles {
+		keys = append(keys, SubscriptionKey{Type: SubscriptionCandles, InstrumentId: id, Interval: interval})
+	}
+	for id, depth := range mds.subs.orderBooks {
+		keys = append(keys, SubscriptionKey{Type: SubscriptionOrderBooks, InstrumentId: id, Depth: depth})
+	}
+	for id := range mds.subs.trades {
+		keys = append(keys, SubscriptionKey{Type: SubscriptionTrades, InstrumentId: id})
+	}
+	for id := range mds.subs.tradingStatuses {
+		keys = append(keys, SubscriptionKey{Type: SubscriptionInfo, InstrumentId: id})
+	}
+	for id := range mds.subs.lastPrices {
+		keys = append(keys, SubscriptionKey{Type: SubscriptionLastPrices, InstrumentId: id})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Type != keys[j].Type {
+			return keys[i].Type < keys[j].Type
+		}
+		return keys[i].InstrumentId < keys[j].InstrumentId
+	})
+	return keys
+}
+
+func (s *SubscriptionsSnapshot) add(t SubscriptionType, st instrumentStatus) {
+	inst := SubscribedInstrument{Figi: st.figi, InstrumentUid: st.uid}
+	switch t {
+	case SubscriptionCandles:
+		s.Candles = append(s.Candles, CandleSubscriptionInfo{SubscribedInstrument: inst, Interval: st.interval})
+	case SubscriptionOrderBooks:
+		s.OrderBooks = append(s.OrderBooks, OrderBookSubscriptionInfo{SubscribedInstrument: inst, Depth: st.depth})
+	case SubscriptionTrades:
+		s.Trades = append(s.Trades, inst)
+	case SubscriptionInfo:
+		s.TradingStatuses = append(s.TradingStatuses, inst)
+	case SubscriptionLastPrices:
+		s.LastPrices = append(s.LastPrices, inst)
+	}
+}
+
+// snapshotKey - подписка на сервере с идентификаторами инструмента для сравнения
+type snapshotKey struct {
+	SubscriptionKey
+	inst SubscribedInstrument
+}
+
+func (k snapshotKey) matches(key SubscriptionKey) bool {
+	return k.Type == key.Type && k.Interval == key.Interval && k.Depth == key.Depth && k.inst.matches(key.InstrumentId)
+}
+
+func (s *SubscriptionsSnapshot) keys() []snapshotKey {
+	keys := make([]snapshotKey, 0)
+	appendKey := func(t SubscriptionType, inst SubscribedInstrument, interval pb.SubscriptionInterval, depth int32) {
+		keys = append(keys, snapshotKey{
+			SubscriptionKey: SubscriptionKey{Type: t, InstrumentId: inst.InstrumentId(), Interval: interval, Depth: depth},
+			inst:            inst,
+		})
+	}
+	for _, c := range s.Candles {
+		appendKey(SubscriptionCandles, c.SubscribedInstrument, c.Interval, 0)
+	}
+	for _, ob := range s.OrderBooks {
+		appendKey(SubscriptionOrderBooks, ob.SubscribedInstrument, 0, ob.Depth)
+	}
+	for _, inst := range s.Trades {
+		appendKey(SubscriptionTrades, inst, 0, 0)
+	}
+	for _, inst := range s.TradingStatuses {
+		appendKey(SubscriptionInfo, inst, 0, 0)
+	}
+	for _, inst := range s.LastPrices {
+		appendKey(SubscriptionLastPrices, inst, 0, 0)
+	}
+	return keys
+}
@@ -0,0 +1,123 @@
+package investgo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+// respondSnapshot - ответ сервера на GetMySubscriptions по типам types с общим trackingId
+func respondSnapshot(mds *MDStream, trackingId string, types ...SubscriptionType) {
+	for _, t := range types {
+		mds.handleSubscriptionResponse(t, trackingId, []instrumentStatus{
+			{figi: "snapshot-" + t.String(), status: pb.SubscriptionStatus_SUBSCRIPTION_STATUS_SUCCESS},
+		})
+	}
+}
+
+func TestSnapshotInterleavedWithSubscriptions(t *testing.T) {
+	mds := newTestMDStream()
+	minute := pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE
+	ok := pb.SubscriptionStatus_SUBSCRIPTION_STATUS_SUCCESS
+
+	before := subscribeTestCandles(t, mds, minute, "a")
+	snap, err := mds.requestMySubscriptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := subscribeTestCandles(t, mds, minute, "b")
+
+	mds.handleSubscriptionResponse(SubscriptionCandles, "before", []instrumentStatus{{figi: "a", status: ok}})
+	respondSnapshot(mds, "snapshot", SubscriptionTrades, SubscriptionCandles)
+	mds.handleSubscriptionResponse(SubscriptionCandles, "after", []instrumentStatus{{figi: "b", status: ok}})
+	respondSnapshot(mds, "snapshot", SubscriptionOrderBooks, SubscriptionInfo, SubscriptionLastPrices)
+
+	if res := waitResult(t, before); res.TrackingId != "before" {
+		t.Errorf("request before snapshot: tracking id %v", res.TrackingId)
+	}
+	if res := waitResult(t, after); res.TrackingId != "after" {
+		t.Errorf("request after snapshot: tracking id %v", res.TrackingId)
+	}
+	<-snap.done
+	if snap.err != nil {
+		t.Fatal(snap.err)
+	}
+	for _, st := range subscriptionTypes {
+		if got := snap.statuses[st]; len(got) != 1 || got[0].figi != "snapshot-"+st.String() {
+			t.Errorf("%v: statuses %+v", st, got)
+		}
+	}
+	if len(mds.snapshots) != 0 {
+		t.Errorf("%v snapshots left pending", len(mds.snapshots))
+	}
+}
+
+func TestSnapshotExpiredPartialResponse(t *testing.T) {
+	mds := newTestMDStream()
+	snap, err := mds.requestMySubscriptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	respondSnapshot(mds, "snapshot", SubscriptionCandles, SubscriptionTrades)
+
+	mds.mu.Lock()
+	mds.expireSnapshotsLocked(snap.expires)
+	mds.mu.Unlock()
+	<-snap.done
+	if !errors.Is(snap.err, ErrSubscriptionsTimeout) {
+		t.Fatalf("error %v, want ErrSubscriptionsTimeout", snap.err)
+	}
+
+	// запоздавшие ответы на GetMySubscriptions не сопоставляются с запросом подписки
+	f := subscribeTestCandles(t, mds, pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE, "a")
+	respondSnapshot(mds, "snapshot", SubscriptionOrderBooks, SubscriptionInfo, SubscriptionLastPrices)
+	mds.handleSubscriptionResponse(SubscriptionCandles, "subscribe", []instrumentStatus{
+		{figi: "a", status: pb.SubscriptionStatus_SUBSCRIPTION_STATUS_SUCCESS},
+	})
+	if res := waitResult(t, f); res.TrackingId != "subscribe" {
+		t.Errorf("subscription tracking id %v", res.TrackingId)
+	}
+	if len(mds.snapshots) != 0 {
+		t.Errorf("%v snapshots left pending", len(mds.snapshots))
+	}
+}
+
+func TestSnapshotExpiredWithoutResponse(t *testing.T) {
+	mds := newTestMDStream()
+	snap, err := mds.requestMySubscriptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := subscribeTestCandles(t, mds, pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE, "a")
+
+	mds.mu.Lock()
+	mds.expireSnapshotsLocked(snap.expires.Add(time.Second))
+	mds.mu.Unlock()
+	if len(mds.snapshots) != 0 {
+		t.Fatalf("%v snapshots left pending", len(mds.snapshots))
+	}
+	mds.handleSubscriptionResponse(SubscriptionCandles, "subscribe", []instrumentStatus{
+		{figi: "a", status: pb.SubscriptionStatus_SUBSCRIPTION_STATUS_SUCCESS},
+	})
+	if res := waitResult(t, f); res.TrackingId != "subscribe" {
+		t.Errorf("subscription tracking id %v", res.TrackingId)
+	}
+}
+
+func TestSnapshotFailedOnReconnect(t *testing.T) {
+	mds := newTestMDStream()
+	snap, err := mds.requestMySubscriptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mds.failPending(ErrStreamReconnected)
+	<-snap.done
+	if !errors.Is(snap.err, ErrStreamReconnected) {
+		t.Errorf("error %v, want ErrStreamReconnected", snap.err)
+	}
+	if len(mds.snapshots) != 0 {
+		t.Errorf("%v snapshots left pending", len(mds.snapshots))
+	}
+}
@@ -0,0 +1,796 @@
+package investgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+// DefaultMaxSubscriptionsPerStream - максимальное количество подписок на один стрим пула по умолчанию
+const DefaultMaxSubscriptionsPerStream = 300
+
+// ErrPoolFull - в пуле нет места для подписки: все стримы заполнены, а открыть новый не позволяет лимит
+var ErrPoolFull = errors.New("market data pool is full")
+
+// marketDataStreamMethod - название метода стрима маркетдаты в лимитах тарифа
+const marketDataStreamMethod = "MarketDataStreamService/MarketDataStream"
+
+// PoolOptions - параметры пула стримов маркетдаты
+type PoolOptions struct {
+	// MaxSubscriptionsPerStream - максимальное количество подписок (тип данных и инструмент) на один стрим,
+	// 0 - DefaultMaxSubscriptionsPerStream
+	MaxSubscriptionsPerStream int
+	// MaxStreams - максимальное количество стримов пула, 0 - по лимиту стримов маркетдаты из GetUserTariff
+	MaxStreams int
+}
+
+// MarketDataPool - пул стримов маркетдаты. Подписки распределяются по стримам так, чтобы на стриме было не больше
+// MaxSubscriptionsPerStream подписок, новые стримы открываются по мере необходимости в пределах MaxStreams.
+// Данные всех стримов приходят в общие каналы. После отписки подписки переносятся со слабо загруженных стримов,
+// если их можно разместить на меньшем количестве стримов, и освободившиеся стримы закрываются
+type MarketDataPool struct {
+	mdsClient *MDStreamClient
+	opts      PoolOptions
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	candle        chan *pb.Candle
+	trade         chan *pb.Trade
+	orderBook     chan *pb.OrderBook
+	lastPrice     chan *pb.LastPrice
+	tradingStatus chan *pb.TradingStatus
+
+	mu        sync.Mutex
+	shards    []*poolShard
+	subs      map[poolKey]*poolSubscription
+	reconnect ReconnectPolicy
+	watchdog  WatchdogOptions
+	recorder  *Recorder
+	// closing - пул завершается, новые стримы не открываются
+	closing bool
+	// wg - горутины Listen и пересылки данных стримов
+	wg   sync.WaitGroup
+	done chan struct{}
+}
+
+// poolShard - стрим пула
+type poolShard struct {
+	stream *MDStream
+	count  int
+}
+
+type poolKey struct {
+	t  SubscriptionType
+	id string
+}
+
+// poolSubscription - подписка пула и стрим, на котором она размещена
+type poolSubscription struct {
+	shard    *poolShard
+	interval pb.SubscriptionInterval
+	depth    int32
+}
+
+// MarketDataPool - метод возвращает пул стримов биржевой информации
+func (c *MDStreamClient) MarketDataPool(opts PoolOptions) (*MarketDataPool, error) {
+	return c.MarketDataPoolCtx(c.ctx, opts)
+}
+
+// MarketDataPoolCtx - MarketDataPool, время жизни пула ограничено контекстом ctx
+func (c *MDStreamClient) MarketDataPoolCtx(ctx context.Context, opts PoolOptions) (*MarketDataPool, error) {
+	if opts.MaxSubscriptionsPerStream <= 0 {
+		opts.MaxSubscriptionsPerStream = DefaultMaxSubscriptionsPerStream
+	}
+	if opts.MaxStreams <= 0 {
+		limit, err := c.marketDataStreamLimit(ctx)
+		if err != nil {
+			return nil, err
+		}
+		opts.MaxStreams = limit
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	p := &MarketDataPool{
+		mdsClient:     c,
+		opts:          opts,
+		ctx:           ctx,
+		cancel:        cancel,
+		candle:        make(chan *pb.Candle, 1),
+		trade:         make(chan *pb.Trade, 1),
+		orderBook:     make(chan *pb.OrderBook, 1),
+		lastPrice:     make(chan *pb.LastPrice, 1),
+		tradingStatus: make(chan *pb.TradingStatus, 1),
+		subs:          make(map[poolKey]*poolSubscription, 0),
+		reconnect:     DefaultReconnectPolicy(),
+		done:          make(chan struct{}),
+	}
+	go p.closeOnDone()
+	c.streams.add(p)
+	return p, nil
+}
+
+// marketDataStreamLimit - количество стримов маркетдаты, которое еще можно открыть по тарифу, не меньше 1
+func (c *MDStreamClient) marketDataStreamLimit(ctx context.Context) (int, error) {
+	resp, err := pb.NewUsersServiceClient(c.conn).GetUserTariff(ctx, &pb.GetUserTariffRequest{})
+	if err != nil {
+		return 0, newAPIError(err, nil)
+	}
+	for _, l := range resp.GetStreamLimits() {
+		for _, s := range l.GetStreams() {
+			if strings.HasSuffix(s, marketDataStreamMethod) {
+				if free := int(l.GetLimit() - l.GetOpen()); free > 0 {
+					return free, nil
+				}
+				return 1, nil
+			}
+		}
+	}
+	c.logger.Infof("Market data stream limit not found in user tariff, pool uses one stream")
+	return 1, nil
+}
+
+// Candles - общий канал свечей всех стримов пула
+func (p *MarketDataPool) Candles() <-chan *pb.Candle {
+	return p.candle
+}
+
+// OrderBooks - общий канал стаканов всех стримов пула
+func (p *MarketDataPool) OrderBooks() <-chan *pb.OrderBook {
+	return p.orderBook
+}
+
+// Trades - общий канал обезличенных сделок всех стримов пула
+func (p *MarketDataPool) Trades() <-chan *pb.Trade {
+	return p.trade
+}
+
+// TradingStatuses - общий канал торговых статусов всех стримов пула
+func (p *MarketDataPool) TradingStatuses() <-chan *pb.TradingStatus {
+	return p.tradingStatus
+}
+
+// LastPrices - общий канал последних цен всех стримов пула
+func (p *MarketDataPool) LastPrices() <-chan *pb.LastPrice {
+	return p.lastPrice
+}
+
+// SetReconnectPolicy - установка политики переподключения для всех стримов пула, в том числе будущих
+func (p *MarketDataPool) SetReconnectPolicy(policy ReconnectPolicy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reconnect = policy
+	for _, s := range p.shards {
+		s.stream.SetReconnectPolicy(policy)
+	}
+}
+
+// SetWatchdog - установка проверки поступления Ping для стримов пула, вызывается до первой подписки
+func (p *MarketDataPool) SetWatchdog(opts WatchdogOptions) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.watchdog = opts
+}
+
+// SetRecorder - запись всех сообщений стримов пула, вызывается до первой подписки
+func (p *MarketDataPool) SetRecorder(r *Recorder) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.recorder = r
+}
+
+// Health - статистика активности каждого открытого стрима пула
+func (p *MarketDataPool) Health() []StreamHealth {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	health := make([]StreamHealth, 0, len(p.shards))
+	for _, s := range p.shards {
+		health = append(health, s.stream.Health())
+	}
+	return health
+}
+
+// StreamLoads - количество подписок на каждом открытом стриме пула
+func (p *MarketDataPool) StreamLoads() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	loads := make([]int, 0, len(p.shards))
+	for _, s := range p.shards {
+		loads = append(loads, s.count)
+	}
+	return loads
+}
+
+// SubscribeCandle - подписка на свечи с заданным интервалом, свечи приходят в общий канал пула
+func (p *MarketDataPool) SubscribeCandle(ids []string, interval pb.SubscriptionInterval) (<-chan *pb.Candle, error) {
+	_, err := p.SubscribeCandleAsync(ids, interval)
+	return p.candle, err
+}
+
+// SubscribeCandleAsync - подписка на свечи с ожиданием ответов стримов, свечи приходят в общий канал пула
+func (p *MarketDataPool) SubscribeCandleAsync(ids []string, interval pb.SubscriptionInterval) (*SubscriptionFuture, error) {
+	return p.subscribe(SubscriptionCandles, ids, &poolSubscription{interval: interval})
+}
+
+// SubscribeOrderBook - подписка на стаканы с заданной глубиной, стаканы приходят в общий канал пула
+func (p *MarketDataPool) SubscribeOrderBook(ids []string, depth int32) (<-chan *pb.OrderBook, error) {
+	_, err := p.SubscribeOrderBookAsync(ids, depth)
+	return p.orderBook, err
+}
+
+// SubscribeOrderBookAsync - подписка на стаканы с ожиданием ответов стримов, стаканы приходят в общий канал пула
+func (p *MarketDataPool) SubscribeOrderBookAsync(ids []string, depth int32) (*SubscriptionFuture, error) {
+	return p.subscribe(SubscriptionOrderBooks, ids, &poolSubscription{depth: depth})
+}
+
+// SubscribeTrade - подписка на обезличенные сделки, сделки приходят в общий канал пула
+func (p *MarketDataPool) SubscribeTrade(ids []string) (<-chan *pb.Trade, error) {
+	_, err := p.SubscribeTradeAsync(ids)
+	return p.trade, err
+}
+
+// SubscribeTradeAsync - подписка на обезличенные сделки с ожиданием ответов стримов, сделки приходят
+// в общий канал пула
+func (p *MarketDataPool) SubscribeTradeAsync(ids []string) (*SubscriptionFuture, error) {
+	return p.subscribe(SubscriptionTrades, ids, &poolSubscription{})
+}
+
+// SubscribeInfo - подписка на торговые статусы, статусы приходят в общий канал пула
+func (p *MarketDataPool) SubscribeInfo(ids []string) (<-chan *pb.TradingStatus, error) {
+	_, err := p.SubscribeInfoAsync(ids)
+	return p.tradingStatus, err
+}
+
+// SubscribeInfoAsync - подписка на торговые статусы с ожиданием ответов стримов, статусы приходят
+// в общий канал пула
+func (p *MarketDataPool) SubscribeInfoAsync(ids []string) (*SubscriptionFuture, error) {
+	return p.subscribe(SubscriptionInfo, ids, &poolSubscription{})
+}
+
+// SubscribeLastPrice - подписка на последние цены, цены приходят в общий канал пула
+func (p *MarketDataPool) SubscribeLastPrice(ids []string) (<-chan *pb.LastPrice, error) {
+	_, err := p.SubscribeLastPriceAsync(ids)
+	return p.lastPrice, err
+}
+
+// SubscribeLastPriceAsync - подписка на последние цены с ожиданием ответов стримов, цены приходят
+// в общий канал пула
+func (p *MarketDataPool) SubscribeLastPriceAsync(ids []string) (*SubscriptionFuture, error) {
+	return p.subscribe(SubscriptionLastPrices, ids, &poolSubscription{})
+}
+
+// UnSubscribeCandle - отписка от свечей
+func (p *MarketDataPool) UnSubscribeCandle(ids []string) error {
+	return p.unsubscribe(SubscriptionCandles, ids)
+}
+
+// UnSubscribeOrderBook - отписка от стаканов
+func (p *MarketDataPool) UnSubscribeOrderBook(ids []string) error {
+	return p.unsubscribe(SubscriptionOrderBooks, ids)
+}
+
+// UnSubscribeTrade - отписка от обезличенных сделок
+func (p *MarketDataPool) UnSubscribeTrade(ids []string) error {
+	return p.unsubscribe(SubscriptionTrades, ids)
+}
+
+// UnSubscribeInfo - отписка от торговых статусов
+func (p *MarketDataPool) UnSubscribeInfo(ids []string) error {
+	return p.unsubscribe(SubscriptionInfo, ids)
+}
+
+// UnSubscribeLastPrice - отписка от последних цен
+func (p *MarketDataPool) UnSubscribeLastPrice(ids []string) error {
+	return p.unsubscribe(SubscriptionLastPrices, ids)
+}
+
+// Stop - завершение работы всех стримов пула, после их завершения закрываются общие каналы
+func (p *MarketDataPool) Stop() {
+	p.cancel()
+}
+
+// wait - ожидание завершения стримов пула и закрытия общих каналов
+func (p *MarketDataPool) wait(ctx context.Context) error {
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// subscribe - размещение подписок ids на стримах пула. Инструменты, уже подписанные на этот тип данных,
+// переподписываются на своем стриме, остальные размещаются на наименее загруженных стримах. Возвращает
+// ожидание ответов всех стримов, на которые отправлены подписки, в том числе вместе с ErrPoolFull
+// или ошибкой отправки в один из стримов. При ошибке размещения или отправки изменения этого вызова
+// отменяются: новые подписки освобождают место, у прежних восстанавливаются параметры
+func (p *MarketDataPool) subscribe(t SubscriptionType, ids []string, params *poolSubscription) (*SubscriptionFuture, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closing {
+		return nil, ErrStreamClosed
+	}
+	placements := make(map[*poolShard][]poolPlacement, 0)
+	var rejected []string
+	var err error
+	for _, id := range uniqueIds(ids) {
+		key := poolKey{t: t, id: id}
+		if sub, ok := p.subs[key]; ok {
+			previous := *sub
+			placements[sub.shard] = append(placements[sub.shard], poolPlacement{id: id, previous: &previous})
+			sub.interval, sub.depth = params.interval, params.depth
+			continue
+		}
+		shard, shardErr := p.shardWithCapacity()
+		if shardErr != nil {
+			if !errors.Is(shardErr, ErrPoolFull) {
+				err = shardErr
+				break
+			}
+			rejected = append(rejected, id)
+			continue
+		}
+		shard.count++
+		p.subs[key] = &poolSubscription{shard: shard, interval: params.interval, depth: params.depth}
+		placements[shard] = append(placements[shard], poolPlacement{id: id})
+	}
+	futures := make([]*SubscriptionFuture, 0, len(placements))
+	var sendErrs []error
+	for shard, placed := range placements {
+		if err == nil {
+			future, sendErr := p.send(shard, t, placementIds(placed), params)
+			if sendErr == nil {
+				futures = append(futures, future)
+				continue
+			}
+			sendErrs = append(sendErrs, sendErr)
+		}
+		p.undo(shard, t, placed)
+	}
+	if len(futures) < len(placements) {
+		// закрытие стримов, открытых этим вызовом и оставшихся без подписок
+		if rebalanceErr := p.rebalance(); rebalanceErr != nil {
+			p.mdsClient.logger.Errorf("Market data pool rebalance error: %v", rebalanceErr.Error())
+		}
+	}
+	if err == nil {
+		err = errors.Join(sendErrs...)
+	}
+	var future *SubscriptionFuture
+	if err == nil || len(futures) > 0 {
+		future = joinFutures(t, pb.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE, futures)
+	}
+	if err != nil {
+		return future, err
+	}
+	if len(rejected) > 0 {
+		return future, fmt.Errorf("%w: %v subscriptions are not placed: %v", ErrPoolFull, t, strings.Join(rejected, ", "))
+	}
+	return future, nil
+}
+
+// poolPlacement - размещение подписки на инструмент id в вызове subscribe. previous - параметры подписки
+// до вызова, nil для новой подписки
+type poolPlacement struct {
+	id       string
+	previous *poolSubscription
+}
+
+func placementIds(placed []poolPlacement) []string {
+	ids := make([]string, 0, len(placed))
+	for _, pl := range placed {
+		ids = append(ids, pl.id)
+	}
+	return ids
+}
+
+// undo - отмена неотправленных размещений на стриме shard, вызывается под p.mu
+func (p *MarketDataPool) undo(shard *poolShard, t SubscriptionType, placed []poolPlacement) {
+	for _, pl := range placed {
+		key := poolKey{t: t, id: pl.id}
+		if pl.previous == nil {
+			p.release(key, shard)
+			continue
+		}
+		if sub, ok := p.subs[key]; ok {
+			sub.interval, sub.depth = pl.previous.interval, pl.previous.depth
+		}
+	}
+}
+
+// joinFutures - ожидание ответов нескольких стримов на подписку одного типа. Результаты объединяются,
+// TrackingId заполняется, только если запрос отправлен в один стрим. Стрим завершает ожидание ответа
+// при своей остановке, поэтому горутина объединения не остается после остановки пула
+func joinFutures(t SubscriptionType, action pb.SubscriptionAction, futures []*SubscriptionFuture) *SubscriptionFuture {
+	if len(futures) == 1 {
+		return futures[0]
+	}
+	joined := newSubscriptionFuture()
+	go func() {
+		result := &SubscriptionResult{Type: t, Action: action}
+		for _, f := range futures {
+			<-f.Done()
+			if f.err != nil {
+				joined.complete(nil, f.err)
+				return
+			}
+			result.Succeeded = append(result.Succeeded, f.result.Succeeded...)
+			result.Failed = append(result.Failed, f.result.Failed...)
+		}
+		joined.complete(result, nil)
+	}()
+	return joined
+}
+
+// send - отправка подписки в стрим, инструменты с ошибкой подписки в ответе стрима освобождают место в пуле.
+// При ошибке отправки подписки пула не изменяются, их восстанавливает вызывающий код
+func (p *MarketDataPool) send(shard *poolShard, t SubscriptionType, ids []string, params *poolSubscription) (*SubscriptionFuture, error) {
+	var future *SubscriptionFuture
+	var err error
+	switch t {
+	case SubscriptionCandles:
+		future, err = shard.stream.SubscribeCandleAsync(ids, params.interval)
+	case SubscriptionOrderBooks:
+		future, err = shard.stream.SubscribeOrderBookAsync(ids, params.depth)
+	case SubscriptionTrades:
+		future, err = shard.stream.SubscribeTradeAsync(ids)
+	case SubscriptionInfo:
+		future, err = shard.stream.SubscribeInfoAsync(ids)
+	case SubscriptionLastPrices:
+		future, err = shard.stream.SubscribeLastPriceAsync(ids)
+	}
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		res, err := future.Wait(p.ctx)
+		if err != nil {
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if len(res.Failed) == 0 || p.closing {
+			return
+		}
+		for _, e := range res.Failed {
+			p.release(poolKey{t: t, id: e.InstrumentId}, shard)
+		}
+		if err := p.rebalance(); err != nil {
+			p.mdsClient.logger.Errorf("Market data pool rebalance error: %v", err.Error())
+		}
+	}()
+	return future, nil
+}
+
+// unsubscribe - отписка ids на их стримах и перераспределение подписок
+func (p *MarketDataPool) unsubscribe(t SubscriptionType, ids []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	byShard := make(map[*poolShard][]string, 0)
+	for _, id := range uniqueIds(ids) {
+		if sub, ok := p.subs[poolKey{t: t, id: id}]; ok {
+			byShard[sub.shard] = append(byShard[sub.shard], id)
+		}
+	}
+	for shard, shardIds := range byShard {
+		var err error
+		if t == SubscriptionCandles {
+			for interval, ids := range p.candleIntervals(shardIds) {
+				if err = unsubscribeStream(shard.stream, t, ids, interval); err != nil {
+					break
+				}
+			}
+		} else {
+			err = unsubscribeStream(shard.stream, t, shardIds, 0)
+		}
+		if err != nil {
+			return err
+		}
+		for _, id := range shardIds {
+			p.release(poolKey{t: t, id: id}, shard)
+		}
+	}
+	return p.rebalance()
+}
+
+// unsubscribeStream - отписка ids от данных типа t на стриме, interval - интервал подписки на свечи
+func unsubscribeStream(stream *MDStream, t SubscriptionType, ids []string, interval pb.SubscriptionInterval) error {
+	switch t {
+	case SubscriptionCandles:
+		return stream.UnSubscribeCandle(ids, interval)
+	case SubscriptionOrderBooks:
+		return stream.UnSubscribeOrderBook(ids)
+	case SubscriptionTrades:
+		return stream.UnSubscribeTrade(ids)
+	case SubscriptionInfo:
+		return stream.UnSubscribeInfo(ids)
+	case SubscriptionLastPrices:
+		return stream.UnSubscribeLastPrice(ids)
+	}
+	return nil
+}
+
+// candleIntervals - инструменты подписок на свечи, сгруппированные по интервалу, вызывается под p.mu
+func (p *MarketDataPool) candleIntervals(ids []string) map[pb.SubscriptionInterval][]string {
+	intervals := make(map[pb.SubscriptionInterval][]string, 0)
+	for _, id := range ids {
+		interval := p.subs[poolKey{t: SubscriptionCandles, id: id}].interval
+		intervals[interval] = append(intervals[interval], id)
+	}
+	return intervals
+}
+
+// release - освобождение места подписки key на стриме shard, вызывается под p.mu
+func (p *MarketDataPool) release(key poolKey, shard *poolShard) {
+	sub, ok := p.subs[key]
+	if !ok || sub.shard != shard {
+		return
+	}
+	delete(p.subs, key)
+	shard.count--
+}
+
+// rebalance - закрытие пустых стримов и перенос подписок с наименее загруженного стрима, пока все подписки
+// помещаются на меньшем количестве стримов. Стрим, с которого перенесены подписки, останавливается
+// после ответов стримов, на которые они перенесены, чтобы данные по ним не прерывались. Вызывается под p.mu
+func (p *MarketDataPool) rebalance() error {
+	for {
+		sort.SliceStable(p.shards, func(i, j int) bool {
+			return p.shards[i].count > p.shards[j].count
+		})
+		n := len(p.shards)
+		if n == 0 {
+			return nil
+		}
+		last := p.shards[n-1]
+		if last.count > 0 {
+			total := 0
+			for _, s := range p.shards {
+				total += s.count
+			}
+			if n == 1 || total > (n-1)*p.opts.MaxSubscriptionsPerStream {
+				return nil
+			}
+		}
+		p.shards = p.shards[:n-1]
+		moves, err := p.move(last)
+		if last.count > 0 {
+			// часть подписок не перенесена, стрим остается в пуле
+			p.shards = append(p.shards, last)
+		}
+		go p.retire(last, moves)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// poolMove - подписки одного типа и с одинаковыми параметрами, перенесенные на стрим shard
+type poolMove struct {
+	shard  *poolShard
+	t      SubscriptionType
+	params poolSubscription
+	ids    []string
+	future *SubscriptionFuture
+}
+
+// move - перенос подписок стрима from на остальные стримы пула, вызывается под p.mu. Подписки, которые
+// не удалось разместить или отправить, остаются на стриме from
+func (p *MarketDataPool) move(from *poolShard) ([]*poolMove, error) {
+	type group struct {
+		shard *poolShard
+		t     SubscriptionType
+		poolSubscription
+	}
+	groups := make(map[group][]string, 0)
+	var err error
+	for key, sub := range p.subs {
+		if sub.shard != from {
+			continue
+		}
+		to, shardErr := p.shardWithCapacity()
+		if shardErr != nil {
+			err = shardErr
+			break
+		}
+		from.count--
+		to.count++
+		sub.shard = to
+		g := group{shard: to, t: key.t, poolSubscription: poolSubscription{interval: sub.interval, depth: sub.depth}}
+		groups[g] = append(groups[g], key.id)
+	}
+	moves := make([]*poolMove, 0, len(groups))
+	for g, ids := range groups {
+		m := &poolMove{shard: g.shard, t: g.t, params: g.poolSubscription, ids: ids}
+		future, sendErr := p.send(g.shard, g.t, ids, &m.params)
+		if sendErr != nil {
+			for _, id := range ids {
+				if sub, ok := p.subs[poolKey{t: g.t, id: id}]; ok && sub.shard == g.shard {
+					sub.shard = from
+					g.shard.count--
+					from.count++
+				}
+			}
+			if err == nil {
+				err = sendErr
+			}
+			continue
+		}
+		m.future = future
+		moves = append(moves, m)
+	}
+	return moves, err
+}
+
+// retire - завершение переноса подписок со стрима shard после ответов стримов, на которые они перенесены.
+// Стрим, удаленный из пула, останавливается, у оставшегося в пуле отменяются перенесенные подписки
+func (p *MarketDataPool) retire(shard *poolShard, moves []*poolMove) {
+	for _, m := range moves {
+		// ответ с ошибкой подписки освобождает ее место в пуле, подписку на прежнем стриме сохранять не нужно
+		_, _ = m.future.Wait(p.ctx)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	inPool := false
+	for _, s := range p.shards {
+		if s == shard {
+			inPool = true
+			break
+		}
+	}
+	if !inPool {
+		shard.stream.Stop()
+		return
+	}
+	for _, m := range moves {
+		ids := make([]string, 0, len(m.ids))
+		for _, id := range m.ids {
+			if sub, ok := p.subs[poolKey{t: m.t, id: id}]; !ok || sub.shard != shard {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		if err := unsubscribeStream(shard.stream, m.t, ids, m.params.interval); err != nil {
+			p.mdsClient.logger.Errorf("Market data pool unsubscribe error: %v", err.Error())
+		}
+	}
+}
+
+// shardWithCapacity - наименее загруженный стрим со свободным местом, при необходимости открывается новый стрим.
+// Вызывается под p.mu
+func (p *MarketDataPool) shardWithCapacity() (*poolShard, error) {
+	var best *poolShard
+	for _, s := range p.shards {
+		if s.count < p.opts.MaxSubscriptionsPerStream && (best == nil || s.count < best.count) {
+			best = s
+		}
+	}
+	if best != nil {
+		return best, nil
+	}
+	if len(p.shards) >= p.opts.MaxStreams {
+		return nil, ErrPoolFull
+	}
+	return p.openShard()
+}
+
+// openShard - открытие нового стрима пула, вызывается под p.mu. После начала завершения пула стримы
+// не открываются, чтобы p.wg.Add не выполнялся после p.wg.Wait в closeOnDone
+func (p *MarketDataPool) openShard() (*poolShard, error) {
+	if p.closing {
+		return nil, ErrStreamClosed
+	}
+	stream, err := p.mdsClient.MarketDataStreamCtx(p.ctx)
+	if err != nil {
+		return nil, err
+	}
+	stream.SetReconnectPolicy(p.reconnect)
+	stream.SetWatchdog(p.watchdog)
+	stream.SetRecorder(p.recorder)
+	shard := &poolShard{stream: stream}
+	p.shards = append(p.shards, shard)
+	p.wg.Add(2)
+	go func() {
+		defer p.wg.Done()
+		err := stream.Listen()
+		if err != nil {
+			p.mdsClient.logger.Errorf("Market data pool stream error: %v", err.Error())
+		}
+	}()
+	go func() {
+		defer p.wg.Done()
+		p.forward(stream)
+	}()
+	return shard, nil
+}
+
+// forward - пересылка данных стрима в общие каналы пула до закрытия каналов стрима
+func (p *MarketDataPool) forward(src MarketDataChannels) {
+	candles, orderBooks, trades := src.Candles(), src.OrderBooks(), src.Trades()
+	tradingStatuses, lastPrices := src.TradingStatuses(), src.LastPrices()
+	for candles != nil || orderBooks != nil || trades != nil || tradingStatuses != nil || lastPrices != nil {
+		select {
+		case c, ok := <-candles:
+			if !ok {
+				candles = nil
+				continue
+			}
+			select {
+			case p.candle <- c:
+			case <-p.ctx.Done():
+			}
+		case ob, ok := <-orderBooks:
+			if !ok {
+				orderBooks = nil
+				continue
+			}
+			select {
+			case p.orderBook <- ob:
+			case <-p.ctx.Done():
+			}
+		case t, ok := <-trades:
+			if !ok {
+				trades = nil
+				continue
+			}
+			select {
+			case p.trade <- t:
+			case <-p.ctx.Done():
+			}
+		case ts, ok := <-tradingStatuses:
+			if !ok {
+				tradingStatuses = nil
+				continue
+			}
+			select {
+			case p.tradingStatus <- ts:
+			case <-p.ctx.Done():
+			}
+		case lp, ok := <-lastPrices:
+			if !ok {
+				lastPrices = nil
+				continue
+			}
+			select {
+			case p.lastPrice <- lp:
+			case <-p.ctx.Done():
+			}
+		}
+	}
+}
+
+// closeOnDone - закрытие общих каналов после остановки пула и завершения всех его стримов
+func (p *MarketDataPool) closeOnDone() {
+	<-p.ctx.Done()
+	p.mu.Lock()
+	p.closing = true
+	p.mu.Unlock()
+	p.wg.Wait()
+	p.mdsClient.logger.Infof("Close market data pool")
+	close(p.candle)
+	close(p.trade)
+	close(p.lastPrice)
+	close(p.orderBook)
+	close(p.tradingStatus)
+	close(p.done)
+	p.mdsClient.streams.remove(p)
+}
+
+func uniqueIds(ids []string) []string {
+	seen := make(map[string]struct{}, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+	return unique
+}
@@ -0,0 +1,135 @@
+package investgo
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+// newTestShardPool - пул из стримов streams без grpc соединения, новые стримы не открываются
+func newTestShardPool(maxPerStream int, streams ...*MDStream) *MarketDataPool {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &MarketDataPool{
+		mdsClient: &MDStreamClient{logger: nopLogger{}},
+		opts:      PoolOptions{MaxSubscriptionsPerStream: maxPerStream, MaxStreams: len(streams)},
+		ctx:       ctx,
+		cancel:    cancel,
+		subs:      make(map[poolKey]*poolSubscription, 0),
+	}
+	for _, s := range streams {
+		p.shards = append(p.shards, &poolShard{stream: s})
+	}
+	return p
+}
+
+// respondAll - успешные ответы стрима на все ожидающие запросы подписки типа t
+func respondAll(mds *MDStream, t SubscriptionType) {
+	mds.mu.Lock()
+	pending := append([]*pendingRequest(nil), mds.pending[t]...)
+	mds.mu.Unlock()
+	for _, req := range pending {
+		statuses := make([]instrumentStatus, 0, len(req.ids))
+		for _, id := range req.ids {
+			statuses = append(statuses, instrumentStatus{figi: id, status: pb.SubscriptionStatus_SUBSCRIPTION_STATUS_SUCCESS})
+		}
+		mds.handleSubscriptionResponse(t, "", statuses)
+	}
+}
+
+// poolSub - копия подписки пула на инструмент id
+func poolSub(p *MarketDataPool, t SubscriptionType, id string) (poolSubscription, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	sub, ok := p.subs[poolKey{t: t, id: id}]
+	if !ok {
+		return poolSubscription{}, false
+	}
+	return *sub, true
+}
+
+func TestMarketDataPoolSendError(t *testing.T) {
+	a, b := newTestMDStream(), newTestMDStream()
+	p := newTestShardPool(2, a, b)
+	defer p.Stop()
+	oneMinute := pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE
+	fiveMinutes := pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_FIVE_MINUTES
+
+	if _, err := p.subscribe(SubscriptionCandles, []string{"x", "y"}, &poolSubscription{interval: oneMinute}); err != nil {
+		t.Fatal(err)
+	}
+	b.stream.(*testStream).err = errors.New("send failed")
+
+	// x переподписывается на a вместе с новой подпиской z, переподписка y на b не отправлена
+	future, err := p.subscribe(SubscriptionCandles, []string{"x", "y", "z"}, &poolSubscription{interval: fiveMinutes})
+	if err == nil || future == nil {
+		t.Fatalf("future %v, error %v, want future of the sent shard with send error", future, err)
+	}
+	respondAll(a, SubscriptionCandles)
+	res := waitResult(t, future)
+	sort.Strings(res.Succeeded)
+	if len(res.Succeeded) != 2 || res.Succeeded[0] != "x" || res.Succeeded[1] != "z" {
+		t.Errorf("succeeded %v, want x and z", res.Succeeded)
+	}
+	// прежняя подписка y остается с прежним интервалом
+	if sub, ok := poolSub(p, SubscriptionCandles, "y"); !ok || sub.interval != oneMinute || sub.shard.stream != b {
+		t.Errorf("subscription y %+v, want kept on b with one minute interval", sub)
+	}
+	if sub, ok := poolSub(p, SubscriptionCandles, "x"); !ok || sub.interval != fiveMinutes {
+		t.Errorf("subscription x %+v, want five minutes interval", sub)
+	}
+
+	// новая подписка на стриме с ошибкой отправки освобождает место
+	future, err = p.subscribe(SubscriptionCandles, []string{"w"}, &poolSubscription{interval: oneMinute})
+	if err == nil || future != nil {
+		t.Fatalf("future %v, error %v, want send error only", future, err)
+	}
+	if _, ok := poolSub(p, SubscriptionCandles, "w"); ok {
+		t.Error("subscription w is kept after send error")
+	}
+	if loads := p.StreamLoads(); len(loads) != 2 || loads[0]+loads[1] != 3 {
+		t.Errorf("stream loads %v, want 3 subscriptions on 2 streams", loads)
+	}
+}
+
+func TestMarketDataPoolRebalanceWaitsForMove(t *testing.T) {
+	a, b := newTestMDStream(), newTestMDStream()
+	p := newTestShardPool(2, a, b)
+	defer p.Stop()
+	if _, err := p.subscribe(SubscriptionTrades, []string{"x", "y", "z"}, &poolSubscription{}); err != nil {
+		t.Fatal(err)
+	}
+	respondAll(a, SubscriptionTrades)
+	respondAll(b, SubscriptionTrades)
+
+	// после отписки x подписки помещаются на одном стриме, одна из них переносится
+	if err := p.UnSubscribeTrade([]string{"x"}); err != nil {
+		t.Fatal(err)
+	}
+	if loads := p.StreamLoads(); len(loads) != 1 || loads[0] != 2 {
+		t.Fatalf("stream loads %v, want 2 on one stream", loads)
+	}
+	p.mu.Lock()
+	remaining := p.shards[0].stream
+	p.mu.Unlock()
+	old := a
+	if remaining == a {
+		old = b
+	}
+	time.Sleep(20 * time.Millisecond)
+	if old.ctx.Err() != nil {
+		t.Fatal("stream is stopped before the moved subscription is confirmed")
+	}
+	respondAll(remaining, SubscriptionTrades)
+	deadline := time.After(10 * time.Second)
+	for old.ctx.Err() == nil {
+		select {
+		case <-deadline:
+			t.Fatal("stream is not stopped after the moved subscription is confirmed")
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package investgo_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/therox/invest-api-go-sdk/investgo"
+	"github.com/therox/invest-api-go-sdk/investgo/investgotest"
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+func newTestPool(t *testing.T, client *investgo.Client, opts investgo.PoolOptions) investgo.MarketDataPooler {
+	t.Helper()
+	pool, err := client.NewMDStreamClient().MarketDataPool(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pool
+}
+
+func instrumentIds(prefix string, n int) []string {
+	ids := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		ids = append(ids, fmt.Sprintf("%v-%v", prefix, i))
+	}
+	return ids
+}
+
+// waitLoads - ожидание, пока загрузка стримов пула не станет равной want
+func waitLoads(ctx context.Context, t *testing.T, pool investgo.MarketDataPooler, want ...int) {
+	t.Helper()
+	for {
+		loads := pool.StreamLoads()
+		sort.Sort(sort.Reverse(sort.IntSlice(loads)))
+		if fmt.Sprint(loads) == fmt.Sprint(want) {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("stream loads %v, want %v", loads, want)
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+// receiveTrade - отправка сделки сервером до ее получения из пула. Сервер открывает стрим при получении заголовков
+// от клиента, поэтому только что открытый клиентом стрим может еще не получать сообщения сервера
+func receiveTrade(ctx context.Context, t *testing.T, server *investgotest.Server, pool investgo.MarketDataPooler, id string) {
+	t.Helper()
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	server.PushTrade(&pb.Trade{Figi: id})
+	for {
+		select {
+		case trade := <-pool.Trades():
+			if trade.GetFigi() == id {
+				return
+			}
+		case <-ticker.C:
+			server.PushTrade(&pb.Trade{Figi: id})
+		case <-ctx.Done():
+			t.Fatalf("trade %v is not received", id)
+		}
+	}
+}
+
+func TestMarketDataPoolSharding(t *testing.T) {
+	client, server := investgotest.NewClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	pool := newTestPool(t, client, investgo.PoolOptions{MaxSubscriptionsPerStream: 2, MaxStreams: 3})
+
+	if _, err := pool.SubscribeTrade(instrumentIds("trade", 3)); err != nil {
+		t.Fatal(err)
+	}
+	waitLoads(ctx, t, pool, 2, 1)
+	// подписки разных типов на один инструмент занимают отдельные места
+	if _, err := pool.SubscribeLastPrice([]string{"trade-0", "trade-0"}); err != nil {
+		t.Fatal(err)
+	}
+	waitLoads(ctx, t, pool, 2, 2)
+	if err := server.WaitStreams(ctx, investgotest.MarketDataStream, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := pool.SubscribeCandle(instrumentIds("candle", 3), pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE)
+	if !errors.Is(err, investgo.ErrPoolFull) {
+		t.Fatalf("error %v, want ErrPoolFull", err)
+	}
+	waitLoads(ctx, t, pool, 2, 2, 2)
+	if err := server.WaitStreams(ctx, investgotest.MarketDataStream, 3); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(pool.Health()); n != 3 {
+		t.Errorf("health of %v streams, want 3", n)
+	}
+
+	receiveTrade(ctx, t, server, pool, "trade-1")
+}
+
+func TestMarketDataPoolRebalance(t *testing.T) {
+	client, server := investgotest.NewClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	pool := newTestPool(t, client, investgo.PoolOptions{MaxSubscriptionsPerStream: 2, MaxStreams: 3})
+
+	ids := instrumentIds("trade", 5)
+	if _, err := pool.SubscribeTrade(ids); err != nil {
+		t.Fatal(err)
+	}
+	waitLoads(ctx, t, pool, 2, 2, 1)
+
+	// после отписки оставшиеся подписки помещаются на одном стриме, остальные стримы закрываются
+	if err := pool.UnSubscribeTrade(ids[:3]); err != nil {
+		t.Fatal(err)
+	}
+	waitLoads(ctx, t, pool, 2)
+	if err := server.WaitStreams(ctx, investgotest.MarketDataStream, 1); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range ids[3:] {
+		receiveTrade(ctx, t, server, pool, id)
+	}
+}
+
+func TestMarketDataPoolFailedSubscriptionReleased(t *testing.T) {
+	client, server := investgotest.NewClient(t)
+	server.SetSubscriptionStatus("unknown", pb.SubscriptionStatus_SUBSCRIPTION_STATUS_INSTRUMENT_NOT_FOUND)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	pool := newTestPool(t, client, investgo.PoolOptions{MaxSubscriptionsPerStream: 2, MaxStreams: 2})
+
+	// подписки размещаются на двух стримах, ответы стримов объединяются
+	f, err := pool.SubscribeTradeAsync([]string{"known-0", "unknown", "known-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := f.Wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Succeeded) != 2 || len(res.Failed) != 1 || res.Failed[0].InstrumentId != "unknown" {
+		t.Errorf("succeeded %v, failed %v, want unknown failed", res.Succeeded, res.Err())
+	}
+	// место неуспешной подписки освобождается, подписки переносятся на один стрим
+	waitLoads(ctx, t, pool, 2)
+	if err := server.WaitStreams(ctx, investgotest.MarketDataStream, 1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMarketDataPoolStop(t *testing.T) {
+	client, server := investgotest.NewClient(t)
+	server.SetSubscriptionStatus("unknown", pb.SubscriptionStatus_SUBSCRIPTION_STATUS_INSTRUMENT_NOT_FOUND)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	pool := newTestPool(t, client, investgo.PoolOptions{MaxSubscriptionsPerStream: 1, MaxStreams: 4})
+
+	// ответы с ошибкой подписки перераспределяют подписки пула одновременно с его остановкой
+	if _, err := pool.SubscribeTrade([]string{"known", "unknown"}); err != nil {
+		t.Fatal(err)
+	}
+	pool.Stop()
+	for range pool.Trades() {
+	}
+	if _, err := pool.SubscribeTrade([]string{"known"}); !errors.Is(err, investgo.ErrStreamClosed) {
+		t.Errorf("subscribe after Stop: error %v, want ErrStreamClosed", err)
+	}
+	if err := server.WaitStreams(ctx, investgotest.MarketDataStream, 0); err != nil {
+		t.Error(err)
+	}
+}
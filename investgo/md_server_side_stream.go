@@ -0,0 +1,216 @@
+package investgo
+
+import (
+	"context"
+	"sync"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+// ServerSideSubscriptions - набор подписок server-side стрима маркетдаты, задается при открытии стрима
+type ServerSideSubscriptions struct {
+	// Candles - инструменты и интервал свечей
+	Candles map[string]pb.SubscriptionInterval
+	// WaitingClose - отправка свечей только после их закрытия
+	WaitingClose bool
+	// OrderBooks - инструменты и глубина стакана
+	OrderBooks      map[string]int32
+	Trades          []string
+	TradingStatuses []string
+	LastPrices      []string
+}
+
+func (s ServerSideSubscriptions) request() *pb.MarketDataServerSideStreamRequest {
+	act := pb.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE
+	req := &pb.MarketDataServerSideStreamRequest{}
+	if len(s.Candles) > 0 {
+		instruments := make([]*pb.CandleInstrument, 0, len(s.Candles))
+		for id, interval := range s.Candles {
+			instruments = append(instruments, &pb.CandleInstrument{InstrumentId: id, Interval: interval})
+		}
+		req.SubscribeCandlesRequest = &pb.SubscribeCandlesRequest{
+			SubscriptionAction: act,
+			Instruments:        instruments,
+			WaitingClose:       s.WaitingClose,
+		}
+	}
+	if len(s.OrderBooks) > 0 {
+		instruments := make([]*pb.OrderBookInstrument, 0, len(s.OrderBooks))
+		for id, depth := range s.OrderBooks {
+			instruments = append(instruments, &pb.OrderBookInstrument{InstrumentId: id, Depth: depth})
+		}
+		req.SubscribeOrderBookRequest = &pb.SubscribeOrderBookRequest{SubscriptionAction: act, Instruments: instruments}
+	}
+	if len(s.Trades) > 0 {
+		instruments := make([]*pb.TradeInstrument, 0, len(s.Trades))
+		for _, id := range s.Trades {
+			instruments = append(instruments, &pb.TradeInstrument{InstrumentId: id})
+		}
+		req.SubscribeTradesRequest = &pb.SubscribeTradesRequest{SubscriptionAction: act, Instruments: instruments}
+	}
+	if len(s.TradingStatuses) > 0 {
+		instruments := make([]*pb.InfoInstrument, 0, len(s.TradingStatuses))
+		for _, id := range s.TradingStatuses {
+			instruments = append(instruments, &pb.InfoInstrument{InstrumentId: id})
+		}
+		req.SubscribeInfoRequest = &pb.SubscribeInfoRequest{SubscriptionAction: act, Instruments: instruments}
+	}
+	if len(s.LastPrices) > 0 {
+		instruments := make([]*pb.LastPriceInstrument, 0, len(s.LastPrices))
+		for _, id := range s.LastPrices {
+			instruments = append(instruments, &pb.LastPriceInstrument{InstrumentId: id})
+		}
+		req.SubscribeLastPriceRequest = &pb.SubscribeLastPriceRequest{SubscriptionAction: act, Instruments: instruments}
+	}
+	return req
+}
+
+// ServerSideStream - server-side стрим маркетдаты с набором подписок, заданным при открытии.
+// Не требует отправки запросов в стрим, поэтому подходит для работы через прокси, плохо поддерживающие
+// bidirectional стримы. При обрыве соединения стрим переоткрывается с тем же набором подписок
+type ServerSideStream struct {
+	stream    pb.MarketDataStreamService_MarketDataServerSideStreamClient
+	mdsClient *MDStreamClient
+	req       *pb.MarketDataServerSideStreamRequest
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	// streamCancel - отмена текущего grpc стрима, при переподключении создается новый
+	streamCancel context.CancelFunc
+
+	listener *mdListener
+
+	// mu - защита ошибок подписки
+	mu     sync.Mutex
+	failed []*SubscriptionError
+}
+
+// Candles - канал свечей по подпискам стрима
+func (s *ServerSideStream) Candles() <-chan *pb.Candle {
+	return s.listener.candle
+}
+
+// OrderBooks - канал стаканов по подпискам стрима
+func (s *ServerSideStream) OrderBooks() <-chan *pb.OrderBook {
+	return s.listener.orderBook
+}
+
+// Trades - канал обезличенных сделок по подпискам стрима
+func (s *ServerSideStream) Trades() <-chan *pb.Trade {
+	return s.listener.trade
+}
+
+// TradingStatuses - канал торговых статусов по подпискам стрима
+func (s *ServerSideStream) TradingStatuses() <-chan *pb.TradingStatus {
+	return s.listener.tradingStatus
+}
+
+// LastPrices - канал последних цен по подпискам стрима
+func (s *ServerSideStream) LastPrices() <-chan *pb.LastPrice {
+	return s.listener.lastPrice
+}
+
+// Failed - инструменты с ошибкой подписки по ответу сервера на текущее подключение
+func (s *ServerSideStream) Failed() []*SubscriptionError {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*SubscriptionError(nil), s.failed...)
+}
+
+// SetReconnectPolicy - метод установки политики переподключения стрима, применяется со следующего переподключения
+func (s *ServerSideStream) SetReconnectPolicy(p ReconnectPolicy) {
+	s.listener.setReconnectPolicy(p)
+}
+
+// SetWatchdog - установка проверки поступления Ping, вызывается до Listen. Принудительное переподключение
+// выполняется согласно ReconnectPolicy
+func (s *ServerSideStream) SetWatchdog(opts WatchdogOptions) {
+	s.listener.watchdog.setOptions(opts)
+}
+
+// Health - статистика активности стрима
+func (s *ServerSideStream) Health() StreamHealth {
+	return s.listener.watchdog.stats()
+}
+
+// SetRecorder - запись всех сообщений стрима, вызывается до Listen
+func (s *ServerSideStream) SetRecorder(r *Recorder) {
+	s.listener.recorder = r
+}
+
+// Listen - метод начинает слушать стрим и отправлять информацию в каналы. При обрыве соединения
+// стрим переоткрывается согласно ReconnectPolicy, каналы не закрываются
+func (s *ServerSideStream) Listen() error {
+	if !s.listener.lifecycle.begin() {
+		return nil
+	}
+	defer s.shutdown()
+	return s.listener.listen(s)
+}
+
+// recv - получение ответа из текущего grpc стрима, стрим заменяется только из горутины Listen
+func (s *ServerSideStream) recv() (*pb.MarketDataResponse, error) {
+	return s.stream.Recv()
+}
+
+// reopenStream - переоткрытие стрима с тем же набором подписок
+func (s *ServerSideStream) reopenStream() error {
+	return s.openStream()
+}
+
+// openStream - открытие нового grpc стрима с набором подписок в рамках контекста ServerSideStream
+func (s *ServerSideStream) openStream() error {
+	ctx, cancel := context.WithCancel(s.ctx)
+	stream, err := s.mdsClient.pbClient.MarketDataServerSideStream(ctx, s.req)
+	if err != nil {
+		cancel()
+		return err
+	}
+	if s.streamCancel != nil {
+		s.streamCancel()
+	}
+	s.stream = stream
+	s.streamCancel = cancel
+	s.listener.watchdog.connected(cancel)
+	s.mu.Lock()
+	s.failed = nil
+	s.mu.Unlock()
+	return nil
+}
+
+// handleSubscriptionResponse - сохранение и логирование ошибок подписки из ответа сервера
+func (s *ServerSideStream) handleSubscriptionResponse(t SubscriptionType, trackingId string, statuses []instrumentStatus) {
+	for _, st := range statuses {
+		if st.status == pb.SubscriptionStatus_SUBSCRIPTION_STATUS_SUCCESS {
+			continue
+		}
+		id := st.uid
+		if id == "" {
+			id = st.figi
+		}
+		e := &SubscriptionError{Type: t, InstrumentId: id, Status: st.status}
+		s.mu.Lock()
+		s.failed = append(s.failed, e)
+		s.mu.Unlock()
+		s.mdsClient.logger.Errorf("Market data %v, tracking id = %v", e.Error(), trackingId)
+	}
+}
+
+func (s *ServerSideStream) shutdown() {
+	s.listener.lifecycle.end(s.listener.closeChannels)
+	s.mdsClient.streams.remove(s)
+}
+
+// Stop - Завершение работы стрима
+func (s *ServerSideStream) Stop() {
+	s.cancel()
+}
+
+// wait - ожидание завершения Listen и закрытия каналов
+func (s *ServerSideStream) wait(ctx context.Context) error {
+	err := s.listener.lifecycle.wait(ctx, s.listener.closeChannels)
+	if err == nil {
+		s.mdsClient.streams.remove(s)
+	}
+	return err
+}
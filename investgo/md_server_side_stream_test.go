@@ -0,0 +1,88 @@
+package investgo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/therox/invest-api-go-sdk/investgo"
+	"github.com/therox/invest-api-go-sdk/investgo/investgotest"
+	pb "github.com/therox/invest-api-go-sdk/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServerSideStreamReconnect(t *testing.T) {
+	client, server := investgotest.NewClient(t)
+	server.SetSubscriptionStatus("unknown", pb.SubscriptionStatus_SUBSCRIPTION_STATUS_INSTRUMENT_NOT_FOUND)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	interval := pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE
+	s, err := client.NewMDStreamClient().ServerSideStream(investgo.ServerSideSubscriptions{
+		Candles: map[string]pb.SubscriptionInterval{"known": interval, "unknown": interval},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reconnected := make(chan struct{}, 1)
+	policy := investgo.DefaultReconnectPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.OnReconnect = func(attempt int, err error) {
+		if err == nil {
+			reconnected <- struct{}{}
+		}
+	}
+	s.SetReconnectPolicy(policy)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Listen()
+	}()
+
+	receiveCandle := func() {
+		t.Helper()
+		if err := server.WaitStreams(ctx, investgotest.MarketDataServerSideStream, 1); err != nil {
+			t.Fatal(err)
+		}
+		server.PushCandle(&pb.Candle{Figi: "known", Interval: interval})
+		select {
+		case c := <-s.Candles():
+			if c.GetFigi() != "known" {
+				t.Errorf("candle figi %v", c.GetFigi())
+			}
+		case <-ctx.Done():
+			t.Fatal("candle is not received")
+		}
+	}
+	receiveCandle()
+	if failed := s.Failed(); len(failed) != 1 || failed[0].InstrumentId != "unknown" {
+		t.Errorf("failed subscriptions %v, want unknown", failed)
+	}
+
+	server.BreakStreams(status.Error(codes.Unavailable, "connection lost"))
+	select {
+	case <-reconnected:
+	case <-ctx.Done():
+		t.Fatal("stream is not reconnected")
+	}
+	receiveCandle()
+	if n := len(server.Requests(investgotest.MarketDataServerSideStream)); n != 2 {
+		t.Errorf("%v stream requests, want 2", n)
+	}
+	if health := s.Health(); health.Reconnects != 1 || health.Messages != 2 {
+		t.Errorf("health %+v, want 1 reconnect and 2 messages", health)
+	}
+
+	s.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Listen: %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("Listen is not finished after Stop")
+	}
+	if _, ok := <-s.Candles(); ok {
+		t.Error("candles channel is not closed")
+	}
+}
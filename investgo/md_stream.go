@@ -2,10 +2,12 @@ package investgo
 
 import (
 	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
 
 	pb "github.com/therox/invest-api-go-sdk/proto"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 type MDStream struct {
@@ -14,14 +16,21 @@ type MDStream struct {
 
 	ctx    context.Context
 	cancel context.CancelFunc
-
-	candle        chan *pb.Candle
-	trade         chan *pb.Trade
-	orderBook     chan *pb.OrderBook
-	lastPrice     chan *pb.LastPrice
-	tradingStatus chan *pb.TradingStatus
-
-	subs subscriptions
+	// streamCancel - отмена текущего grpc стрима, при переподключении создается новый
+	streamCancel context.CancelFunc
+
+	listener *mdListener
+
+	// mu - защита grpc стрима, подписок и очередей ожидания ответа. Удерживается на время отправки запроса,
+	// чтобы запросы из разных горутин не отправлялись одновременно и порядок запросов в очереди совпадал
+	// с порядком отправки. При переподключении удерживается от замены стрима до восстановления подписок
+	mu      sync.Mutex
+	subs    subscriptions
+	pending map[SubscriptionType][]*pendingRequest
+	// snapshots - запросы GetMySubscriptions, ожидающие ответа
+	snapshots []*snapshotRequest
+	// seq - номер последнего отправленного запроса для сопоставления ответов с запросами GetMySubscriptions
+	seq uint64
 }
 
 type subscriptions struct {
@@ -34,26 +43,43 @@ type subscriptions struct {
 
 // SubscribeCandle - Метод подписки на свечи с заданным интервалом
 func (mds *MDStream) SubscribeCandle(ids []string, interval pb.SubscriptionInterval) (<-chan *pb.Candle, error) {
-	err := mds.sendCandlesReq(ids, interval, pb.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE)
+	_, err := mds.SubscribeCandleAsync(ids, interval)
 	if err != nil {
 		return nil, err
 	}
-	for _, id := range ids {
-		mds.subs.candles[id] = interval
-	}
-	return mds.candle, nil
+	return mds.listener.candle, nil
+}
+
+// SubscribeCandleAsync - подписка на свечи с ожиданием ответа сервера, свечи приходят в канал Candles
+func (mds *MDStream) SubscribeCandleAsync(ids []string, interval pb.SubscriptionInterval) (*SubscriptionFuture, error) {
+	act := pb.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE
+	return mds.request(SubscriptionCandles, ids, act, func() error {
+		return mds.sendCandlesReq(ids, interval, act)
+	}, func() {
+		for _, id := range ids {
+			mds.subs.candles[id] = interval
+		}
+	})
 }
 
 // UnSubscribeCandle - Метод отписки от свечей
 func (mds *MDStream) UnSubscribeCandle(ids []string, interval pb.SubscriptionInterval) error {
-	err := mds.sendCandlesReq(ids, interval, pb.SubscriptionAction_SUBSCRIPTION_ACTION_UNSUBSCRIBE)
-	if err != nil {
-		return err
-	}
-	for _, id := range ids {
-		delete(mds.subs.candles, id)
-	}
-	return nil
+	mds.mu.Lock()
+	defer mds.mu.Unlock()
+	return mds.unSubscribeCandle(ids, interval)
+}
+
+// unSubscribeCandle - UnSubscribeCandle, вызывается под mds.mu
+func (mds *MDStream) unSubscribeCandle(ids []string, interval pb.SubscriptionInterval) error {
+	act := pb.SubscriptionAction_SUBSCRIPTION_ACTION_UNSUBSCRIBE
+	_, err := mds.requestLocked(SubscriptionCandles, ids, act, func() error {
+		return mds.sendCandlesReq(ids, interval, act)
+	}, func() {
+		for _, id := range ids {
+			delete(mds.subs.candles, id)
+		}
+	})
+	return err
 }
 
 func (mds *MDStream) sendCandlesReq(ids []string, interval pb.SubscriptionInterval, act pb.SubscriptionAction) error {
@@ -65,39 +91,59 @@ func (mds *MDStream) sendCandlesReq(ids []string, interval pb.SubscriptionInterv
 		})
 	}
 
-	WCFlag := interval == pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE
-
 	return mds.stream.Send(&pb.MarketDataRequest{
 		Payload: &pb.MarketDataRequest_SubscribeCandlesRequest{
 			SubscribeCandlesRequest: &pb.SubscribeCandlesRequest{
 				SubscriptionAction: act,
 				Instruments:        instruments,
-				WaitingClose:       WCFlag,
+				WaitingClose:       waitingClose(interval),
 			}}})
 }
 
+// waitingClose - свечи интервала приходят из стрима только после закрытия
+func waitingClose(interval pb.SubscriptionInterval) bool {
+	return interval == pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE
+}
+
 // SubscribeOrderBook - метод подписки на стаканы инструментов с одинаковой глубиной
 func (mds *MDStream) SubscribeOrderBook(ids []string, depth int32) (<-chan *pb.OrderBook, error) {
-	err := mds.sendOrderBookReq(ids, depth, pb.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE)
+	_, err := mds.SubscribeOrderBookAsync(ids, depth)
 	if err != nil {
 		return nil, err
 	}
-	for _, id := range ids {
-		mds.subs.orderBooks[id] = depth
-	}
-	return mds.orderBook, nil
+	return mds.listener.orderBook, nil
+}
+
+// SubscribeOrderBookAsync - подписка на стаканы с ожиданием ответа сервера, стаканы приходят в канал OrderBooks
+func (mds *MDStream) SubscribeOrderBookAsync(ids []string, depth int32) (*SubscriptionFuture, error) {
+	act := pb.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE
+	return mds.request(SubscriptionOrderBooks, ids, act, func() error {
+		return mds.sendOrderBookReq(ids, depth, act)
+	}, func() {
+		for _, id := range ids {
+			mds.subs.orderBooks[id] = depth
+		}
+	})
 }
 
 // UnSubscribeOrderBook - метод отдписки от стаканов инструментов
 func (mds *MDStream) UnSubscribeOrderBook(ids []string) error {
-	err := mds.sendOrderBookReq(ids, 0, pb.SubscriptionAction_SUBSCRIPTION_ACTION_UNSUBSCRIBE)
-	if err != nil {
-		return err
-	}
-	for _, id := range ids {
-		delete(mds.subs.orderBooks, id)
-	}
-	return nil
+	mds.mu.Lock()
+	defer mds.mu.Unlock()
+	return mds.unSubscribeOrderBook(ids)
+}
+
+// unSubscribeOrderBook - UnSubscribeOrderBook, вызывается под mds.mu
+func (mds *MDStream) unSubscribeOrderBook(ids []string) error {
+	act := pb.SubscriptionAction_SUBSCRIPTION_ACTION_UNSUBSCRIBE
+	_, err := mds.requestLocked(SubscriptionOrderBooks, ids, act, func() error {
+		return mds.sendOrderBookReq(ids, 0, act)
+	}, func() {
+		for _, id := range ids {
+			delete(mds.subs.orderBooks, id)
+		}
+	})
+	return err
 }
 
 func (mds *MDStream) sendOrderBookReq(ids []string, depth int32, act pb.SubscriptionAction) error {
@@ -118,26 +164,44 @@ func (mds *MDStream) sendOrderBookReq(ids []string, depth int32, act pb.Subscrip
 
 // SubscribeTrade - метод подписки на ленту обезличенных сделок
 func (mds *MDStream) SubscribeTrade(ids []string) (<-chan *pb.Trade, error) {
-	err := mds.sendTradesReq(ids, pb.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE)
+	_, err := mds.SubscribeTradeAsync(ids)
 	if err != nil {
 		return nil, err
 	}
-	for _, id := range ids {
-		mds.subs.trades[id] = struct{}{}
-	}
-	return mds.trade, nil
+	return mds.listener.trade, nil
+}
+
+// SubscribeTradeAsync - подписка на ленту обезличенных сделок с ожиданием ответа сервера,
+// сделки приходят в канал Trades
+func (mds *MDStream) SubscribeTradeAsync(ids []string) (*SubscriptionFuture, error) {
+	act := pb.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE
+	return mds.request(SubscriptionTrades, ids, act, func() error {
+		return mds.sendTradesReq(ids, act)
+	}, func() {
+		for _, id := range ids {
+			mds.subs.trades[id] = struct{}{}
+		}
+	})
 }
 
 // UnSubscribeTrade - метод отписки от ленты обезличенных сделок
 func (mds *MDStream) UnSubscribeTrade(ids []string) error {
-	err := mds.sendTradesReq(ids, pb.SubscriptionAction_SUBSCRIPTION_ACTION_UNSUBSCRIBE)
-	if err != nil {
-		return err
-	}
-	for _, id := range ids {
-		delete(mds.subs.trades, id)
-	}
-	return nil
+	mds.mu.Lock()
+	defer mds.mu.Unlock()
+	return mds.unSubscribeTrade(ids)
+}
+
+// unSubscribeTrade - UnSubscribeTrade, вызывается под mds.mu
+func (mds *MDStream) unSubscribeTrade(ids []string) error {
+	act := pb.SubscriptionAction_SUBSCRIPTION_ACTION_UNSUBSCRIBE
+	_, err := mds.requestLocked(SubscriptionTrades, ids, act, func() error {
+		return mds.sendTradesReq(ids, act)
+	}, func() {
+		for _, id := range ids {
+			delete(mds.subs.trades, id)
+		}
+	})
+	return err
 }
 
 func (mds *MDStream) sendTradesReq(ids []string, act pb.SubscriptionAction) error {
@@ -157,26 +221,44 @@ func (mds *MDStream) sendTradesReq(ids []string, act pb.SubscriptionAction) erro
 
 // SubscribeInfo - метод подписки на торговые статусы инструментов
 func (mds *MDStream) SubscribeInfo(ids []string) (<-chan *pb.TradingStatus, error) {
-	err := mds.sendInfoReq(ids, pb.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE)
+	_, err := mds.SubscribeInfoAsync(ids)
 	if err != nil {
 		return nil, err
 	}
-	for _, id := range ids {
-		mds.subs.tradingStatuses[id] = struct{}{}
-	}
-	return mds.tradingStatus, nil
+	return mds.listener.tradingStatus, nil
+}
+
+// SubscribeInfoAsync - подписка на торговые статусы с ожиданием ответа сервера,
+// статусы приходят в канал TradingStatuses
+func (mds *MDStream) SubscribeInfoAsync(ids []string) (*SubscriptionFuture, error) {
+	act := pb.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE
+	return mds.request(SubscriptionInfo, ids, act, func() error {
+		return mds.sendInfoReq(ids, act)
+	}, func() {
+		for _, id := range ids {
+			mds.subs.tradingStatuses[id] = struct{}{}
+		}
+	})
 }
 
 // UnSubscribeInfo - метод отписки от торговых статусов инструментов
 func (mds *MDStream) UnSubscribeInfo(ids []string) error {
-	err := mds.sendInfoReq(ids, pb.SubscriptionAction_SUBSCRIPTION_ACTION_UNSUBSCRIBE)
-	if err != nil {
-		return err
-	}
-	for _, id := range ids {
-		delete(mds.subs.tradingStatuses, id)
-	}
-	return nil
+	mds.mu.Lock()
+	defer mds.mu.Unlock()
+	return mds.unSubscribeInfo(ids)
+}
+
+// unSubscribeInfo - UnSubscribeInfo, вызывается под mds.mu
+func (mds *MDStream) unSubscribeInfo(ids []string) error {
+	act := pb.SubscriptionAction_SUBSCRIPTION_ACTION_UNSUBSCRIBE
+	_, err := mds.requestLocked(SubscriptionInfo, ids, act, func() error {
+		return mds.sendInfoReq(ids, act)
+	}, func() {
+		for _, id := range ids {
+			delete(mds.subs.tradingStatuses, id)
+		}
+	})
+	return err
 }
 
 func (mds *MDStream) sendInfoReq(ids []string, act pb.SubscriptionAction) error {
@@ -196,26 +278,44 @@ func (mds *MDStream) sendInfoReq(ids []string, act pb.SubscriptionAction) error
 
 // SubscribeLastPrice - метод подписки на последние цены инструментов
 func (mds *MDStream) SubscribeLastPrice(ids []string) (<-chan *pb.LastPrice, error) {
-	err := mds.sendLastPriceReq(ids, pb.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE)
+	_, err := mds.SubscribeLastPriceAsync(ids)
 	if err != nil {
 		return nil, err
 	}
-	for _, id := range ids {
-		mds.subs.lastPrices[id] = struct{}{}
-	}
-	return mds.lastPrice, nil
+	return mds.listener.lastPrice, nil
+}
+
+// SubscribeLastPriceAsync - подписка на последние цены с ожиданием ответа сервера,
+// цены приходят в канал LastPrices
+func (mds *MDStream) SubscribeLastPriceAsync(ids []string) (*SubscriptionFuture, error) {
+	act := pb.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE
+	return mds.request(SubscriptionLastPrices, ids, act, func() error {
+		return mds.sendLastPriceReq(ids, act)
+	}, func() {
+		for _, id := range ids {
+			mds.subs.lastPrices[id] = struct{}{}
+		}
+	})
 }
 
 // UnSubscribeLastPrice - метод отписки от последних цен инструментов
 func (mds *MDStream) UnSubscribeLastPrice(ids []string) error {
-	err := mds.sendLastPriceReq(ids, pb.SubscriptionAction_SUBSCRIPTION_ACTION_UNSUBSCRIBE)
-	if err != nil {
-		return err
-	}
-	for _, id := range ids {
-		delete(mds.subs.lastPrices, id)
-	}
-	return nil
+	mds.mu.Lock()
+	defer mds.mu.Unlock()
+	return mds.unSubscribeLastPrice(ids)
+}
+
+// unSubscribeLastPrice - UnSubscribeLastPrice, вызывается под mds.mu
+func (mds *MDStream) unSubscribeLastPrice(ids []string) error {
+	act := pb.SubscriptionAction_SUBSCRIPTION_ACTION_UNSUBSCRIBE
+	_, err := mds.requestLocked(SubscriptionLastPrices, ids, act, func() error {
+		return mds.sendLastPriceReq(ids, act)
+	}, func() {
+		for _, id := range ids {
+			delete(mds.subs.lastPrices, id)
+		}
+	})
+	return err
 }
 
 func (mds *MDStream) sendLastPriceReq(ids []string, act pb.SubscriptionAction) error {
@@ -233,84 +333,176 @@ func (mds *MDStream) sendLastPriceReq(ids []string, act pb.SubscriptionAction) e
 			}}})
 }
 
-// GetMySubscriptions - метод получения подписок в рамках данного стрима
+// request - отправка запроса подписки с постановкой в очередь ожидания ответа,
+// apply изменяет подписки стрима после успешной отправки
+func (mds *MDStream) request(t SubscriptionType, ids []string, act pb.SubscriptionAction, send func() error, apply func()) (*SubscriptionFuture, error) {
+	mds.mu.Lock()
+	defer mds.mu.Unlock()
+	return mds.requestLocked(t, ids, act, send, apply)
+}
+
+// requestLocked - request, вызывается под mds.mu. Запрос без apply восстанавливает сохраненные подписки
+// в новом стриме, в котором подписок до запроса нет, поэтому при ошибке такие подписки удаляются.
+// Если стрим разорван и еще не переоткрыт в Listen, отправка возвращает io.EOF: подписки стрима все равно
+// изменяются и восстанавливаются после переподключения, ожидание ответа завершается с ErrStreamReconnected
+func (mds *MDStream) requestLocked(t SubscriptionType, ids []string, act pb.SubscriptionAction, send func() error, apply func()) (*SubscriptionFuture, error) {
+	req := &pendingRequest{
+		future: newSubscriptionFuture(),
+		ids:    append([]string(nil), ids...),
+		action: act,
+	}
+	if apply != nil {
+		req.previous = mds.subs.states(t, ids)
+	}
+	mds.enqueue(t, req)
+	if err := send(); err != nil && (apply == nil || !errors.Is(err, io.EOF) || mds.ctx.Err() != nil) {
+		mds.dequeueLast(t)
+		return nil, err
+	}
+	if apply != nil {
+		apply()
+	}
+	req.applied = mds.subs.states(t, ids)
+	return req.future, nil
+}
+
+// Candles - канал свечей по подпискам стрима
+func (mds *MDStream) Candles() <-chan *pb.Candle {
+	return mds.listener.candle
+}
+
+// OrderBooks - канал стаканов по подпискам стрима
+func (mds *MDStream) OrderBooks() <-chan *pb.OrderBook {
+	return mds.listener.orderBook
+}
+
+// Trades - канал обезличенных сделок по подпискам стрима
+func (mds *MDStream) Trades() <-chan *pb.Trade {
+	return mds.listener.trade
+}
+
+// TradingStatuses - канал торговых статусов по подпискам стрима
+func (mds *MDStream) TradingStatuses() <-chan *pb.TradingStatus {
+	return mds.listener.tradingStatus
+}
+
+// LastPrices - канал последних цен по подпискам стрима
+func (mds *MDStream) LastPrices() <-chan *pb.LastPrice {
+	return mds.listener.lastPrice
+}
+
+// GetMySubscriptions - метод получения подписок в рамках данного стрима, ответ сервера не возвращается.
+// Для получения подписок используйте MySubscriptions
 func (mds *MDStream) GetMySubscriptions() error {
-	return mds.stream.Send(&pb.MarketDataRequest{
+	_, err := mds.requestMySubscriptions()
+	return err
+}
+
+// requestMySubscriptions - отправка запроса GetMySubscriptions и постановка его в ожидание ответа
+func (mds *MDStream) requestMySubscriptions() (*snapshotRequest, error) {
+	mds.mu.Lock()
+	defer mds.mu.Unlock()
+	mds.seq++
+	req := newSnapshotRequest(mds.seq, time.Now().Add(mySubscriptionsTimeout))
+	mds.snapshots = append(mds.snapshots, req)
+	err := mds.stream.Send(&pb.MarketDataRequest{
 		Payload: &pb.MarketDataRequest_GetMySubscriptions{
 			GetMySubscriptions: &pb.GetMySubscriptions{}}})
+	if err != nil {
+		mds.removeSnapshotLocked(req)
+		return nil, err
+	}
+	return req, nil
 }
 
+// SetReconnectPolicy - метод установки политики переподключения стрима, применяется со следующего переподключения
+func (mds *MDStream) SetReconnectPolicy(p ReconnectPolicy) {
+	mds.listener.setReconnectPolicy(p)
+}
+
+// SetWatchdog - установка проверки поступления Ping, вызывается до Listen. Принудительное переподключение
+// выполняется согласно ReconnectPolicy
+func (mds *MDStream) SetWatchdog(opts WatchdogOptions) {
+	mds.listener.watchdog.setOptions(opts)
+}
+
+// Health - статистика активности стрима
+func (mds *MDStream) Health() StreamHealth {
+	return mds.listener.watchdog.stats()
+}
+
+// SetRecorder - запись всех сообщений стрима, вызывается до Listen
+func (mds *MDStream) SetRecorder(r *Recorder) {
+	mds.listener.recorder = r
+}
+
+// Listen - метод начинает слушать стрим и отправлять информацию в каналы. При обрыве соединения
+// стрим переоткрывается согласно ReconnectPolicy, все подписки восстанавливаются, каналы не закрываются
 func (mds *MDStream) Listen() error {
+	if !mds.listener.lifecycle.begin() {
+		return nil
+	}
 	defer mds.shutdown()
-	for {
-		select {
-		case <-mds.ctx.Done():
-			mds.mdsClient.logger.Infof("Stop listening")
-			return nil
-		default:
-			resp, err := mds.stream.Recv()
-			if err != nil {
-				// если ошибка связана с завершением контекста, обрабатываем ее
-				switch {
-				case status.Code(err) == codes.Canceled:
-					mds.mdsClient.logger.Infof("Stop listening")
-					return nil
-				case status.Code(err) == codes.Unavailable:
-					return err
-					// mds.mdsClient.Logger.Infof("Unavailable")
-					//err := mds.retryH()
-					//if err != nil {
-					//	return err
-					//}
-				default:
-					return err
-				}
-			} else {
-				// логика определения того что пришло и отправка информации в нужный канал
-				mds.sendRespToChannel(resp)
-			}
-		}
+	return mds.listener.listen(mds)
+}
+
+// recv - получение ответа из текущего grpc стрима. Стрим заменяется только в reopenStream из горутины Listen,
+// поэтому читается без блокировки
+func (mds *MDStream) recv() (*pb.MarketDataResponse, error) {
+	return mds.stream.Recv()
+}
+
+// openStream - открытие нового grpc стрима в рамках контекста MDStream
+func (mds *MDStream) openStream() error {
+	stream, cancel, err := mds.newStream()
+	if err != nil {
+		return err
 	}
+	mds.mu.Lock()
+	defer mds.mu.Unlock()
+	mds.replaceStream(stream, cancel)
+	return nil
 }
 
-//func (mds *MDStream) retryH() error {
-//	newStream, err := mds.mdsClient.pbStreamH()
-//	if err != nil {
-//		return err
-//	}
-//	mds.stream = newStream
-//	mds.mdsClient.Logger.Infof(mds.mdsClient.conn.GetState().String())
-//	err = mds.SubscribeAll()
-//	if err != nil {
-//		return err
-//	}
-//	return nil
-//}
-
-func (mds *MDStream) sendRespToChannel(resp *pb.MarketDataResponse) {
-	switch resp.GetPayload().(type) {
-	case *pb.MarketDataResponse_Candle:
-		mds.candle <- resp.GetCandle()
-	case *pb.MarketDataResponse_Orderbook:
-		mds.orderBook <- resp.GetOrderbook()
-	case *pb.MarketDataResponse_Trade:
-		mds.trade <- resp.GetTrade()
-	case *pb.MarketDataResponse_LastPrice:
-		mds.lastPrice <- resp.GetLastPrice()
-	case *pb.MarketDataResponse_TradingStatus:
-		mds.tradingStatus <- resp.GetTradingStatus()
-	default:
-		mds.mdsClient.logger.Infof("Info from MD stream %v", resp.String())
+// reopenStream - замена стрима новым и восстановление подписок. Запросы из других горутин ждут,
+// пока подписки не будут отправлены в новый стрим, поэтому не попадают в него раньше них
+func (mds *MDStream) reopenStream() error {
+	stream, cancel, err := mds.newStream()
+	if err != nil {
+		return err
 	}
+	mds.mu.Lock()
+	defer mds.mu.Unlock()
+	mds.replaceStream(stream, cancel)
+	// ответы на запросы, отправленные в прежний стрим, не придут
+	mds.failPendingLocked(ErrStreamReconnected)
+	return mds.subscribeAll()
+}
+
+func (mds *MDStream) newStream() (pb.MarketDataStreamService_MarketDataStreamClient, context.CancelFunc, error) {
+	ctx, cancel := context.WithCancel(mds.ctx)
+	stream, err := mds.mdsClient.pbClient.MarketDataStream(ctx)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return stream, cancel, nil
+}
+
+// replaceStream - замена текущего grpc стрима, вызывается под mds.mu
+func (mds *MDStream) replaceStream(stream pb.MarketDataStreamService_MarketDataStreamClient, cancel context.CancelFunc) {
+	if mds.streamCancel != nil {
+		mds.streamCancel()
+	}
+	mds.stream = stream
+	mds.streamCancel = cancel
+	mds.listener.watchdog.connected(cancel)
 }
 
 func (mds *MDStream) shutdown() {
-	mds.mdsClient.logger.Infof("Close market data stream")
-	close(mds.candle)
-	close(mds.trade)
-	close(mds.lastPrice)
-	close(mds.orderBook)
-	close(mds.tradingStatus)
+	mds.failPending(ErrStreamClosed)
+	mds.listener.lifecycle.end(mds.listener.closeChannels)
+	mds.mdsClient.streams.remove(mds)
 }
 
 // Stop - Завершение работы стрима
@@ -318,66 +510,141 @@ func (mds *MDStream) Stop() {
 	mds.cancel()
 }
 
-// UnSubscribeAll - Метод отписки от всей информации, отслеживаемой на данный момент
+// wait - ожидание завершения Listen и закрытия каналов
+func (mds *MDStream) wait(ctx context.Context) error {
+	err := mds.listener.lifecycle.wait(ctx, mds.listener.closeChannels)
+	if err == nil {
+		mds.mdsClient.streams.remove(mds)
+	}
+	return err
+}
+
+// UnSubscribeAll - Метод отписки от всей информации, отслеживаемой на данный момент. Подписки из других
+// горутин, отправленные во время отписки, отправляются после нее и сохраняются
 func (mds *MDStream) UnSubscribeAll() error {
-	ids := make([]string, 0)
-	if len(mds.subs.candles) > 0 {
-		intervals := make(map[pb.SubscriptionInterval][]string, 0)
+	mds.mu.Lock()
+	defer mds.mu.Unlock()
+	candles := groupCandles(mds.subs.candles)
+	orderBooks := keys(mds.subs.orderBooks)
+	trades := keys(mds.subs.trades)
+	tradingStatuses := keys(mds.subs.tradingStatuses)
+	lastPrices := keys(mds.subs.lastPrices)
+
+	for interval, ids := range candles {
+		err := mds.unSubscribeCandle(ids, interval)
+		if err != nil {
+			return err
+		}
+	}
 
-		for id, interval := range mds.subs.candles {
-			intervals[interval] = append(intervals[interval], id)
-			delete(mds.subs.candles, id)
+	if len(trades) > 0 {
+		err := mds.unSubscribeTrade(trades)
+		if err != nil {
+			return err
 		}
-		for interval, ids := range intervals {
-			err := mds.UnSubscribeCandle(ids, interval)
-			if err != nil {
-				return err
-			}
+	}
+
+	if len(tradingStatuses) > 0 {
+		err := mds.unSubscribeInfo(tradingStatuses)
+		if err != nil {
+			return err
 		}
 	}
 
-	if len(mds.subs.trades) > 0 {
-		for id := range mds.subs.trades {
-			ids = append(ids, id)
-			delete(mds.subs.trades, id)
+	if len(lastPrices) > 0 {
+		err := mds.unSubscribeLastPrice(lastPrices)
+		if err != nil {
+			return err
 		}
-		err := mds.UnSubscribeTrade(ids)
+	}
+
+	if len(orderBooks) > 0 {
+		err := mds.unSubscribeOrderBook(orderBooks)
 		if err != nil {
 			return err
 		}
-		ids = nil
 	}
 
-	if len(mds.subs.tradingStatuses) > 0 {
-		for id := range mds.subs.tradingStatuses {
-			ids = append(ids, id)
-			delete(mds.subs.tradingStatuses, id)
+	return nil
+}
+
+// unSubscribeAllWait - UnSubscribeAll с ожиданием ответов сервера на запросы отписки, пока Listen читает стрим.
+// Используется перед остановкой стрима, чтобы отписка не потерялась при отмене grpc стрима
+func (mds *MDStream) unSubscribeAllWait(ctx context.Context) error {
+	err := mds.UnSubscribeAll()
+	if err != nil {
+		return err
+	}
+	if !mds.listener.lifecycle.running() {
+		return nil
+	}
+	for _, f := range mds.pendingFutures() {
+		select {
+		case <-f.Done():
+		case <-mds.listener.lifecycle.done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
 		}
-		err := mds.UnSubscribeInfo(ids)
+	}
+	return nil
+}
+
+// subscribeAll - повторная отправка всех запросов подписки, сохраненных в subscriptions, вызывается под mds.mu
+func (mds *MDStream) subscribeAll() error {
+	act := pb.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE
+
+	candles := groupCandles(mds.subs.candles)
+	depths := make(map[int32][]string, 0)
+	for id, depth := range mds.subs.orderBooks {
+		depths[depth] = append(depths[depth], id)
+	}
+	trades := keys(mds.subs.trades)
+	tradingStatuses := keys(mds.subs.tradingStatuses)
+	lastPrices := keys(mds.subs.lastPrices)
+
+	for interval, ids := range candles {
+		ids, interval := ids, interval
+		_, err := mds.requestLocked(SubscriptionCandles, ids, act, func() error {
+			return mds.sendCandlesReq(ids, interval, act)
+		}, nil)
 		if err != nil {
 			return err
 		}
-		ids = nil
 	}
 
-	if len(mds.subs.lastPrices) > 0 {
-		for id := range mds.subs.lastPrices {
-			ids = append(ids, id)
-			delete(mds.subs.lastPrices, id)
+	for depth, ids := range depths {
+		ids, depth := ids, depth
+		_, err := mds.requestLocked(SubscriptionOrderBooks, ids, act, func() error {
+			return mds.sendOrderBookReq(ids, depth, act)
+		}, nil)
+		if err != nil {
+			return err
 		}
-		err := mds.UnSubscribeLastPrice(ids)
+	}
+
+	if len(trades) > 0 {
+		_, err := mds.requestLocked(SubscriptionTrades, trades, act, func() error {
+			return mds.sendTradesReq(trades, act)
+		}, nil)
 		if err != nil {
 			return err
 		}
-		ids = nil
 	}
 
-	if len(mds.subs.orderBooks) > 0 {
-		for id := range mds.subs.orderBooks {
-			ids = append(ids, id)
-			delete(mds.subs.orderBooks, id)
+	if len(tradingStatuses) > 0 {
+		_, err := mds.requestLocked(SubscriptionInfo, tradingStatuses, act, func() error {
+			return mds.sendInfoReq(tradingStatuses, act)
+		}, nil)
+		if err != nil {
+			return err
 		}
-		err := mds.UnSubscribeOrderBook(ids)
+	}
+
+	if len(lastPrices) > 0 {
+		_, err := mds.requestLocked(SubscriptionLastPrices, lastPrices, act, func() error {
+			return mds.sendLastPriceReq(lastPrices, act)
+		}, nil)
 		if err != nil {
 			return err
 		}
@@ -386,69 +653,19 @@ func (mds *MDStream) UnSubscribeAll() error {
 	return nil
 }
 
-//func (mds *MDStream) SubscribeAll() error {
-//	ids := make([]string, 0)
-//	if len(mds.subs.candles) > 0 {
-//		intervals := make(map[pb.SubscriptionInterval][]string, 0)
-//
-//		for id, interval := range mds.subs.candles {
-//			intervals[interval] = append(intervals[interval], id)
-//			//delete(mds.subs.candles, id)
-//		}
-//		for interval, ids := range intervals {
-//			_, err := mds.SubscribeCandle(ids, interval)
-//			if err != nil {
-//				return err
-//			}
-//		}
-//	}
-//
-//	if len(mds.subs.trades) > 0 {
-//		for id := range mds.subs.trades {
-//			ids = append(ids, id)
-//			//delete(mds.subs.trades, id)
-//		}
-//		_, err := mds.SubscribeTrade(ids)
-//		if err != nil {
-//			return err
-//		}
-//		ids = nil
-//	}
-//
-//	if len(mds.subs.tradingStatuses) > 0 {
-//		for id := range mds.subs.tradingStatuses {
-//			ids = append(ids, id)
-//			//delete(mds.subs.tradingStatuses, id)
-//		}
-//		_, err := mds.SubscribeInfo(ids)
-//		if err != nil {
-//			return err
-//		}
-//		ids = nil
-//	}
-//
-//	if len(mds.subs.lastPrices) > 0 {
-//		for id := range mds.subs.lastPrices {
-//			ids = append(ids, id)
-//			//delete(mds.subs.lastPrices, id)
-//		}
-//		_, err := mds.SubscribeLastPrice(ids)
-//		if err != nil {
-//			return err
-//		}
-//		ids = nil
-//	}
-//
-//	if len(mds.subs.orderBooks) > 0 {
-//		for id := range mds.subs.orderBooks {
-//			ids = append(ids, id)
-//			//delete(mds.subs.orderBooks, id)
-//		}
-//		_, err := mds.SubscribeOrderBook(ids, 1)
-//		if err != nil {
-//			return err
-//		}
-//	}
-//
-//	return nil
-//}
+// groupCandles - инструменты подписок на свечи, сгруппированные по интервалу
+func groupCandles(candles map[string]pb.SubscriptionInterval) map[pb.SubscriptionInterval][]string {
+	intervals := make(map[pb.SubscriptionInterval][]string, 0)
+	for id, interval := range candles {
+		intervals[interval] = append(intervals[interval], id)
+	}
+	return intervals
+}
+
+func keys[V any](m map[string]V) []string {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	return ids
+}
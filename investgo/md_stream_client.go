@@ -13,26 +13,22 @@ type MDStreamClient struct {
 	logger   Logger
 	ctx      context.Context
 	pbClient pb.MarketDataStreamServiceClient
+	streams  *streamRegistry
 }
 
 // MarketDataStream - метод возвращает стрим биржевой информации
 func (c *MDStreamClient) MarketDataStream() (*MDStream, error) {
-	ctx, cancel := context.WithCancel(c.ctx)
-	stream, err := c.pbClient.MarketDataStream(ctx)
-	if err != nil {
-		cancel()
-		return nil, err
-	}
-	return &MDStream{
-		stream:        stream,
-		mdsClient:     c,
-		ctx:           ctx,
-		cancel:        cancel,
-		candle:        make(chan *pb.Candle, 1),
-		trade:         make(chan *pb.Trade, 1),
-		orderBook:     make(chan *pb.OrderBook, 1),
-		lastPrice:     make(chan *pb.LastPrice, 1),
-		tradingStatus: make(chan *pb.TradingStatus, 1),
+	return c.MarketDataStreamCtx(c.ctx)
+}
+
+// MarketDataStreamCtx - MarketDataStream, время жизни стрима ограничено контекстом ctx
+func (c *MDStreamClient) MarketDataStreamCtx(ctx context.Context) (*MDStream, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	mds := &MDStream{
+		mdsClient: c,
+		ctx:       ctx,
+		cancel:    cancel,
+		listener:  newMDListener(ctx, "Market data stream", c.logger),
 		subs: subscriptions{
 			candles:         make(map[string]pb.SubscriptionInterval, 0),
 			orderBooks:      make(map[string]int32, 0),
@@ -40,14 +36,84 @@ func (c *MDStreamClient) MarketDataStream() (*MDStream, error) {
 			tradingStatuses: make(map[string]struct{}, 0),
 			lastPrices:      make(map[string]struct{}, 0),
 		},
-	}, nil
+		pending: make(map[SubscriptionType][]*pendingRequest, 0),
+	}
+	err := mds.openStream()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	c.streams.add(mds)
+	return mds, nil
+}
+
+// ServerSideStream - метод возвращает server-side стрим биржевой информации с набором подписок subs
+func (c *MDStreamClient) ServerSideStream(subs ServerSideSubscriptions) (*ServerSideStream, error) {
+	return c.ServerSideStreamCtx(c.ctx, subs)
+}
+
+// ServerSideStreamCtx - ServerSideStream, время жизни стрима ограничено контекстом ctx
+func (c *MDStreamClient) ServerSideStreamCtx(ctx context.Context, subs ServerSideSubscriptions) (*ServerSideStream, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &ServerSideStream{
+		mdsClient: c,
+		req:       subs.request(),
+		ctx:       ctx,
+		cancel:    cancel,
+		listener:  newMDListener(ctx, "Market data server-side stream", c.logger),
+	}
+	err := s.openStream()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	c.streams.add(s)
+	return s, nil
+}
+
+// AsMarketDataStreamService - c в виде MarketDataStreamService, стримы возвращаются как интерфейсы,
+// поэтому в тестах фабрику можно заменить моком из investgomock
+func AsMarketDataStreamService(c *MDStreamClient) MarketDataStreamService {
+	return mdStreamService{client: c}
 }
 
-// grpc.WaitForReady(false)
-//func (c *MDStreamClient) pbStreamH() (pb.MarketDataStreamService_MarketDataStreamClient, error) {
-//	stream, err := c.pbClient.MarketDataStream(c.ctx, grpc.WaitForReady(false))
-//	if err != nil {
-//		return nil, err
-//	}
-//	return stream, nil
-//}
+// mdStreamService - MarketDataStreamService поверх *MDStreamClient
+type mdStreamService struct {
+	client *MDStreamClient
+}
+
+func (s mdStreamService) MarketDataStream() (MarketDataStreamer, error) {
+	return s.MarketDataStreamCtx(s.client.ctx)
+}
+
+func (s mdStreamService) MarketDataStreamCtx(ctx context.Context) (MarketDataStreamer, error) {
+	mds, err := s.client.MarketDataStreamCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return mds, nil
+}
+
+func (s mdStreamService) ServerSideStream(subs ServerSideSubscriptions) (ServerSideStreamer, error) {
+	return s.ServerSideStreamCtx(s.client.ctx, subs)
+}
+
+func (s mdStreamService) ServerSideStreamCtx(ctx context.Context, subs ServerSideSubscriptions) (ServerSideStreamer, error) {
+	stream, err := s.client.ServerSideStreamCtx(ctx, subs)
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+func (s mdStreamService) MarketDataPool(opts PoolOptions) (MarketDataPooler, error) {
+	return s.MarketDataPoolCtx(s.client.ctx, opts)
+}
+
+func (s mdStreamService) MarketDataPoolCtx(ctx context.Context, opts PoolOptions) (MarketDataPooler, error) {
+	pool, err := s.client.MarketDataPoolCtx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return pool, nil
+}
@@ -0,0 +1,320 @@
+package investgo_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/therox/invest-api-go-sdk/investgo"
+	"github.com/therox/invest-api-go-sdk/investgo/investgotest"
+	pb "github.com/therox/invest-api-go-sdk/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// listenMDStream - стрим маркетдаты, слушающий соединение с сервером, и канал с результатом Listen
+func listenMDStream(t *testing.T, client *investgo.Client, server *investgotest.Server, reconnected chan<- struct{}) (investgo.MarketDataStreamer, <-chan error) {
+	t.Helper()
+	mds, err := client.NewMDStreamClient().MarketDataStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := investgo.DefaultReconnectPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = 10 * time.Millisecond
+	policy.OnReconnect = func(attempt int, err error) {
+		if err == nil && reconnected != nil {
+			reconnected <- struct{}{}
+		}
+	}
+	mds.SetReconnectPolicy(policy)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- mds.Listen()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	if err := server.WaitStreams(ctx, investgotest.MarketDataStream, 1); err != nil {
+		t.Fatal(err)
+	}
+	return mds, done
+}
+
+// breakMDStream - обрыв стрима с codes.Unavailable и ожидание переподключения. grpc сам повторяет
+// стрим, по которому еще не получено ни одного ответа, поэтому перед обрывом получается ответ на GetMySubscriptions
+func breakMDStream(ctx context.Context, t *testing.T, mds investgo.MarketDataStreamer, server *investgotest.Server, reconnected <-chan struct{}) {
+	t.Helper()
+	if _, err := mds.MySubscriptions(ctx); err != nil {
+		t.Fatal(err)
+	}
+	server.BreakStreams(status.Error(codes.Unavailable, "connection lost"))
+	select {
+	case <-reconnected:
+	case <-ctx.Done():
+		t.Fatal("stream is not reconnected")
+	}
+}
+
+func TestMDStreamConcurrentSubscriptionsDuringReconnect(t *testing.T) {
+	client, server := investgotest.NewClient(t)
+	reconnected := make(chan struct{}, 1)
+	mds, _ := listenMDStream(t, client, server, reconnected)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	const workers = 8
+	interval := pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		id := fmt.Sprintf("instrument-%v", w)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// подписка и отписка до окончания переподключений, последней отправляется подписка
+			for {
+				f, err := mds.SubscribeCandleAsync([]string{id}, interval)
+				if err != nil {
+					errs <- err
+					return
+				}
+				// ответ на запрос, отправленный в разорванный стрим, не придет, подписка восстанавливается
+				if _, err := f.Wait(ctx); err != nil && !errors.Is(err, investgo.ErrStreamReconnected) {
+					errs <- err
+					return
+				}
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if err := mds.UnSubscribeCandle([]string{id}, interval); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 3; i++ {
+		breakMDStream(ctx, t, mds, server, reconnected)
+	}
+	close(stop)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	diff, err := mds.ReconcileSubscriptions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.InSync() {
+		t.Errorf("subscriptions out of sync: missing %+v, unexpected %+v", diff.Missing, diff.Unexpected)
+	}
+	snapshot, err := mds.MySubscriptions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.Candles) != workers {
+		t.Errorf("server has %v candle subscriptions, want %v", len(snapshot.Candles), workers)
+	}
+
+	server.PushCandle(&pb.Candle{Figi: "instrument-0", Interval: interval})
+	select {
+	case c := <-mds.Candles():
+		if c.GetFigi() != "instrument-0" {
+			t.Errorf("candle figi %v", c.GetFigi())
+		}
+	case <-ctx.Done():
+		t.Fatal("candle is not received after reconnect")
+	}
+}
+
+func TestMDStreamFailedSubscription(t *testing.T) {
+	client, server := investgotest.NewClient(t)
+	server.SetSubscriptionStatus("unknown", pb.SubscriptionStatus_SUBSCRIPTION_STATUS_INSTRUMENT_NOT_FOUND)
+	reconnected := make(chan struct{}, 1)
+	mds, _ := listenMDStream(t, client, server, reconnected)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	f, err := mds.SubscribeTradeAsync([]string{"known", "unknown"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := f.Wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Succeeded) != 1 || res.Succeeded[0] != "known" {
+		t.Errorf("succeeded %v, want [known]", res.Succeeded)
+	}
+	if len(res.Failed) != 1 || res.Failed[0].InstrumentId != "unknown" ||
+		res.Failed[0].Status != pb.SubscriptionStatus_SUBSCRIPTION_STATUS_INSTRUMENT_NOT_FOUND {
+		t.Fatalf("failed %v, want unknown: INSTRUMENT_NOT_FOUND", res.Err())
+	}
+	var subErr *investgo.SubscriptionError
+	if !errors.As(res.Err(), &subErr) || subErr.Type != investgo.SubscriptionTrades {
+		t.Errorf("Err() = %v, want trades SubscriptionError", res.Err())
+	}
+
+	// неуспешная подписка не сохраняется в подписках стрима и не восстанавливается после переподключения
+	diff, err := mds.ReconcileSubscriptions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.InSync() {
+		t.Errorf("subscriptions out of sync: missing %+v, unexpected %+v", diff.Missing, diff.Unexpected)
+	}
+	breakMDStream(ctx, t, mds, server, reconnected)
+	snapshot, err := mds.MySubscriptions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.Trades) != 1 || snapshot.Trades[0].InstrumentId() != "known" {
+		t.Errorf("trades after reconnect %+v, want [known]", snapshot.Trades)
+	}
+}
+
+func TestClientShutdownStopsMDStream(t *testing.T) {
+	client, server := investgotest.NewClient(t)
+	mds, done := listenMDStream(t, client, server, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	f, err := mds.SubscribeLastPriceAsync([]string{"instrument"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Listen: %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("Listen is not finished after Shutdown")
+	}
+	if _, ok := <-mds.LastPrices(); ok {
+		t.Error("last prices channel is not closed")
+	}
+
+	// перед остановкой стрим отписывается от всех инструментов
+	if diff := mds.CompareSubscriptions(&investgo.SubscriptionsSnapshot{}); !diff.InSync() {
+		t.Errorf("subscriptions after shutdown %+v", diff.Missing)
+	}
+	if err := server.WaitStreams(ctx, investgotest.MarketDataStream, 0); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMarketDataStreamService(t *testing.T) {
+	client, server := investgotest.NewClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	// обертка клиента стримов возвращает стрим как интерфейс, сам клиент - конкретный тип
+	streams := investgo.AsMarketDataStreamService(client.NewMDStreamClient())
+	mds, err := streams.MarketDataStreamCtx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := mds.(*investgo.MDStream); !ok {
+		t.Errorf("stream %T, want *investgo.MDStream", mds)
+	}
+	go mds.Listen()
+	defer mds.Stop()
+	if _, err := mds.SubscribeLastPrice([]string{"figi"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.WaitStreams(ctx, investgotest.MarketDataStream, 1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMDStreamReconnectRestoresSubscriptions(t *testing.T) {
+	client, server := investgotest.NewClient(t)
+	reconnected := make(chan struct{}, 1)
+	mds, done := listenMDStream(t, client, server, reconnected)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	interval := pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_FIVE_MINUTES
+	candles, err := mds.SubscribeCandle([]string{"candle"}, interval)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orderBooks, err := mds.SubscribeOrderBook([]string{"order-book"}, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mds.SubscribeTrade([]string{"trade"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mds.SubscribeInfo([]string{"info"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mds.SubscribeLastPrice([]string{"last-price"}); err != nil {
+		t.Fatal(err)
+	}
+	want, err := mds.MySubscriptions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	breakMDStream(ctx, t, mds, server, reconnected)
+	select {
+	case err := <-done:
+		t.Fatalf("Listen finished after reconnect: %v", err)
+	default:
+	}
+	if n := len(server.Requests(investgotest.MarketDataStream)); n < 2 {
+		t.Errorf("%v market data streams opened, want reopened stream", n)
+	}
+
+	// подписки восстановлены в новом стриме с исходным интервалом и глубиной стакана
+	got, err := mds.MySubscriptions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := mds.CompareSubscriptions(got); !diff.InSync() {
+		t.Errorf("subscriptions after reconnect: missing %+v, unexpected %+v", diff.Missing, diff.Unexpected)
+	}
+	if len(got.OrderBooks) != 1 || got.OrderBooks[0].Depth != 20 {
+		t.Errorf("order books after reconnect %+v, want depth 20", got.OrderBooks)
+	}
+	if len(got.Candles) != len(want.Candles) || len(got.Trades) != len(want.Trades) ||
+		len(got.TradingStatuses) != len(want.TradingStatuses) || len(got.LastPrices) != len(want.LastPrices) {
+		t.Errorf("subscriptions after reconnect %+v, want %+v", got, want)
+	}
+
+	// каналы, полученные до переподключения, продолжают получать данные
+	server.PushOrderBook(&pb.OrderBook{Figi: "order-book", Depth: 20})
+	select {
+	case ob := <-orderBooks:
+		if ob.GetFigi() != "order-book" {
+			t.Errorf("order book figi %v", ob.GetFigi())
+		}
+	case <-ctx.Done():
+		t.Fatal("order book is not received after reconnect")
+	}
+	server.PushCandle(&pb.Candle{Figi: "candle", Interval: interval})
+	select {
+	case c := <-candles:
+		if c.GetFigi() != "candle" {
+			t.Errorf("candle figi %v", c.GetFigi())
+		}
+	case <-ctx.Done():
+		t.Fatal("candle is not received after reconnect")
+	}
+}
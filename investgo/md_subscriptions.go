@@ -0,0 +1,390 @@
+package investgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+// ErrStreamReconnected - стрим переподключен до получения ответа на запрос подписки,
+// подписка восстанавливается на новом стриме
+var ErrStreamReconnected = errors.New("market data stream reconnected before subscription response")
+
+// ErrStreamClosed - стрим завершен до получения ответа на запрос подписки
+var ErrStreamClosed = errors.New("market data stream closed before subscription response")
+
+// ErrSubscriptionStatusMissing - инструмента из запроса нет в ответе сервера на подписку
+var ErrSubscriptionStatusMissing = errors.New("instrument is missing from subscription response")
+
+// SubscriptionType - тип подписки в стриме маркетдаты
+type SubscriptionType int
+
+const (
+	SubscriptionCandles SubscriptionType = iota
+	SubscriptionOrderBooks
+	SubscriptionTrades
+	SubscriptionInfo
+	SubscriptionLastPrices
+)
+
+// subscriptionTypes - все типы подписок
+var subscriptionTypes = []SubscriptionType{SubscriptionCandles, SubscriptionOrderBooks, SubscriptionTrades, SubscriptionInfo, SubscriptionLastPrices}
+
+func (t SubscriptionType) String() string {
+	switch t {
+	case SubscriptionCandles:
+		return "candles"
+	case SubscriptionOrderBooks:
+		return "order books"
+	case SubscriptionTrades:
+		return "trades"
+	case SubscriptionInfo:
+		return "trading statuses"
+	case SubscriptionLastPrices:
+		return "last prices"
+	default:
+		return "unknown"
+	}
+}
+
+// SubscriptionError - ошибка подписки по инструменту
+type SubscriptionError struct {
+	Type         SubscriptionType
+	InstrumentId string
+	Status       pb.SubscriptionStatus
+	// Err - причина ошибки, если в ответе нет статуса инструмента, например ErrSubscriptionStatusMissing
+	Err error
+}
+
+func (e *SubscriptionError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%v subscription %v: %v", e.Type, e.InstrumentId, e.Err.Error())
+	}
+	return fmt.Sprintf("%v subscription %v: %v", e.Type, e.InstrumentId, e.Status.String())
+}
+
+// Unwrap - причина ошибки Err
+func (e *SubscriptionError) Unwrap() error {
+	return e.Err
+}
+
+// SubscriptionResult - ответ сервера на запрос подписки или отписки
+type SubscriptionResult struct {
+	Type       SubscriptionType
+	Action     pb.SubscriptionAction
+	TrackingId string
+	// Succeeded - инструменты из запроса с успешным статусом
+	Succeeded []string
+	// Failed - инструменты из запроса с ошибкой или без статуса в ответе, при подписке они не сохраняются
+	// в подписках стрима
+	Failed []*SubscriptionError
+}
+
+// Err - ошибки по всем инструментам из Failed, nil если все инструменты успешны
+func (r *SubscriptionResult) Err() error {
+	errs := make([]error, 0, len(r.Failed))
+	for _, e := range r.Failed {
+		errs = append(errs, e)
+	}
+	return errors.Join(errs...)
+}
+
+// SubscriptionFuture - ожидание ответа сервера на запрос подписки. Ответ приходит в Listen,
+// поэтому для его получения стрим должен слушаться
+type SubscriptionFuture struct {
+	done   chan struct{}
+	result *SubscriptionResult
+	err    error
+}
+
+func newSubscriptionFuture() *SubscriptionFuture {
+	return &SubscriptionFuture{done: make(chan struct{})}
+}
+
+// CompletedSubscriptionFuture - уже завершенное ожидание с результатом result или ошибкой err,
+// например для ответа моков стримов в тестах
+func CompletedSubscriptionFuture(result *SubscriptionResult, err error) *SubscriptionFuture {
+	f := newSubscriptionFuture()
+	f.complete(result, err)
+	return f
+}
+
+func (f *SubscriptionFuture) complete(result *SubscriptionResult, err error) {
+	f.result = result
+	f.err = err
+	close(f.done)
+}
+
+// Done - канал закрывается при получении ответа или завершении стрима
+func (f *SubscriptionFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait - ожидание ответа сервера. Ошибки по отдельным инструментам возвращаются в SubscriptionResult.Failed,
+// ошибка возвращается, если ответ не получен
+func (f *SubscriptionFuture) Wait(ctx context.Context) (*SubscriptionResult, error) {
+	select {
+	case <-f.done:
+		return f.result, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// pendingRequest - запрос подписки, ожидающий ответа. Сервер отвечает на запросы одного типа в порядке их
+// отправки, поэтому ответы сопоставляются с запросами по очереди для каждого типа подписки
+type pendingRequest struct {
+	future *SubscriptionFuture
+	ids    []string
+	action pb.SubscriptionAction
+	// seq - номер запроса в порядке отправки
+	seq uint64
+	// previous - подписки на инструменты из запроса до его отправки, applied - после отправки.
+	// При ошибке подписки восстанавливается previous, если подписку не изменил более поздний запрос
+	previous, applied map[string]subscriptionState
+}
+
+// subscriptionState - параметры подписки на инструмент в подписках стрима
+type subscriptionState struct {
+	interval pb.SubscriptionInterval
+	depth    int32
+}
+
+// instrumentStatus - статус подписки по инструменту из ответа сервера
+type instrumentStatus struct {
+	figi     string
+	uid      string
+	status   pb.SubscriptionStatus
+	interval pb.SubscriptionInterval
+	depth    int32
+}
+
+func (s instrumentStatus) matches(id string) bool {
+	return id != "" && (id == s.figi || id == s.uid)
+}
+
+// enqueue - добавление запроса в очередь ожидания ответа, вызывается под mds.mu
+func (mds *MDStream) enqueue(t SubscriptionType, req *pendingRequest) {
+	mds.seq++
+	req.seq = mds.seq
+	mds.pending[t] = append(mds.pending[t], req)
+}
+
+// dequeueLast - удаление последнего запроса из очереди, если его не удалось отправить. Вызывается под mds.mu
+func (mds *MDStream) dequeueLast(t SubscriptionType) {
+	if n := len(mds.pending[t]); n > 0 {
+		mds.pending[t] = mds.pending[t][:n-1]
+	}
+}
+
+// pendingFutures - результаты запросов подписки, ожидающих ответа сервера
+func (mds *MDStream) pendingFutures() []*SubscriptionFuture {
+	mds.mu.Lock()
+	defer mds.mu.Unlock()
+	var futures []*SubscriptionFuture
+	for _, t := range subscriptionTypes {
+		for _, req := range mds.pending[t] {
+			futures = append(futures, req.future)
+		}
+	}
+	return futures
+}
+
+// failPending - завершение всех ожидающих запросов с ошибкой err
+func (mds *MDStream) failPending(err error) {
+	mds.mu.Lock()
+	defer mds.mu.Unlock()
+	mds.failPendingLocked(err)
+}
+
+// failPendingLocked - failPending, вызывается под mds.mu
+func (mds *MDStream) failPendingLocked(err error) {
+	for _, t := range subscriptionTypes {
+		for _, req := range mds.pending[t] {
+			req.future.complete(nil, err)
+		}
+		mds.pending[t] = nil
+	}
+	for _, req := range mds.snapshots {
+		if !req.finished() {
+			req.complete(err)
+		}
+	}
+	mds.snapshots = nil
+}
+
+// handleSubscriptionResponse - сопоставление ответа с запросом и удаление неуспешных подписок
+func (mds *MDStream) handleSubscriptionResponse(t SubscriptionType, trackingId string, statuses []instrumentStatus) {
+	mds.mu.Lock()
+	if mds.handleSnapshotResponse(t, trackingId, statuses) {
+		mds.mu.Unlock()
+		return
+	}
+	if len(mds.pending[t]) == 0 {
+		mds.mu.Unlock()
+		mds.mdsClient.logger.Infof("Unexpected %v subscription response, tracking id = %v", t, trackingId)
+		return
+	}
+	req := mds.pending[t][0]
+	mds.pending[t] = mds.pending[t][1:]
+
+	result := &SubscriptionResult{Type: t, Action: req.action, TrackingId: trackingId}
+	for i, id := range req.ids {
+		st, ok := findStatus(statuses, i, len(req.ids), id)
+		if ok && st == pb.SubscriptionStatus_SUBSCRIPTION_STATUS_SUCCESS {
+			result.Succeeded = append(result.Succeeded, id)
+			continue
+		}
+		subErr := &SubscriptionError{Type: t, InstrumentId: id, Status: st}
+		if !ok {
+			// подписка на инструмент без статуса в ответе не подтверждена сервером
+			subErr.Err = ErrSubscriptionStatusMissing
+		}
+		result.Failed = append(result.Failed, subErr)
+		if req.action == pb.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE {
+			mds.revert(t, req, id)
+		}
+	}
+	mds.mu.Unlock()
+
+	for _, e := range result.Failed {
+		mds.mdsClient.logger.Errorf("Market data %v, tracking id = %v", e.Error(), trackingId)
+	}
+	req.future.complete(result, nil)
+}
+
+// revert - отмена неуспешной подписки на инструмент id, вызывается под mds.mu. Подписка, которая была
+// до запроса, например с другим интервалом, остается действующей на сервере и восстанавливается
+func (mds *MDStream) revert(t SubscriptionType, req *pendingRequest, id string) {
+	current, ok := mds.subs.get(t, id)
+	if !ok || current != req.applied[id] {
+		// подписку изменил более поздний запрос
+		return
+	}
+	if prev, ok := req.previous[id]; ok {
+		mds.subs.set(t, id, prev)
+		return
+	}
+	mds.subs.remove(t, id)
+}
+
+// findStatus - статус инструмента id, i-го из n инструментов запроса. Порядок статусов в ответе не гарантирован,
+// поэтому статус ищется по figi и instrument_uid, а по позиции - только если в статусе нет идентификаторов
+func findStatus(statuses []instrumentStatus, i, n int, id string) (pb.SubscriptionStatus, bool) {
+	for _, s := range statuses {
+		if s.matches(id) {
+			return s.status, true
+		}
+	}
+	if len(statuses) == n && statuses[i].figi == "" && statuses[i].uid == "" {
+		return statuses[i].status, true
+	}
+	return 0, false
+}
+
+// subscriptionStatuses - тип подписки и статусы по инструментам из ответа на подписку
+func subscriptionStatuses(resp *pb.MarketDataResponse) (SubscriptionType, string, []instrumentStatus, bool) {
+	switch payload := resp.GetPayload().(type) {
+	case *pb.MarketDataResponse_SubscribeCandlesResponse:
+		r := payload.SubscribeCandlesResponse
+		statuses := make([]instrumentStatus, 0, len(r.GetCandlesSubscriptions()))
+		for _, s := range r.GetCandlesSubscriptions() {
+			statuses = append(statuses, instrumentStatus{figi: s.GetFigi(), uid: s.GetInstrumentUid(), status: s.GetSubscriptionStatus(), interval: s.GetInterval()})
+		}
+		return SubscriptionCandles, r.GetTrackingId(), statuses, true
+	case *pb.MarketDataResponse_SubscribeOrderBookResponse:
+		r := payload.SubscribeOrderBookResponse
+		statuses := make([]instrumentStatus, 0, len(r.GetOrderBookSubscriptions()))
+		for _, s := range r.GetOrderBookSubscriptions() {
+			statuses = append(statuses, instrumentStatus{figi: s.GetFigi(), uid: s.GetInstrumentUid(), status: s.GetSubscriptionStatus(), depth: s.GetDepth()})
+		}
+		return SubscriptionOrderBooks, r.GetTrackingId(), statuses, true
+	case *pb.MarketDataResponse_SubscribeTradesResponse:
+		r := payload.SubscribeTradesResponse
+		statuses := make([]instrumentStatus, 0, len(r.GetTradeSubscriptions()))
+		for _, s := range r.GetTradeSubscriptions() {
+			statuses = append(statuses, instrumentStatus{figi: s.GetFigi(), uid: s.GetInstrumentUid(), status: s.GetSubscriptionStatus()})
+		}
+		return SubscriptionTrades, r.GetTrackingId(), statuses, true
+	case *pb.MarketDataResponse_SubscribeInfoResponse:
+		r := payload.SubscribeInfoResponse
+		statuses := make([]instrumentStatus, 0, len(r.GetInfoSubscriptions()))
+		for _, s := range r.GetInfoSubscriptions() {
+			statuses = append(statuses, instrumentStatus{figi: s.GetFigi(), uid: s.GetInstrumentUid(), status: s.GetSubscriptionStatus()})
+		}
+		return SubscriptionInfo, r.GetTrackingId(), statuses, true
+	case *pb.MarketDataResponse_SubscribeLastPriceResponse:
+		r := payload.SubscribeLastPriceResponse
+		statuses := make([]instrumentStatus, 0, len(r.GetLastPriceSubscriptions()))
+		for _, s := range r.GetLastPriceSubscriptions() {
+			statuses = append(statuses, instrumentStatus{figi: s.GetFigi(), uid: s.GetInstrumentUid(), status: s.GetSubscriptionStatus()})
+		}
+		return SubscriptionLastPrices, r.GetTrackingId(), statuses, true
+	default:
+		return 0, "", nil, false
+	}
+}
+
+// states - параметры подписок на инструменты ids, инструменты без подписки не включаются
+func (s *subscriptions) states(t SubscriptionType, ids []string) map[string]subscriptionState {
+	states := make(map[string]subscriptionState, len(ids))
+	for _, id := range ids {
+		if st, ok := s.get(t, id); ok {
+			states[id] = st
+		}
+	}
+	return states
+}
+
+// get - параметры подписки на инструмент
+func (s *subscriptions) get(t SubscriptionType, id string) (subscriptionState, bool) {
+	var ok bool
+	var st subscriptionState
+	switch t {
+	case SubscriptionCandles:
+		st.interval, ok = s.candles[id]
+	case SubscriptionOrderBooks:
+		st.depth, ok = s.orderBooks[id]
+	case SubscriptionTrades:
+		_, ok = s.trades[id]
+	case SubscriptionInfo:
+		_, ok = s.tradingStatuses[id]
+	case SubscriptionLastPrices:
+		_, ok = s.lastPrices[id]
+	}
+	return st, ok
+}
+
+// set - сохранение подписки на инструмент
+func (s *subscriptions) set(t SubscriptionType, id string, st subscriptionState) {
+	switch t {
+	case SubscriptionCandles:
+		s.candles[id] = st.interval
+	case SubscriptionOrderBooks:
+		s.orderBooks[id] = st.depth
+	case SubscriptionTrades:
+		s.trades[id] = struct{}{}
+	case SubscriptionInfo:
+		s.tradingStatuses[id] = struct{}{}
+	case SubscriptionLastPrices:
+		s.lastPrices[id] = struct{}{}
+	}
+}
+
+// remove - удаление подписки по инструменту
+func (s *subscriptions) remove(t SubscriptionType, id string) {
+	switch t {
+	case SubscriptionCandles:
+		delete(s.candles, id)
+	case SubscriptionOrderBooks:
+		delete(s.orderBooks, id)
+	case SubscriptionTrades:
+		delete(s.trades, id)
+	case SubscriptionInfo:
+		delete(s.tradingStatuses, id)
+	case SubscriptionLastPrices:
+		delete(s.lastPrices, id)
+	}
+}
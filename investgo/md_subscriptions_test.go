@@ -0,0 +1,216 @@
+package investgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Infof(string, ...any)  {}
+func (nopLogger) Errorf(string, ...any) {}
+func (nopLogger) Fatalf(string, ...any) {}
+
+// testStream - grpc стрим, сохраняющий отправленные запросы. При заданной err запросы не отправляются
+type testStream struct {
+	pb.MarketDataStreamService_MarketDataStreamClient
+	sent []*pb.MarketDataRequest
+	err  error
+}
+
+func (s *testStream) Send(req *pb.MarketDataRequest) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.sent = append(s.sent, req)
+	return nil
+}
+
+// newTestMDStream - стрим без grpc соединения, ответы сервера передаются в handleSubscriptionResponse из теста
+func newTestMDStream() *MDStream {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &MDStream{
+		ctx:       ctx,
+		cancel:    cancel,
+		stream:    &testStream{},
+		mdsClient: &MDStreamClient{logger: nopLogger{}},
+		subs: subscriptions{
+			candles:         make(map[string]pb.SubscriptionInterval, 0),
+			orderBooks:      make(map[string]int32, 0),
+			trades:          make(map[string]struct{}, 0),
+			tradingStatuses: make(map[string]struct{}, 0),
+			lastPrices:      make(map[string]struct{}, 0),
+		},
+		pending: make(map[SubscriptionType][]*pendingRequest, 0),
+	}
+}
+
+func subscribeTestCandles(t *testing.T, mds *MDStream, interval pb.SubscriptionInterval, ids ...string) *SubscriptionFuture {
+	t.Helper()
+	f, err := mds.request(SubscriptionCandles, ids, pb.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE, func() error {
+		return nil
+	}, func() {
+		for _, id := range ids {
+			mds.subs.candles[id] = interval
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func waitResult(t *testing.T, f *SubscriptionFuture) *SubscriptionResult {
+	t.Helper()
+	res, err := f.Wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestFindStatus(t *testing.T) {
+	ok := pb.SubscriptionStatus_SUBSCRIPTION_STATUS_SUCCESS
+	bad := pb.SubscriptionStatus_SUBSCRIPTION_STATUS_INSTRUMENT_NOT_FOUND
+	tests := []struct {
+		name     string
+		statuses []instrumentStatus
+		i, n     int
+		id       string
+		want     pb.SubscriptionStatus
+		found    bool
+	}{
+		{
+			name:     "reordered response",
+			statuses: []instrumentStatus{{figi: "b", status: bad}, {figi: "a", status: ok}},
+			i:        0, n: 2, id: "a", want: ok, found: true,
+		},
+		{
+			name:     "match by uid",
+			statuses: []instrumentStatus{{figi: "figi", uid: "uid", status: bad}},
+			i:        0, n: 1, id: "uid", want: bad, found: true,
+		},
+		{
+			name:     "position without ids",
+			statuses: []instrumentStatus{{status: ok}, {status: bad}},
+			i:        1, n: 2, id: "b", want: bad, found: true,
+		},
+		{
+			name:     "no position fallback for other instrument",
+			statuses: []instrumentStatus{{figi: "x", status: bad}, {figi: "y", status: bad}},
+			i:        0, n: 2, id: "a",
+		},
+		{
+			name:     "missing",
+			statuses: []instrumentStatus{{status: bad}},
+			i:        0, n: 2, id: "a",
+		},
+	}
+	for _, tt := range tests {
+		st, found := findStatus(tt.statuses, tt.i, tt.n, tt.id)
+		if found != tt.found || st != tt.want {
+			t.Errorf("%v: got %v/%v, want %v/%v", tt.name, st, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestFailedResubscribeKeepsPreviousSubscription(t *testing.T) {
+	mds := newTestMDStream()
+	minute := pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE
+	fiveMinutes := pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_FIVE_MINUTES
+	ok := pb.SubscriptionStatus_SUBSCRIPTION_STATUS_SUCCESS
+	bad := pb.SubscriptionStatus_SUBSCRIPTION_STATUS_LIMIT_IS_EXCEEDED
+
+	f := subscribeTestCandles(t, mds, minute, "a", "b")
+	mds.handleSubscriptionResponse(SubscriptionCandles, "1", []instrumentStatus{
+		{figi: "b", status: ok}, {figi: "a", status: ok},
+	})
+	if res := waitResult(t, f); len(res.Failed) != 0 {
+		t.Fatalf("failed: %v", res.Err())
+	}
+
+	// смена интервала отклонена сервером, подписка на минутные свечи остается действующей,
+	// новая подписка на c удаляется
+	f = subscribeTestCandles(t, mds, fiveMinutes, "a", "c")
+	mds.handleSubscriptionResponse(SubscriptionCandles, "2", []instrumentStatus{
+		{figi: "c", status: bad}, {figi: "a", status: bad},
+	})
+	res := waitResult(t, f)
+	if len(res.Failed) != 2 {
+		t.Fatalf("failed = %v, want a and c", res.Err())
+	}
+	if got, ok := mds.subs.candles["a"]; !ok || got != minute {
+		t.Errorf("a: interval %v (subscribed %v), want %v", got, ok, minute)
+	}
+	if _, ok := mds.subs.candles["c"]; ok {
+		t.Error("c: failed subscription is kept")
+	}
+	if got := mds.subs.candles["b"]; got != minute {
+		t.Errorf("b: interval %v, want %v", got, minute)
+	}
+}
+
+func TestFailedSubscribeKeepsLaterRequest(t *testing.T) {
+	mds := newTestMDStream()
+	minute := pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE
+	fiveMinutes := pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_FIVE_MINUTES
+
+	first := subscribeTestCandles(t, mds, minute, "a")
+	second := subscribeTestCandles(t, mds, fiveMinutes, "a")
+	mds.handleSubscriptionResponse(SubscriptionCandles, "1", []instrumentStatus{
+		{figi: "a", status: pb.SubscriptionStatus_SUBSCRIPTION_STATUS_INTERNAL_ERROR},
+	})
+	mds.handleSubscriptionResponse(SubscriptionCandles, "2", []instrumentStatus{
+		{figi: "a", status: pb.SubscriptionStatus_SUBSCRIPTION_STATUS_SUCCESS},
+	})
+	waitResult(t, first)
+	waitResult(t, second)
+	if got := mds.subs.candles["a"]; got != fiveMinutes {
+		t.Errorf("a: interval %v, want %v", got, fiveMinutes)
+	}
+}
+
+func TestFailedRestoreRemovesSubscription(t *testing.T) {
+	mds := newTestMDStream()
+	minute := pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE
+	mds.subs.candles["a"] = minute
+
+	// восстановление подписок после переподключения отправляется без apply
+	f, err := mds.request(SubscriptionCandles, []string{"a"}, pb.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE, func() error {
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mds.handleSubscriptionResponse(SubscriptionCandles, "1", []instrumentStatus{
+		{figi: "a", status: pb.SubscriptionStatus_SUBSCRIPTION_STATUS_INSTRUMENT_NOT_FOUND},
+	})
+	waitResult(t, f)
+	if _, ok := mds.subs.candles["a"]; ok {
+		t.Error("a: subscription failed on the new stream is kept")
+	}
+}
+
+func TestMissingStatusFails(t *testing.T) {
+	mds := newTestMDStream()
+	minute := pb.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE
+
+	// статус по b в ответе отсутствует, подписка на b не подтверждена сервером
+	f := subscribeTestCandles(t, mds, minute, "a", "b")
+	mds.handleSubscriptionResponse(SubscriptionCandles, "1", []instrumentStatus{
+		{figi: "a", status: pb.SubscriptionStatus_SUBSCRIPTION_STATUS_SUCCESS},
+	})
+	res := waitResult(t, f)
+	if len(res.Succeeded) != 1 || res.Succeeded[0] != "a" {
+		t.Errorf("succeeded %v, want a", res.Succeeded)
+	}
+	if len(res.Failed) != 1 || res.Failed[0].InstrumentId != "b" || !errors.Is(res.Failed[0], ErrSubscriptionStatusMissing) {
+		t.Fatalf("failed %v, want b without status", res.Err())
+	}
+	if _, ok := mds.subs.candles["b"]; ok {
+		t.Error("b: subscription without status is kept")
+	}
+}
@@ -19,8 +19,13 @@ type OperationsServiceClient struct {
 
 // GetOperations - Метод получения списка операций по счёту
 func (os *OperationsServiceClient) GetOperations(req *GetOperationsRequest) (*OperationsResponse, error) {
+	return os.GetOperationsCtx(os.ctx, req)
+}
+
+// GetOperationsCtx - GetOperations с контекстом запроса
+func (os *OperationsServiceClient) GetOperationsCtx(ctx context.Context, req *GetOperationsRequest) (*OperationsResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := os.pbClient.GetOperations(os.ctx, &pb.OperationsRequest{
+	resp, err := os.pbClient.GetOperations(ctx, &pb.OperationsRequest{
 		AccountId: req.AccountId,
 		From:      TimeToTimestamp(req.From),
 		To:        TimeToTimestamp(req.To),
@@ -29,6 +34,7 @@ func (os *OperationsServiceClient) GetOperations(req *GetOperationsRequest) (*Op
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &OperationsResponse{
 		OperationsResponse: resp,
@@ -38,13 +44,19 @@ func (os *OperationsServiceClient) GetOperations(req *GetOperationsRequest) (*Op
 
 // GetPortfolio - Метод получения портфеля по счёту
 func (os *OperationsServiceClient) GetPortfolio(accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*PortfolioResponse, error) {
+	return os.GetPortfolioCtx(os.ctx, accountId, currency)
+}
+
+// GetPortfolioCtx - GetPortfolio с контекстом запроса
+func (os *OperationsServiceClient) GetPortfolioCtx(ctx context.Context, accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*PortfolioResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := os.pbClient.GetPortfolio(os.ctx, &pb.PortfolioRequest{
+	resp, err := os.pbClient.GetPortfolio(ctx, &pb.PortfolioRequest{
 		AccountId: accountId,
 		Currency:  currency,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &PortfolioResponse{
 		PortfolioResponse: resp,
@@ -54,12 +66,18 @@ func (os *OperationsServiceClient) GetPortfolio(accountId string, currency pb.Po
 
 // GetPositions - Метод получения списка позиций по счёту
 func (os *OperationsServiceClient) GetPositions(accountId string) (*PositionsResponse, error) {
+	return os.GetPositionsCtx(os.ctx, accountId)
+}
+
+// GetPositionsCtx - GetPositions с контекстом запроса
+func (os *OperationsServiceClient) GetPositionsCtx(ctx context.Context, accountId string) (*PositionsResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := os.pbClient.GetPositions(os.ctx, &pb.PositionsRequest{
+	resp, err := os.pbClient.GetPositions(ctx, &pb.PositionsRequest{
 		AccountId: accountId,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &PositionsResponse{
 		PositionsResponse: resp,
@@ -69,12 +87,18 @@ func (os *OperationsServiceClient) GetPositions(accountId string) (*PositionsRes
 
 // GetWithdrawLimits - Метод получения доступного остатка для вывода средств
 func (os *OperationsServiceClient) GetWithdrawLimits(accountId string) (*WithdrawLimitsResponse, error) {
+	return os.GetWithdrawLimitsCtx(os.ctx, accountId)
+}
+
+// GetWithdrawLimitsCtx - GetWithdrawLimits с контекстом запроса
+func (os *OperationsServiceClient) GetWithdrawLimitsCtx(ctx context.Context, accountId string) (*WithdrawLimitsResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := os.pbClient.GetWithdrawLimits(os.ctx, &pb.WithdrawLimitsRequest{
+	resp, err := os.pbClient.GetWithdrawLimits(ctx, &pb.WithdrawLimitsRequest{
 		AccountId: accountId,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &WithdrawLimitsResponse{
 		WithdrawLimitsResponse: resp,
@@ -84,8 +108,13 @@ func (os *OperationsServiceClient) GetWithdrawLimits(accountId string) (*Withdra
 
 // GetBrokerReport - Метод получения брокерского отчёта
 func (os *OperationsServiceClient) GetBrokerReport(taskId string, page int32) (*GetBrokerReportResponse, error) {
+	return os.GetBrokerReportCtx(os.ctx, taskId, page)
+}
+
+// GetBrokerReportCtx - GetBrokerReport с контекстом запроса
+func (os *OperationsServiceClient) GetBrokerReportCtx(ctx context.Context, taskId string, page int32) (*GetBrokerReportResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := os.pbClient.GetBrokerReport(os.ctx, &pb.BrokerReportRequest{
+	resp, err := os.pbClient.GetBrokerReport(ctx, &pb.BrokerReportRequest{
 		Payload: &pb.BrokerReportRequest_GetBrokerReportRequest{
 			GetBrokerReportRequest: &pb.GetBrokerReportRequest{
 				TaskId: taskId,
@@ -95,6 +124,7 @@ func (os *OperationsServiceClient) GetBrokerReport(taskId string, page int32) (*
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetBrokerReportResponse{
 		GetBrokerReportResponse: resp.GetGetBrokerReportResponse(),
@@ -104,8 +134,13 @@ func (os *OperationsServiceClient) GetBrokerReport(taskId string, page int32) (*
 
 // GenerateBrokerReport - Метод получения брокерского отчёта
 func (os *OperationsServiceClient) GenerateBrokerReport(accountId string, from, to time.Time) (*GenerateBrokerReportResponse, error) {
+	return os.GenerateBrokerReportCtx(os.ctx, accountId, from, to)
+}
+
+// GenerateBrokerReportCtx - GenerateBrokerReport с контекстом запроса
+func (os *OperationsServiceClient) GenerateBrokerReportCtx(ctx context.Context, accountId string, from, to time.Time) (*GenerateBrokerReportResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := os.pbClient.GetBrokerReport(os.ctx, &pb.BrokerReportRequest{
+	resp, err := os.pbClient.GetBrokerReport(ctx, &pb.BrokerReportRequest{
 		Payload: &pb.BrokerReportRequest_GenerateBrokerReportRequest{
 			GenerateBrokerReportRequest: &pb.GenerateBrokerReportRequest{
 				AccountId: accountId,
@@ -116,6 +151,7 @@ func (os *OperationsServiceClient) GenerateBrokerReport(accountId string, from,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GenerateBrokerReportResponse{
 		GenerateBrokerReportResponse: resp.GetGenerateBrokerReportResponse(),
@@ -125,8 +161,13 @@ func (os *OperationsServiceClient) GenerateBrokerReport(accountId string, from,
 
 // GetDividentsForeignIssuer - Метод получения отчёта "Справка о доходах за пределами РФ"
 func (os *OperationsServiceClient) GetDividentsForeignIssuer(taskId string, page int32) (*GetDividendsForeignIssuerResponse, error) {
+	return os.GetDividentsForeignIssuerCtx(os.ctx, taskId, page)
+}
+
+// GetDividentsForeignIssuerCtx - GetDividentsForeignIssuer с контекстом запроса
+func (os *OperationsServiceClient) GetDividentsForeignIssuerCtx(ctx context.Context, taskId string, page int32) (*GetDividendsForeignIssuerResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := os.pbClient.GetDividendsForeignIssuer(os.ctx, &pb.GetDividendsForeignIssuerRequest{
+	resp, err := os.pbClient.GetDividendsForeignIssuer(ctx, &pb.GetDividendsForeignIssuerRequest{
 		Payload: &pb.GetDividendsForeignIssuerRequest_GetDivForeignIssuerReport{
 			GetDivForeignIssuerReport: &pb.GetDividendsForeignIssuerReportRequest{
 				TaskId: taskId,
@@ -136,6 +177,7 @@ func (os *OperationsServiceClient) GetDividentsForeignIssuer(taskId string, page
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetDividendsForeignIssuerResponse{
 		GetDividendsForeignIssuerResponse: resp,
@@ -145,8 +187,13 @@ func (os *OperationsServiceClient) GetDividentsForeignIssuer(taskId string, page
 
 // GenerateDividentsForeignIssuer - Метод получения отчёта "Справка о доходах за пределами РФ"
 func (os *OperationsServiceClient) GenerateDividentsForeignIssuer(accountId string, from, to time.Time) (*GetDividendsForeignIssuerResponse, error) {
+	return os.GenerateDividentsForeignIssuerCtx(os.ctx, accountId, from, to)
+}
+
+// GenerateDividentsForeignIssuerCtx - GenerateDividentsForeignIssuer с контекстом запроса
+func (os *OperationsServiceClient) GenerateDividentsForeignIssuerCtx(ctx context.Context, accountId string, from, to time.Time) (*GetDividendsForeignIssuerResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := os.pbClient.GetDividendsForeignIssuer(os.ctx, &pb.GetDividendsForeignIssuerRequest{
+	resp, err := os.pbClient.GetDividendsForeignIssuer(ctx, &pb.GetDividendsForeignIssuerRequest{
 		Payload: &pb.GetDividendsForeignIssuerRequest_GenerateDivForeignIssuerReport{
 			GenerateDivForeignIssuerReport: &pb.GenerateDividendsForeignIssuerReportRequest{
 				AccountId: accountId,
@@ -157,6 +204,7 @@ func (os *OperationsServiceClient) GenerateDividentsForeignIssuer(accountId stri
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetDividendsForeignIssuerResponse{
 		GetDividendsForeignIssuerResponse: resp,
@@ -166,15 +214,25 @@ func (os *OperationsServiceClient) GenerateDividentsForeignIssuer(accountId stri
 
 // GetOperationsByCursorShort - Метод получения списка операций по счёту с пагинацией
 func (os *OperationsServiceClient) GetOperationsByCursorShort(accountId string) (*GetOperationsByCursorResponse, error) {
-	return os.GetOperationsByCursor(&GetOperationsByCursorRequest{
+	return os.GetOperationsByCursorShortCtx(os.ctx, accountId)
+}
+
+// GetOperationsByCursorShortCtx - GetOperationsByCursorShort с контекстом запроса
+func (os *OperationsServiceClient) GetOperationsByCursorShortCtx(ctx context.Context, accountId string) (*GetOperationsByCursorResponse, error) {
+	return os.GetOperationsByCursorCtx(ctx, &GetOperationsByCursorRequest{
 		AccountId: accountId,
 	})
 }
 
 // GetOperationsByCursor - Метод получения списка операций по счёту с пагинацией
 func (os *OperationsServiceClient) GetOperationsByCursor(req *GetOperationsByCursorRequest) (*GetOperationsByCursorResponse, error) {
+	return os.GetOperationsByCursorCtx(os.ctx, req)
+}
+
+// GetOperationsByCursorCtx - GetOperationsByCursor с контекстом запроса
+func (os *OperationsServiceClient) GetOperationsByCursorCtx(ctx context.Context, req *GetOperationsByCursorRequest) (*GetOperationsByCursorResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := os.pbClient.GetOperationsByCursor(os.ctx, &pb.GetOperationsByCursorRequest{
+	resp, err := os.pbClient.GetOperationsByCursor(ctx, &pb.GetOperationsByCursorRequest{
 		AccountId:          req.AccountId,
 		InstrumentId:       req.InstrumentId,
 		From:               TimeToTimestamp(req.From),
@@ -189,6 +247,7 @@ func (os *OperationsServiceClient) GetOperationsByCursor(req *GetOperationsByCur
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetOperationsByCursorResponse{
 		GetOperationsByCursorResponse: resp,
@@ -13,42 +13,94 @@ type OperationsStreamClient struct {
 	logger   Logger
 	ctx      context.Context
 	pbClient pb.OperationsStreamServiceClient
+	streams  *streamRegistry
 }
 
 // PortfolioStream - Server-side stream обновлений портфеля
 func (o *OperationsStreamClient) PortfolioStream(accounts []string) (*PortfolioStream, error) {
-	ctx, cancel := context.WithCancel(o.ctx)
-	stream, err := o.pbClient.PortfolioStream(ctx, &pb.PortfolioStreamRequest{
-		Accounts: accounts,
-	})
-	if err != nil {
-		cancel()
-		return nil, err
-	}
-	return &PortfolioStream{
-		stream:           stream,
+	return o.PortfolioStreamCtx(o.ctx, accounts)
+}
+
+// PortfolioStreamCtx - PortfolioStream, время жизни стрима ограничено контекстом ctx
+func (o *OperationsStreamClient) PortfolioStreamCtx(ctx context.Context, accounts []string) (*PortfolioStream, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	ps := &PortfolioStream{
 		operationsClient: o,
+		req:              &pb.PortfolioStreamRequest{Accounts: accounts},
 		portfolios:       make(chan *pb.PortfolioResponse),
 		ctx:              ctx,
 		cancel:           cancel,
-	}, nil
-}
-
-// PositionsStream - Server-side stream обновлений информации по изменению позиций портфеля
-func (o *OperationsStreamClient) PositionsStream(accounts []string) (*PositionsStream, error) {
-	ctx, cancel := context.WithCancel(o.ctx)
-	stream, err := o.pbClient.PositionsStream(ctx, &pb.PositionsStreamRequest{
-		Accounts: accounts,
-	})
+		lifecycle:        newStreamLifecycle(),
+		watchdog:         newStreamWatchdog(),
+		reconnect:        DefaultReconnectPolicy(),
+	}
+	err := ps.openStream()
 	if err != nil {
 		cancel()
 		return nil, err
 	}
-	return &PositionsStream{
-		stream:           stream,
+	o.streams.add(ps)
+	return ps, nil
+}
+
+// PositionsStream - Server-side stream обновлений информации по изменению позиций портфеля
+func (o *OperationsStreamClient) PositionsStream(accounts []string) (*PositionsStream, error) {
+	return o.PositionsStreamCtx(o.ctx, accounts)
+}
+
+// PositionsStreamCtx - PositionsStream, время жизни стрима ограничено контекстом ctx
+func (o *OperationsStreamClient) PositionsStreamCtx(ctx context.Context, accounts []string) (*PositionsStream, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	ps := &PositionsStream{
 		operationsClient: o,
+		req:              &pb.PositionsStreamRequest{Accounts: accounts},
 		positions:        make(chan *pb.PositionData),
 		ctx:              ctx,
 		cancel:           cancel,
-	}, nil
+		lifecycle:        newStreamLifecycle(),
+		watchdog:         newStreamWatchdog(),
+		reconnect:        DefaultReconnectPolicy(),
+	}
+	err := ps.openStream()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	o.streams.add(ps)
+	return ps, nil
+}
+
+// AsOperationsStreamService - o в виде OperationsStreamService, стримы возвращаются как интерфейсы,
+// поэтому в тестах фабрику можно заменить моком из investgomock
+func AsOperationsStreamService(o *OperationsStreamClient) OperationsStreamService {
+	return operationsStreamService{client: o}
+}
+
+// operationsStreamService - OperationsStreamService поверх *OperationsStreamClient
+type operationsStreamService struct {
+	client *OperationsStreamClient
+}
+
+func (s operationsStreamService) PortfolioStream(accounts []string) (PortfolioStreamer, error) {
+	return s.PortfolioStreamCtx(s.client.ctx, accounts)
+}
+
+func (s operationsStreamService) PortfolioStreamCtx(ctx context.Context, accounts []string) (PortfolioStreamer, error) {
+	stream, err := s.client.PortfolioStreamCtx(ctx, accounts)
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+func (s operationsStreamService) PositionsStream(accounts []string) (PositionsStreamer, error) {
+	return s.PositionsStreamCtx(s.client.ctx, accounts)
+}
+
+func (s operationsStreamService) PositionsStreamCtx(ctx context.Context, accounts []string) (PositionsStreamer, error) {
+	stream, err := s.client.PositionsStreamCtx(ctx, accounts)
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
 }
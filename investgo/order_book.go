@@ -0,0 +1,334 @@
+package investgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+// ErrInsufficientLiquidity - в стакане недостаточно лотов для заявки заданного объема
+var ErrInsufficientLiquidity = errors.New("insufficient order book liquidity")
+
+// OrderBookBookOptions - параметры хранилища стаканов
+type OrderBookBookOptions struct {
+	// StaleAfter - время без обновлений, после которого стакан считается устаревшим, 0 - проверка выключена
+	StaleAfter time.Duration
+}
+
+// OrderBookBook - хранилище последних стаканов по инструментам. Заполняется стаканами из MDStream
+// методами Update или Run, начальное состояние можно загрузить методом Seed. Стакан доступен
+// как по figi, так и по instrument_uid
+type OrderBookBook struct {
+	opts OrderBookBookOptions
+
+	mu sync.RWMutex
+	// books - стаканы по figi и instrument_uid, оба ключа указывают на один стакан
+	books map[string]*LocalOrderBook
+}
+
+// NewOrderBookBook - создание пустого хранилища стаканов
+func NewOrderBookBook(opts OrderBookBookOptions) *OrderBookBook {
+	return &OrderBookBook{
+		opts:  opts,
+		books: make(map[string]*LocalOrderBook, 0),
+	}
+}
+
+// Seed - загрузка стаканов инструментов ids глубиной depth методом GetOrderBook. Ошибки по отдельным
+// инструментам не прерывают загрузку остальных и возвращаются вместе
+func (b *OrderBookBook) Seed(ctx context.Context, md MarketDataService, ids []string, depth int32) error {
+	var errs []error
+	for _, id := range ids {
+		resp, err := md.GetOrderBookCtx(ctx, id, depth)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", id, err))
+			continue
+		}
+		b.Update(&pb.OrderBook{
+			Figi:          resp.GetFigi(),
+			Depth:         resp.GetDepth(),
+			IsConsistent:  true,
+			Bids:          resp.GetBids(),
+			Asks:          resp.GetAsks(),
+			Time:          resp.GetOrderbookTs(),
+			LimitUp:       resp.GetLimitUp(),
+			LimitDown:     resp.GetLimitDown(),
+			InstrumentUid: resp.GetInstrumentUid(),
+		})
+	}
+	return errors.Join(errs...)
+}
+
+// Update - замена стакана инструмента. Стакан старше сохраненного по времени биржи игнорируется,
+// в этом случае возвращается false
+func (b *OrderBookBook) Update(ob *pb.OrderBook) bool {
+	book := newLocalOrderBook(ob)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	current := b.books[book.Figi]
+	if current == nil {
+		current = b.books[book.InstrumentUid]
+	}
+	if current != nil && book.Time.Before(current.Time) {
+		return false
+	}
+	if book.Figi != "" {
+		b.books[book.Figi] = book
+	}
+	if book.InstrumentUid != "" {
+		b.books[book.InstrumentUid] = book
+	}
+	return true
+}
+
+// Run - обновление стаканов из канала src до его закрытия, например MDStream.OrderBooks()
+func (b *OrderBookBook) Run(src <-chan *pb.OrderBook) {
+	for ob := range src {
+		b.Update(ob)
+	}
+}
+
+// Book - последний стакан инструмента по figi или instrument_uid. Стакан не изменяется
+// при последующих обновлениях и может использоваться без блокировок
+func (b *OrderBookBook) Book(id string) (*LocalOrderBook, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	book, ok := b.books[id]
+	return book, ok
+}
+
+// Stale - true, если стакана инструмента нет или он не обновлялся дольше StaleAfter
+func (b *OrderBookBook) Stale(id string) bool {
+	book, ok := b.Book(id)
+	return !ok || b.stale(book, time.Now())
+}
+
+// StaleBooks - стаканы, которые не обновлялись дольше StaleAfter
+func (b *OrderBookBook) StaleBooks() []*LocalOrderBook {
+	now := time.Now()
+	return b.filter(func(book *LocalOrderBook) bool {
+		return b.stale(book, now)
+	})
+}
+
+// CrossedBooks - стаканы, в которых лучшая цена покупки не ниже лучшей цены продажи
+func (b *OrderBookBook) CrossedBooks() []*LocalOrderBook {
+	return b.filter((*LocalOrderBook).IsCrossed)
+}
+
+func (b *OrderBookBook) stale(book *LocalOrderBook, now time.Time) bool {
+	return b.opts.StaleAfter > 0 && now.Sub(book.ReceivedAt) > b.opts.StaleAfter
+}
+
+// filter - стаканы, удовлетворяющие fn, каждый стакан учитывается один раз
+func (b *OrderBookBook) filter(fn func(*LocalOrderBook) bool) []*LocalOrderBook {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	seen := make(map[*LocalOrderBook]struct{}, len(b.books))
+	books := make([]*LocalOrderBook, 0)
+	for _, book := range b.books {
+		if _, ok := seen[book]; ok {
+			continue
+		}
+		seen[book] = struct{}{}
+		if fn(book) {
+			books = append(books, book)
+		}
+	}
+	return books
+}
+
+// LocalOrderBook - стакан инструмента в хранилище OrderBookBook
+type LocalOrderBook struct {
+	Figi          string
+	InstrumentUid string
+	Depth         int32
+	// IsConsistent - признак консистентности стакана по данным биржи
+	IsConsistent bool
+	// Bids - заявки на покупку, от лучшей цены к худшей
+	Bids []*pb.Order
+	// Asks - заявки на продажу, от лучшей цены к худшей
+	Asks []*pb.Order
+	// Time - время формирования стакана на бирже
+	Time time.Time
+	// ReceivedAt - время получения стакана
+	ReceivedAt time.Time
+	LimitUp    *pb.Quotation
+	LimitDown  *pb.Quotation
+}
+
+func newLocalOrderBook(ob *pb.OrderBook) *LocalOrderBook {
+	return &LocalOrderBook{
+		Figi:          ob.GetFigi(),
+		InstrumentUid: ob.GetInstrumentUid(),
+		Depth:         ob.GetDepth(),
+		IsConsistent:  ob.GetIsConsistent(),
+		Bids:          ob.GetBids(),
+		Asks:          ob.GetAsks(),
+		Time:          ob.GetTime().AsTime(),
+		ReceivedAt:    time.Now(),
+		LimitUp:       ob.GetLimitUp(),
+		LimitDown:     ob.GetLimitDown(),
+	}
+}
+
+// BestBid - лучшая заявка на покупку, nil если заявок на покупку нет
+func (ob *LocalOrderBook) BestBid() *pb.Order {
+	if len(ob.Bids) == 0 {
+		return nil
+	}
+	return ob.Bids[0]
+}
+
+// BestAsk - лучшая заявка на продажу, nil если заявок на продажу нет
+func (ob *LocalOrderBook) BestAsk() *pb.Order {
+	if len(ob.Asks) == 0 {
+		return nil
+	}
+	return ob.Asks[0]
+}
+
+// IsCrossed - true, если лучшая цена покупки не ниже лучшей цены продажи
+func (ob *LocalOrderBook) IsCrossed() bool {
+	bid, ask := ob.BestBid(), ob.BestAsk()
+	return bid != nil && ask != nil && bid.GetPrice().Cmp(ask.GetPrice()) >= 0
+}
+
+// Spread - разница лучших цен продажи и покупки, nil если одна из сторон стакана пуста или при переполнении
+func (ob *LocalOrderBook) Spread() *pb.Quotation {
+	bid, ask := ob.BestBid(), ob.BestAsk()
+	if bid == nil || ask == nil {
+		return nil
+	}
+	spread, _ := ask.GetPrice().Sub(bid.GetPrice())
+	return spread
+}
+
+// Mid - среднее лучших цен покупки и продажи, nil если одна из сторон стакана пуста или при переполнении
+func (ob *LocalOrderBook) Mid() *pb.Quotation {
+	bid, ask := ob.BestBid(), ob.BestAsk()
+	if bid == nil || ask == nil {
+		return nil
+	}
+	sum, err := bid.GetPrice().Add(ask.GetPrice())
+	if err != nil {
+		return nil
+	}
+	mid, _ := sum.Div(pb.NewQuotation(2, 0))
+	return mid
+}
+
+// Microprice - среднее лучших цен, взвешенное по объему противоположной стороны:
+// (bid * askQty + ask * bidQty) / (bidQty + askQty). nil если одна из сторон стакана пуста или при переполнении
+func (ob *LocalOrderBook) Microprice() *pb.Quotation {
+	bid, ask := ob.BestBid(), ob.BestAsk()
+	if bid == nil || ask == nil {
+		return nil
+	}
+	total := bid.GetQuantity() + ask.GetQuantity()
+	if total == 0 {
+		return ob.Mid()
+	}
+	bidWeighted, err := bid.GetPrice().MulInt(ask.GetQuantity())
+	if err != nil {
+		return nil
+	}
+	askWeighted, err := ask.GetPrice().MulInt(bid.GetQuantity())
+	if err != nil {
+		return nil
+	}
+	weighted, err := bidWeighted.Add(askWeighted)
+	if err != nil {
+		return nil
+	}
+	price, _ := weighted.Div(pb.NewQuotation(total, 0))
+	return price
+}
+
+// Imbalance - дисбаланс объемов первых levels уровней стакана (bidQty - askQty) / (bidQty + askQty)
+// в диапазоне [-1, 1], положительный при перевесе покупателей. levels = 0 - все уровни стакана
+func (ob *LocalOrderBook) Imbalance(levels int) float64 {
+	bids, asks := volume(ob.Bids, levels), volume(ob.Asks, levels)
+	if bids+asks == 0 {
+		return 0
+	}
+	return float64(bids-asks) / float64(bids+asks)
+}
+
+// WeightedPrice - средняя цена исполнения рыночной заявки на lots лотов по текущему стакану.
+// Покупка исполняется по заявкам на продажу, продажа - по заявкам на покупку. Если объема стакана
+// недостаточно, возвращается ErrInsufficientLiquidity
+func (ob *LocalOrderBook) WeightedPrice(direction pb.OrderDirection, lots int64) (*pb.Quotation, error) {
+	if lots <= 0 {
+		return nil, fmt.Errorf("lots must be positive, got %v", lots)
+	}
+	levels, err := ob.side(direction)
+	if err != nil {
+		return nil, err
+	}
+	cost := pb.NewQuotation(0, 0)
+	rest := lots
+	for _, level := range levels {
+		if rest == 0 {
+			break
+		}
+		qty := level.GetQuantity()
+		if qty > rest {
+			qty = rest
+		}
+		levelCost, err := level.GetPrice().MulInt(qty)
+		if err != nil {
+			return nil, err
+		}
+		cost, err = cost.Add(levelCost)
+		if err != nil {
+			return nil, err
+		}
+		rest -= qty
+	}
+	if rest > 0 {
+		return nil, fmt.Errorf("%w: %v of %v lots available", ErrInsufficientLiquidity, lots-rest, lots)
+	}
+	return cost.Div(pb.NewQuotation(lots, 0))
+}
+
+// Slippage - оценка проскальзывания рыночной заявки на lots лотов: разница средней цены исполнения
+// и лучшей цены стакана, неотрицательная для обоих направлений
+func (ob *LocalOrderBook) Slippage(direction pb.OrderDirection, lots int64) (*pb.Quotation, error) {
+	price, err := ob.WeightedPrice(direction, lots)
+	if err != nil {
+		return nil, err
+	}
+	if direction == pb.OrderDirection_ORDER_DIRECTION_BUY {
+		return price.Sub(ob.BestAsk().GetPrice())
+	}
+	return ob.BestBid().GetPrice().Sub(price)
+}
+
+// side - уровни стакана, по которым исполняется рыночная заявка направления direction
+func (ob *LocalOrderBook) side(direction pb.OrderDirection) ([]*pb.Order, error) {
+	switch direction {
+	case pb.OrderDirection_ORDER_DIRECTION_BUY:
+		return ob.Asks, nil
+	case pb.OrderDirection_ORDER_DIRECTION_SELL:
+		return ob.Bids, nil
+	default:
+		return nil, fmt.Errorf("unexpected order direction %v", direction)
+	}
+}
+
+// volume - суммарное количество лотов первых levels уровней, levels = 0 - всех уровней
+func volume(orders []*pb.Order, levels int) int64 {
+	if levels > 0 && levels < len(orders) {
+		orders = orders[:levels]
+	}
+	var total int64
+	for _, o := range orders {
+		total += o.GetQuantity()
+	}
+	return total
+}
@@ -0,0 +1,149 @@
+package investgo_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/therox/invest-api-go-sdk/investgo"
+	pb "github.com/therox/invest-api-go-sdk/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func orders(levels ...int64) []*pb.Order {
+	o := make([]*pb.Order, 0, len(levels)/2)
+	for i := 0; i+1 < len(levels); i += 2 {
+		o = append(o, &pb.Order{Price: pb.NewQuotation(levels[i], 0), Quantity: levels[i+1]})
+	}
+	return o
+}
+
+// testOrderBook - стакан 100x10, 99x20 на покупку и 101x30, 102x10, 103x5 на продажу
+func testOrderBook(ts time.Time) *pb.OrderBook {
+	return &pb.OrderBook{
+		Figi:          "figi",
+		InstrumentUid: "uid",
+		Depth:         3,
+		IsConsistent:  true,
+		Bids:          orders(100, 10, 99, 20),
+		Asks:          orders(101, 30, 102, 10, 103, 5),
+		Time:          timestamppb.New(ts),
+	}
+}
+
+func TestLocalOrderBookPrices(t *testing.T) {
+	b := investgo.NewOrderBookBook(investgo.OrderBookBookOptions{})
+	b.Update(testOrderBook(time.Now()))
+	ob, ok := b.Book("figi")
+	if !ok {
+		t.Fatal("order book is not found by figi")
+	}
+	if got := ob.Spread(); got.Cmp(pb.NewQuotation(1, 0)) != 0 {
+		t.Errorf("spread %v, want 1", got.ToString())
+	}
+	if got := ob.Mid(); got.Cmp(pb.NewQuotation(100, 500000000)) != 0 {
+		t.Errorf("mid %v, want 100.5", got.ToString())
+	}
+	// (100 * 30 + 101 * 10) / 40, цена смещена к стороне с меньшим объемом
+	if got := ob.Microprice(); got.Cmp(pb.NewQuotation(100, 250000000)) != 0 {
+		t.Errorf("microprice %v, want 100.25", got.ToString())
+	}
+	if got := ob.Imbalance(1); got != -0.5 {
+		t.Errorf("imbalance of the best level %v, want -0.5", got)
+	}
+	if got := ob.Imbalance(0); got != -0.2 {
+		t.Errorf("imbalance of all levels %v, want -0.2", got)
+	}
+
+	empty := &investgo.LocalOrderBook{Bids: orders(100, 10)}
+	if empty.Spread() != nil || empty.Mid() != nil || empty.Microprice() != nil {
+		t.Error("prices of the order book without asks are not nil")
+	}
+}
+
+func TestLocalOrderBookSlippage(t *testing.T) {
+	ob := &investgo.LocalOrderBook{Bids: orders(100, 10, 99, 20), Asks: orders(101, 30, 102, 10, 103, 5)}
+	tests := []struct {
+		direction pb.OrderDirection
+		lots      int64
+		price     *pb.Quotation
+		slippage  *pb.Quotation
+	}{
+		// 30 лотов по 101 и 10 по 102
+		{pb.OrderDirection_ORDER_DIRECTION_BUY, 40, pb.NewQuotation(101, 250000000), pb.NewQuotation(0, 250000000)},
+		{pb.OrderDirection_ORDER_DIRECTION_BUY, 30, pb.NewQuotation(101, 0), pb.NewQuotation(0, 0)},
+		// 10 лотов по 100 и 10 по 99
+		{pb.OrderDirection_ORDER_DIRECTION_SELL, 20, pb.NewQuotation(99, 500000000), pb.NewQuotation(0, 500000000)},
+	}
+	for _, tt := range tests {
+		price, err := ob.WeightedPrice(tt.direction, tt.lots)
+		if err != nil {
+			t.Fatal(err)
+		}
+		slippage, err := ob.Slippage(tt.direction, tt.lots)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if price.Cmp(tt.price) != 0 || slippage.Cmp(tt.slippage) != 0 {
+			t.Errorf("%v %v lots: price %v, slippage %v, want %v and %v", tt.direction, tt.lots,
+				price.ToString(), slippage.ToString(), tt.price.ToString(), tt.slippage.ToString())
+		}
+	}
+
+	if _, err := ob.Slippage(pb.OrderDirection_ORDER_DIRECTION_BUY, 46); !errors.Is(err, investgo.ErrInsufficientLiquidity) {
+		t.Errorf("error %v for 46 of 45 lots, want ErrInsufficientLiquidity", err)
+	}
+	if _, err := ob.WeightedPrice(pb.OrderDirection_ORDER_DIRECTION_UNSPECIFIED, 1); err == nil {
+		t.Error("unspecified direction is not an error")
+	}
+
+	huge := &investgo.LocalOrderBook{Bids: orders(100, 10), Asks: orders(math.MaxInt64, 10)}
+	if _, err := huge.WeightedPrice(pb.OrderDirection_ORDER_DIRECTION_BUY, 2); !errors.Is(err, pb.ErrOutOfRange) {
+		t.Errorf("error %v for overflowing order cost, want ErrOutOfRange", err)
+	}
+	if huge.Mid() != nil || huge.Microprice() != nil {
+		t.Error("overflowing prices are not nil")
+	}
+}
+
+func TestOrderBookBookUpdate(t *testing.T) {
+	b := investgo.NewOrderBookBook(investgo.OrderBookBookOptions{StaleAfter: 10 * time.Millisecond})
+	now := time.Now()
+	if !b.Update(testOrderBook(now)) {
+		t.Fatal("first order book is not stored")
+	}
+
+	// стакан старше сохраненного по времени биржи игнорируется
+	old := testOrderBook(now.Add(-time.Second))
+	old.Bids = orders(90, 1)
+	if b.Update(old) {
+		t.Error("older order book replaced the stored one")
+	}
+	byFigi, _ := b.Book("figi")
+	byUid, ok := b.Book("uid")
+	if !ok || byFigi != byUid || byUid.BestBid().GetPrice().Cmp(pb.NewQuotation(100, 0)) != 0 {
+		t.Errorf("order book by uid %+v, want the same book as by figi", byUid)
+	}
+
+	crossed := testOrderBook(now.Add(time.Second))
+	crossed.Bids = orders(102, 1)
+	b.Update(crossed)
+	if books := b.CrossedBooks(); len(books) != 1 || books[0].Figi != "figi" {
+		t.Errorf("crossed books %v, want 1", len(books))
+	}
+
+	if b.Stale("figi") {
+		t.Error("order book is stale right after update")
+	}
+	if !b.Stale("unknown") {
+		t.Error("unknown order book is not stale")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if !b.Stale("figi") {
+		t.Error("order book is not stale after StaleAfter")
+	}
+	if books := b.StaleBooks(); len(books) != 1 {
+		t.Errorf("%v stale books, want 1", len(books))
+	}
+}
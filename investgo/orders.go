@@ -18,8 +18,13 @@ type OrdersServiceClient struct {
 
 // PostOrder - Метод выставления биржевой заявки
 func (os *OrdersServiceClient) PostOrder(req *PostOrderRequest) (*PostOrderResponse, error) {
+	return os.PostOrderCtx(os.ctx, req)
+}
+
+// PostOrderCtx - PostOrder с контекстом запроса
+func (os *OrdersServiceClient) PostOrderCtx(ctx context.Context, req *PostOrderRequest) (*PostOrderResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := os.pbClient.PostOrder(os.ctx, &pb.PostOrderRequest{
+	resp, err := os.pbClient.PostOrder(ctx, &pb.PostOrderRequest{
 		Quantity:     req.Quantity,
 		Price:        req.Price,
 		Direction:    req.Direction,
@@ -30,6 +35,7 @@ func (os *OrdersServiceClient) PostOrder(req *PostOrderRequest) (*PostOrderRespo
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &PostOrderResponse{
 		PostOrderResponse: resp,
@@ -39,8 +45,13 @@ func (os *OrdersServiceClient) PostOrder(req *PostOrderRequest) (*PostOrderRespo
 
 // Buy - Метод выставления поручения на покупку инструмента
 func (os *OrdersServiceClient) Buy(req *PostOrderRequestShort) (*PostOrderResponse, error) {
+	return os.BuyCtx(os.ctx, req)
+}
+
+// BuyCtx - Buy с контекстом запроса
+func (os *OrdersServiceClient) BuyCtx(ctx context.Context, req *PostOrderRequestShort) (*PostOrderResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := os.pbClient.PostOrder(os.ctx, &pb.PostOrderRequest{
+	resp, err := os.pbClient.PostOrder(ctx, &pb.PostOrderRequest{
 		Quantity:     req.Quantity,
 		Price:        req.Price,
 		Direction:    pb.OrderDirection_ORDER_DIRECTION_BUY,
@@ -51,6 +62,7 @@ func (os *OrdersServiceClient) Buy(req *PostOrderRequestShort) (*PostOrderRespon
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &PostOrderResponse{
 		PostOrderResponse: resp,
@@ -60,8 +72,13 @@ func (os *OrdersServiceClient) Buy(req *PostOrderRequestShort) (*PostOrderRespon
 
 // Sell - Метод выставления поручения на продажу инструмента
 func (os *OrdersServiceClient) Sell(req *PostOrderRequestShort) (*PostOrderResponse, error) {
+	return os.SellCtx(os.ctx, req)
+}
+
+// SellCtx - Sell с контекстом запроса
+func (os *OrdersServiceClient) SellCtx(ctx context.Context, req *PostOrderRequestShort) (*PostOrderResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := os.pbClient.PostOrder(os.ctx, &pb.PostOrderRequest{
+	resp, err := os.pbClient.PostOrder(ctx, &pb.PostOrderRequest{
 		Quantity:     req.Quantity,
 		Price:        req.Price,
 		Direction:    pb.OrderDirection_ORDER_DIRECTION_SELL,
@@ -72,6 +89,7 @@ func (os *OrdersServiceClient) Sell(req *PostOrderRequestShort) (*PostOrderRespo
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &PostOrderResponse{
 		PostOrderResponse: resp,
@@ -81,13 +99,19 @@ func (os *OrdersServiceClient) Sell(req *PostOrderRequestShort) (*PostOrderRespo
 
 // CancelOrder - Метод отмены биржевой заявки
 func (os *OrdersServiceClient) CancelOrder(accountId, orderId string) (*CancelOrderResponse, error) {
+	return os.CancelOrderCtx(os.ctx, accountId, orderId)
+}
+
+// CancelOrderCtx - CancelOrder с контекстом запроса
+func (os *OrdersServiceClient) CancelOrderCtx(ctx context.Context, accountId, orderId string) (*CancelOrderResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := os.pbClient.CancelOrder(os.ctx, &pb.CancelOrderRequest{
+	resp, err := os.pbClient.CancelOrder(ctx, &pb.CancelOrderRequest{
 		AccountId: accountId,
 		OrderId:   orderId,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &CancelOrderResponse{
 		CancelOrderResponse: resp,
@@ -97,13 +121,19 @@ func (os *OrdersServiceClient) CancelOrder(accountId, orderId string) (*CancelOr
 
 // GetOrderState - Метод получения статуса торгового поручения
 func (os *OrdersServiceClient) GetOrderState(accountId, orderId string) (*GetOrderStateResponse, error) {
+	return os.GetOrderStateCtx(os.ctx, accountId, orderId)
+}
+
+// GetOrderStateCtx - GetOrderState с контекстом запроса
+func (os *OrdersServiceClient) GetOrderStateCtx(ctx context.Context, accountId, orderId string) (*GetOrderStateResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := os.pbClient.GetOrderState(os.ctx, &pb.GetOrderStateRequest{
+	resp, err := os.pbClient.GetOrderState(ctx, &pb.GetOrderStateRequest{
 		AccountId: accountId,
 		OrderId:   orderId,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetOrderStateResponse{
 		OrderState: resp,
@@ -113,12 +143,18 @@ func (os *OrdersServiceClient) GetOrderState(accountId, orderId string) (*GetOrd
 
 // GetOrders - Метод получения списка активных заявок по счёту
 func (os *OrdersServiceClient) GetOrders(accountId string) (*GetOrdersResponse, error) {
+	return os.GetOrdersCtx(os.ctx, accountId)
+}
+
+// GetOrdersCtx - GetOrders с контекстом запроса
+func (os *OrdersServiceClient) GetOrdersCtx(ctx context.Context, accountId string) (*GetOrdersResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := os.pbClient.GetOrders(os.ctx, &pb.GetOrdersRequest{
+	resp, err := os.pbClient.GetOrders(ctx, &pb.GetOrdersRequest{
 		AccountId: accountId,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetOrdersResponse{
 		GetOrdersResponse: resp,
@@ -128,8 +164,13 @@ func (os *OrdersServiceClient) GetOrders(accountId string) (*GetOrdersResponse,
 
 // ReplaceOrder - Метод изменения выставленной заявки
 func (os *OrdersServiceClient) ReplaceOrder(req *ReplaceOrderRequest) (*PostOrderResponse, error) {
+	return os.ReplaceOrderCtx(os.ctx, req)
+}
+
+// ReplaceOrderCtx - ReplaceOrder с контекстом запроса
+func (os *OrdersServiceClient) ReplaceOrderCtx(ctx context.Context, req *ReplaceOrderRequest) (*PostOrderResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := os.pbClient.ReplaceOrder(os.ctx, &pb.ReplaceOrderRequest{
+	resp, err := os.pbClient.ReplaceOrder(ctx, &pb.ReplaceOrderRequest{
 		AccountId:      req.AccountId,
 		OrderId:        req.OrderId,
 		IdempotencyKey: req.NewOrderId,
@@ -139,6 +180,7 @@ func (os *OrdersServiceClient) ReplaceOrder(req *ReplaceOrderRequest) (*PostOrde
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &PostOrderResponse{
 		PostOrderResponse: resp,
@@ -13,23 +13,55 @@ type OrdersStreamClient struct {
 	logger   Logger
 	ctx      context.Context
 	pbClient pb.OrdersStreamServiceClient
+	streams  *streamRegistry
 }
 
 // TradesStream - Стрим сделок по запрашиваемым аккаунтам
 func (o *OrdersStreamClient) TradesStream(accounts []string) (*TradesStream, error) {
-	ctx, cancel := context.WithCancel(o.ctx)
-	stream, err := o.pbClient.TradesStream(ctx, &pb.TradesStreamRequest{
-		Accounts: accounts,
-	})
-	if err != nil {
-		cancel()
-		return nil, err
-	}
-	return &TradesStream{
-		stream:       stream,
+	return o.TradesStreamCtx(o.ctx, accounts)
+}
+
+// TradesStreamCtx - TradesStream, время жизни стрима ограничено контекстом ctx
+func (o *OrdersStreamClient) TradesStreamCtx(ctx context.Context, accounts []string) (*TradesStream, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	ts := &TradesStream{
 		ordersClient: o,
+		req:          &pb.TradesStreamRequest{Accounts: accounts},
 		trades:       make(chan *pb.OrderTrades),
 		ctx:          ctx,
 		cancel:       cancel,
-	}, nil
+		lifecycle:    newStreamLifecycle(),
+		watchdog:     newStreamWatchdog(),
+		reconnect:    DefaultReconnectPolicy(),
+	}
+	err := ts.openStream()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	o.streams.add(ts)
+	return ts, nil
+}
+
+// AsOrdersStreamService - o в виде OrdersStreamService, стримы возвращаются как интерфейсы,
+// поэтому в тестах фабрику можно заменить моком из investgomock
+func AsOrdersStreamService(o *OrdersStreamClient) OrdersStreamService {
+	return ordersStreamService{client: o}
+}
+
+// ordersStreamService - OrdersStreamService поверх *OrdersStreamClient
+type ordersStreamService struct {
+	client *OrdersStreamClient
+}
+
+func (s ordersStreamService) TradesStream(accounts []string) (TradesStreamer, error) {
+	return s.TradesStreamCtx(s.client.ctx, accounts)
+}
+
+func (s ordersStreamService) TradesStreamCtx(ctx context.Context, accounts []string) (TradesStreamer, error) {
+	stream, err := s.client.TradesStreamCtx(ctx, accounts)
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
 }
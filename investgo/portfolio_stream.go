@@ -11,9 +11,15 @@ import (
 type PortfolioStream struct {
 	stream           pb.OperationsStreamService_PortfolioStreamClient
 	operationsClient *OperationsStreamClient
+	req              *pb.PortfolioStreamRequest
 
-	ctx    context.Context
-	cancel context.CancelFunc
+	ctx       context.Context
+	cancel    context.CancelFunc
+	lifecycle streamLifecycle
+	watchdog  *streamWatchdog
+	recorder  *Recorder
+	// reconnect - политика принудительного переподключения зависшего стрима
+	reconnect ReconnectPolicy
 
 	portfolios chan *pb.PortfolioResponse
 }
@@ -25,7 +31,11 @@ func (p *PortfolioStream) Portfolios() <-chan *pb.PortfolioResponse {
 
 // Listen - метод начинает слушать стрим и отправлять информацию в канал, для получения канала: Portfolios()
 func (p *PortfolioStream) Listen() error {
+	if !p.lifecycle.begin() {
+		return nil
+	}
 	defer p.shutdown()
+	go p.watchdog.run(p.ctx, p.operationsClient.logger, "Portfolio stream")
 	for {
 		select {
 		case <-p.ctx.Done():
@@ -34,6 +44,15 @@ func (p *PortfolioStream) Listen() error {
 			resp, err := p.stream.Recv()
 			if err != nil {
 				switch {
+				case p.watchdog.reconnectRequested(p.ctx, err):
+					p.operationsClient.logger.Infof("Portfolio stream is stale, reconnecting")
+					err := p.reconnect.retry(p.ctx, p.operationsClient.logger, "Portfolio stream", err, p.openStream)
+					if err != nil {
+						if p.ctx.Err() != nil {
+							return nil
+						}
+						return err
+					}
 				case status.Code(err) == codes.Canceled:
 					p.operationsClient.logger.Infof("Stop listening portfolios")
 					return nil
@@ -41,9 +60,16 @@ func (p *PortfolioStream) Listen() error {
 					return err
 				}
 			} else {
+				p.recorder.record(RecordedPortfolio, resp)
 				switch resp.GetPayload().(type) {
 				case *pb.PortfolioStreamResponse_Portfolio:
-					p.portfolios <- resp.GetPortfolio()
+					p.watchdog.data()
+					select {
+					case p.portfolios <- resp.GetPortfolio():
+					case <-p.ctx.Done():
+					}
+				case *pb.PortfolioStreamResponse_Ping:
+					p.watchdog.ping()
 				default:
 					p.operationsClient.logger.Infof("Info from Portfolio stream %v", resp.String())
 				}
@@ -52,7 +78,45 @@ func (p *PortfolioStream) Listen() error {
 	}
 }
 
+// SetReconnectPolicy - установка политики принудительного переподключения зависшего стрима, вызывается до Listen
+func (p *PortfolioStream) SetReconnectPolicy(policy ReconnectPolicy) {
+	p.reconnect = policy
+}
+
+// SetWatchdog - установка проверки поступления Ping, вызывается до Listen
+func (p *PortfolioStream) SetWatchdog(opts WatchdogOptions) {
+	p.watchdog.setOptions(opts)
+}
+
+// Health - статистика активности стрима
+func (p *PortfolioStream) Health() StreamHealth {
+	return p.watchdog.stats()
+}
+
+// SetRecorder - запись всех сообщений стрима, вызывается до Listen
+func (p *PortfolioStream) SetRecorder(r *Recorder) {
+	p.recorder = r
+}
+
+// openStream - открытие нового grpc стрима в рамках контекста PortfolioStream
+func (p *PortfolioStream) openStream() error {
+	ctx, cancel := context.WithCancel(p.ctx)
+	stream, err := p.operationsClient.pbClient.PortfolioStream(ctx, p.req)
+	if err != nil {
+		cancel()
+		return err
+	}
+	p.stream = stream
+	p.watchdog.connected(cancel)
+	return nil
+}
+
 func (p *PortfolioStream) shutdown() {
+	p.lifecycle.end(p.closeChannels)
+	p.operationsClient.streams.remove(p)
+}
+
+func (p *PortfolioStream) closeChannels() {
 	p.operationsClient.logger.Infof("Close portfolio stream")
 	close(p.portfolios)
 }
@@ -61,3 +125,12 @@ func (p *PortfolioStream) shutdown() {
 func (p *PortfolioStream) Stop() {
 	p.cancel()
 }
+
+// wait - ожидание завершения Listen и закрытия канала
+func (p *PortfolioStream) wait(ctx context.Context) error {
+	err := p.lifecycle.wait(ctx, p.closeChannels)
+	if err == nil {
+		p.operationsClient.streams.remove(p)
+	}
+	return err
+}
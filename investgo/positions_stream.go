@@ -11,9 +11,15 @@ import (
 type PositionsStream struct {
 	stream           pb.OperationsStreamService_PositionsStreamClient
 	operationsClient *OperationsStreamClient
+	req              *pb.PositionsStreamRequest
 
-	ctx    context.Context
-	cancel context.CancelFunc
+	ctx       context.Context
+	cancel    context.CancelFunc
+	lifecycle streamLifecycle
+	watchdog  *streamWatchdog
+	recorder  *Recorder
+	// reconnect - политика принудительного переподключения зависшего стрима
+	reconnect ReconnectPolicy
 
 	positions chan *pb.PositionData
 }
@@ -25,7 +31,11 @@ func (p *PositionsStream) Positions() <-chan *pb.PositionData {
 
 // Listen - метод начинает слушать стрим и отправлять информацию в канал, для получения канала: Positions()
 func (p *PositionsStream) Listen() error {
+	if !p.lifecycle.begin() {
+		return nil
+	}
 	defer p.shutdown()
+	go p.watchdog.run(p.ctx, p.operationsClient.logger, "Positions stream")
 	for {
 		select {
 		case <-p.ctx.Done():
@@ -34,6 +44,15 @@ func (p *PositionsStream) Listen() error {
 			resp, err := p.stream.Recv()
 			if err != nil {
 				switch {
+				case p.watchdog.reconnectRequested(p.ctx, err):
+					p.operationsClient.logger.Infof("Positions stream is stale, reconnecting")
+					err := p.reconnect.retry(p.ctx, p.operationsClient.logger, "Positions stream", err, p.openStream)
+					if err != nil {
+						if p.ctx.Err() != nil {
+							return nil
+						}
+						return err
+					}
 				case status.Code(err) == codes.Canceled:
 					p.operationsClient.logger.Infof("Stop listening positions")
 					return nil
@@ -41,9 +60,16 @@ func (p *PositionsStream) Listen() error {
 					return err
 				}
 			} else {
+				p.recorder.record(RecordedPositions, resp)
 				switch resp.GetPayload().(type) {
 				case *pb.PositionsStreamResponse_Position:
-					p.positions <- resp.GetPosition()
+					p.watchdog.data()
+					select {
+					case p.positions <- resp.GetPosition():
+					case <-p.ctx.Done():
+					}
+				case *pb.PositionsStreamResponse_Ping:
+					p.watchdog.ping()
 				default:
 					p.operationsClient.logger.Infof("Info from Positions stream %v", resp.String())
 				}
@@ -52,7 +78,45 @@ func (p *PositionsStream) Listen() error {
 	}
 }
 
+// SetReconnectPolicy - установка политики принудительного переподключения зависшего стрима, вызывается до Listen
+func (p *PositionsStream) SetReconnectPolicy(policy ReconnectPolicy) {
+	p.reconnect = policy
+}
+
+// SetWatchdog - установка проверки поступления Ping, вызывается до Listen
+func (p *PositionsStream) SetWatchdog(opts WatchdogOptions) {
+	p.watchdog.setOptions(opts)
+}
+
+// Health - статистика активности стрима
+func (p *PositionsStream) Health() StreamHealth {
+	return p.watchdog.stats()
+}
+
+// SetRecorder - запись всех сообщений стрима, вызывается до Listen
+func (p *PositionsStream) SetRecorder(r *Recorder) {
+	p.recorder = r
+}
+
+// openStream - открытие нового grpc стрима в рамках контекста PositionsStream
+func (p *PositionsStream) openStream() error {
+	ctx, cancel := context.WithCancel(p.ctx)
+	stream, err := p.operationsClient.pbClient.PositionsStream(ctx, p.req)
+	if err != nil {
+		cancel()
+		return err
+	}
+	p.stream = stream
+	p.watchdog.connected(cancel)
+	return nil
+}
+
 func (p *PositionsStream) shutdown() {
+	p.lifecycle.end(p.closeChannels)
+	p.operationsClient.streams.remove(p)
+}
+
+func (p *PositionsStream) closeChannels() {
 	p.operationsClient.logger.Infof("Close positions stream")
 	close(p.positions)
 }
@@ -61,3 +125,12 @@ func (p *PositionsStream) shutdown() {
 func (p *PositionsStream) Stop() {
 	p.cancel()
 }
+
+// wait - ожидание завершения Listen и закрытия канала
+func (p *PositionsStream) wait(ctx context.Context) error {
+	err := p.lifecycle.wait(ctx, p.closeChannels)
+	if err == nil {
+		p.operationsClient.streams.remove(p)
+	}
+	return err
+}
@@ -0,0 +1,282 @@
+package investgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+// PriceSource - источник цены в PriceCache
+type PriceSource int
+
+const (
+	// PriceLast - цена последней сделки из GetLastPrices или стрима
+	PriceLast PriceSource = iota
+	// PriceClose - цена закрытия торговой сессии из GetClosePrices, если последней цены нет
+	PriceClose
+)
+
+// Quote - цена инструмента в PriceCache
+type Quote struct {
+	Figi          string
+	InstrumentUid string
+	Price         *pb.Quotation
+	// Time - время цены по данным биржи
+	Time time.Time
+	// ReceivedAt - время получения цены
+	ReceivedAt time.Time
+	Source     PriceSource
+}
+
+// Age - возраст цены от момента AgeFrom
+func (q Quote) Age() time.Duration {
+	return time.Since(q.AgeFrom())
+}
+
+// AgeFrom - момент, от которого считается возраст цены. Для последней цены это время по данным биржи,
+// для цены закрытия - время получения, так как время биржи у нее всегда в прошлой сессии и такая цена
+// устаревала бы сразу после загрузки
+func (q Quote) AgeFrom() time.Time {
+	if q.Source == PriceClose {
+		return q.ReceivedAt
+	}
+	return q.Time
+}
+
+// LastPriceSubscriber - подписка на последние цены для PriceCache, реализуется MDStream и MarketDataPool
+type LastPriceSubscriber interface {
+	SubscribeLastPriceAsync(ids []string) (*SubscriptionFuture, error)
+}
+
+// ErrPriceCacheNotRunning - подписка невозможна, так как Run не запущен и цены из стрима некому читать
+var ErrPriceCacheNotRunning = errors.New("price cache is not running")
+
+// PriceCacheOptions - параметры кэша цен
+type PriceCacheOptions struct {
+	// MaxAge - возраст цены (Quote.Age), после которого GetFresh запрашивает ее заново, 0 - цена не устаревает
+	MaxAge time.Duration
+}
+
+// PriceCache - общий кэш последних цен инструментов. Цены загружаются методами GetLastPrices и GetClosePrices
+// и обновляются из стрима, на каждый инструмент оформляется одна подписка независимо от количества
+// компонентов, которые его используют. Цена заменяется только более новой по времени биржи, поэтому
+// запоздавший снимок не перезаписывает цену из стрима. Цена доступна по figi и instrument_uid
+type PriceCache struct {
+	md   MarketDataService
+	sub  LastPriceSubscriber
+	opts PriceCacheOptions
+
+	mu sync.RWMutex
+	// quotes - цены по figi и instrument_uid, оба ключа указывают на одну цену
+	quotes map[string]*Quote
+	// tracked - инструменты, на которые оформлена подписка
+	tracked map[string]struct{}
+
+	// changes - рассылка изменений цен
+	changes *MDDispatcher
+	// running - закрывается при запуске Run
+	running chan struct{}
+	runOnce sync.Once
+}
+
+// NewPriceCache - создание кэша цен, sub может быть nil, тогда подписка не оформляется и цены обновляются
+// из Run и Refresh. Если sub задан, цены из стрима читает Run, его нужно запустить до Track
+func NewPriceCache(md MarketDataService, sub LastPriceSubscriber, opts PriceCacheOptions) *PriceCache {
+	return &PriceCache{
+		md:      md,
+		sub:     sub,
+		opts:    opts,
+		quotes:  make(map[string]*Quote, 0),
+		tracked: make(map[string]struct{}, 0),
+		changes: NewMDDispatcher(),
+		running: make(chan struct{}),
+	}
+}
+
+// Track - загрузка цен инструментов ids (figi или instrument_uid) и подписка на последние цены по инструментам,
+// которые еще не отслеживаются. Подписка оформляется до загрузки цен, чтобы не пропустить обновления, Track
+// ожидает запуска Run и ответа сервера на подписку, если Run не запущен до отмены ctx, возвращается
+// ErrPriceCacheNotRunning. При ошибке инструменты не отслеживаются и могут быть переданы в Track повторно
+func (c *PriceCache) Track(ctx context.Context, ids []string) error {
+	c.mu.Lock()
+	added := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := c.tracked[id]; !ok {
+			c.tracked[id] = struct{}{}
+			added = append(added, id)
+		}
+	}
+	c.mu.Unlock()
+	if len(added) == 0 {
+		return nil
+	}
+	if c.sub != nil {
+		if err := c.subscribe(ctx, added); err != nil {
+			c.untrack(added)
+			return err
+		}
+	}
+	if err := c.Refresh(ctx, added); err != nil {
+		c.untrack(added)
+		return err
+	}
+	return nil
+}
+
+// subscribe - подписка на последние цены ids после запуска Run с ожиданием ответа сервера
+func (c *PriceCache) subscribe(ctx context.Context, ids []string) error {
+	select {
+	case <-c.running:
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %w", ErrPriceCacheNotRunning, ctx.Err())
+	}
+	future, err := c.sub.SubscribeLastPriceAsync(ids)
+	if err != nil {
+		return err
+	}
+	res, err := future.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	return res.Err()
+}
+
+// Run - обновление цен из канала src до его закрытия, например MDStream.LastPrices() или канала потребителя
+// MDDispatcher. После закрытия src закрываются каналы Changes
+func (c *PriceCache) Run(src <-chan *pb.LastPrice) {
+	c.runOnce.Do(func() {
+		close(c.running)
+	})
+	defer c.changes.closeAll()
+	for lp := range src {
+		c.update(lp, PriceLast)
+	}
+}
+
+// Refresh - запрос последних цен инструментов ids, для инструментов без последней цены запрашивается
+// цена закрытия
+func (c *PriceCache) Refresh(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	last, err := c.md.GetLastPricesCtx(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, lp := range last.GetLastPrices() {
+		if lp.GetPrice() != nil && lp.GetTime() != nil {
+			c.update(lp, PriceLast)
+		}
+	}
+	missing := make([]string, 0)
+	for _, id := range ids {
+		if _, ok := c.Get(id); !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	closePrices, err := c.md.GetClosePricesCtx(ctx, missing)
+	if err != nil {
+		return err
+	}
+	for _, cp := range closePrices.GetClosePrices() {
+		c.update(&pb.LastPrice{
+			Figi:          cp.GetFigi(),
+			Price:         cp.GetPrice(),
+			Time:          cp.GetTime(),
+			InstrumentUid: cp.GetInstrumentUid(),
+		}, PriceClose)
+	}
+	return nil
+}
+
+// Get - цена инструмента по figi или instrument_uid
+func (c *PriceCache) Get(id string) (Quote, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	q, ok := c.quotes[id]
+	if !ok {
+		return Quote{}, false
+	}
+	return *q, true
+}
+
+// GetFresh - цена инструмента, которая запрашивается заново, если ее нет или она старше MaxAge
+func (c *PriceCache) GetFresh(ctx context.Context, id string) (Quote, error) {
+	q, ok := c.Get(id)
+	if ok && (c.opts.MaxAge <= 0 || q.Age() <= c.opts.MaxAge) {
+		return q, nil
+	}
+	if err := c.Refresh(ctx, []string{id}); err != nil {
+		return Quote{}, err
+	}
+	q, ok = c.Get(id)
+	if !ok {
+		return Quote{}, fmt.Errorf("no price for %v", id)
+	}
+	return q, nil
+}
+
+// Changes - канал изменений цен инструментов ids, при пустом ids - всех инструментов. Отправляется
+// только цена, отличающаяся от предыдущей
+func (c *PriceCache) Changes(ids []string, opts ConsumerOptions) *Consumer[*pb.LastPrice] {
+	return c.changes.LastPrices(ids, opts)
+}
+
+// OnChange - вызов fn для изменений цен инструментов ids в отдельной горутине потребителя
+func (c *PriceCache) OnChange(ids []string, opts ConsumerOptions, fn func(*pb.LastPrice)) DispatchHandle {
+	return c.changes.OnLastPrice(ids, opts, fn)
+}
+
+// update - замена цены более новой, при изменении цены отправляется уведомление
+func (c *PriceCache) update(lp *pb.LastPrice, source PriceSource) {
+	q := &Quote{
+		Figi:          lp.GetFigi(),
+		InstrumentUid: lp.GetInstrumentUid(),
+		Price:         lp.GetPrice(),
+		Time:          lp.GetTime().AsTime(),
+		ReceivedAt:    time.Now(),
+		Source:        source,
+	}
+	c.mu.Lock()
+	current := c.quotes[q.Figi]
+	if current == nil {
+		current = c.quotes[q.InstrumentUid]
+	}
+	if current != nil && q.Time.Before(current.Time) {
+		c.mu.Unlock()
+		return
+	}
+	if current != nil {
+		// стрим и снимок могут не содержать один из идентификаторов
+		if q.Figi == "" {
+			q.Figi = current.Figi
+		}
+		if q.InstrumentUid == "" {
+			q.InstrumentUid = current.InstrumentUid
+		}
+	}
+	for _, key := range []string{q.Figi, q.InstrumentUid} {
+		if key != "" {
+			c.quotes[key] = q
+		}
+	}
+	c.mu.Unlock()
+	if current == nil || !current.Price.Equal(q.Price) {
+		c.changes.dispatch(SubscriptionLastPrices, q.Figi, q.InstrumentUid, lp)
+	}
+}
+
+func (c *PriceCache) untrack(ids []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range ids {
+		delete(c.tracked, id)
+	}
+}
@@ -0,0 +1,177 @@
+package investgo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/therox/invest-api-go-sdk/investgo"
+	"github.com/therox/invest-api-go-sdk/investgo/investgomock"
+	pb "github.com/therox/invest-api-go-sdk/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// priceService - последние цены lastPrices и цена закрытия вчерашней сессии для остальных инструментов
+func priceService(lastPrices map[string]*pb.LastPrice) *investgomock.MarketDataServiceMock {
+	return &investgomock.MarketDataServiceMock{
+		GetLastPricesCtxFunc: func(ctx context.Context, ids []string) (*investgo.GetLastPricesResponse, error) {
+			resp := &pb.GetLastPricesResponse{}
+			for _, id := range ids {
+				lp, ok := lastPrices[id]
+				if !ok {
+					// инструмент без сделок возвращается без цены
+					lp = &pb.LastPrice{Figi: id}
+				}
+				resp.LastPrices = append(resp.LastPrices, lp)
+			}
+			return &investgo.GetLastPricesResponse{GetLastPricesResponse: resp}, nil
+		},
+		GetClosePricesCtxFunc: func(ctx context.Context, ids []string) (*investgo.GetClosePricesResponse, error) {
+			resp := &pb.GetClosePricesResponse{}
+			for _, id := range ids {
+				resp.ClosePrices = append(resp.ClosePrices, &pb.InstrumentClosePriceResponse{
+					Figi:  id,
+					Price: pb.NewQuotation(100, 0),
+					Time:  timestamppb.New(time.Now().Add(-18 * time.Hour)),
+				})
+			}
+			return &investgo.GetClosePricesResponse{GetClosePricesResponse: resp}, nil
+		},
+	}
+}
+
+func TestPriceCacheClosePriceAge(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	md := priceService(nil)
+	prices := investgo.NewPriceCache(md, nil, investgo.PriceCacheOptions{MaxAge: time.Minute})
+	if err := prices.Track(ctx, []string{"figi"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// цена закрытия прошлой сессии не устаревает сразу после загрузки
+	q, err := prices.GetFresh(ctx, "figi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Source != investgo.PriceClose || q.Age() > time.Minute || !q.AgeFrom().Equal(q.ReceivedAt) {
+		t.Errorf("quote %+v, age %v, want fresh close price", q, q.Age())
+	}
+	if n := len(md.CallsTo("GetLastPricesCtx")) + len(md.CallsTo("GetClosePricesCtx")); n != 2 {
+		t.Errorf("%v price requests, want 2 from Track only", n)
+	}
+}
+
+func TestPriceCacheStaleLastPrice(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	lastPrices := map[string]*pb.LastPrice{
+		"figi": {Figi: "figi", Price: pb.NewQuotation(10, 0), Time: timestamppb.New(time.Now().Add(-time.Hour))},
+	}
+	md := priceService(lastPrices)
+	prices := investgo.NewPriceCache(md, nil, investgo.PriceCacheOptions{MaxAge: time.Minute})
+	if err := prices.Track(ctx, []string{"figi"}); err != nil {
+		t.Fatal(err)
+	}
+	q, ok := prices.Get("figi")
+	if !ok || q.Source != investgo.PriceLast || q.Age() < time.Hour {
+		t.Fatalf("quote %+v, want last price an hour old by exchange time", q)
+	}
+
+	// последняя цена старше MaxAge по времени биржи запрашивается заново
+	lastPrices["figi"] = &pb.LastPrice{Figi: "figi", Price: pb.NewQuotation(11, 0), Time: timestamppb.Now()}
+	q, err := prices.GetFresh(ctx, "figi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Price.Cmp(pb.NewQuotation(11, 0)) != 0 || q.Age() > time.Minute {
+		t.Errorf("quote %+v, want refreshed price 11", q)
+	}
+	if n := len(md.CallsTo("GetLastPricesCtx")); n != 2 {
+		t.Errorf("%v GetLastPrices requests, want 2", n)
+	}
+	if n := len(md.CallsTo("GetClosePricesCtx")); n != 0 {
+		t.Errorf("%v GetClosePrices requests for instrument with last price", n)
+	}
+}
+
+func lastPriceSubscriber() *investgomock.MarketDataStreamerMock {
+	return &investgomock.MarketDataStreamerMock{
+		SubscribeLastPriceAsyncFunc: func(ids []string) (*investgo.SubscriptionFuture, error) {
+			return investgo.CompletedSubscriptionFuture(&investgo.SubscriptionResult{
+				Type:      investgo.SubscriptionLastPrices,
+				Succeeded: ids,
+			}, nil), nil
+		},
+	}
+}
+
+func TestPriceCacheTrackRequiresRun(t *testing.T) {
+	md := priceService(nil)
+	sub := lastPriceSubscriber()
+	prices := investgo.NewPriceCache(md, sub, investgo.PriceCacheOptions{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := prices.Track(ctx, []string{"figi"}); !errors.Is(err, investgo.ErrPriceCacheNotRunning) {
+		t.Fatalf("Track without Run: %v, want ErrPriceCacheNotRunning", err)
+	}
+	if n := len(sub.CallsTo("SubscribeLastPriceAsync")); n != 0 {
+		t.Fatalf("%v subscriptions without Run", n)
+	}
+
+	src := make(chan *pb.LastPrice)
+	defer close(src)
+	go prices.Run(src)
+	ctx, cancel = context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	if err := prices.Track(ctx, []string{"figi"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(sub.CallsTo("SubscribeLastPriceAsync")); n != 1 {
+		t.Errorf("%v subscriptions, want 1 after Run", n)
+	}
+
+	// цены из стрима читает Run
+	src <- &pb.LastPrice{Figi: "figi", Price: pb.NewQuotation(12, 0), Time: timestamppb.Now()}
+	for ctx.Err() == nil {
+		if q, ok := prices.Get("figi"); ok && q.Source == investgo.PriceLast {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Error("stream price is not applied")
+}
+
+func TestPriceCacheTrackUntracksOnError(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	md := priceService(nil)
+	lastPrices := md.GetLastPricesCtxFunc
+	md.GetLastPricesCtxFunc = func(ctx context.Context, ids []string) (*investgo.GetLastPricesResponse, error) {
+		if len(md.CallsTo("GetLastPricesCtx")) == 1 {
+			return nil, errors.New("unavailable")
+		}
+		return lastPrices(ctx, ids)
+	}
+	sub := lastPriceSubscriber()
+	prices := investgo.NewPriceCache(md, sub, investgo.PriceCacheOptions{})
+	src := make(chan *pb.LastPrice)
+	defer close(src)
+	go prices.Run(src)
+
+	if err := prices.Track(ctx, []string{"figi"}); err == nil {
+		t.Fatal("Track error is lost")
+	}
+	// инструмент без цены не считается отслеживаемым, повторный Track загружает цену
+	if err := prices.Track(ctx, []string{"figi"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := prices.Get("figi"); !ok {
+		t.Error("no price after retried Track")
+	}
+	if n := len(sub.CallsTo("SubscribeLastPriceAsync")); n != 2 {
+		t.Errorf("%v subscriptions, want 2", n)
+	}
+}
@@ -0,0 +1,179 @@
+package investgo
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// rateLimiter - клиентский ограничитель unary-запросов, построенный по лимитам тарифа пользователя.
+// Методы одной группы UnaryLimit расходуют общий бакет
+type rateLimiter struct {
+	mu      sync.RWMutex
+	buckets map[string]*tokenBucket
+	logger  Logger
+}
+
+func newRateLimiter(l Logger) *rateLimiter {
+	return &rateLimiter{
+		buckets: make(map[string]*tokenBucket, 0),
+		logger:  l,
+	}
+}
+
+// tariffTimeout - максимальное время запроса тарифа при создании клиента, включая повторы
+const tariffTimeout = 10 * time.Second
+
+// load - запрос тарифа пользователя и построение бакетов по полученным лимитам. Запрос ограничен
+// tariffTimeout, при ошибке бакеты не меняются
+func (r *rateLimiter) load(ctx context.Context, client pb.UsersServiceClient) error {
+	ctx, cancel := context.WithTimeout(ctx, tariffTimeout)
+	defer cancel()
+	resp, err := client.GetUserTariff(ctx, &pb.GetUserTariffRequest{})
+	if err != nil {
+		return err
+	}
+	r.setLimits(resp.GetUnaryLimits())
+	return nil
+}
+
+func (r *rateLimiter) setLimits(limits []*pb.UnaryLimit) {
+	buckets := make(map[string]*tokenBucket, 0)
+	for _, limit := range limits {
+		if limit.GetLimitPerMinute() <= 0 {
+			continue
+		}
+		bucket := newTokenBucket(int(limit.GetLimitPerMinute()), time.Minute)
+		for _, method := range limit.GetMethods() {
+			buckets[normalizeMethod(method)] = bucket
+		}
+	}
+	r.mu.Lock()
+	r.buckets = buckets
+	r.mu.Unlock()
+}
+
+func (r *rateLimiter) bucket(method string) *tokenBucket {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.buckets[normalizeMethod(method)]
+}
+
+// limits - true, если для метода есть лимит тарифа, то есть запросы ограничиваются лимитером
+func (r *rateLimiter) limits(method string) bool {
+	return r.bucket(method) != nil
+}
+
+// unaryInterceptor - ожидание свободного токена перед вызовом метода и корректировка бакета
+// по заголовкам x-ratelimit-remaining и x-ratelimit-reset из ответа
+func (r *rateLimiter) unaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	bucket := r.bucket(method)
+	if bucket == nil {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	if err := bucket.wait(ctx); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	var header, trailer metadata.MD
+	opts = append(opts, grpc.Header(&header), grpc.Trailer(&trailer))
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if err != nil {
+		header = trailer
+	}
+	bucket.update(RemainingLimitFromHeader(header), ResetLimitFromHeader(header))
+	return err
+}
+
+// normalizeMethod - приведение имени метода к виду service/method без ведущего слеша
+func normalizeMethod(method string) string {
+	return strings.TrimPrefix(method, "/")
+}
+
+// tokenBucket - бакет на limit запросов за period с равномерным пополнением
+type tokenBucket struct {
+	mu           sync.Mutex
+	capacity     float64
+	tokens       float64
+	rate         float64
+	last         time.Time
+	blockedUntil time.Time
+}
+
+func newTokenBucket(limit int, period time.Duration) *tokenBucket {
+	return &tokenBucket{
+		capacity: float64(limit),
+		tokens:   float64(limit),
+		rate:     float64(limit) / period.Seconds(),
+		last:     time.Now(),
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+}
+
+// wait - блокирует до получения токена или завершения контекста
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.refill(now)
+		var delay time.Duration
+		switch {
+		case now.Before(b.blockedUntil):
+			delay = b.blockedUntil.Sub(now)
+		case b.tokens >= 1:
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		default:
+			delay = time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		}
+		b.mu.Unlock()
+
+		if err := sleepCtx(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// sleepCtx - ожидание d или завершения контекста
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// update - корректировка бакета по остатку запросов и времени до сброса лимита (в секундах),
+// отрицательные значения игнорируются
+func (b *tokenBucket) update(remaining, reset int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	b.refill(now)
+	if remaining >= 0 && float64(remaining) < b.tokens {
+		b.tokens = float64(remaining)
+	}
+	if remaining == 0 && reset > 0 {
+		b.blockedUntil = now.Add(time.Duration(reset) * time.Second)
+		b.tokens = 0
+	}
+}
@@ -0,0 +1,264 @@
+package investgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestTokenBucketWait(t *testing.T) {
+	tests := []struct {
+		name   string
+		limit  int
+		period time.Duration
+		calls  int
+		// min, max - ожидаемое время всех вызовов wait
+		min, max time.Duration
+	}{
+		{name: "within limit", limit: 3, period: 300 * time.Millisecond, calls: 3, max: 50 * time.Millisecond},
+		{name: "one over limit", limit: 2, period: 200 * time.Millisecond, calls: 3, min: 80 * time.Millisecond, max: 300 * time.Millisecond},
+		{name: "two over limit", limit: 2, period: 200 * time.Millisecond, calls: 4, min: 180 * time.Millisecond, max: 500 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			b := newTokenBucket(tt.limit, tt.period)
+			start := time.Now()
+			for i := 0; i < tt.calls; i++ {
+				if err := b.wait(ctx); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if elapsed := time.Since(start); elapsed < tt.min || elapsed > tt.max {
+				t.Errorf("%v calls took %v, want [%v, %v]", tt.calls, elapsed, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestTokenBucketWaitCanceled(t *testing.T) {
+	b := newTokenBucket(1, time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := b.wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("wait on empty bucket: %v, want deadline exceeded", err)
+	}
+}
+
+func TestTokenBucketRefill(t *testing.T) {
+	tests := []struct {
+		name    string
+		tokens  float64
+		elapsed time.Duration
+		want    float64
+	}{
+		{name: "empty, no time passed", tokens: 0, elapsed: 0, want: 0},
+		{name: "empty, half period", tokens: 0, elapsed: 30 * time.Second, want: 30},
+		{name: "partial", tokens: 10, elapsed: 10 * time.Second, want: 20},
+		{name: "capped by capacity", tokens: 50, elapsed: time.Minute, want: 60},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTokenBucket(60, time.Minute)
+			now := time.Now()
+			b.tokens, b.last = tt.tokens, now.Add(-tt.elapsed)
+			b.refill(now)
+			if b.tokens != tt.want {
+				t.Errorf("tokens %v, want %v", b.tokens, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenBucketUpdate(t *testing.T) {
+	tests := []struct {
+		name      string
+		tokens    float64
+		remaining int
+		reset     int
+		want      float64
+		blocked   time.Duration
+	}{
+		{name: "no headers", tokens: 10, remaining: -1, reset: -1, want: 10},
+		{name: "server has fewer", tokens: 10, remaining: 4, reset: 30, want: 4},
+		{name: "server has more", tokens: 10, remaining: 50, reset: 30, want: 10},
+		{name: "exhausted", tokens: 10, remaining: 0, reset: 2, want: 0, blocked: 2 * time.Second},
+		{name: "exhausted without reset", tokens: 10, remaining: 0, reset: -1, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTokenBucket(60, time.Hour)
+			b.tokens = tt.tokens
+			now := time.Now()
+			b.update(tt.remaining, tt.reset)
+			if b.tokens < tt.want || b.tokens > tt.want+0.01 {
+				t.Errorf("tokens %v, want %v", b.tokens, tt.want)
+			}
+			if tt.blocked == 0 {
+				if !b.blockedUntil.IsZero() {
+					t.Errorf("blocked until %v", b.blockedUntil)
+				}
+				return
+			}
+			if d := b.blockedUntil.Sub(now); d < tt.blocked || d > tt.blocked+time.Second {
+				t.Errorf("blocked for %v, want %v", d, tt.blocked)
+			}
+		})
+	}
+}
+
+func TestRateLimiterSetLimits(t *testing.T) {
+	r := newRateLimiter(nopLogger{})
+	r.setLimits([]*pb.UnaryLimit{
+		{LimitPerMinute: 300, Methods: []string{
+			"tinkoff.public.invest.api.contract.v1.MarketDataService/GetCandles",
+			"/tinkoff.public.invest.api.contract.v1.MarketDataService/GetLastPrices",
+		}},
+		{LimitPerMinute: 100, Methods: []string{"tinkoff.public.invest.api.contract.v1.OrdersService/PostOrder"}},
+		{LimitPerMinute: 0, Methods: []string{"tinkoff.public.invest.api.contract.v1.UsersService/GetInfo"}},
+	})
+
+	tests := []struct {
+		method string
+		limit  float64
+	}{
+		{method: "/tinkoff.public.invest.api.contract.v1.MarketDataService/GetCandles", limit: 300},
+		{method: "tinkoff.public.invest.api.contract.v1.MarketDataService/GetLastPrices", limit: 300},
+		{method: "/tinkoff.public.invest.api.contract.v1.OrdersService/PostOrder", limit: 100},
+		{method: "/tinkoff.public.invest.api.contract.v1.UsersService/GetInfo"},
+		{method: "/tinkoff.public.invest.api.contract.v1.UsersService/GetAccounts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			b := r.bucket(tt.method)
+			if tt.limit == 0 {
+				if b != nil || r.limits(tt.method) {
+					t.Errorf("bucket for method without limit")
+				}
+				return
+			}
+			if b == nil || b.capacity != tt.limit {
+				t.Fatalf("bucket %+v, want limit %v", b, tt.limit)
+			}
+		})
+	}
+	// методы одной группы расходуют общий бакет
+	candles := r.bucket("/tinkoff.public.invest.api.contract.v1.MarketDataService/GetCandles")
+	if candles != r.bucket("/tinkoff.public.invest.api.contract.v1.MarketDataService/GetLastPrices") {
+		t.Error("methods of one limit group have different buckets")
+	}
+
+	// новый тариф полностью заменяет лимиты
+	r.setLimits([]*pb.UnaryLimit{
+		{LimitPerMinute: 50, Methods: []string{"tinkoff.public.invest.api.contract.v1.OrdersService/PostOrder"}},
+	})
+	if r.limits("/tinkoff.public.invest.api.contract.v1.MarketDataService/GetCandles") {
+		t.Error("limit from the previous tariff is kept")
+	}
+	if b := r.bucket("/tinkoff.public.invest.api.contract.v1.OrdersService/PostOrder"); b == nil || b.capacity != 50 {
+		t.Errorf("PostOrder bucket %+v, want limit 50", b)
+	}
+}
+
+// tariffClient - UsersServiceClient, отвечающий на GetUserTariff
+type tariffClient struct {
+	pb.UsersServiceClient
+	resp     *pb.GetUserTariffResponse
+	err      error
+	deadline time.Time
+}
+
+func (c *tariffClient) GetUserTariff(ctx context.Context, _ *pb.GetUserTariffRequest, _ ...grpc.CallOption) (*pb.GetUserTariffResponse, error) {
+	c.deadline, _ = ctx.Deadline()
+	return c.resp, c.err
+}
+
+func TestRateLimiterLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  *tariffClient
+		limited bool
+	}{
+		{
+			name: "tariff",
+			client: &tariffClient{resp: &pb.GetUserTariffResponse{UnaryLimits: []*pb.UnaryLimit{
+				{LimitPerMinute: 10, Methods: []string{"tinkoff.public.invest.api.contract.v1.UsersService/GetInfo"}},
+			}}},
+			limited: true,
+		},
+		{
+			name:   "error keeps limiter inactive",
+			client: &tariffClient{err: status.Error(codes.DeadlineExceeded, "timeout")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRateLimiter(nopLogger{})
+			err := r.load(context.Background(), tt.client)
+			if (err == nil) != (tt.client.err == nil) {
+				t.Fatalf("load error %v, want %v", err, tt.client.err)
+			}
+			if d := time.Until(tt.client.deadline); d <= 0 || d > tariffTimeout {
+				t.Errorf("tariff request deadline in %v, want at most %v", d, tariffTimeout)
+			}
+			if got := r.limits("/tinkoff.public.invest.api.contract.v1.UsersService/GetInfo"); got != tt.limited {
+				t.Errorf("limits = %v, want %v", got, tt.limited)
+			}
+		})
+	}
+}
+
+func TestRateLimiterInterceptorHeaders(t *testing.T) {
+	const method = "/tinkoff.public.invest.api.contract.v1.UsersService/GetInfo"
+	tests := []struct {
+		name    string
+		header  metadata.MD
+		trailer metadata.MD
+		err     error
+		want    float64
+	}{
+		{name: "remaining in header", header: metadata.Pairs("x-ratelimit-remaining", "3", "x-ratelimit-reset", "20"), want: 3},
+		{name: "no headers", want: 9},
+		{
+			name:    "remaining in trailer on error",
+			trailer: metadata.Pairs("x-ratelimit-remaining", "0", "x-ratelimit-reset", "20"),
+			err:     status.Error(codes.ResourceExhausted, "80002"),
+			want:    0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRateLimiter(nopLogger{})
+			r.setLimits([]*pb.UnaryLimit{{LimitPerMinute: 10, Methods: []string{method}}})
+			invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				for _, opt := range opts {
+					switch o := opt.(type) {
+					case grpc.HeaderCallOption:
+						*o.HeaderAddr = tt.header
+					case grpc.TrailerCallOption:
+						*o.TrailerAddr = tt.trailer
+					}
+				}
+				return tt.err
+			}
+			err := r.unaryInterceptor(context.Background(), method, nil, nil, nil, invoker)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("interceptor error %v, want %v", err, tt.err)
+			}
+			if b := r.bucket(method); b.tokens < tt.want || b.tokens > tt.want+0.01 {
+				t.Errorf("tokens %v, want %v", b.tokens, tt.want)
+			}
+		})
+	}
+}
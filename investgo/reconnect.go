@@ -0,0 +1,82 @@
+package investgo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ReconnectPolicy - настройки переподключения стрима
+type ReconnectPolicy struct {
+	// Disabled - отключение переподключения, при обрыве соединения Listen возвращает ошибку
+	Disabled bool
+	// MaxAttempts - максимальное количество попыток подряд, 0 - без ограничений
+	MaxAttempts int
+	// InitialBackoff - задержка перед первой попыткой
+	InitialBackoff time.Duration
+	// MaxBackoff - максимальная задержка между попытками
+	MaxBackoff time.Duration
+	// BackoffMultiplier - множитель задержки для следующей попытки
+	BackoffMultiplier float64
+	// OnReconnect - вызывается после каждой попытки переподключения, err == nil если попытка успешна
+	OnReconnect func(attempt int, err error)
+}
+
+// DefaultReconnectPolicy - политика переподключения по умолчанию
+func DefaultReconnectPolicy() ReconnectPolicy {
+	return ReconnectPolicy{
+		MaxAttempts:       0,
+		InitialBackoff:    time.Second,
+		MaxBackoff:        30 * time.Second,
+		BackoffMultiplier: 2,
+	}
+}
+
+// backoff - задержка перед попыткой с номером attempt, нумерация с 1
+func (p ReconnectPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		if p.BackoffMultiplier > 1 {
+			d = time.Duration(float64(d) * p.BackoffMultiplier)
+		}
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return d
+}
+
+func (p ReconnectPolicy) notify(attempt int, err error) {
+	if p.OnReconnect != nil {
+		p.OnReconnect(attempt, err)
+	}
+}
+
+// retry - переоткрытие стрима функцией reopen с задержками политики до успеха, отмены ctx или исчерпания
+// попыток. cause - ошибка, вызвавшая переподключение, name - название стрима для логов
+func (p ReconnectPolicy) retry(ctx context.Context, l Logger, name string, cause error, reopen func() error) error {
+	if p.Disabled {
+		return cause
+	}
+	for attempt := 1; p.MaxAttempts == 0 || attempt <= p.MaxAttempts; attempt++ {
+		timer := time.NewTimer(p.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		err := reopen()
+		p.notify(attempt, err)
+		if err == nil {
+			l.Infof("%v reconnected, attempt %v", name, attempt)
+			return nil
+		}
+		l.Errorf("%v reconnect attempt %v failed: %v", name, attempt, err.Error())
+	}
+	return fmt.Errorf("%v reconnect failed after %v attempts: %w", strings.ToLower(name), p.MaxAttempts, cause)
+}
@@ -0,0 +1,274 @@
+package investgo
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// RecordFormat - формат файла записи стримов
+type RecordFormat int
+
+const (
+	// RecordProtobuf - записи подряд: время получения в наносекундах, тип стрима и длина сообщения
+	// в виде varint, затем сообщение в protobuf
+	RecordProtobuf RecordFormat = iota
+	// RecordJSON - одна запись в строке: {"time": ..., "stream": ..., "payload": {...}}
+	RecordJSON
+)
+
+// RecordedStream - тип стрима, из которого получено записанное сообщение
+type RecordedStream int
+
+const (
+	// RecordedMarketData - pb.MarketDataResponse из MDStream или ServerSideStream
+	RecordedMarketData RecordedStream = iota + 1
+	// RecordedPortfolio - pb.PortfolioStreamResponse из PortfolioStream
+	RecordedPortfolio
+	// RecordedPositions - pb.PositionsStreamResponse из PositionsStream
+	RecordedPositions
+	// RecordedTrades - pb.TradesStreamResponse из TradesStream
+	RecordedTrades
+)
+
+var recordedStreamNames = map[RecordedStream]string{
+	RecordedMarketData: "market_data",
+	RecordedPortfolio:  "portfolio",
+	RecordedPositions:  "positions",
+	RecordedTrades:     "trades",
+}
+
+func (s RecordedStream) String() string {
+	if name, ok := recordedStreamNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("RecordedStream(%d)", int(s))
+}
+
+// newMessage - пустое сообщение стрима s для чтения записи
+func (s RecordedStream) newMessage() (proto.Message, error) {
+	switch s {
+	case RecordedMarketData:
+		return &pb.MarketDataResponse{}, nil
+	case RecordedPortfolio:
+		return &pb.PortfolioStreamResponse{}, nil
+	case RecordedPositions:
+		return &pb.PositionsStreamResponse{}, nil
+	case RecordedTrades:
+		return &pb.TradesStreamResponse{}, nil
+	default:
+		return nil, fmt.Errorf("unexpected recorded stream %v", s)
+	}
+}
+
+// Record - записанное сообщение стрима
+type Record struct {
+	// Time - время получения сообщения
+	Time    time.Time
+	Stream  RecordedStream
+	Message proto.Message
+}
+
+// jsonRecord - запись в формате RecordJSON
+type jsonRecord struct {
+	Time    time.Time       `json:"time"`
+	Stream  string          `json:"stream"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Recorder - запись всех сообщений стримов, включая Ping и ответы на подписки, с временем получения.
+// Подключается к стримам методом SetRecorder, один Recorder может использоваться несколькими стримами
+type Recorder struct {
+	mu     sync.Mutex
+	w      *bufio.Writer
+	closer io.Closer
+	format RecordFormat
+	err    error
+}
+
+// NewRecorder - запись сообщений стримов в w в формате format
+func NewRecorder(w io.Writer, format RecordFormat) *Recorder {
+	return &Recorder{
+		w:      bufio.NewWriter(w),
+		format: format,
+	}
+}
+
+// CreateRecorder - запись сообщений стримов в файл path, файл закрывается в Close
+func CreateRecorder(path string, format RecordFormat) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	r := NewRecorder(f, format)
+	r.closer = f
+	return r, nil
+}
+
+// Err - первая ошибка записи, после ошибки сообщения не записываются
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Close - запись буфера и закрытие файла, созданного CreateRecorder. Стримы с этим Recorder
+// должны быть остановлены до вызова Close
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := r.w.Flush()
+	if r.closer != nil {
+		err = errors.Join(err, r.closer.Close())
+		r.closer = nil
+	}
+	if r.err == nil {
+		r.err = err
+	}
+	return err
+}
+
+// Write - запись сообщения msg стрима s, полученного в момент t
+func (r *Recorder) Write(t time.Time, s RecordedStream, msg proto.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	var err error
+	switch r.format {
+	case RecordJSON:
+		err = r.writeJSON(t, s, msg)
+	default:
+		err = r.writeProtobuf(t, s, msg)
+	}
+	r.err = err
+	return err
+}
+
+// record - запись сообщения, полученного из стрима сейчас. nil Recorder ничего не записывает
+func (r *Recorder) record(s RecordedStream, msg proto.Message) {
+	if r == nil {
+		return
+	}
+	_ = r.Write(time.Now(), s, msg)
+}
+
+func (r *Recorder) writeProtobuf(t time.Time, s RecordedStream, msg proto.Message) error {
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	header := make([]byte, 0, 3*binary.MaxVarintLen64)
+	header = binary.AppendUvarint(header, uint64(t.UnixNano()))
+	header = binary.AppendUvarint(header, uint64(s))
+	header = binary.AppendUvarint(header, uint64(len(payload)))
+	if _, err := r.w.Write(header); err != nil {
+		return err
+	}
+	_, err = r.w.Write(payload)
+	return err
+}
+
+func (r *Recorder) writeJSON(t time.Time, s RecordedStream, msg proto.Message) error {
+	payload, err := protojson.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(jsonRecord{Time: t, Stream: s.String(), Payload: payload})
+	if err != nil {
+		return err
+	}
+	_, err = r.w.Write(append(line, '\n'))
+	return err
+}
+
+// RecordReader - чтение записей, сделанных Recorder, формат определяется автоматически
+type RecordReader struct {
+	r *bufio.Reader
+}
+
+// NewRecordReader - чтение записей из r
+func NewRecordReader(r io.Reader) *RecordReader {
+	return &RecordReader{r: bufio.NewReader(r)}
+}
+
+// Read - следующая запись, io.EOF - записи закончились
+func (rr *RecordReader) Read() (*Record, error) {
+	first, err := rr.r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	// первый байт записи в protobuf - начало varint времени, у которого всегда установлен старший бит
+	if first[0] == '{' {
+		return rr.readJSON()
+	}
+	return rr.readProtobuf()
+}
+
+func (rr *RecordReader) readProtobuf() (*Record, error) {
+	var header [3]uint64
+	for i := range header {
+		v, err := binary.ReadUvarint(rr.r)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		header[i] = v
+	}
+	s := RecordedStream(header[1])
+	msg, err := s.newMessage()
+	if err != nil {
+		return nil, err
+	}
+	payload := make([]byte, header[2])
+	if _, err := io.ReadFull(rr.r, payload); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if err := proto.Unmarshal(payload, msg); err != nil {
+		return nil, err
+	}
+	return &Record{Time: time.Unix(0, int64(header[0])), Stream: s, Message: msg}, nil
+}
+
+func (rr *RecordReader) readJSON() (*Record, error) {
+	line, err := rr.r.ReadBytes('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && len(line) > 0) {
+		return nil, err
+	}
+	var jr jsonRecord
+	if err := json.Unmarshal(line, &jr); err != nil {
+		return nil, err
+	}
+	var s RecordedStream
+	for stream, name := range recordedStreamNames {
+		if name == jr.Stream {
+			s = stream
+		}
+	}
+	msg, err := s.newMessage()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected recorded stream %q", jr.Stream)
+	}
+	if err := protojson.Unmarshal(jr.Payload, msg); err != nil {
+		return nil, err
+	}
+	return &Record{Time: jr.Time, Stream: s, Message: msg}, nil
+}
+
+// unexpectedEOF - конец файла внутри записи
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,158 @@
+package investgo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func testRecords() []Record {
+	ts := time.Date(2023, 3, 1, 10, 0, 0, 123456789, time.UTC)
+	return []Record{
+		{Time: ts, Stream: RecordedMarketData, Message: &pb.MarketDataResponse{
+			Payload: &pb.MarketDataResponse_Candle{Candle: &pb.Candle{Figi: "figi", Close: pb.NewQuotation(10, 500000000)}},
+		}},
+		{Time: ts.Add(time.Millisecond), Stream: RecordedPortfolio, Message: &pb.PortfolioStreamResponse{
+			Payload: &pb.PortfolioStreamResponse_Portfolio{Portfolio: &pb.PortfolioResponse{AccountId: "account"}},
+		}},
+		{Time: ts.Add(2 * time.Millisecond), Stream: RecordedMarketData, Message: &pb.MarketDataResponse{
+			Payload: &pb.MarketDataResponse_Ping{Ping: &pb.Ping{Time: timestamppb.New(ts)}},
+		}},
+		{Time: ts.Add(3 * time.Millisecond), Stream: RecordedPositions, Message: &pb.PositionsStreamResponse{
+			Payload: &pb.PositionsStreamResponse_Position{Position: &pb.PositionData{AccountId: "account"}},
+		}},
+		{Time: ts.Add(4 * time.Millisecond), Stream: RecordedTrades, Message: &pb.TradesStreamResponse{
+			Payload: &pb.TradesStreamResponse_OrderTrades{OrderTrades: &pb.OrderTrades{OrderId: "order"}},
+		}},
+		{Time: ts.Add(5 * time.Millisecond), Stream: RecordedMarketData, Message: &pb.MarketDataResponse{
+			Payload: &pb.MarketDataResponse_Trade{Trade: &pb.Trade{Figi: "figi", Quantity: 3}},
+		}},
+	}
+}
+
+// writeTestRecords - запись testRecords в формате format
+func writeTestRecords(t *testing.T, format RecordFormat) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf, format)
+	for _, r := range testRecords() {
+		if err := rec.Write(r.Time, r.Stream, r.Message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestRecorderRoundTrip(t *testing.T) {
+	for _, format := range []RecordFormat{RecordProtobuf, RecordJSON} {
+		buf := writeTestRecords(t, format)
+		reader := NewRecordReader(buf)
+		for i, want := range testRecords() {
+			got, err := reader.Read()
+			if err != nil {
+				t.Fatalf("format %v, record %v: %v", format, i, err)
+			}
+			if !got.Time.Equal(want.Time) || got.Stream != want.Stream || !proto.Equal(got.Message, want.Message) {
+				t.Errorf("format %v, record %v: got %v %v %v, want %v %v %v", format, i,
+					got.Time, got.Stream, got.Message, want.Time, want.Stream, want.Message)
+			}
+		}
+		if _, err := reader.Read(); !errors.Is(err, io.EOF) {
+			t.Errorf("format %v: error %v after the last record, want io.EOF", format, err)
+		}
+	}
+}
+
+func TestRecordReaderTruncated(t *testing.T) {
+	buf := writeTestRecords(t, RecordProtobuf)
+	data := buf.Bytes()
+	reader := NewRecordReader(bytes.NewReader(data[:len(data)-1]))
+	var err error
+	for err == nil {
+		_, err = reader.Read()
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("error %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestReplayerStreamsFilter(t *testing.T) {
+	buf := writeTestRecords(t, RecordJSON)
+	// читаются только каналы маркетдаты, записи портфеля, позиций и сделок по счету пропускаются
+	r := NewReplayer(buf, ReplayOptions{Streams: []RecordedStream{RecordedMarketData}})
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Listen()
+	}()
+
+	timeout := time.After(10 * time.Second)
+	candles, trades := r.Candles(), r.Trades()
+	var got []string
+	for candles != nil || trades != nil {
+		select {
+		case c, ok := <-candles:
+			if !ok {
+				candles = nil
+				continue
+			}
+			got = append(got, "candle "+c.GetFigi())
+		case tr, ok := <-trades:
+			if !ok {
+				trades = nil
+				continue
+			}
+			got = append(got, "trade "+tr.GetFigi())
+		case <-timeout:
+			t.Fatal("replay is blocked")
+		}
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != "candle figi" || got[1] != "trade figi" {
+		t.Errorf("replayed %v, want candle and trade", got)
+	}
+	if _, ok := <-r.Portfolios(); ok {
+		t.Error("portfolio is replayed with market data filter")
+	}
+}
+
+func TestReplayerSpeed(t *testing.T) {
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf, RecordProtobuf)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		msg := &pb.MarketDataResponse{Payload: &pb.MarketDataResponse_LastPrice{LastPrice: &pb.LastPrice{Figi: "figi"}}}
+		if err := rec.Write(start.Add(time.Duration(i)*time.Second), RecordedMarketData, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 2 секунды записи воспроизводятся в 20 раз быстрее
+	r := NewReplayer(&buf, ReplayOptions{Speed: 20})
+	go r.Listen()
+	begin := time.Now()
+	n := 0
+	for range r.LastPrices() {
+		n++
+	}
+	elapsed := time.Since(begin)
+	if n != 3 {
+		t.Errorf("%v last prices, want 3", n)
+	}
+	if elapsed < 100*time.Millisecond || elapsed > time.Second {
+		t.Errorf("replay took %v, want about 100ms", elapsed)
+	}
+}
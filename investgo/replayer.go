@@ -0,0 +1,225 @@
+package investgo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+// ReplayOptions - параметры воспроизведения записи
+type ReplayOptions struct {
+	// Speed - скорость воспроизведения: 1 - с исходными интервалами между сообщениями, 10 - в 10 раз быстрее,
+	// 0 - без задержек
+	Speed float64
+	// Streams - воспроизводимые стримы, записи остальных стримов пропускаются и не влияют на задержки.
+	// nil - все стримы записи
+	Streams []RecordedStream
+}
+
+// replays - воспроизводится ли стрим s
+func (o ReplayOptions) replays(s RecordedStream) bool {
+	if len(o.Streams) == 0 {
+		return true
+	}
+	for _, stream := range o.Streams {
+		if stream == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Replayer - воспроизведение записи Recorder через такие же каналы, как у MDStream, PortfolioStream,
+// PositionsStream и TradesStream. Читать нужно каналы всех воспроизводимых стримов, которые есть в записи,
+// для чтения части стримов их нужно перечислить в ReplayOptions.Streams
+type Replayer struct {
+	reader *RecordReader
+	closer io.Closer
+	opts   ReplayOptions
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	once   sync.Once
+
+	candle        chan *pb.Candle
+	trade         chan *pb.Trade
+	orderBook     chan *pb.OrderBook
+	lastPrice     chan *pb.LastPrice
+	tradingStatus chan *pb.TradingStatus
+	portfolios    chan *pb.PortfolioResponse
+	positions     chan *pb.PositionData
+	orderTrades   chan *pb.OrderTrades
+}
+
+// NewReplayer - воспроизведение записи из r
+func NewReplayer(r io.Reader, opts ReplayOptions) *Replayer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Replayer{
+		reader:        NewRecordReader(r),
+		opts:          opts,
+		ctx:           ctx,
+		cancel:        cancel,
+		candle:        make(chan *pb.Candle, 1),
+		trade:         make(chan *pb.Trade, 1),
+		orderBook:     make(chan *pb.OrderBook, 1),
+		lastPrice:     make(chan *pb.LastPrice, 1),
+		tradingStatus: make(chan *pb.TradingStatus, 1),
+		portfolios:    make(chan *pb.PortfolioResponse, 1),
+		positions:     make(chan *pb.PositionData, 1),
+		orderTrades:   make(chan *pb.OrderTrades, 1),
+	}
+}
+
+// OpenReplayer - воспроизведение записи из файла path, файл закрывается после завершения Listen
+func OpenReplayer(path string, opts ReplayOptions) (*Replayer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r := NewReplayer(f, opts)
+	r.closer = f
+	return r, nil
+}
+
+// Candles - канал свечей из записи
+func (r *Replayer) Candles() <-chan *pb.Candle {
+	return r.candle
+}
+
+// OrderBooks - канал стаканов из записи
+func (r *Replayer) OrderBooks() <-chan *pb.OrderBook {
+	return r.orderBook
+}
+
+// Trades - канал обезличенных сделок из записи
+func (r *Replayer) Trades() <-chan *pb.Trade {
+	return r.trade
+}
+
+// TradingStatuses - канал торговых статусов из записи
+func (r *Replayer) TradingStatuses() <-chan *pb.TradingStatus {
+	return r.tradingStatus
+}
+
+// LastPrices - канал последних цен из записи
+func (r *Replayer) LastPrices() <-chan *pb.LastPrice {
+	return r.lastPrice
+}
+
+// Portfolios - канал обновлений портфеля из записи
+func (r *Replayer) Portfolios() <-chan *pb.PortfolioResponse {
+	return r.portfolios
+}
+
+// Positions - канал изменений позиций из записи
+func (r *Replayer) Positions() <-chan *pb.PositionData {
+	return r.positions
+}
+
+// OrderTrades - канал сделок по заявкам из записи
+func (r *Replayer) OrderTrades() <-chan *pb.OrderTrades {
+	return r.orderTrades
+}
+
+// Listen - воспроизведение записи до ее окончания или вызова Stop, после чего каналы закрываются
+func (r *Replayer) Listen() error {
+	defer r.shutdown()
+	var start, first time.Time
+	for {
+		rec, err := r.reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !r.opts.replays(rec.Stream) {
+			continue
+		}
+		if first.IsZero() {
+			start, first = time.Now(), rec.Time
+		}
+		if r.opts.Speed > 0 {
+			delay := time.Until(start.Add(time.Duration(float64(rec.Time.Sub(first)) / r.opts.Speed)))
+			if delay > 0 {
+				timer := time.NewTimer(delay)
+				select {
+				case <-r.ctx.Done():
+					timer.Stop()
+					return nil
+				case <-timer.C:
+				}
+			}
+		}
+		if r.ctx.Err() != nil {
+			return nil
+		}
+		r.send(rec)
+	}
+}
+
+// Stop - завершение воспроизведения
+func (r *Replayer) Stop() {
+	r.cancel()
+}
+
+// send - отправка данных записи в канал, Ping и ответы на подписки пропускаются
+func (r *Replayer) send(rec *Record) {
+	switch msg := rec.Message.(type) {
+	case *pb.MarketDataResponse:
+		switch msg.GetPayload().(type) {
+		case *pb.MarketDataResponse_Candle:
+			sendCtx(r.ctx, r.candle, msg.GetCandle())
+		case *pb.MarketDataResponse_Orderbook:
+			sendCtx(r.ctx, r.orderBook, msg.GetOrderbook())
+		case *pb.MarketDataResponse_Trade:
+			sendCtx(r.ctx, r.trade, msg.GetTrade())
+		case *pb.MarketDataResponse_LastPrice:
+			sendCtx(r.ctx, r.lastPrice, msg.GetLastPrice())
+		case *pb.MarketDataResponse_TradingStatus:
+			sendCtx(r.ctx, r.tradingStatus, msg.GetTradingStatus())
+		}
+	case *pb.PortfolioStreamResponse:
+		if p := msg.GetPortfolio(); p != nil {
+			sendCtx(r.ctx, r.portfolios, p)
+		}
+	case *pb.PositionsStreamResponse:
+		if p := msg.GetPosition(); p != nil {
+			sendCtx(r.ctx, r.positions, p)
+		}
+	case *pb.TradesStreamResponse:
+		if t := msg.GetOrderTrades(); t != nil {
+			sendCtx(r.ctx, r.orderTrades, t)
+		}
+	}
+}
+
+func (r *Replayer) shutdown() {
+	r.once.Do(func() {
+		r.cancel()
+		if r.closer != nil {
+			r.closer.Close()
+		}
+		close(r.candle)
+		close(r.trade)
+		close(r.orderBook)
+		close(r.lastPrice)
+		close(r.tradingStatus)
+		close(r.portfolios)
+		close(r.positions)
+		close(r.orderTrades)
+	})
+}
+
+// sendCtx - отправка v в ch до завершения ctx
+func sendCtx[T any](ctx context.Context, ch chan<- T, v T) {
+	select {
+	case ch <- v:
+	case <-ctx.Done():
+	}
+}
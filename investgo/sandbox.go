@@ -18,10 +18,16 @@ type SandboxServiceClient struct {
 
 // OpenSandboxAccount - Метод регистрации счёта в песочнице
 func (s *SandboxServiceClient) OpenSandboxAccount() (*OpenSandboxAccountResponse, error) {
+	return s.OpenSandboxAccountCtx(s.ctx)
+}
+
+// OpenSandboxAccountCtx - OpenSandboxAccount с контекстом запроса
+func (s *SandboxServiceClient) OpenSandboxAccountCtx(ctx context.Context) (*OpenSandboxAccountResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := s.pbClient.OpenSandboxAccount(s.ctx, &pb.OpenSandboxAccountRequest{}, grpc.Header(&header), grpc.Trailer(&trailer))
+	resp, err := s.pbClient.OpenSandboxAccount(ctx, &pb.OpenSandboxAccountRequest{}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &OpenSandboxAccountResponse{
 		OpenSandboxAccountResponse: resp,
@@ -31,10 +37,16 @@ func (s *SandboxServiceClient) OpenSandboxAccount() (*OpenSandboxAccountResponse
 
 // GetSandboxAccounts - Метод получения счетов в песочнице
 func (s *SandboxServiceClient) GetSandboxAccounts() (*GetAccountsResponse, error) {
+	return s.GetSandboxAccountsCtx(s.ctx)
+}
+
+// GetSandboxAccountsCtx - GetSandboxAccounts с контекстом запроса
+func (s *SandboxServiceClient) GetSandboxAccountsCtx(ctx context.Context) (*GetAccountsResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := s.pbClient.GetSandboxAccounts(s.ctx, &pb.GetAccountsRequest{}, grpc.Header(&header), grpc.Trailer(&trailer))
+	resp, err := s.pbClient.GetSandboxAccounts(ctx, &pb.GetAccountsRequest{}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetAccountsResponse{
 		GetAccountsResponse: resp,
@@ -44,12 +56,18 @@ func (s *SandboxServiceClient) GetSandboxAccounts() (*GetAccountsResponse, error
 
 // CloseSandboxAccount - Метод закрытия счёта в песочнице
 func (s *SandboxServiceClient) CloseSandboxAccount(accountId string) (*CloseSandboxAccountResponse, error) {
+	return s.CloseSandboxAccountCtx(s.ctx, accountId)
+}
+
+// CloseSandboxAccountCtx - CloseSandboxAccount с контекстом запроса
+func (s *SandboxServiceClient) CloseSandboxAccountCtx(ctx context.Context, accountId string) (*CloseSandboxAccountResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := s.pbClient.CloseSandboxAccount(s.ctx, &pb.CloseSandboxAccountRequest{
+	resp, err := s.pbClient.CloseSandboxAccount(ctx, &pb.CloseSandboxAccountRequest{
 		AccountId: accountId,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &CloseSandboxAccountResponse{
 		CloseSandboxAccountResponse: resp,
@@ -59,8 +77,13 @@ func (s *SandboxServiceClient) CloseSandboxAccount(accountId string) (*CloseSand
 
 // PostSandboxOrder - Метод выставления торгового поручения в песочнице
 func (s *SandboxServiceClient) PostSandboxOrder(req *PostOrderRequest) (*PostOrderResponse, error) {
+	return s.PostSandboxOrderCtx(s.ctx, req)
+}
+
+// PostSandboxOrderCtx - PostSandboxOrder с контекстом запроса
+func (s *SandboxServiceClient) PostSandboxOrderCtx(ctx context.Context, req *PostOrderRequest) (*PostOrderResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := s.pbClient.PostSandboxOrder(s.ctx, &pb.PostOrderRequest{
+	resp, err := s.pbClient.PostSandboxOrder(ctx, &pb.PostOrderRequest{
 		Quantity:     req.Quantity,
 		Price:        req.Price,
 		Direction:    req.Direction,
@@ -71,6 +94,7 @@ func (s *SandboxServiceClient) PostSandboxOrder(req *PostOrderRequest) (*PostOrd
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &PostOrderResponse{
 		PostOrderResponse: resp,
@@ -80,8 +104,13 @@ func (s *SandboxServiceClient) PostSandboxOrder(req *PostOrderRequest) (*PostOrd
 
 // ReplaceSandboxOrder - Метод изменения выставленной заявки
 func (s *SandboxServiceClient) ReplaceSandboxOrder(req *ReplaceOrderRequest) (*PostOrderResponse, error) {
+	return s.ReplaceSandboxOrderCtx(s.ctx, req)
+}
+
+// ReplaceSandboxOrderCtx - ReplaceSandboxOrder с контекстом запроса
+func (s *SandboxServiceClient) ReplaceSandboxOrderCtx(ctx context.Context, req *ReplaceOrderRequest) (*PostOrderResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := s.pbClient.ReplaceSandboxOrder(s.ctx, &pb.ReplaceOrderRequest{
+	resp, err := s.pbClient.ReplaceSandboxOrder(ctx, &pb.ReplaceOrderRequest{
 		AccountId:      req.AccountId,
 		OrderId:        req.OrderId,
 		IdempotencyKey: req.NewOrderId,
@@ -91,6 +120,7 @@ func (s *SandboxServiceClient) ReplaceSandboxOrder(req *ReplaceOrderRequest) (*P
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &PostOrderResponse{
 		PostOrderResponse: resp,
@@ -100,12 +130,18 @@ func (s *SandboxServiceClient) ReplaceSandboxOrder(req *ReplaceOrderRequest) (*P
 
 // GetSandboxOrders - Метод получения списка активных заявок по счёту в песочнице
 func (s *SandboxServiceClient) GetSandboxOrders(accountId string) (*GetOrdersResponse, error) {
+	return s.GetSandboxOrdersCtx(s.ctx, accountId)
+}
+
+// GetSandboxOrdersCtx - GetSandboxOrders с контекстом запроса
+func (s *SandboxServiceClient) GetSandboxOrdersCtx(ctx context.Context, accountId string) (*GetOrdersResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := s.pbClient.GetSandboxOrders(s.ctx, &pb.GetOrdersRequest{
+	resp, err := s.pbClient.GetSandboxOrders(ctx, &pb.GetOrdersRequest{
 		AccountId: accountId,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetOrdersResponse{
 		GetOrdersResponse: resp,
@@ -115,13 +151,19 @@ func (s *SandboxServiceClient) GetSandboxOrders(accountId string) (*GetOrdersRes
 
 // CancelSandboxOrder - Метод отмены торгового поручения в песочнице
 func (s *SandboxServiceClient) CancelSandboxOrder(accountId, orderId string) (*CancelOrderResponse, error) {
+	return s.CancelSandboxOrderCtx(s.ctx, accountId, orderId)
+}
+
+// CancelSandboxOrderCtx - CancelSandboxOrder с контекстом запроса
+func (s *SandboxServiceClient) CancelSandboxOrderCtx(ctx context.Context, accountId, orderId string) (*CancelOrderResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := s.pbClient.CancelSandboxOrder(s.ctx, &pb.CancelOrderRequest{
+	resp, err := s.pbClient.CancelSandboxOrder(ctx, &pb.CancelOrderRequest{
 		AccountId: accountId,
 		OrderId:   orderId,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &CancelOrderResponse{
 		CancelOrderResponse: resp,
@@ -131,13 +173,19 @@ func (s *SandboxServiceClient) CancelSandboxOrder(accountId, orderId string) (*C
 
 // GetSandboxOrderState - Метод получения статуса заявки в песочнице
 func (s *SandboxServiceClient) GetSandboxOrderState(accountId, orderId string) (*GetOrderStateResponse, error) {
+	return s.GetSandboxOrderStateCtx(s.ctx, accountId, orderId)
+}
+
+// GetSandboxOrderStateCtx - GetSandboxOrderState с контекстом запроса
+func (s *SandboxServiceClient) GetSandboxOrderStateCtx(ctx context.Context, accountId, orderId string) (*GetOrderStateResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := s.pbClient.GetSandboxOrderState(s.ctx, &pb.GetOrderStateRequest{
+	resp, err := s.pbClient.GetSandboxOrderState(ctx, &pb.GetOrderStateRequest{
 		AccountId: accountId,
 		OrderId:   orderId,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetOrderStateResponse{
 		OrderState: resp,
@@ -147,12 +195,18 @@ func (s *SandboxServiceClient) GetSandboxOrderState(accountId, orderId string) (
 
 // GetSandboxPositions - Метод получения позиций по виртуальному счёту песочницы
 func (s *SandboxServiceClient) GetSandboxPositions(accountId string) (*PositionsResponse, error) {
+	return s.GetSandboxPositionsCtx(s.ctx, accountId)
+}
+
+// GetSandboxPositionsCtx - GetSandboxPositions с контекстом запроса
+func (s *SandboxServiceClient) GetSandboxPositionsCtx(ctx context.Context, accountId string) (*PositionsResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := s.pbClient.GetSandboxPositions(s.ctx, &pb.PositionsRequest{
+	resp, err := s.pbClient.GetSandboxPositions(ctx, &pb.PositionsRequest{
 		AccountId: accountId,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &PositionsResponse{
 		PositionsResponse: resp,
@@ -162,8 +216,13 @@ func (s *SandboxServiceClient) GetSandboxPositions(accountId string) (*Positions
 
 // GetSandboxOperations - Метод получения операций в песочнице по номеру счёта
 func (s *SandboxServiceClient) GetSandboxOperations(req *GetOperationsRequest) (*OperationsResponse, error) {
+	return s.GetSandboxOperationsCtx(s.ctx, req)
+}
+
+// GetSandboxOperationsCtx - GetSandboxOperations с контекстом запроса
+func (s *SandboxServiceClient) GetSandboxOperationsCtx(ctx context.Context, req *GetOperationsRequest) (*OperationsResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := s.pbClient.GetSandboxOperations(s.ctx, &pb.OperationsRequest{
+	resp, err := s.pbClient.GetSandboxOperations(ctx, &pb.OperationsRequest{
 		AccountId: req.AccountId,
 		From:      TimeToTimestamp(req.From),
 		To:        TimeToTimestamp(req.To),
@@ -172,6 +231,7 @@ func (s *SandboxServiceClient) GetSandboxOperations(req *GetOperationsRequest) (
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &OperationsResponse{
 		OperationsResponse: resp,
@@ -181,8 +241,13 @@ func (s *SandboxServiceClient) GetSandboxOperations(req *GetOperationsRequest) (
 
 // GetSandboxOperationsByCursor - Метод получения операций в песочнице по номеру счета с пагинацией
 func (s *SandboxServiceClient) GetSandboxOperationsByCursor(req *GetOperationsByCursorRequest) (*GetOperationsByCursorResponse, error) {
+	return s.GetSandboxOperationsByCursorCtx(s.ctx, req)
+}
+
+// GetSandboxOperationsByCursorCtx - GetSandboxOperationsByCursor с контекстом запроса
+func (s *SandboxServiceClient) GetSandboxOperationsByCursorCtx(ctx context.Context, req *GetOperationsByCursorRequest) (*GetOperationsByCursorResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := s.pbClient.GetSandboxOperationsByCursor(s.ctx, &pb.GetOperationsByCursorRequest{
+	resp, err := s.pbClient.GetSandboxOperationsByCursor(ctx, &pb.GetOperationsByCursorRequest{
 		AccountId:          req.AccountId,
 		InstrumentId:       req.InstrumentId,
 		From:               TimeToTimestamp(req.From),
@@ -197,6 +262,7 @@ func (s *SandboxServiceClient) GetSandboxOperationsByCursor(req *GetOperationsBy
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetOperationsByCursorResponse{
 		GetOperationsByCursorResponse: resp,
@@ -206,13 +272,19 @@ func (s *SandboxServiceClient) GetSandboxOperationsByCursor(req *GetOperationsBy
 
 // GetSandboxPortfolio - Метод получения портфолио в песочнице
 func (s *SandboxServiceClient) GetSandboxPortfolio(accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*PortfolioResponse, error) {
+	return s.GetSandboxPortfolioCtx(s.ctx, accountId, currency)
+}
+
+// GetSandboxPortfolioCtx - GetSandboxPortfolio с контекстом запроса
+func (s *SandboxServiceClient) GetSandboxPortfolioCtx(ctx context.Context, accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*PortfolioResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := s.pbClient.GetSandboxPortfolio(s.ctx, &pb.PortfolioRequest{
+	resp, err := s.pbClient.GetSandboxPortfolio(ctx, &pb.PortfolioRequest{
 		AccountId: accountId,
 		Currency:  currency,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &PortfolioResponse{
 		PortfolioResponse: resp,
@@ -222,12 +294,18 @@ func (s *SandboxServiceClient) GetSandboxPortfolio(accountId string, currency pb
 
 // GetSandboxWithdrawLimits - Метод получения доступного остатка для вывода средств в песочнице
 func (s *SandboxServiceClient) GetSandboxWithdrawLimits(accountId string) (*WithdrawLimitsResponse, error) {
+	return s.GetSandboxWithdrawLimitsCtx(s.ctx, accountId)
+}
+
+// GetSandboxWithdrawLimitsCtx - GetSandboxWithdrawLimits с контекстом запроса
+func (s *SandboxServiceClient) GetSandboxWithdrawLimitsCtx(ctx context.Context, accountId string) (*WithdrawLimitsResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := s.pbClient.GetSandboxWithdrawLimits(s.ctx, &pb.WithdrawLimitsRequest{
+	resp, err := s.pbClient.GetSandboxWithdrawLimits(ctx, &pb.WithdrawLimitsRequest{
 		AccountId: accountId,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &WithdrawLimitsResponse{
 		WithdrawLimitsResponse: resp,
@@ -237,8 +315,13 @@ func (s *SandboxServiceClient) GetSandboxWithdrawLimits(accountId string) (*With
 
 // SandboxPayIn - Метод пополнения счёта в песочнице
 func (s *SandboxServiceClient) SandboxPayIn(req *SandboxPayInRequest) (*SandboxPayInResponse, error) {
+	return s.SandboxPayInCtx(s.ctx, req)
+}
+
+// SandboxPayInCtx - SandboxPayIn с контекстом запроса
+func (s *SandboxServiceClient) SandboxPayInCtx(ctx context.Context, req *SandboxPayInRequest) (*SandboxPayInResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := s.pbClient.SandboxPayIn(s.ctx, &pb.SandboxPayInRequest{
+	resp, err := s.pbClient.SandboxPayIn(ctx, &pb.SandboxPayInRequest{
 		AccountId: req.AccountId,
 		Amount: &pb.MoneyValue{
 			Currency: req.Currency,
@@ -248,6 +331,7 @@ func (s *SandboxServiceClient) SandboxPayIn(req *SandboxPayInRequest) (*SandboxP
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &SandboxPayInResponse{
 		SandboxPayInResponse: resp,
@@ -18,8 +18,13 @@ type StopOrdersServiceClient struct {
 
 // PostStopOrder - Метод выставления стоп-заявки
 func (s *StopOrdersServiceClient) PostStopOrder(req *PostStopOrderRequest) (*PostStopOrderResponse, error) {
+	return s.PostStopOrderCtx(s.ctx, req)
+}
+
+// PostStopOrderCtx - PostStopOrder с контекстом запроса
+func (s *StopOrdersServiceClient) PostStopOrderCtx(ctx context.Context, req *PostStopOrderRequest) (*PostStopOrderResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := s.pbClient.PostStopOrder(s.ctx, &pb.PostStopOrderRequest{
+	resp, err := s.pbClient.PostStopOrder(ctx, &pb.PostStopOrderRequest{
 		Quantity:       req.Quantity,
 		Price:          req.Price,
 		StopPrice:      req.StopPrice,
@@ -32,6 +37,7 @@ func (s *StopOrdersServiceClient) PostStopOrder(req *PostStopOrderRequest) (*Pos
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &PostStopOrderResponse{
 		PostStopOrderResponse: resp,
@@ -41,12 +47,18 @@ func (s *StopOrdersServiceClient) PostStopOrder(req *PostStopOrderRequest) (*Pos
 
 // GetStopOrders - Метод получения списка активных стоп заявок по счёту
 func (s *StopOrdersServiceClient) GetStopOrders(accountId string) (*GetStopOrdersResponse, error) {
+	return s.GetStopOrdersCtx(s.ctx, accountId)
+}
+
+// GetStopOrdersCtx - GetStopOrders с контекстом запроса
+func (s *StopOrdersServiceClient) GetStopOrdersCtx(ctx context.Context, accountId string) (*GetStopOrdersResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := s.pbClient.GetStopOrders(s.ctx, &pb.GetStopOrdersRequest{
+	resp, err := s.pbClient.GetStopOrders(ctx, &pb.GetStopOrdersRequest{
 		AccountId: accountId,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetStopOrdersResponse{
 		GetStopOrdersResponse: resp,
@@ -56,13 +68,19 @@ func (s *StopOrdersServiceClient) GetStopOrders(accountId string) (*GetStopOrder
 
 // CancelStopOrder - Метод отмены стоп-заявки
 func (s *StopOrdersServiceClient) CancelStopOrder(accountId, stopOrderId string) (*CancelStopOrderResponse, error) {
+	return s.CancelStopOrderCtx(s.ctx, accountId, stopOrderId)
+}
+
+// CancelStopOrderCtx - CancelStopOrder с контекстом запроса
+func (s *StopOrdersServiceClient) CancelStopOrderCtx(ctx context.Context, accountId, stopOrderId string) (*CancelStopOrderResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := s.pbClient.CancelStopOrder(s.ctx, &pb.CancelStopOrderRequest{
+	resp, err := s.pbClient.CancelStopOrder(ctx, &pb.CancelStopOrderRequest{
 		AccountId:   accountId,
 		StopOrderId: stopOrderId,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &CancelStopOrderResponse{
 		CancelStopOrderResponse: resp,
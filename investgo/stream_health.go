@@ -0,0 +1,156 @@
+package investgo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WatchdogOptions - параметры проверки активности стрима по сообщениям Ping
+type WatchdogOptions struct {
+	// StaleTimeout - время без Ping, после которого стрим считается зависшим, 0 - проверка выключена
+	StaleTimeout time.Duration
+	// ForceReconnect - переоткрытие зависшего стрима, подписки стрима маркетдаты восстанавливаются
+	ForceReconnect bool
+	// OnStale - вызывается при обнаружении зависшего стрима
+	OnStale func(health StreamHealth)
+}
+
+// StreamHealth - статистика активности стрима
+type StreamHealth struct {
+	// ConnectedAt - время открытия текущего grpc стрима
+	ConnectedAt time.Time
+	// LastPing - время получения последнего Ping, нулевое если Ping не приходил
+	LastPing time.Time
+	// LastData - время получения последнего сообщения с данными
+	LastData time.Time
+	Pings    uint64
+	Messages uint64
+	// Reconnects - количество переоткрытий стрима, в том числе принудительных
+	Reconnects uint64
+	// StaleEvents - количество обнаружений зависшего стрима
+	StaleEvents uint64
+	// Stale - с момента последнего Ping или открытия стрима прошло больше StaleTimeout
+	Stale bool
+}
+
+// streamWatchdog - статистика активности стрима и проверка поступления Ping
+type streamWatchdog struct {
+	mu     sync.Mutex
+	opts   WatchdogOptions
+	health StreamHealth
+	// cancel - отмена текущего grpc стрима для принудительного переподключения
+	cancel context.CancelFunc
+	// forced - текущий grpc стрим отменен для переподключения, а не остановки
+	forced bool
+}
+
+func newStreamWatchdog() *streamWatchdog {
+	return &streamWatchdog{}
+}
+
+// setOptions - установка параметров проверки, применяется при следующем вызове Listen
+func (w *streamWatchdog) setOptions(opts WatchdogOptions) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.opts = opts
+}
+
+// connected - открыт новый grpc стрим, cancel отменяет его
+func (w *streamWatchdog) connected(cancel context.CancelFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.health.ConnectedAt.IsZero() {
+		w.health.Reconnects++
+	}
+	w.health.ConnectedAt = time.Now()
+	w.health.Stale = false
+	w.cancel = cancel
+	w.forced = false
+}
+
+func (w *streamWatchdog) ping() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.health.LastPing = time.Now()
+	w.health.Pings++
+	w.health.Stale = false
+}
+
+func (w *streamWatchdog) data() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.health.LastData = time.Now()
+	w.health.Messages++
+}
+
+// takeForced - true, если текущий grpc стрим был отменен для принудительного переподключения
+func (w *streamWatchdog) takeForced() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	forced := w.forced
+	w.forced = false
+	return forced
+}
+
+func (w *streamWatchdog) stats() StreamHealth {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.health
+}
+
+// run - проверка поступления Ping до завершения ctx, name используется в логах
+func (w *streamWatchdog) run(ctx context.Context, l Logger, name string) {
+	w.mu.Lock()
+	opts := w.opts
+	w.mu.Unlock()
+	if opts.StaleTimeout <= 0 {
+		return
+	}
+	ticker := time.NewTicker(opts.StaleTimeout / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		health, stale := w.check(opts)
+		if !stale {
+			continue
+		}
+		l.Errorf("%v is stale: no ping for %v", name, opts.StaleTimeout)
+		if opts.OnStale != nil {
+			opts.OnStale(health)
+		}
+	}
+}
+
+// check - true, если стрим стал зависшим с прошлой проверки. При ForceReconnect отменяет текущий grpc стрим
+func (w *streamWatchdog) check(opts WatchdogOptions) (StreamHealth, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	last := w.health.LastPing
+	if w.health.ConnectedAt.After(last) {
+		last = w.health.ConnectedAt
+	}
+	if w.health.Stale || last.IsZero() || time.Since(last) <= opts.StaleTimeout {
+		return w.health, false
+	}
+	w.health.Stale = true
+	w.health.StaleEvents++
+	if opts.ForceReconnect && w.cancel != nil {
+		w.forced = true
+		w.cancel()
+	}
+	return w.health, true
+}
+
+// reconnectRequested - true, если ошибка Recv вызвана отменой текущего grpc стрима для принудительного
+// переподключения, а не остановкой стрима с контекстом ctx
+func (w *streamWatchdog) reconnectRequested(ctx context.Context, err error) bool {
+	return status.Code(err) == codes.Canceled && ctx.Err() == nil && w.takeForced()
+}
@@ -0,0 +1,131 @@
+package investgo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/therox/invest-api-go-sdk/investgo"
+	"github.com/therox/invest-api-go-sdk/investgo/investgotest"
+)
+
+// watchedStream - общие методы стримов операций и сделок с проверкой Ping
+type watchedStream interface {
+	SetWatchdog(opts investgo.WatchdogOptions)
+	SetReconnectPolicy(policy investgo.ReconnectPolicy)
+	Health() investgo.StreamHealth
+	Listen() error
+	Stop()
+}
+
+var watchedStreams = []struct {
+	method string
+	open   func(client *investgo.Client) (watchedStream, error)
+}{
+	{
+		method: investgotest.PortfolioStream,
+		open: func(client *investgo.Client) (watchedStream, error) {
+			return client.NewOperationsStreamClient().PortfolioStream([]string{"account"})
+		},
+	},
+	{
+		method: investgotest.PositionsStream,
+		open: func(client *investgo.Client) (watchedStream, error) {
+			return client.NewOperationsStreamClient().PositionsStream([]string{"account"})
+		},
+	},
+	{
+		method: investgotest.TradesStream,
+		open: func(client *investgo.Client) (watchedStream, error) {
+			return client.NewOrdersStreamClient().TradesStream([]string{"account"})
+		},
+	},
+}
+
+func TestStreamForceReconnectUsesPolicy(t *testing.T) {
+	const backoff = 100 * time.Millisecond
+	for _, tt := range watchedStreams {
+		t.Run(tt.method, func(t *testing.T) {
+			client, server := investgotest.NewClient(t)
+			stream, err := tt.open(client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer stream.Stop()
+
+			stale := make(chan time.Time, 10)
+			reconnected := make(chan time.Time, 10)
+			stream.SetWatchdog(investgo.WatchdogOptions{
+				StaleTimeout:   20 * time.Millisecond,
+				ForceReconnect: true,
+				OnStale: func(investgo.StreamHealth) {
+					stale <- time.Now()
+				},
+			})
+			policy := investgo.DefaultReconnectPolicy()
+			policy.InitialBackoff = backoff
+			policy.OnReconnect = func(attempt int, err error) {
+				if err == nil {
+					reconnected <- time.Now()
+				}
+			}
+			stream.SetReconnectPolicy(policy)
+			go stream.Listen()
+
+			ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+			defer cancel()
+			var staleAt, reconnectedAt time.Time
+			select {
+			case staleAt = <-stale:
+			case <-ctx.Done():
+				t.Fatal("stream is not stale")
+			}
+			select {
+			case reconnectedAt = <-reconnected:
+			case <-ctx.Done():
+				t.Fatal("stream is not reconnected")
+			}
+			// сервер не отправляет Ping, без задержки политики стрим переподключался бы каждые StaleTimeout
+			if d := reconnectedAt.Sub(staleAt); d < backoff {
+				t.Errorf("reconnected %v after stale, want at least %v", d, backoff)
+			}
+			if err := server.WaitStreams(ctx, tt.method, 1); err != nil {
+				t.Fatal(err)
+			}
+			if n := len(server.Requests(tt.method)); n < 2 {
+				t.Errorf("%v stream requests, want reopened stream", n)
+			}
+			if h := stream.Health(); h.Reconnects < 1 || h.StaleEvents < 1 {
+				t.Errorf("health %+v, want reconnect and stale event", h)
+			}
+		})
+	}
+}
+
+func TestStreamForceReconnectDisabled(t *testing.T) {
+	for _, tt := range watchedStreams {
+		t.Run(tt.method, func(t *testing.T) {
+			client, _ := investgotest.NewClient(t)
+			stream, err := tt.open(client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer stream.Stop()
+			stream.SetWatchdog(investgo.WatchdogOptions{StaleTimeout: 20 * time.Millisecond, ForceReconnect: true})
+			stream.SetReconnectPolicy(investgo.ReconnectPolicy{Disabled: true})
+
+			done := make(chan error, 1)
+			go func() {
+				done <- stream.Listen()
+			}()
+			select {
+			case err := <-done:
+				if err == nil {
+					t.Error("Listen returns nil, want stream error")
+				}
+			case <-time.After(testTimeout):
+				t.Fatal("Listen is not stopped")
+			}
+		})
+	}
+}
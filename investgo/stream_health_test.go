@@ -0,0 +1,174 @@
+package investgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStreamWatchdogStats(t *testing.T) {
+	w := newStreamWatchdog()
+	w.connected(func() {})
+	if h := w.stats(); h.ConnectedAt.IsZero() || h.Reconnects != 0 || !h.LastPing.IsZero() || !h.LastData.IsZero() {
+		t.Fatalf("health after connect %+v", h)
+	}
+
+	w.ping()
+	w.data()
+	w.data()
+	h := w.stats()
+	if h.Pings != 1 || h.LastPing.IsZero() || h.Messages != 2 || h.LastData.IsZero() {
+		t.Errorf("health %+v, want 1 ping and 2 messages", h)
+	}
+
+	w.connected(func() {})
+	if h := w.stats(); h.Reconnects != 1 || h.Pings != 1 {
+		t.Errorf("health after reconnect %+v, want 1 reconnect and kept counters", h)
+	}
+}
+
+func TestStreamWatchdogStale(t *testing.T) {
+	opts := WatchdogOptions{StaleTimeout: 20 * time.Millisecond}
+	tests := []struct {
+		name string
+		// prepare - состояние стрима перед проверкой
+		prepare func(w *streamWatchdog)
+		stale   bool
+	}{
+		{
+			name:    "fresh connect",
+			prepare: func(w *streamWatchdog) {},
+		},
+		{
+			name: "no ping after connect",
+			prepare: func(w *streamWatchdog) {
+				w.health.ConnectedAt = time.Now().Add(-time.Second)
+			},
+			stale: true,
+		},
+		{
+			name: "recent ping",
+			prepare: func(w *streamWatchdog) {
+				w.health.ConnectedAt = time.Now().Add(-time.Second)
+				w.ping()
+			},
+		},
+		{
+			name: "old ping",
+			prepare: func(w *streamWatchdog) {
+				w.health.ConnectedAt = time.Now().Add(-time.Second)
+				w.health.LastPing = time.Now().Add(-time.Second)
+			},
+			stale: true,
+		},
+		{
+			// данные без Ping не считаются признаком живого стрима
+			name: "data without ping",
+			prepare: func(w *streamWatchdog) {
+				w.health.ConnectedAt = time.Now().Add(-time.Second)
+				w.data()
+			},
+			stale: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newStreamWatchdog()
+			w.connected(func() {})
+			tt.prepare(w)
+			h, stale := w.check(opts)
+			if stale != tt.stale || h.Stale != tt.stale {
+				t.Fatalf("stale %v, health %+v, want %v", stale, h, tt.stale)
+			}
+			if !tt.stale {
+				return
+			}
+			// повторная проверка не создает новое событие, Ping снимает признак
+			if _, stale := w.check(opts); stale || w.stats().StaleEvents != 1 {
+				t.Errorf("stale event repeated, health %+v", w.stats())
+			}
+			w.ping()
+			if w.stats().Stale {
+				t.Error("ping does not clear stale flag")
+			}
+		})
+	}
+}
+
+func TestStreamWatchdogForceReconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tests := []struct {
+		name   string
+		force  bool
+		forced bool
+	}{
+		{name: "force reconnect", force: true, forced: true},
+		{name: "report only", force: false, forced: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streamCtx, streamCancel := context.WithCancel(ctx)
+			defer streamCancel()
+			w := newStreamWatchdog()
+			w.connected(streamCancel)
+			w.health.ConnectedAt = time.Now().Add(-time.Second)
+
+			if _, stale := w.check(WatchdogOptions{StaleTimeout: time.Millisecond, ForceReconnect: tt.force}); !stale {
+				t.Fatal("stream is not stale")
+			}
+			if canceled := streamCtx.Err() != nil; canceled != tt.forced {
+				t.Fatalf("grpc stream canceled %v, want %v", canceled, tt.forced)
+			}
+			canceledErr := status.Error(codes.Canceled, "context canceled")
+			if got := w.reconnectRequested(ctx, canceledErr); got != tt.forced {
+				t.Errorf("reconnectRequested %v, want %v", got, tt.forced)
+			}
+			// запрос переподключения используется один раз
+			if w.reconnectRequested(ctx, canceledErr) {
+				t.Error("reconnect is requested twice")
+			}
+		})
+	}
+
+	// отмена стрима вместе с родительским контекстом - остановка, а не переподключение
+	stopped, stop := context.WithCancel(context.Background())
+	w := newStreamWatchdog()
+	w.connected(stop)
+	w.health.ConnectedAt = time.Now().Add(-time.Second)
+	w.check(WatchdogOptions{StaleTimeout: time.Millisecond, ForceReconnect: true})
+	if w.reconnectRequested(stopped, status.Error(codes.Canceled, "")) {
+		t.Error("reconnect is requested for stopped stream")
+	}
+	if w.reconnectRequested(ctx, errors.New("other")) {
+		t.Error("reconnect is requested for non-cancel error")
+	}
+}
+
+func TestStreamWatchdogRun(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := make(chan StreamHealth, 1)
+	w := newStreamWatchdog()
+	w.setOptions(WatchdogOptions{
+		StaleTimeout: 20 * time.Millisecond,
+		OnStale: func(health StreamHealth) {
+			events <- health
+		},
+	})
+	w.connected(func() {})
+	go w.run(ctx, nopLogger{}, "test stream")
+
+	select {
+	case h := <-events:
+		if !h.Stale || h.StaleEvents != 1 {
+			t.Errorf("OnStale health %+v", h)
+		}
+	case <-ctx.Done():
+		t.Fatal("OnStale is not called")
+	}
+}
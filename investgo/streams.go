@@ -0,0 +1,108 @@
+package investgo
+
+import (
+	"context"
+	"sync"
+)
+
+// managedStream - стрим, созданный клиентом и завершаемый в Client.Shutdown
+type managedStream interface {
+	// Stop - отмена контекста стрима
+	Stop()
+	// wait - ожидание завершения Listen и закрытия каналов стрима
+	wait(ctx context.Context) error
+}
+
+// streamRegistry - стримы, открытые через клиента
+type streamRegistry struct {
+	mu      sync.Mutex
+	streams map[managedStream]struct{}
+}
+
+func newStreamRegistry() *streamRegistry {
+	return &streamRegistry{
+		streams: make(map[managedStream]struct{}, 0),
+	}
+}
+
+func (r *streamRegistry) add(s managedStream) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.streams[s] = struct{}{}
+}
+
+func (r *streamRegistry) remove(s managedStream) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.streams, s)
+}
+
+func (r *streamRegistry) list() []managedStream {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	streams := make([]managedStream, 0, len(r.streams))
+	for s := range r.streams {
+		streams = append(streams, s)
+	}
+	return streams
+}
+
+// streamLifecycle - состояние цикла Listen стрима. Каналы стрима закрываются один раз:
+// либо при выходе из Listen, либо в wait, если Listen не был запущен
+type streamLifecycle struct {
+	mu        sync.Mutex
+	listening bool
+	closed    bool
+	done      chan struct{}
+}
+
+func newStreamLifecycle() streamLifecycle {
+	return streamLifecycle{done: make(chan struct{})}
+}
+
+// begin - вызывается в начале Listen, false если стрим уже завершен или Listen уже запущен
+func (l *streamLifecycle) begin() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed || l.listening {
+		return false
+	}
+	l.listening = true
+	return true
+}
+
+// running - Listen запущен и еще не завершен
+func (l *streamLifecycle) running() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.listening && !l.closed
+}
+
+// end - вызывается при выходе из Listen, closeChannels закрывает каналы стрима
+func (l *streamLifecycle) end(closeChannels func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return
+	}
+	l.closed = true
+	closeChannels()
+	close(l.done)
+}
+
+// wait - ожидание выхода из Listen, если Listen не запущен - каналы закрываются сразу
+func (l *streamLifecycle) wait(ctx context.Context, closeChannels func()) error {
+	l.mu.Lock()
+	if !l.listening && !l.closed {
+		l.closed = true
+		closeChannels()
+		close(l.done)
+	}
+	l.mu.Unlock()
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
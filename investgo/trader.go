@@ -0,0 +1,198 @@
+package investgo
+
+import (
+	"context"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+// Trader - торговые операции по счёту, одинаковые для боевого контура и песочницы.
+// Позволяет переносить код стратегии из песочницы в боевой контур без изменений
+type Trader interface {
+	// PostOrder - выставление заявки
+	PostOrder(req *PostOrderRequest) (*PostOrderResponse, error)
+	PostOrderCtx(ctx context.Context, req *PostOrderRequest) (*PostOrderResponse, error)
+	// Buy - выставление заявки на покупку
+	Buy(req *PostOrderRequestShort) (*PostOrderResponse, error)
+	BuyCtx(ctx context.Context, req *PostOrderRequestShort) (*PostOrderResponse, error)
+	// Sell - выставление заявки на продажу
+	Sell(req *PostOrderRequestShort) (*PostOrderResponse, error)
+	SellCtx(ctx context.Context, req *PostOrderRequestShort) (*PostOrderResponse, error)
+	// ReplaceOrder - изменение выставленной заявки
+	ReplaceOrder(req *ReplaceOrderRequest) (*PostOrderResponse, error)
+	ReplaceOrderCtx(ctx context.Context, req *ReplaceOrderRequest) (*PostOrderResponse, error)
+	// CancelOrder - отмена заявки
+	CancelOrder(accountId, orderId string) (*CancelOrderResponse, error)
+	CancelOrderCtx(ctx context.Context, accountId, orderId string) (*CancelOrderResponse, error)
+	// GetOrderState - статус заявки
+	GetOrderState(accountId, orderId string) (*GetOrderStateResponse, error)
+	GetOrderStateCtx(ctx context.Context, accountId, orderId string) (*GetOrderStateResponse, error)
+	// GetOrders - список активных заявок по счёту
+	GetOrders(accountId string) (*GetOrdersResponse, error)
+	GetOrdersCtx(ctx context.Context, accountId string) (*GetOrdersResponse, error)
+	// GetPositions - позиции по счёту
+	GetPositions(accountId string) (*PositionsResponse, error)
+	GetPositionsCtx(ctx context.Context, accountId string) (*PositionsResponse, error)
+	// GetPortfolio - портфель по счёту
+	GetPortfolio(accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*PortfolioResponse, error)
+	GetPortfolioCtx(ctx context.Context, accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*PortfolioResponse, error)
+	// GetOperations - операции по счёту
+	GetOperations(req *GetOperationsRequest) (*OperationsResponse, error)
+	GetOperationsCtx(ctx context.Context, req *GetOperationsRequest) (*OperationsResponse, error)
+	// GetOperationsByCursor - операции по счёту с пагинацией
+	GetOperationsByCursor(req *GetOperationsByCursorRequest) (*GetOperationsByCursorResponse, error)
+	GetOperationsByCursorCtx(ctx context.Context, req *GetOperationsByCursorRequest) (*GetOperationsByCursorResponse, error)
+	// GetWithdrawLimits - доступный для вывода остаток
+	GetWithdrawLimits(accountId string) (*WithdrawLimitsResponse, error)
+	GetWithdrawLimitsCtx(ctx context.Context, accountId string) (*WithdrawLimitsResponse, error)
+}
+
+// NewTrader - создание Trader, песочница или боевой контур выбираются по конфигурации клиента.
+// Возвращает ошибку, если флаг Sandbox задан, а адрес указывает на боевой контур
+func (c *Client) NewTrader() (Trader, error) {
+	if err := c.Config.checkSandbox(); err != nil {
+		return nil, err
+	}
+	if c.Config.Sandbox || c.Config.EndPoint == EndPointSandbox {
+		return c.NewSandboxTrader(), nil
+	}
+	return c.NewProdTrader(), nil
+}
+
+// NewProdTrader - создание Trader для боевого контура
+func (c *Client) NewProdTrader() Trader {
+	return &prodTrader{
+		OrdersServiceClient:     c.NewOrdersServiceClient(),
+		OperationsServiceClient: c.NewOperationsServiceClient(),
+	}
+}
+
+// NewSandboxTrader - создание Trader для песочницы
+func (c *Client) NewSandboxTrader() Trader {
+	return &sandboxTrader{
+		sandbox: c.NewSandboxServiceClient(),
+	}
+}
+
+// prodTrader - Trader поверх сервисов ордеров и операций
+type prodTrader struct {
+	*OrdersServiceClient
+	*OperationsServiceClient
+}
+
+// sandboxTrader - Trader поверх сервиса песочницы
+type sandboxTrader struct {
+	sandbox *SandboxServiceClient
+}
+
+func (t *sandboxTrader) PostOrder(req *PostOrderRequest) (*PostOrderResponse, error) {
+	return t.PostOrderCtx(t.sandbox.ctx, req)
+}
+
+func (t *sandboxTrader) PostOrderCtx(ctx context.Context, req *PostOrderRequest) (*PostOrderResponse, error) {
+	return t.sandbox.PostSandboxOrderCtx(ctx, req)
+}
+
+func (t *sandboxTrader) Buy(req *PostOrderRequestShort) (*PostOrderResponse, error) {
+	return t.BuyCtx(t.sandbox.ctx, req)
+}
+
+func (t *sandboxTrader) BuyCtx(ctx context.Context, req *PostOrderRequestShort) (*PostOrderResponse, error) {
+	return t.sandbox.PostSandboxOrderCtx(ctx, &PostOrderRequest{
+		InstrumentId: req.InstrumentId,
+		Quantity:     req.Quantity,
+		Price:        req.Price,
+		Direction:    pb.OrderDirection_ORDER_DIRECTION_BUY,
+		AccountId:    req.AccountId,
+		OrderType:    req.OrderType,
+		OrderId:      req.OrderId,
+	})
+}
+
+func (t *sandboxTrader) Sell(req *PostOrderRequestShort) (*PostOrderResponse, error) {
+	return t.SellCtx(t.sandbox.ctx, req)
+}
+
+func (t *sandboxTrader) SellCtx(ctx context.Context, req *PostOrderRequestShort) (*PostOrderResponse, error) {
+	return t.sandbox.PostSandboxOrderCtx(ctx, &PostOrderRequest{
+		InstrumentId: req.InstrumentId,
+		Quantity:     req.Quantity,
+		Price:        req.Price,
+		Direction:    pb.OrderDirection_ORDER_DIRECTION_SELL,
+		AccountId:    req.AccountId,
+		OrderType:    req.OrderType,
+		OrderId:      req.OrderId,
+	})
+}
+
+func (t *sandboxTrader) ReplaceOrder(req *ReplaceOrderRequest) (*PostOrderResponse, error) {
+	return t.ReplaceOrderCtx(t.sandbox.ctx, req)
+}
+
+func (t *sandboxTrader) ReplaceOrderCtx(ctx context.Context, req *ReplaceOrderRequest) (*PostOrderResponse, error) {
+	return t.sandbox.ReplaceSandboxOrderCtx(ctx, req)
+}
+
+func (t *sandboxTrader) CancelOrder(accountId, orderId string) (*CancelOrderResponse, error) {
+	return t.CancelOrderCtx(t.sandbox.ctx, accountId, orderId)
+}
+
+func (t *sandboxTrader) CancelOrderCtx(ctx context.Context, accountId, orderId string) (*CancelOrderResponse, error) {
+	return t.sandbox.CancelSandboxOrderCtx(ctx, accountId, orderId)
+}
+
+func (t *sandboxTrader) GetOrderState(accountId, orderId string) (*GetOrderStateResponse, error) {
+	return t.GetOrderStateCtx(t.sandbox.ctx, accountId, orderId)
+}
+
+func (t *sandboxTrader) GetOrderStateCtx(ctx context.Context, accountId, orderId string) (*GetOrderStateResponse, error) {
+	return t.sandbox.GetSandboxOrderStateCtx(ctx, accountId, orderId)
+}
+
+func (t *sandboxTrader) GetOrders(accountId string) (*GetOrdersResponse, error) {
+	return t.GetOrdersCtx(t.sandbox.ctx, accountId)
+}
+
+func (t *sandboxTrader) GetOrdersCtx(ctx context.Context, accountId string) (*GetOrdersResponse, error) {
+	return t.sandbox.GetSandboxOrdersCtx(ctx, accountId)
+}
+
+func (t *sandboxTrader) GetPositions(accountId string) (*PositionsResponse, error) {
+	return t.GetPositionsCtx(t.sandbox.ctx, accountId)
+}
+
+func (t *sandboxTrader) GetPositionsCtx(ctx context.Context, accountId string) (*PositionsResponse, error) {
+	return t.sandbox.GetSandboxPositionsCtx(ctx, accountId)
+}
+
+func (t *sandboxTrader) GetPortfolio(accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*PortfolioResponse, error) {
+	return t.GetPortfolioCtx(t.sandbox.ctx, accountId, currency)
+}
+
+func (t *sandboxTrader) GetPortfolioCtx(ctx context.Context, accountId string, currency pb.PortfolioRequest_CurrencyRequest) (*PortfolioResponse, error) {
+	return t.sandbox.GetSandboxPortfolioCtx(ctx, accountId, currency)
+}
+
+func (t *sandboxTrader) GetOperations(req *GetOperationsRequest) (*OperationsResponse, error) {
+	return t.GetOperationsCtx(t.sandbox.ctx, req)
+}
+
+func (t *sandboxTrader) GetOperationsCtx(ctx context.Context, req *GetOperationsRequest) (*OperationsResponse, error) {
+	return t.sandbox.GetSandboxOperationsCtx(ctx, req)
+}
+
+func (t *sandboxTrader) GetOperationsByCursor(req *GetOperationsByCursorRequest) (*GetOperationsByCursorResponse, error) {
+	return t.GetOperationsByCursorCtx(t.sandbox.ctx, req)
+}
+
+func (t *sandboxTrader) GetOperationsByCursorCtx(ctx context.Context, req *GetOperationsByCursorRequest) (*GetOperationsByCursorResponse, error) {
+	return t.sandbox.GetSandboxOperationsByCursorCtx(ctx, req)
+}
+
+func (t *sandboxTrader) GetWithdrawLimits(accountId string) (*WithdrawLimitsResponse, error) {
+	return t.GetWithdrawLimitsCtx(t.sandbox.ctx, accountId)
+}
+
+func (t *sandboxTrader) GetWithdrawLimitsCtx(ctx context.Context, accountId string) (*WithdrawLimitsResponse, error) {
+	return t.sandbox.GetSandboxWithdrawLimitsCtx(ctx, accountId)
+}
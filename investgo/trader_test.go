@@ -0,0 +1,32 @@
+package investgo
+
+import "testing"
+
+func TestNewTraderSandboxEndPoint(t *testing.T) {
+	tests := []struct {
+		config  Config
+		sandbox bool
+		wantErr bool
+	}{
+		{config: Config{EndPoint: EndPointProd}},
+		{config: Config{EndPoint: EndPointSandbox}, sandbox: true},
+		{config: Config{EndPoint: EndPointSandbox, Sandbox: true}, sandbox: true},
+		{config: Config{EndPoint: EndPointProd, Sandbox: true}, wantErr: true},
+	}
+	for _, tt := range tests {
+		c := &Client{Config: tt.config}
+		trader, err := c.NewTrader()
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%+v: expected error", tt.config)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%+v: %v", tt.config, err)
+		}
+		if _, ok := trader.(*sandboxTrader); ok != tt.sandbox {
+			t.Errorf("%+v: sandbox trader = %v, want %v", tt.config, ok, tt.sandbox)
+		}
+	}
+}
@@ -11,9 +11,15 @@ import (
 type TradesStream struct {
 	stream       pb.OrdersStreamService_TradesStreamClient
 	ordersClient *OrdersStreamClient
+	req          *pb.TradesStreamRequest
 
-	ctx    context.Context
-	cancel context.CancelFunc
+	ctx       context.Context
+	cancel    context.CancelFunc
+	lifecycle streamLifecycle
+	watchdog  *streamWatchdog
+	recorder  *Recorder
+	// reconnect - политика принудительного переподключения зависшего стрима
+	reconnect ReconnectPolicy
 
 	trades chan *pb.OrderTrades
 }
@@ -25,7 +31,11 @@ func (t *TradesStream) Trades() <-chan *pb.OrderTrades {
 
 // Listen - метод начинает слушать стрим и отправлять информацию в канал, для получения канала: Trades()
 func (t *TradesStream) Listen() error {
+	if !t.lifecycle.begin() {
+		return nil
+	}
 	defer t.shutdown()
+	go t.watchdog.run(t.ctx, t.ordersClient.logger, "Trades stream")
 	for {
 		select {
 		case <-t.ctx.Done():
@@ -34,6 +44,15 @@ func (t *TradesStream) Listen() error {
 			resp, err := t.stream.Recv()
 			if err != nil {
 				switch {
+				case t.watchdog.reconnectRequested(t.ctx, err):
+					t.ordersClient.logger.Infof("Trades stream is stale, reconnecting")
+					err := t.reconnect.retry(t.ctx, t.ordersClient.logger, "Trades stream", err, t.openStream)
+					if err != nil {
+						if t.ctx.Err() != nil {
+							return nil
+						}
+						return err
+					}
 				case status.Code(err) == codes.Canceled:
 					t.ordersClient.logger.Infof("Stop listening order trades")
 					return nil
@@ -41,9 +60,16 @@ func (t *TradesStream) Listen() error {
 					return err
 				}
 			} else {
+				t.recorder.record(RecordedTrades, resp)
 				switch resp.GetPayload().(type) {
 				case *pb.TradesStreamResponse_OrderTrades:
-					t.trades <- resp.GetOrderTrades()
+					t.watchdog.data()
+					select {
+					case t.trades <- resp.GetOrderTrades():
+					case <-t.ctx.Done():
+					}
+				case *pb.TradesStreamResponse_Ping:
+					t.watchdog.ping()
 				default:
 					t.ordersClient.logger.Infof("Info from Trades stream %v", resp.String())
 				}
@@ -52,7 +78,45 @@ func (t *TradesStream) Listen() error {
 	}
 }
 
+// SetReconnectPolicy - установка политики принудительного переподключения зависшего стрима, вызывается до Listen
+func (t *TradesStream) SetReconnectPolicy(policy ReconnectPolicy) {
+	t.reconnect = policy
+}
+
+// SetWatchdog - установка проверки поступления Ping, вызывается до Listen
+func (t *TradesStream) SetWatchdog(opts WatchdogOptions) {
+	t.watchdog.setOptions(opts)
+}
+
+// Health - статистика активности стрима
+func (t *TradesStream) Health() StreamHealth {
+	return t.watchdog.stats()
+}
+
+// SetRecorder - запись всех сообщений стрима, вызывается до Listen
+func (t *TradesStream) SetRecorder(r *Recorder) {
+	t.recorder = r
+}
+
+// openStream - открытие нового grpc стрима в рамках контекста TradesStream
+func (t *TradesStream) openStream() error {
+	ctx, cancel := context.WithCancel(t.ctx)
+	stream, err := t.ordersClient.pbClient.TradesStream(ctx, t.req)
+	if err != nil {
+		cancel()
+		return err
+	}
+	t.stream = stream
+	t.watchdog.connected(cancel)
+	return nil
+}
+
 func (t *TradesStream) shutdown() {
+	t.lifecycle.end(t.closeChannels)
+	t.ordersClient.streams.remove(t)
+}
+
+func (t *TradesStream) closeChannels() {
 	t.ordersClient.logger.Infof("Close trades stream")
 	close(t.trades)
 }
@@ -61,3 +125,12 @@ func (t *TradesStream) shutdown() {
 func (t *TradesStream) Stop() {
 	t.cancel()
 }
+
+// wait - ожидание завершения Listen и закрытия канала
+func (t *TradesStream) wait(ctx context.Context) error {
+	err := t.lifecycle.wait(ctx, t.closeChannels)
+	if err == nil {
+		t.ordersClient.streams.remove(t)
+	}
+	return err
+}
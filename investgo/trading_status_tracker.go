@@ -0,0 +1,253 @@
+package investgo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pb "github.com/therox/invest-api-go-sdk/proto"
+)
+
+// TradingPhase - укрупненная фаза торгов, к которой относится SecurityTradingStatus
+type TradingPhase int
+
+const (
+	// PhaseClosed - торги недоступны или сессия закрыта
+	PhaseClosed TradingPhase = iota
+	// PhaseOpeningAuction - период открытия или аукцион открытия
+	PhaseOpeningAuction
+	// PhaseTrading - нормальная торговля, в том числе в режиме внутренней ликвидности брокера
+	PhaseTrading
+	// PhaseHalted - перерыв в торговле, дискретный аукцион или аукцион крупных пакетов
+	PhaseHalted
+	// PhaseClosingAuction - период закрытия, аукцион закрытия или торги по цене аукциона закрытия
+	PhaseClosingAuction
+)
+
+// TradingPhaseOf - фаза торгов для статуса s
+func TradingPhaseOf(s pb.SecurityTradingStatus) TradingPhase {
+	switch s {
+	case pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_OPENING_PERIOD,
+		pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_OPENING_AUCTION_PERIOD:
+		return PhaseOpeningAuction
+	case pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_NORMAL_TRADING,
+		pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_DEALER_NORMAL_TRADING,
+		pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_SESSION_OPEN:
+		return PhaseTrading
+	case pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_BREAK_IN_TRADING,
+		pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_DEALER_BREAK_IN_TRADING,
+		pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_DISCRETE_AUCTION,
+		pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_DARK_POOL_AUCTION:
+		return PhaseHalted
+	case pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_CLOSING_PERIOD,
+		pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_CLOSING_AUCTION,
+		pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_TRADING_AT_CLOSING_AUCTION_PRICE:
+		return PhaseClosingAuction
+	default:
+		return PhaseClosed
+	}
+}
+
+// TradingEventType - тип перехода между торговыми статусами
+type TradingEventType int
+
+const (
+	// TradingStatusChanged - статус или признаки доступности заявок изменились в пределах фазы торгов
+	TradingStatusChanged TradingEventType = iota
+	// TradingOpeningAuction - начался период открытия или аукцион открытия
+	TradingOpeningAuction
+	// TradingOpened - начались торги после закрытия или аукциона открытия
+	TradingOpened
+	// TradingHalted - торги приостановлены
+	TradingHalted
+	// TradingResumed - торги возобновлены после приостановки
+	TradingResumed
+	// TradingClosingAuction - начался период закрытия или аукцион закрытия
+	TradingClosingAuction
+	// TradingClosed - торги закрыты
+	TradingClosed
+)
+
+// InstrumentTradingStatus - торговый статус инструмента
+type InstrumentTradingStatus struct {
+	Figi                 string
+	InstrumentUid        string
+	Status               pb.SecurityTradingStatus
+	LimitOrderAvailable  bool
+	MarketOrderAvailable bool
+	// ApiTradeAvailable - признак доступности торгов через API, приходит только в GetTradingStatuses
+	// и сохраняется при обновлениях из стрима
+	ApiTradeAvailable bool
+	// Time - время статуса, нулевое для статуса из GetTradingStatuses, поэтому его заменяет любой статус из стрима
+	Time time.Time
+}
+
+// Phase - фаза торгов текущего статуса
+func (s InstrumentTradingStatus) Phase() TradingPhase {
+	return TradingPhaseOf(s.Status)
+}
+
+// TradingEvent - переход инструмента между торговыми статусами
+type TradingEvent struct {
+	Type TradingEventType
+	// Previous - предыдущий статус, нулевой для первого статуса инструмента
+	Previous InstrumentTradingStatus
+	Current  InstrumentTradingStatus
+}
+
+// TradingStatusTracker - текущие торговые статусы инструментов по данным GetTradingStatuses и SubscribeInfo.
+// При изменении статуса или признаков доступности заявок отправляет событие перехода
+type TradingStatusTracker struct {
+	mu sync.RWMutex
+	// statuses - статусы по figi и instrument_uid, оба ключа указывают на один статус
+	statuses map[string]*InstrumentTradingStatus
+
+	// events - рассылка событий перехода
+	events *MDDispatcher
+}
+
+// NewTradingStatusTracker - создание трекера торговых статусов
+func NewTradingStatusTracker() *TradingStatusTracker {
+	return &TradingStatusTracker{
+		statuses: make(map[string]*InstrumentTradingStatus, 0),
+		events:   NewMDDispatcher(),
+	}
+}
+
+// Seed - загрузка статусов инструментов ids методом GetTradingStatuses. События для загруженных
+// статусов не отправляются
+func (t *TradingStatusTracker) Seed(ctx context.Context, md MarketDataService, ids []string) error {
+	resp, err := md.GetTradingStatusesCtx(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, ts := range resp.GetTradingStatuses() {
+		t.set(InstrumentTradingStatus{
+			Figi:                 ts.GetFigi(),
+			InstrumentUid:        ts.GetInstrumentUid(),
+			Status:               ts.GetTradingStatus(),
+			LimitOrderAvailable:  ts.GetLimitOrderAvailableFlag(),
+			MarketOrderAvailable: ts.GetMarketOrderAvailableFlag(),
+			ApiTradeAvailable:    ts.GetApiTradeAvailableFlag(),
+		}, false)
+	}
+	return nil
+}
+
+// Run - обновление статусов из канала src до его закрытия, например MDStream.TradingStatuses(),
+// после чего каналы Events закрываются
+func (t *TradingStatusTracker) Run(src <-chan *pb.TradingStatus) {
+	defer t.events.closeAll()
+	for ts := range src {
+		t.Update(ts)
+	}
+}
+
+// Update - обновление статуса инструмента, статус старше текущего игнорируется
+func (t *TradingStatusTracker) Update(ts *pb.TradingStatus) {
+	t.set(InstrumentTradingStatus{
+		Figi:                 ts.GetFigi(),
+		InstrumentUid:        ts.GetInstrumentUid(),
+		Status:               ts.GetTradingStatus(),
+		LimitOrderAvailable:  ts.GetLimitOrderAvailableFlag(),
+		MarketOrderAvailable: ts.GetMarketOrderAvailableFlag(),
+		Time:                 ts.GetTime().AsTime(),
+	}, true)
+}
+
+// Status - текущий статус инструмента по figi или instrument_uid
+func (t *TradingStatusTracker) Status(id string) (InstrumentTradingStatus, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	s, ok := t.statuses[id]
+	if !ok {
+		return InstrumentTradingStatus{}, false
+	}
+	return *s, true
+}
+
+// MarketOrderAvailable - true, если по инструменту сейчас принимаются рыночные заявки
+func (t *TradingStatusTracker) MarketOrderAvailable(id string) bool {
+	s, ok := t.Status(id)
+	return ok && s.MarketOrderAvailable
+}
+
+// LimitOrderAvailable - true, если по инструменту сейчас принимаются лимитные заявки
+func (t *TradingStatusTracker) LimitOrderAvailable(id string) bool {
+	s, ok := t.Status(id)
+	return ok && s.LimitOrderAvailable
+}
+
+// Events - канал событий перехода по инструментам ids, при пустом ids - по всем инструментам
+func (t *TradingStatusTracker) Events(ids []string, opts ConsumerOptions) *Consumer[TradingEvent] {
+	return addConsumer[TradingEvent](t.events, SubscriptionInfo, ids, opts)
+}
+
+// OnEvent - вызов fn для событий перехода по инструментам ids в отдельной горутине потребителя
+func (t *TradingStatusTracker) OnEvent(ids []string, opts ConsumerOptions, fn func(TradingEvent)) DispatchHandle {
+	return handle(t.Events(ids, opts), fn)
+}
+
+// set - сохранение статуса s, notify - отправлять событие перехода
+func (t *TradingStatusTracker) set(s InstrumentTradingStatus, notify bool) {
+	t.mu.Lock()
+	current := t.statuses[s.Figi]
+	if current == nil {
+		current = t.statuses[s.InstrumentUid]
+	}
+	var previous InstrumentTradingStatus
+	if current != nil {
+		if s.Time.Before(current.Time) {
+			t.mu.Unlock()
+			return
+		}
+		previous = *current
+		if s.Figi == "" {
+			s.Figi = current.Figi
+		}
+		if s.InstrumentUid == "" {
+			s.InstrumentUid = current.InstrumentUid
+		}
+		if notify {
+			// в стриме нет признака доступности торгов через API
+			s.ApiTradeAvailable = current.ApiTradeAvailable
+		}
+	}
+	for _, key := range []string{s.Figi, s.InstrumentUid} {
+		if key != "" {
+			t.statuses[key] = &s
+		}
+	}
+	t.mu.Unlock()
+	if !notify {
+		return
+	}
+	if current != nil && previous.Status == s.Status &&
+		previous.LimitOrderAvailable == s.LimitOrderAvailable && previous.MarketOrderAvailable == s.MarketOrderAvailable {
+		return
+	}
+	e := TradingEvent{Type: transition(current != nil, previous.Phase(), s.Phase()), Previous: previous, Current: s}
+	t.events.dispatch(SubscriptionInfo, s.Figi, s.InstrumentUid, e)
+}
+
+// transition - тип события при переходе между фазами, known - предыдущий статус известен
+func transition(known bool, from, to TradingPhase) TradingEventType {
+	if known && from == to {
+		return TradingStatusChanged
+	}
+	switch to {
+	case PhaseOpeningAuction:
+		return TradingOpeningAuction
+	case PhaseTrading:
+		if known && from == PhaseHalted {
+			return TradingResumed
+		}
+		return TradingOpened
+	case PhaseHalted:
+		return TradingHalted
+	case PhaseClosingAuction:
+		return TradingClosingAuction
+	default:
+		return TradingClosed
+	}
+}
@@ -0,0 +1,98 @@
+package investgo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/therox/invest-api-go-sdk/investgo"
+	"github.com/therox/invest-api-go-sdk/investgo/investgomock"
+	pb "github.com/therox/invest-api-go-sdk/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestTradingStatusTrackerTransitions(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	md := &investgomock.MarketDataServiceMock{
+		GetTradingStatusesCtxFunc: func(ctx context.Context, ids []string) (*investgo.GetTradingStatusesResponse, error) {
+			return &investgo.GetTradingStatusesResponse{GetTradingStatusesResponse: &pb.GetTradingStatusesResponse{
+				TradingStatuses: []*pb.GetTradingStatusResponse{{
+					Figi:                     "figi",
+					InstrumentUid:            "uid",
+					TradingStatus:            pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_NORMAL_TRADING,
+					LimitOrderAvailableFlag:  true,
+					MarketOrderAvailableFlag: true,
+					ApiTradeAvailableFlag:    true,
+				}},
+			}}, nil
+		},
+	}
+	tracker := investgo.NewTradingStatusTracker()
+	if err := tracker.Seed(ctx, md, []string{"figi"}); err != nil {
+		t.Fatal(err)
+	}
+	if !tracker.MarketOrderAvailable("uid") {
+		t.Fatal("market orders are not available after Seed")
+	}
+	events := tracker.Events(nil, investgo.ConsumerOptions{BufferSize: 16})
+
+	start := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+	status := func(figi, uid string, minute int, s pb.SecurityTradingStatus, market bool) *pb.TradingStatus {
+		return &pb.TradingStatus{
+			Figi:                     figi,
+			InstrumentUid:            uid,
+			TradingStatus:            s,
+			Time:                     timestamppb.New(start.Add(time.Duration(minute) * time.Minute)),
+			LimitOrderAvailableFlag:  market,
+			MarketOrderAvailableFlag: market,
+		}
+	}
+	src := make(chan *pb.TradingStatus, 16)
+	src <- status("figi", "uid", 1, pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_BREAK_IN_TRADING, false)
+	src <- status("figi", "uid", 0, pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_CLOSING_AUCTION, false) // устаревший статус
+	src <- status("", "uid", 2, pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_NORMAL_TRADING, true)
+	src <- status("figi", "uid", 3, pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_NORMAL_TRADING, true) // без изменений
+	src <- status("figi", "uid", 4, pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_DEALER_NORMAL_TRADING, true)
+	src <- status("figi", "uid", 5, pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_CLOSING_AUCTION, false)
+	src <- status("figi", "uid", 6, pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_NOT_AVAILABLE_FOR_TRADING, false)
+	src <- status("other", "other-uid", 0, pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_OPENING_AUCTION_PERIOD, false)
+	close(src)
+	tracker.Run(src)
+
+	var got []investgo.TradingEvent
+	for e := range events.C() {
+		got = append(got, e)
+	}
+	want := []investgo.TradingEventType{
+		investgo.TradingHalted,
+		investgo.TradingResumed,
+		investgo.TradingStatusChanged,
+		investgo.TradingClosingAuction,
+		investgo.TradingClosed,
+		investgo.TradingOpeningAuction,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("%v events %+v, want %v", len(got), got, want)
+	}
+	for i, w := range want {
+		if got[i].Type != w {
+			t.Errorf("event %v: type %v, want %v", i, got[i].Type, w)
+		}
+	}
+	// статус из стрима без figi дополняется сохраненным figi
+	if resumed := got[1]; resumed.Current.Figi != "figi" || resumed.Previous.Phase() != investgo.PhaseHalted {
+		t.Errorf("resumed event %+v", resumed)
+	}
+	if opening := got[5]; opening.Previous.Status != pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_UNSPECIFIED {
+		t.Errorf("first status of instrument has previous status %v", opening.Previous.Status)
+	}
+
+	s, ok := tracker.Status("figi")
+	if !ok || s.Phase() != investgo.PhaseClosed || !s.ApiTradeAvailable {
+		t.Errorf("status %+v, want closed with api trade flag from Seed", s)
+	}
+	if tracker.MarketOrderAvailable("figi") || tracker.LimitOrderAvailable("other") {
+		t.Error("orders are available after close")
+	}
+}
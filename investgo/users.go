@@ -18,10 +18,16 @@ type UsersServiceClient struct {
 
 // GetAccounts - Метод получения счетов пользователя
 func (us *UsersServiceClient) GetAccounts() (*GetAccountsResponse, error) {
+	return us.GetAccountsCtx(us.ctx)
+}
+
+// GetAccountsCtx - GetAccounts с контекстом запроса
+func (us *UsersServiceClient) GetAccountsCtx(ctx context.Context) (*GetAccountsResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := us.pbClient.GetAccounts(us.ctx, &pb.GetAccountsRequest{}, grpc.Header(&header), grpc.Trailer(&trailer))
+	resp, err := us.pbClient.GetAccounts(ctx, &pb.GetAccountsRequest{}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetAccountsResponse{
 		GetAccountsResponse: resp,
@@ -31,12 +37,18 @@ func (us *UsersServiceClient) GetAccounts() (*GetAccountsResponse, error) {
 
 // GetMarginAttributes - Расчёт маржинальных показателей по счёту
 func (us *UsersServiceClient) GetMarginAttributes(accountId string) (*GetMarginAttributesResponse, error) {
+	return us.GetMarginAttributesCtx(us.ctx, accountId)
+}
+
+// GetMarginAttributesCtx - GetMarginAttributes с контекстом запроса
+func (us *UsersServiceClient) GetMarginAttributesCtx(ctx context.Context, accountId string) (*GetMarginAttributesResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := us.pbClient.GetMarginAttributes(us.ctx, &pb.GetMarginAttributesRequest{
+	resp, err := us.pbClient.GetMarginAttributes(ctx, &pb.GetMarginAttributesRequest{
 		AccountId: accountId,
 	}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetMarginAttributesResponse{
 		GetMarginAttributesResponse: resp,
@@ -46,10 +58,16 @@ func (us *UsersServiceClient) GetMarginAttributes(accountId string) (*GetMarginA
 
 // GetUserTariff - Запрос тарифа пользователя
 func (us *UsersServiceClient) GetUserTariff() (*GetUserTariffResponse, error) {
+	return us.GetUserTariffCtx(us.ctx)
+}
+
+// GetUserTariffCtx - GetUserTariff с контекстом запроса
+func (us *UsersServiceClient) GetUserTariffCtx(ctx context.Context) (*GetUserTariffResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := us.pbClient.GetUserTariff(us.ctx, &pb.GetUserTariffRequest{}, grpc.Header(&header), grpc.Trailer(&trailer))
+	resp, err := us.pbClient.GetUserTariff(ctx, &pb.GetUserTariffRequest{}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetUserTariffResponse{
 		GetUserTariffResponse: resp,
@@ -59,10 +77,16 @@ func (us *UsersServiceClient) GetUserTariff() (*GetUserTariffResponse, error) {
 
 // GetInfo - Метод получения информации о пользователе
 func (us *UsersServiceClient) GetInfo() (*GetInfoResponse, error) {
+	return us.GetInfoCtx(us.ctx)
+}
+
+// GetInfoCtx - GetInfo с контекстом запроса
+func (us *UsersServiceClient) GetInfoCtx(ctx context.Context) (*GetInfoResponse, error) {
 	var header, trailer metadata.MD
-	resp, err := us.pbClient.GetInfo(us.ctx, &pb.GetInfoRequest{}, grpc.Header(&header), grpc.Trailer(&trailer))
+	resp, err := us.pbClient.GetInfo(ctx, &pb.GetInfoRequest{}, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
 		header = trailer
+		err = newAPIError(err, header)
 	}
 	return &GetInfoResponse{
 		GetInfoResponse: resp,
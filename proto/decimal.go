@@ -0,0 +1,499 @@
+package investapi
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// nanoScale - number of nano units in one unit of Quotation and MoneyValue
+const nanoScale = 1_000_000_000
+
+// Arithmetic is exact: operands are converted to nano units in big.Int. Add, Sub, Mul, MulRound, MulInt,
+// division, parsing and conversion from float return ErrOutOfRange if the result does not fit into int64
+// units. Results of Neg, Round, RoundToStep and NewQuotation are clamped to the nearest representable value,
+// that is math.MaxInt64 units and 999999999 nano or math.MinInt64 units and -999999999 nano
+
+var (
+	// ErrOutOfRange - value does not fit into Quotation or MoneyValue
+	ErrOutOfRange = errors.New("value out of range")
+	// ErrCurrencyMismatch - arithmetic on MoneyValue values with different currencies
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	// ErrDivisionByZero - division by zero Quotation or MoneyValue
+	ErrDivisionByZero = errors.New("division by zero")
+
+	bigNanoScale = big.NewInt(nanoScale)
+)
+
+// RoundingMode - rounding mode for results that do not fit into nano precision
+type RoundingMode int
+
+const (
+	// RoundHalfUp - round to nearest, ties away from zero
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven - round to nearest, ties to even (banker's rounding)
+	RoundHalfEven
+	// RoundDown - round towards zero
+	RoundDown
+	// RoundUp - round away from zero
+	RoundUp
+	// RoundFloor - round towards negative infinity
+	RoundFloor
+	// RoundCeiling - round towards positive infinity
+	RoundCeiling
+)
+
+// NewQuotation - create Quotation from units and nano, the result is normalized so that
+// nano is in (-1e9, 1e9) and has the same sign as units
+func NewQuotation(units int64, nano int32) *Quotation {
+	u, n := normalize(units, int64(nano))
+	return &Quotation{Units: u, Nano: n}
+}
+
+var (
+	// MaxQuotation - the greatest value of Quotation, result of clamping on overflow
+	MaxQuotation = &Quotation{Units: math.MaxInt64, Nano: nanoScale - 1}
+	// MinQuotation - the least value of Quotation, result of clamping on overflow
+	MinQuotation = &Quotation{Units: math.MinInt64, Nano: -(nanoScale - 1)}
+)
+
+// QuotationFromString - parse decimal string like "-123.456" or "1.5e-3". Returns an error if the value
+// has more than 9 fractional digits or does not fit into Quotation
+func QuotationFromString(s string) (*Quotation, error) {
+	r, err := parseRat(s)
+	if err != nil {
+		return nil, err
+	}
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(bigNanoScale))
+	if !scaled.IsInt() {
+		return nil, fmt.Errorf("%q: precision is greater than 1e-9", s)
+	}
+	units, nano, err := fromNano(scaled.Num())
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w", s, err)
+	}
+	return &Quotation{Units: units, Nano: nano}, nil
+}
+
+// QuotationFromFloat - convert float to Quotation, the shortest decimal representation of f
+// is rounded to nano precision with mode
+func QuotationFromFloat(f float64, mode RoundingMode) (*Quotation, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("invalid float value %v", f)
+	}
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	if !ok {
+		return nil, fmt.Errorf("invalid float value %v", f)
+	}
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(bigNanoScale))
+	units, nano, err := fromNano(divRound(scaled.Num(), scaled.Denom(), mode))
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", f, err)
+	}
+	return &Quotation{Units: units, Nano: nano}, nil
+}
+
+// ToString - get value as decimal string without trailing zeros, for example "-12.05"
+func (q *Quotation) ToString() string {
+	return formatDecimal(q.GetUnits(), q.GetNano())
+}
+
+// IsZero - true if value is zero, nil Quotation is zero
+func (q *Quotation) IsZero() bool {
+	return q.GetUnits() == 0 && q.GetNano() == 0
+}
+
+// Sign - -1, 0 or +1 depending on the sign of value
+func (q *Quotation) Sign() int {
+	return sign(q.GetUnits(), q.GetNano())
+}
+
+// Cmp - compare values: -1 if q < o, 0 if q == o, +1 if q > o. nil is treated as zero
+func (q *Quotation) Cmp(o *Quotation) int {
+	return cmp(q.GetUnits(), q.GetNano(), o.GetUnits(), o.GetNano())
+}
+
+// Equal - true if values are equal
+func (q *Quotation) Equal(o *Quotation) bool {
+	return q.Cmp(o) == 0
+}
+
+// Neg - get -q, -MinQuotation is clamped to MaxQuotation
+func (q *Quotation) Neg() *Quotation {
+	units, nano := clamp(new(big.Int).Neg(toNano(q.GetUnits(), q.GetNano())))
+	return &Quotation{Units: units, Nano: nano}
+}
+
+// Abs - get |q|
+func (q *Quotation) Abs() *Quotation {
+	if q.Sign() < 0 {
+		return q.Neg()
+	}
+	return &Quotation{Units: q.GetUnits(), Nano: q.GetNano()}
+}
+
+// Add - get q + o, returns ErrOutOfRange on overflow
+func (q *Quotation) Add(o *Quotation) (*Quotation, error) {
+	units, nano, err := add(q.GetUnits(), q.GetNano(), o.GetUnits(), o.GetNano())
+	if err != nil {
+		return nil, err
+	}
+	return &Quotation{Units: units, Nano: nano}, nil
+}
+
+// Sub - get q - o, returns ErrOutOfRange on overflow
+func (q *Quotation) Sub(o *Quotation) (*Quotation, error) {
+	units, nano, err := sub(q.GetUnits(), q.GetNano(), o.GetUnits(), o.GetNano())
+	if err != nil {
+		return nil, err
+	}
+	return &Quotation{Units: units, Nano: nano}, nil
+}
+
+// Mul - get q * o rounded half up to nano precision, returns ErrOutOfRange on overflow
+func (q *Quotation) Mul(o *Quotation) (*Quotation, error) {
+	return q.MulRound(o, RoundHalfUp)
+}
+
+// MulRound - get q * o rounded to nano precision with mode, returns ErrOutOfRange on overflow
+func (q *Quotation) MulRound(o *Quotation, mode RoundingMode) (*Quotation, error) {
+	units, nano, err := mul(q.GetUnits(), q.GetNano(), o.GetUnits(), o.GetNano(), mode)
+	if err != nil {
+		return nil, err
+	}
+	return &Quotation{Units: units, Nano: nano}, nil
+}
+
+// MulInt - get q * n, returns ErrOutOfRange on overflow
+func (q *Quotation) MulInt(n int64) (*Quotation, error) {
+	return q.Mul(&Quotation{Units: n})
+}
+
+// Div - get q / o rounded half up to nano precision
+func (q *Quotation) Div(o *Quotation) (*Quotation, error) {
+	return q.DivRound(o, RoundHalfUp)
+}
+
+// DivRound - get q / o rounded to nano precision with mode
+func (q *Quotation) DivRound(o *Quotation, mode RoundingMode) (*Quotation, error) {
+	units, nano, err := div(q.GetUnits(), q.GetNano(), o.GetUnits(), o.GetNano(), mode)
+	if err != nil {
+		return nil, err
+	}
+	return &Quotation{Units: units, Nano: nano}, nil
+}
+
+// Round - round value to places fractional digits (0..9) with mode
+func (q *Quotation) Round(places int, mode RoundingMode) *Quotation {
+	units, nano := round(q.GetUnits(), q.GetNano(), places, mode)
+	return &Quotation{Units: units, Nano: nano}
+}
+
+// RoundToStep - round value to a multiple of step with mode, for example price to min price increment.
+// Zero step returns value unchanged
+func (q *Quotation) RoundToStep(step *Quotation, mode RoundingMode) *Quotation {
+	units, nano := roundToStep(q.GetUnits(), q.GetNano(), step.GetUnits(), step.GetNano(), mode)
+	return &Quotation{Units: units, Nano: nano}
+}
+
+// IsMultipleOf - true if value is a multiple of step
+func (q *Quotation) IsMultipleOf(step *Quotation) bool {
+	s := toNano(step.GetUnits(), step.GetNano())
+	if s.Sign() == 0 {
+		return true
+	}
+	return new(big.Int).Rem(toNano(q.GetUnits(), q.GetNano()), s).Sign() == 0
+}
+
+// NewMoneyValue - create MoneyValue with currency from Quotation
+func NewMoneyValue(currency string, q *Quotation) *MoneyValue {
+	return &MoneyValue{Currency: currency, Units: q.GetUnits(), Nano: q.GetNano()}
+}
+
+// MoneyValueFromString - parse decimal string, see QuotationFromString
+func MoneyValueFromString(s, currency string) (*MoneyValue, error) {
+	q, err := QuotationFromString(s)
+	if err != nil {
+		return nil, err
+	}
+	return NewMoneyValue(currency, q), nil
+}
+
+// ToQuotation - get value without currency
+func (mv *MoneyValue) ToQuotation() *Quotation {
+	return &Quotation{Units: mv.GetUnits(), Nano: mv.GetNano()}
+}
+
+// ToString - get value as decimal string with currency, for example "-12.05 rub"
+func (mv *MoneyValue) ToString() string {
+	s := formatDecimal(mv.GetUnits(), mv.GetNano())
+	if mv.GetCurrency() == "" {
+		return s
+	}
+	return s + " " + mv.GetCurrency()
+}
+
+// IsZero - true if value is zero, nil MoneyValue is zero
+func (mv *MoneyValue) IsZero() bool {
+	return mv.GetUnits() == 0 && mv.GetNano() == 0
+}
+
+// Sign - -1, 0 or +1 depending on the sign of value
+func (mv *MoneyValue) Sign() int {
+	return sign(mv.GetUnits(), mv.GetNano())
+}
+
+// SameCurrency - true if currencies are equal ignoring case. nil MoneyValue matches any currency
+func (mv *MoneyValue) SameCurrency(o *MoneyValue) bool {
+	return mv == nil || o == nil || strings.EqualFold(mv.GetCurrency(), o.GetCurrency())
+}
+
+// Cmp - compare values: -1 if mv < o, 0 if mv == o, +1 if mv > o. Returns ErrCurrencyMismatch
+// for different currencies
+func (mv *MoneyValue) Cmp(o *MoneyValue) (int, error) {
+	if !mv.SameCurrency(o) {
+		return 0, mismatch(mv, o)
+	}
+	return cmp(mv.GetUnits(), mv.GetNano(), o.GetUnits(), o.GetNano()), nil
+}
+
+// Neg - get -mv, clamped on overflow as Quotation.Neg
+func (mv *MoneyValue) Neg() *MoneyValue {
+	units, nano := clamp(new(big.Int).Neg(toNano(mv.GetUnits(), mv.GetNano())))
+	return &MoneyValue{Currency: mv.GetCurrency(), Units: units, Nano: nano}
+}
+
+// Abs - get |mv|
+func (mv *MoneyValue) Abs() *MoneyValue {
+	if mv.Sign() < 0 {
+		return mv.Neg()
+	}
+	return &MoneyValue{Currency: mv.GetCurrency(), Units: mv.GetUnits(), Nano: mv.GetNano()}
+}
+
+// Add - get mv + o, returns ErrCurrencyMismatch for different currencies and ErrOutOfRange on overflow
+func (mv *MoneyValue) Add(o *MoneyValue) (*MoneyValue, error) {
+	if !mv.SameCurrency(o) {
+		return nil, mismatch(mv, o)
+	}
+	units, nano, err := add(mv.GetUnits(), mv.GetNano(), o.GetUnits(), o.GetNano())
+	if err != nil {
+		return nil, err
+	}
+	return &MoneyValue{Currency: currencyOf(mv, o), Units: units, Nano: nano}, nil
+}
+
+// Sub - get mv - o, returns ErrCurrencyMismatch for different currencies and ErrOutOfRange on overflow
+func (mv *MoneyValue) Sub(o *MoneyValue) (*MoneyValue, error) {
+	if !mv.SameCurrency(o) {
+		return nil, mismatch(mv, o)
+	}
+	units, nano, err := sub(mv.GetUnits(), mv.GetNano(), o.GetUnits(), o.GetNano())
+	if err != nil {
+		return nil, err
+	}
+	return &MoneyValue{Currency: currencyOf(mv, o), Units: units, Nano: nano}, nil
+}
+
+// Mul - get mv * q rounded half up to nano precision, for example price * quantity. Returns ErrOutOfRange
+// on overflow
+func (mv *MoneyValue) Mul(q *Quotation) (*MoneyValue, error) {
+	return mv.MulRound(q, RoundHalfUp)
+}
+
+// MulRound - get mv * q rounded to nano precision with mode, returns ErrOutOfRange on overflow
+func (mv *MoneyValue) MulRound(q *Quotation, mode RoundingMode) (*MoneyValue, error) {
+	units, nano, err := mul(mv.GetUnits(), mv.GetNano(), q.GetUnits(), q.GetNano(), mode)
+	if err != nil {
+		return nil, err
+	}
+	return &MoneyValue{Currency: mv.GetCurrency(), Units: units, Nano: nano}, nil
+}
+
+// Div - get mv / q rounded half up to nano precision
+func (mv *MoneyValue) Div(q *Quotation) (*MoneyValue, error) {
+	return mv.DivRound(q, RoundHalfUp)
+}
+
+// DivRound - get mv / q rounded to nano precision with mode
+func (mv *MoneyValue) DivRound(q *Quotation, mode RoundingMode) (*MoneyValue, error) {
+	units, nano, err := div(mv.GetUnits(), mv.GetNano(), q.GetUnits(), q.GetNano(), mode)
+	if err != nil {
+		return nil, err
+	}
+	return &MoneyValue{Currency: mv.GetCurrency(), Units: units, Nano: nano}, nil
+}
+
+// Ratio - get mv / o for values with the same currency, for example share of position in portfolio
+func (mv *MoneyValue) Ratio(o *MoneyValue, mode RoundingMode) (*Quotation, error) {
+	if !mv.SameCurrency(o) {
+		return nil, mismatch(mv, o)
+	}
+	return mv.ToQuotation().DivRound(o.ToQuotation(), mode)
+}
+
+// Round - round value to places fractional digits (0..9) with mode
+func (mv *MoneyValue) Round(places int, mode RoundingMode) *MoneyValue {
+	units, nano := round(mv.GetUnits(), mv.GetNano(), places, mode)
+	return &MoneyValue{Currency: mv.GetCurrency(), Units: units, Nano: nano}
+}
+
+func mismatch(a, b *MoneyValue) error {
+	return fmt.Errorf("%w: %q and %q", ErrCurrencyMismatch, a.GetCurrency(), b.GetCurrency())
+}
+
+// currencyOf - currency of result, nil operand takes currency of the other one
+func currencyOf(a, b *MoneyValue) string {
+	if a.GetCurrency() != "" {
+		return a.GetCurrency()
+	}
+	return b.GetCurrency()
+}
+
+// normalize - carry nano into units so that |nano| < 1e9 and nano has the sign of units, clamped on overflow
+func normalize(units, nano int64) (int64, int32) {
+	v := new(big.Int).Mul(big.NewInt(units), bigNanoScale)
+	return clamp(v.Add(v, big.NewInt(nano)))
+}
+
+func add(au int64, an int32, bu int64, bn int32) (int64, int32, error) {
+	return fromNano(new(big.Int).Add(toNano(au, an), toNano(bu, bn)))
+}
+
+func sub(au int64, an int32, bu int64, bn int32) (int64, int32, error) {
+	return fromNano(new(big.Int).Sub(toNano(au, an), toNano(bu, bn)))
+}
+
+func sign(units int64, nano int32) int {
+	switch {
+	case units > 0 || (units == 0 && nano > 0):
+		return 1
+	case units < 0 || (units == 0 && nano < 0):
+		return -1
+	default:
+		return 0
+	}
+}
+
+func cmp(au int64, an int32, bu int64, bn int32) int {
+	return toNano(au, an).Cmp(toNano(bu, bn))
+}
+
+func toNano(units int64, nano int32) *big.Int {
+	v := new(big.Int).Mul(big.NewInt(units), bigNanoScale)
+	return v.Add(v, big.NewInt(int64(nano)))
+}
+
+func fromNano(v *big.Int) (int64, int32, error) {
+	units, nano := new(big.Int).QuoRem(v, bigNanoScale, new(big.Int))
+	if !units.IsInt64() {
+		return 0, 0, ErrOutOfRange
+	}
+	return units.Int64(), int32(nano.Int64()), nil
+}
+
+// clamp - result of Neg and rounding, clamped to MinQuotation..MaxQuotation on overflow
+func clamp(v *big.Int) (int64, int32) {
+	units, nano, err := fromNano(v)
+	if err == nil {
+		return units, nano
+	}
+	if v.Sign() > 0 {
+		return math.MaxInt64, nanoScale - 1
+	}
+	return math.MinInt64, -(nanoScale - 1)
+}
+
+func mul(au int64, an int32, bu int64, bn int32, mode RoundingMode) (int64, int32, error) {
+	product := new(big.Int).Mul(toNano(au, an), toNano(bu, bn))
+	return fromNano(divRound(product, bigNanoScale, mode))
+}
+
+func div(au int64, an int32, bu int64, bn int32, mode RoundingMode) (int64, int32, error) {
+	divisor := toNano(bu, bn)
+	if divisor.Sign() == 0 {
+		return 0, 0, ErrDivisionByZero
+	}
+	dividend := new(big.Int).Mul(toNano(au, an), bigNanoScale)
+	return fromNano(divRound(dividend, divisor, mode))
+}
+
+func round(units int64, nano int32, places int, mode RoundingMode) (int64, int32) {
+	if places >= 9 {
+		return normalize(units, int64(nano))
+	}
+	if places < 0 {
+		places = 0
+	}
+	step := big.NewInt(int64(math.Pow10(9 - places)))
+	v := divRound(toNano(units, nano), step, mode)
+	return clamp(v.Mul(v, step))
+}
+
+func roundToStep(units int64, nano int32, stepUnits int64, stepNano int32, mode RoundingMode) (int64, int32) {
+	step := toNano(stepUnits, stepNano)
+	if step.Sign() == 0 {
+		return normalize(units, int64(nano))
+	}
+	v := divRound(toNano(units, nano), step, mode)
+	return clamp(v.Mul(v, step))
+}
+
+// divRound - num / den rounded to integer with mode
+func divRound(num, den *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	s := num.Sign() * den.Sign()
+	var away bool
+	switch mode {
+	case RoundDown:
+		away = false
+	case RoundUp:
+		away = true
+	case RoundFloor:
+		away = s < 0
+	case RoundCeiling:
+		away = s > 0
+	default:
+		half := new(big.Int).Abs(r)
+		half.Lsh(half, 1)
+		c := half.Cmp(new(big.Int).Abs(den))
+		away = c > 0 || (c == 0 && (mode == RoundHalfUp || q.Bit(0) == 1))
+	}
+	if away {
+		q.Add(q, big.NewInt(int64(s)))
+	}
+	return q
+}
+
+func parseRat(s string) (*big.Rat, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s, "/xXoObBpP_") {
+		return nil, fmt.Errorf("invalid decimal %q", s)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal %q", s)
+	}
+	return r, nil
+}
+
+func formatDecimal(units int64, nano int32) string {
+	v := toNano(units, nano)
+	var b strings.Builder
+	if v.Sign() < 0 {
+		b.WriteByte('-')
+	}
+	u, n := new(big.Int).QuoRem(v.Abs(v), bigNanoScale, new(big.Int))
+	b.WriteString(u.String())
+	if n.Sign() != 0 {
+		b.WriteByte('.')
+		b.WriteString(strings.TrimRight(fmt.Sprintf("%09d", n.Int64()), "0"))
+	}
+	return b.String()
+}
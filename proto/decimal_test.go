@@ -0,0 +1,234 @@
+package investapi
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func q(t *testing.T, s string) *Quotation {
+	t.Helper()
+	v, err := QuotationFromString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestQuotationFromString(t *testing.T) {
+	tests := []struct {
+		in    string
+		units int64
+		nano  int32
+		err   bool
+	}{
+		{in: "0", units: 0, nano: 0},
+		{in: "123.456", units: 123, nano: 456_000_000},
+		{in: "-0.5", units: 0, nano: -500_000_000},
+		{in: "-12.000000001", units: -12, nano: -1},
+		{in: "1.5e-3", units: 0, nano: 1_500_000},
+		{in: "9223372036854775807.999999999", units: math.MaxInt64, nano: 999_999_999},
+		{in: "0.0000000001", err: true},
+		{in: "9223372036854775808", err: true},
+		{in: "1/2", err: true},
+		{in: "", err: true},
+	}
+	for _, tt := range tests {
+		v, err := QuotationFromString(tt.in)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%q: expected error, got %v", tt.in, v.ToString())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.in, err)
+			continue
+		}
+		if v.GetUnits() != tt.units || v.GetNano() != tt.nano {
+			t.Errorf("%q: got %v/%v, want %v/%v", tt.in, v.GetUnits(), v.GetNano(), tt.units, tt.nano)
+		}
+	}
+	if _, err := QuotationFromString("1e30"); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("1e30: error %v, want ErrOutOfRange", err)
+	}
+}
+
+func TestQuotationArithmetic(t *testing.T) {
+	ok := func(v *Quotation, err error) *Quotation {
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	tests := []struct {
+		name string
+		got  *Quotation
+		want string
+	}{
+		{name: "add", got: ok(q(t, "1.7").Add(q(t, "2.6"))), want: "4.3"},
+		{name: "add opposite signs", got: ok(q(t, "1.2").Add(q(t, "-3.5"))), want: "-2.3"},
+		{name: "add nil", got: ok(q(t, "1.2").Add(nil)), want: "1.2"},
+		{name: "sub", got: ok(q(t, "1.2").Sub(q(t, "3.5"))), want: "-2.3"},
+		{name: "sub nano borrow", got: ok(q(t, "1").Sub(q(t, "0.000000001"))), want: "0.999999999"},
+		{name: "mul", got: ok(q(t, "1.5").Mul(q(t, "-2.25"))), want: "-3.375"},
+		{name: "mul rounding", got: ok(q(t, "0.000000001").Mul(q(t, "0.5"))), want: "0.000000001"},
+		{name: "mul round down", got: ok(q(t, "0.000000001").MulRound(q(t, "0.5"), RoundDown)), want: "0"},
+		{name: "mul int", got: ok(q(t, "12.34").MulInt(-3)), want: "-37.02"},
+		{name: "add max in range", got: ok((&Quotation{Units: math.MaxInt64}).Add(&Quotation{Units: -1})), want: "9223372036854775806"},
+		{name: "neg", got: q(t, "-0.1").Neg(), want: "0.1"},
+		{name: "abs", got: q(t, "-7.25").Abs(), want: "7.25"},
+		{name: "round to step", got: q(t, "101.37").RoundToStep(q(t, "0.05"), RoundHalfUp), want: "101.35"},
+		{name: "round to step down", got: q(t, "-101.39").RoundToStep(q(t, "0.05"), RoundDown), want: "-101.35"},
+		{name: "round to zero step", got: q(t, "101.37").RoundToStep(nil, RoundHalfUp), want: "101.37"},
+		{name: "normalized", got: NewQuotation(1, -1), want: "0.999999999"},
+	}
+	for _, tt := range tests {
+		if got := tt.got.ToString(); got != tt.want {
+			t.Errorf("%v: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestQuotationOverflow(t *testing.T) {
+	maxUnits := &Quotation{Units: math.MaxInt64}
+	minUnits := &Quotation{Units: math.MinInt64}
+	tests := []struct {
+		name string
+		op   func() (*Quotation, error)
+	}{
+		{name: "add", op: func() (*Quotation, error) { return maxUnits.Add(&Quotation{Units: 1}) }},
+		{name: "add nano carry", op: func() (*Quotation, error) { return MaxQuotation.Add(&Quotation{Nano: 1}) }},
+		{name: "sub", op: func() (*Quotation, error) { return minUnits.Sub(&Quotation{Units: 1}) }},
+		{name: "sub min", op: func() (*Quotation, error) { return (&Quotation{}).Sub(minUnits) }},
+		{name: "mul int", op: func() (*Quotation, error) { return maxUnits.MulInt(3) }},
+		{name: "mul int negative", op: func() (*Quotation, error) { return maxUnits.MulInt(-3) }},
+		{name: "mul", op: func() (*Quotation, error) { return maxUnits.Mul(maxUnits) }},
+		{name: "mul round", op: func() (*Quotation, error) { return minUnits.MulRound(q(t, "1.5"), RoundDown) }},
+		{name: "div", op: func() (*Quotation, error) { return maxUnits.Div(q(t, "0.5")) }},
+	}
+	for _, tt := range tests {
+		if got, err := tt.op(); !errors.Is(err, ErrOutOfRange) || got != nil {
+			t.Errorf("%v: got %v, %v, want ErrOutOfRange", tt.name, got.ToString(), err)
+		}
+	}
+}
+
+func TestQuotationOverflowClamps(t *testing.T) {
+	tests := []struct {
+		name string
+		got  *Quotation
+		want *Quotation
+	}{
+		{name: "neg", got: MinQuotation.Neg(), want: MaxQuotation},
+		{name: "round", got: MaxQuotation.Round(0, RoundUp), want: MaxQuotation},
+		{name: "round to step", got: MaxQuotation.RoundToStep(q(t, "10"), RoundUp), want: MaxQuotation},
+		{name: "normalize", got: NewQuotation(math.MaxInt64, 1_000_000_000), want: MaxQuotation},
+	}
+	for _, tt := range tests {
+		if tt.got.GetUnits() != tt.want.GetUnits() || tt.got.GetNano() != tt.want.GetNano() {
+			t.Errorf("%v: got %v, want %v", tt.name, tt.got.ToString(), tt.want.ToString())
+		}
+	}
+}
+
+func TestQuotationRoundingModes(t *testing.T) {
+	tests := []struct {
+		in   string
+		mode RoundingMode
+		want string
+	}{
+		{in: "2.5", mode: RoundHalfUp, want: "3"},
+		{in: "-2.5", mode: RoundHalfUp, want: "-3"},
+		{in: "2.5", mode: RoundHalfEven, want: "2"},
+		{in: "3.5", mode: RoundHalfEven, want: "4"},
+		{in: "-2.5", mode: RoundHalfEven, want: "-2"},
+		{in: "2.49", mode: RoundHalfUp, want: "2"},
+		{in: "2.51", mode: RoundHalfEven, want: "3"},
+		{in: "2.9", mode: RoundDown, want: "2"},
+		{in: "-2.9", mode: RoundDown, want: "-2"},
+		{in: "2.1", mode: RoundUp, want: "3"},
+		{in: "-2.1", mode: RoundUp, want: "-3"},
+		{in: "2.9", mode: RoundFloor, want: "2"},
+		{in: "-2.1", mode: RoundFloor, want: "-3"},
+		{in: "2.1", mode: RoundCeiling, want: "3"},
+		{in: "-2.9", mode: RoundCeiling, want: "-2"},
+		{in: "2", mode: RoundUp, want: "2"},
+	}
+	for _, tt := range tests {
+		if got := q(t, tt.in).Round(0, tt.mode).ToString(); got != tt.want {
+			t.Errorf("Round(%v, %v): got %v, want %v", tt.in, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestQuotationDiv(t *testing.T) {
+	tests := []struct {
+		a, b string
+		mode RoundingMode
+		want string
+	}{
+		{a: "10", b: "4", mode: RoundHalfUp, want: "2.5"},
+		{a: "1", b: "3", mode: RoundHalfUp, want: "0.333333333"},
+		{a: "2", b: "3", mode: RoundHalfUp, want: "0.666666667"},
+		{a: "2", b: "3", mode: RoundDown, want: "0.666666666"},
+		{a: "-1", b: "3", mode: RoundFloor, want: "-0.333333334"},
+		{a: "-1", b: "3", mode: RoundCeiling, want: "-0.333333333"},
+		{a: "0.000000005", b: "10", mode: RoundHalfEven, want: "0"},
+		{a: "0.000000015", b: "10", mode: RoundHalfEven, want: "0.000000002"},
+	}
+	for _, tt := range tests {
+		got, err := q(t, tt.a).DivRound(q(t, tt.b), tt.mode)
+		if err != nil {
+			t.Errorf("%v / %v: %v", tt.a, tt.b, err)
+			continue
+		}
+		if got.ToString() != tt.want {
+			t.Errorf("%v / %v (%v): got %v, want %v", tt.a, tt.b, tt.mode, got.ToString(), tt.want)
+		}
+	}
+	if _, err := q(t, "1").Div(&Quotation{}); !errors.Is(err, ErrDivisionByZero) {
+		t.Errorf("division by zero: error %v, want ErrDivisionByZero", err)
+	}
+}
+
+func TestMoneyValueArithmetic(t *testing.T) {
+	rub := func(s string) *MoneyValue {
+		v, err := MoneyValueFromString(s, "rub")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	sum, err := rub("100.5").Add(rub("0.75"))
+	if err != nil || sum.ToString() != "101.25 rub" {
+		t.Errorf("add: got %v, %v", sum.ToString(), err)
+	}
+	diff, err := rub("1").Sub(rub("1.000000001"))
+	if err != nil || diff.ToString() != "-0.000000001 rub" {
+		t.Errorf("sub: got %v, %v", diff.ToString(), err)
+	}
+	if _, err := rub("1").Add(&MoneyValue{Currency: "usd", Units: 1}); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("add usd: error %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := (&MoneyValue{Currency: "rub", Units: math.MaxInt64}).Add(rub("1")); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("add overflow: error %v, want ErrOutOfRange", err)
+	}
+	if _, err := (&MoneyValue{Currency: "rub", Units: math.MinInt64}).Sub(rub("1")); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("sub overflow: error %v, want ErrOutOfRange", err)
+	}
+	product, err := rub("10.01").Mul(q(t, "3"))
+	if err != nil || product.ToString() != "30.03 rub" {
+		t.Errorf("mul: got %v, %v", product.ToString(), err)
+	}
+	if _, err := rub("10").Mul(&Quotation{Units: math.MaxInt64}); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("mul overflow: error %v, want ErrOutOfRange", err)
+	}
+	ratio, err := rub("1").Ratio(rub("3"), RoundHalfEven)
+	if err != nil || ratio.ToString() != "0.333333333" {
+		t.Errorf("ratio: got %v, %v", ratio.ToString(), err)
+	}
+	if c, err := rub("1.5").Cmp(rub("1.25")); err != nil || c != 1 {
+		t.Errorf("cmp: got %v, %v", c, err)
+	}
+}